@@ -0,0 +1,56 @@
+// audit-verify は監査ログのハッシュチェーンを検証するコマンドです
+// 不整合が見つかった場合は最初の不整合を出力し、終了コード 1 で終了します
+//
+//	go run ./cmd/audit-verify
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/config"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+func main() {
+	// .envファイルを読み込み（開発環境用）
+	_ = godotenv.Load()
+
+	cfg := config.Load()
+
+	dsn := cfg.Database.DSN()
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		log.Fatalf("❌ データベース接続に失敗しました: %v", err)
+	}
+
+	auditLogRepo := repository.NewAuditLogRepository(db, util.NewHashChain(cfg.Audit.HashKey))
+	auditLogService := service.NewAuditLogService(auditLogRepo, repository.NewTxManager(db), nil, nil, nil, zap.NewNop())
+
+	result, err := auditLogService.VerifyChain(context.Background(), 1) // システムユーザー
+	if err != nil {
+		log.Fatalf("❌ 監査ログの検証に失敗しました: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		log.Fatalf("❌ 検証結果の出力に失敗しました: %v", err)
+	}
+
+	if !result.Valid {
+		fmt.Fprintf(os.Stderr, "❌ 監査ログ ID %d で不整合を検出しました: %s\n", result.BrokenLink.ID, result.BrokenLink.Reason)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "✅ %d 件の監査ログを検証しました（末尾 ID %d）\n", result.CheckedCount, result.HeadID)
+}
@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -19,9 +22,16 @@ import (
 	"github.com/varubogu/effisio/backend/internal/config"
 	"github.com/varubogu/effisio/backend/internal/handler"
 	"github.com/varubogu/effisio/backend/internal/middleware"
+	"github.com/varubogu/effisio/backend/internal/model"
 	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/internal/scheduler"
 	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/archivestore"
+	"github.com/varubogu/effisio/backend/pkg/auditsink"
+	"github.com/varubogu/effisio/backend/pkg/geoip"
+	"github.com/varubogu/effisio/backend/pkg/mailer"
 	"github.com/varubogu/effisio/backend/pkg/util"
+	"github.com/varubogu/effisio/backend/pkg/webhook"
 )
 
 func main() {
@@ -52,24 +62,181 @@ func main() {
 
 	// ユーティリティの初期化
 	jwtService := util.NewJWTService(
-		[]byte(cfg.JWT.Secret),
+		cfg.JWT.Secret,
 		cfg.JWT.AccessTokenExpiration,
 		cfg.JWT.RefreshTokenExpiration,
 	)
 
+	// JWTのシークレットが漏れると監査ログを改ざんしたうえでハッシュチェーンを作り直せるため、別の鍵を使う
+	if cfg.Audit.HashKeyFallback {
+		if cfg.Server.Env == "production" {
+			logger.Fatal("❌ AUDIT_HASH_KEY にJWT_SECRETとは別の鍵を設定してください")
+		}
+		logger.Warn("⚠️  監査ログのハッシュチェーンの鍵にJWT_SECRETを使用しています（AUDIT_HASH_KEY を設定してください）")
+	}
+
 	// リポジトリの初期化
 	userRepo := repository.NewUserRepository(db)
 	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
-	auditLogRepo := repository.NewAuditLogRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db, util.NewHashChain(cfg.Audit.HashKey))
+	invitationRepo := repository.NewInvitationRepository(db)
+	userAttributeRepo := repository.NewUserAttributeDefinitionRepository(db)
+	groupRepo := repository.NewGroupRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	auditRetentionPolicyRepo := repository.NewAuditRetentionPolicyRepository(db)
+	auditLogArchiveRepo := repository.NewAuditLogArchiveRepository(db)
+	legalHoldRepo := repository.NewLegalHoldRepository(db)
+	securityAlertRepo := repository.NewSecurityAlertRepository(db)
+
+	// メール送信の初期化
+	mail := initMailer(cfg, logger)
 
 	// サービスの初期化
 	// AuditLogServiceは最初に初期化（他のサービスで使用されるため）
-	auditLogService := service.NewAuditLogService(auditLogRepo, logger)
+	var auditLogWriter *service.AuditLogWriter
+	if cfg.Audit.AsyncEnabled {
+		auditLogWriter = service.NewAuditLogWriter(auditLogRepo, service.AuditLogWriterConfig{
+			QueueSize:     cfg.Audit.QueueSize,
+			BatchSize:     cfg.Audit.BatchSize,
+			FlushInterval: cfg.Audit.FlushInterval,
+			Backpressure:  cfg.Audit.Backpressure,
+			BlockTimeout:  cfg.Audit.BlockTimeout,
+		}, logger)
+	}
+	auditSinks, err := initAuditSinks(cfg, logger)
+	if err != nil {
+		logger.Fatal("❌ 監査ログの転送先の初期化に失敗しました", zap.Error(err))
+	}
+	// 訴訟ホールドの判定は監査ログの削除・匿名化を行うすべてのサービスで共有する
+	legalHoldChecker := service.NewLegalHoldChecker(legalHoldRepo, userRepo)
+	auditLogService := service.NewAuditLogService(auditLogRepo, repository.NewTxManager(db), auditLogWriter, auditSinks, legalHoldChecker, logger)
+	// 転送先の登録後に書き込みを開始する
+	if auditSinks != nil {
+		auditSinks.Start()
+	}
+	if auditLogWriter != nil {
+		auditLogWriter.Start()
+	}
 
 	// 他のサービスの初期化（AuditLogServiceを注入）
-	userService := service.NewUserService(userRepo, logger, auditLogService)
-	authService := service.NewAuthService(userRepo, refreshTokenRepo, jwtService, logger, auditLogService)
+	emailVerificationService := service.NewEmailVerificationService(
+		userRepo,
+		jwtService,
+		mail,
+		cfg.User.EmailVerificationTTL,
+		cfg.User.EmailVerificationURL,
+		logger,
+		auditLogService,
+	)
+	userAttributeService := service.NewUserAttributeService(userAttributeRepo, logger, auditLogService)
+	groupService := service.NewGroupService(groupRepo, userRepo, logger, auditLogService)
+	webhookService := service.NewWebhookService(
+		webhookRepo,
+		webhook.NewSender(cfg.Webhook.Timeout),
+		service.WebhookDeliveryConfig{
+			BatchSize:     cfg.Webhook.BatchSize,
+			Timeout:       cfg.Webhook.Timeout,
+			MaxAttempts:   cfg.Webhook.MaxAttempts,
+			RetryInterval: cfg.Webhook.RetryInterval,
+			MaxRetryDelay: cfg.Webhook.MaxRetryDelay,
+		},
+		logger,
+		auditLogService,
+	)
+	userService := service.NewUserService(userRepo, logger, auditLogService, emailVerificationService, userAttributeService, groupService, webhookService, legalHoldChecker)
+	var loginDetector *service.LoginDetector
+	if cfg.Security.DetectionEnabled {
+		geo, err := initGeoIP(cfg, logger)
+		if err != nil {
+			logger.Fatal("❌ GeoIPデータベースの読み込みに失敗しました", zap.Error(err))
+		}
+		loginDetector = service.NewLoginDetector(
+			securityAlertRepo,
+			auditLogRepo,
+			userRepo,
+			refreshTokenRepo,
+			geo,
+			service.LoginDetectionConfig{
+				HistoryWindow:       cfg.Security.HistoryWindow,
+				AlertDedupWindow:    cfg.Security.AlertDedupWindow,
+				SprayingWindow:      cfg.Security.SprayingWindow,
+				SprayingThreshold:   cfg.Security.SprayingThreshold,
+				IPLockoutDuration:   cfg.Security.IPLockoutDuration,
+				NewIPEnabled:        cfg.Security.NewIPEnabled,
+				NewUserAgentEnabled: cfg.Security.NewUserAgentEnabled,
+				TravelMaxSpeedKmh:   float64(cfg.Security.TravelMaxSpeedKmh),
+				TravelMinDistanceKm: float64(cfg.Security.TravelMinDistanceKm),
+				Actions: map[string][]string{
+					model.SecurityRulePasswordSpraying: cfg.Security.SprayingActions,
+					model.SecurityRuleNewIP:            cfg.Security.NewIPActions,
+					model.SecurityRuleNewUserAgent:     cfg.Security.NewUserAgentActions,
+					model.SecurityRuleImpossibleTravel: cfg.Security.TravelActions,
+				},
+			},
+			logger,
+			auditLogService,
+			webhookService,
+		)
+	} else {
+		logger.Info("⏸  不審なログインの検知は無効です（SECURITY_DETECTION_ENABLED=false）")
+	}
+	authService := service.NewAuthService(userRepo, refreshTokenRepo, jwtService, logger, auditLogService, groupService, webhookService, loginDetector)
 	dashboardService := service.NewDashboardService(userRepo, logger)
+	privacyService := service.NewPrivacyService(userRepo, refreshTokenRepo, auditLogRepo, legalHoldChecker, logger, auditLogService)
+	invitationService := service.NewInvitationService(
+		userRepo,
+		invitationRepo,
+		jwtService,
+		mail,
+		cfg.User.InvitationTTL,
+		cfg.User.InvitationURL,
+		logger,
+		auditLogService,
+	)
+	userLifecycleService := service.NewUserLifecycleService(userRepo, mail, logger, auditLogService)
+	auditRetentionService := service.NewAuditRetentionService(
+		auditRetentionPolicyRepo,
+		auditLogRepo,
+		legalHoldChecker,
+		service.AuditRetentionConfig{
+			MinRetentionDays: cfg.Audit.RetentionMinDays,
+			BatchSize:        cfg.Audit.RetentionBatchSize,
+			BatchPause:       cfg.Audit.RetentionBatchPause,
+		},
+		logger,
+		auditLogService,
+	)
+	archiveStore, err := initArchiveStore(cfg)
+	if err != nil {
+		logger.Fatal("❌ 監査ログのアーカイブの保存先の初期化に失敗しました", zap.Error(err))
+	}
+	auditArchiveService := service.NewAuditArchiveService(
+		auditLogArchiveRepo,
+		auditLogRepo,
+		archiveStore,
+		legalHoldChecker,
+		service.AuditArchiveConfig{
+			ArchiveAfterDays: cfg.Audit.ArchiveAfterDays,
+			SegmentSize:      cfg.Audit.ArchiveSegmentSize,
+			MaxSegments:      cfg.Audit.ArchiveMaxSegments,
+			SegmentPause:     cfg.Audit.ArchiveSegmentPause,
+		},
+		logger,
+		auditLogService,
+	)
+	auditPartitionService := service.NewAuditPartitionService(
+		auditLogRepo,
+		legalHoldChecker,
+		service.AuditPartitionConfig{
+			PremakeMonths:   cfg.Audit.PartitionPremakeMonths,
+			RetentionMonths: cfg.Audit.PartitionRetentionMonths,
+			DropDetached:    cfg.Audit.PartitionDropDetached,
+		},
+		logger,
+		auditLogService,
+	)
+	legalHoldService := service.NewLegalHoldService(legalHoldRepo, userRepo, logger, auditLogService)
+	securityAlertService := service.NewSecurityAlertService(securityAlertRepo, logger, auditLogService)
 
 	// ハンドラーの初期化
 	healthHandler := handler.NewHealthHandler(logger)
@@ -77,13 +244,63 @@ func main() {
 	authHandler := handler.NewAuthHandler(authService, logger)
 	dashboardHandler := handler.NewDashboardHandler(dashboardService, logger)
 	auditLogHandler := handler.NewAuditLogHandler(auditLogService, logger)
+	privacyHandler := handler.NewPrivacyHandler(privacyService, logger)
+	invitationHandler := handler.NewInvitationHandler(invitationService, logger)
+	emailVerificationHandler := handler.NewEmailVerificationHandler(emailVerificationService, logger)
+	userAttributeHandler := handler.NewUserAttributeHandler(userAttributeService, logger)
+	groupHandler := handler.NewGroupHandler(groupService, logger)
+	webhookHandler := handler.NewWebhookHandler(webhookService, logger)
+	auditRetentionHandler := handler.NewAuditRetentionHandler(auditRetentionService, logger)
+	auditArchiveHandler := handler.NewAuditArchiveHandler(auditArchiveService, logger)
+	auditPartitionHandler := handler.NewAuditPartitionHandler(auditPartitionService, logger)
+	legalHoldHandler := handler.NewLegalHoldHandler(legalHoldService, logger)
+	securityAlertHandler := handler.NewSecurityAlertHandler(securityAlertService, logger)
+	auditLogBroker := service.NewAuditLogBroker(cfg.Audit.StreamMaxClients)
+	auditLogStreamService := service.NewAuditLogStreamService(auditLogRepo, auditLogBroker, cfg.Audit.StreamBatchSize, logger)
+	auditLogStreamHandler := handler.NewAuditLogStreamHandler(auditLogStreamService, cfg.Audit.StreamHeartbeat, cfg.Audit.StreamWriteTimeout, logger)
 
 	// ミドルウェアの初期化
 	authMiddleware := middleware.NewAuthMiddleware(jwtService, logger)
-	rbacMiddleware := middleware.NewRBACMiddleware(logger)
+	auditMiddleware := middleware.NewAuditMiddleware(auditLogService, middleware.AuditAccessConfig{
+		ReadEnabled:         cfg.Audit.ReadEnabled,
+		DenialEnabled:       cfg.Audit.DenialEnabled,
+		ReadDedupWindow:     cfg.Audit.ReadDedupWindow,
+		ReadDedupMaxEntries: cfg.Audit.ReadDedupMaxEntries,
+	}, logger)
+	rbacMiddleware := middleware.NewRBACMiddleware(logger, auditMiddleware)
+
+	// バックグラウンドジョブの開始
+	jobCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	schedulerDone := make(chan struct{})
+	if cfg.Scheduler.Enabled {
+		sched, err := initScheduler(db, cfg, userService, userLifecycleService, webhookService, auditRetentionService, auditArchiveService, auditPartitionService, logger)
+		if err != nil {
+			logger.Fatal("❌ スケジューラの初期化に失敗しました", zap.Error(err))
+		}
+		go func() {
+			defer close(schedulerDone)
+			sched.Start(jobCtx)
+		}()
+	} else {
+		logger.Info("⏸  スケジューラは無効です（SCHEDULER_ENABLED=false）")
+		close(schedulerDone)
+	}
+
+	// 監査ログの追加通知の受信（全レプリカで受信し、各レプリカに接続中のストリームへ知らせる）
+	if cfg.Audit.StreamEnabled {
+		listener, err := repository.NewAuditLogListener(cfg.Database.DSN(), logger)
+		if err != nil {
+			// 通知を受信できない場合もハートビートごとの確認で配信は継続する
+			logger.Warn("⚠️  監査ログの通知を受信できません。ハートビートごとに確認します", zap.Error(err))
+		} else {
+			defer listener.Close()
+			go listener.Run(jobCtx, auditLogBroker.Notify)
+		}
+	}
 
 	// Ginルーターの設定
-	router := setupRouter(cfg, logger, healthHandler, userHandler, authHandler, dashboardHandler, auditLogHandler, authMiddleware, rbacMiddleware)
+	router := setupRouter(cfg, logger, healthHandler, userHandler, authHandler, dashboardHandler, auditLogHandler, privacyHandler, invitationHandler, emailVerificationHandler, userAttributeHandler, groupHandler, webhookHandler, auditLogStreamHandler, auditRetentionHandler, auditArchiveHandler, auditPartitionHandler, legalHoldHandler, securityAlertHandler, authMiddleware, rbacMiddleware, auditMiddleware)
 
 	// HTTPサーバーの設定
 	srv := &http.Server{
@@ -93,6 +310,8 @@ func main() {
 		WriteTimeout:   cfg.Server.WriteTimeout,
 		MaxHeaderBytes: 1 << 20, // 1 MB
 	}
+	// シャットダウン時に接続中のストリームを終了させる
+	srv.RegisterOnShutdown(auditLogBroker.Close)
 
 	// グレースフルシャットダウンの設定
 	go func() {
@@ -115,10 +334,36 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// バックグラウンドジョブを停止（実行中のジョブの終了とリーダーロックの解放を待つ）
+	stopJobs()
+	select {
+	case <-schedulerDone:
+	case <-ctx.Done():
+		logger.Warn("⚠️  バックグラウンドジョブの停止を待たずに終了します")
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		logger.Fatal("❌ サーバーのシャットダウンに失敗しました", zap.Error(err))
 	}
 
+	// キューに残っている監査ログを書き込んでから終了する
+	if auditLogWriter != nil {
+		drainCtx, drainCancel := context.WithTimeout(context.Background(), cfg.Audit.DrainTimeout)
+		defer drainCancel()
+		if err := auditLogWriter.Close(drainCtx); err != nil {
+			logger.Error("❌ 監査ログの書き込みが完了しませんでした", zap.Error(err))
+		}
+	}
+
+	// 書き込んだ監査ログの転送を終えてから終了する
+	if auditSinks != nil {
+		sinkCtx, sinkCancel := context.WithTimeout(context.Background(), cfg.Audit.DrainTimeout)
+		defer sinkCancel()
+		if err := auditSinks.Close(sinkCtx); err != nil {
+			logger.Error("❌ 監査ログの転送が完了しませんでした", zap.Error(err))
+		}
+	}
+
 	logger.Info("✅ サーバーが正常に終了しました")
 }
 
@@ -133,15 +378,7 @@ func initLogger() (*zap.Logger, error) {
 
 // initDB はデータベース接続を初期化します
 func initDB(cfg *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.User,
-		cfg.Database.Password,
-		cfg.Database.Name,
-		cfg.Database.SSLMode,
-	)
+	dsn := cfg.Database.DSN()
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
@@ -161,6 +398,236 @@ func initDB(cfg *config.Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// initAuditSinks は設定に応じて監査ログの転送先（syslog、ファイル）を初期化します
+// 転送先が1つもない場合は nil を返します
+func initAuditSinks(cfg *config.Config, logger *zap.Logger) (*auditsink.Dispatcher, error) {
+	dispatcher := auditsink.NewDispatcher(auditsink.Config{
+		BufferSize:    cfg.Audit.SinkBufferSize,
+		MaxRetries:    cfg.Audit.SinkMaxRetries,
+		RetryInterval: cfg.Audit.SinkRetryInterval,
+	}, logger)
+
+	if cfg.Audit.SyslogEnabled {
+		syslogConfig := auditsink.SyslogConfig{
+			Network: cfg.Audit.SyslogNetwork,
+			Address: cfg.Audit.SyslogAddress,
+			Format:  cfg.Audit.SyslogFormat,
+		}
+		if cfg.Audit.SyslogCAFile != "" {
+			pem, err := os.ReadFile(cfg.Audit.SyslogCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read syslog CA file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, errors.New("no certificates found in syslog CA file")
+			}
+			syslogConfig.TLSConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+		}
+		sink, err := auditsink.NewSyslogSink(syslogConfig)
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.Add(sink, auditsink.Filter{
+			Actions:       cfg.Audit.SyslogActions,
+			ResourceTypes: cfg.Audit.SyslogResourceTypes,
+			Statuses:      cfg.Audit.SyslogStatuses,
+		})
+		logger.Info("Audit events are forwarded to syslog",
+			zap.String("network", cfg.Audit.SyslogNetwork),
+			zap.String("address", cfg.Audit.SyslogAddress),
+			zap.String("format", cfg.Audit.SyslogFormat),
+		)
+	}
+
+	if cfg.Audit.FileEnabled {
+		sink, err := auditsink.NewFileSink(cfg.Audit.FilePath, int64(cfg.Audit.FileMaxSizeMB)<<20, cfg.Audit.FileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		dispatcher.Add(sink, auditsink.Filter{
+			Actions:       cfg.Audit.FileActions,
+			ResourceTypes: cfg.Audit.FileResourceTypes,
+			Statuses:      cfg.Audit.FileStatuses,
+		})
+		logger.Info("Audit events are written to file", zap.String("path", cfg.Audit.FilePath))
+	}
+
+	if dispatcher.Len() == 0 {
+		return nil, nil
+	}
+	return dispatcher, nil
+}
+
+// initArchiveStore は設定に応じて監査ログのアーカイブの保存先（ローカル、S3互換ストレージ）を初期化します
+func initArchiveStore(cfg *config.Config) (archivestore.Store, error) {
+	switch cfg.Audit.ArchiveStorage {
+	case "local":
+		return archivestore.NewLocalStore(cfg.Audit.ArchiveDir)
+	case "s3":
+		return archivestore.NewS3Store(archivestore.S3Config{
+			Endpoint:  cfg.Audit.ArchiveS3Endpoint,
+			Region:    cfg.Audit.ArchiveS3Region,
+			Bucket:    cfg.Audit.ArchiveS3Bucket,
+			Prefix:    cfg.Audit.ArchiveS3Prefix,
+			AccessKey: cfg.Audit.ArchiveS3AccessKey,
+			SecretKey: cfg.Audit.ArchiveS3SecretKey,
+			PathStyle: cfg.Audit.ArchiveS3PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("unknown audit archive storage %q", cfg.Audit.ArchiveStorage)
+	}
+}
+
+// initMailer は設定に応じたメール送信手段を初期化します
+func initMailer(cfg *config.Config, logger *zap.Logger) mailer.Mailer {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mailer.NewSMTPMailer(
+			cfg.Mail.SMTPHost,
+			cfg.Mail.SMTPPort,
+			cfg.Mail.SMTPUsername,
+			cfg.Mail.SMTPPassword,
+			cfg.Mail.From,
+		)
+	default:
+		if cfg.Server.Env == "production" {
+			logger.Warn("⚠️  メールは送信されずログに出力されます（MAIL_DRIVER=smtp を設定してください）")
+		}
+		return mailer.NewLogMailer(logger)
+	}
+}
+
+// initGeoIP は移動の判定に使うGeoIPデータベースを読み込みます（未設定の場合は nil）
+func initGeoIP(cfg *config.Config, logger *zap.Logger) (*geoip.DB, error) {
+	if cfg.Security.GeoIPFile == "" {
+		logger.Info("⏸  GeoIPデータベースが未設定のため、移動の判定は無効です（SECURITY_GEOIP_FILE）")
+		return nil, nil
+	}
+	db, err := geoip.Open(cfg.Security.GeoIPFile)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("GeoIP database loaded", zap.String("path", cfg.Security.GeoIPFile), zap.Int("networks", db.Len()))
+	return db, nil
+}
+
+// initScheduler はバックグラウンドジョブのスケジューラを初期化します
+// 複数のレプリカで起動しても、アドバイザリーロックを取得した1台のみがジョブを実行します
+func initScheduler(
+	db *gorm.DB,
+	cfg *config.Config,
+	userService *service.UserService,
+	userLifecycleService *service.UserLifecycleService,
+	webhookService *service.WebhookService,
+	auditRetentionService *service.AuditRetentionService,
+	auditArchiveService *service.AuditArchiveService,
+	auditPartitionService *service.AuditPartitionService,
+	logger *zap.Logger,
+) (*scheduler.Scheduler, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	lock := scheduler.NewAdvisoryLock(sqlDB, cfg.Scheduler.LockKey)
+	sched := scheduler.NewScheduler(lock, cfg.Scheduler.RetryInterval, cfg.Scheduler.CheckInterval, logger)
+
+	// 保持期間を過ぎた削除済みユーザーの物理削除
+	sched.Register(scheduler.Job{
+		Name:     "user_purge",
+		Interval: cfg.User.PurgeInterval,
+		Run: func(ctx context.Context) error {
+			_, err := userService.PurgeDeleted(ctx, model.SystemUserID, cfg.User.DeletedRetention)
+			return err
+		},
+	})
+
+	// 休眠ユーザーへの予告と無効化（期間が0の場合は無効）
+	if cfg.User.DormantAfter > 0 {
+		sched.Register(scheduler.Job{
+			Name:     "user_dormant",
+			Interval: cfg.User.LifecycleInterval,
+			Run: func(ctx context.Context) error {
+				_, _, err := userLifecycleService.ProcessDormant(ctx, cfg.User.DormantAfter, cfg.User.DormantWarningBefore)
+				return err
+			},
+		})
+	}
+
+	// 利用期限を過ぎたユーザーの無効化
+	sched.Register(scheduler.Job{
+		Name:     "user_expiry",
+		Interval: cfg.User.LifecycleInterval,
+		Run: func(ctx context.Context) error {
+			_, err := userLifecycleService.ExpireAccounts(ctx)
+			return err
+		},
+	})
+
+	// Webhookの送信と再試行（無効の場合は配信がキューに残り、有効にした時点で送信される）
+	if cfg.Webhook.Enabled {
+		sched.Register(scheduler.Job{
+			Name:     "webhook_delivery",
+			Interval: cfg.Webhook.PollInterval,
+			Run: func(ctx context.Context) error {
+				_, err := webhookService.ProcessDeliveries(ctx)
+				return err
+			},
+		})
+	}
+
+	// 保持期間ポリシーに従った監査ログの削除
+	if cfg.Audit.RetentionEnabled {
+		sched.Register(scheduler.Job{
+			Name:     "audit_retention",
+			Interval: cfg.Audit.RetentionInterval,
+			Run: func(ctx context.Context) error {
+				_, err := auditRetentionService.Run(ctx, 1, false) // システムユーザー
+				return err
+			},
+		})
+	}
+
+	// 古い監査ログのアーカイブと削除
+	if cfg.Audit.ArchiveEnabled {
+		sched.Register(scheduler.Job{
+			Name:     "audit_archive",
+			Interval: cfg.Audit.ArchiveInterval,
+			Run: func(ctx context.Context) error {
+				result, err := auditArchiveService.Run(ctx, 1) // システムユーザー
+				if err != nil {
+					return err
+				}
+				if result.Error != "" {
+					return errors.New(result.Error)
+				}
+				return nil
+			},
+		})
+	}
+
+	// 監査ログのパーティションの事前作成と切り離し
+	if cfg.Audit.PartitionEnabled {
+		sched.Register(scheduler.Job{
+			Name:     "audit_partition",
+			Interval: cfg.Audit.PartitionInterval,
+			Run: func(ctx context.Context) error {
+				result, err := auditPartitionService.Maintain(ctx, 1) // システムユーザー
+				if err != nil {
+					return err
+				}
+				if result.Error != "" {
+					return errors.New(result.Error)
+				}
+				return nil
+			},
+		})
+	}
+
+	return sched, nil
+}
+
 // setupRouter はGinルーターを設定します
 func setupRouter(
 	cfg *config.Config,
@@ -170,8 +637,21 @@ func setupRouter(
 	authHandler *handler.AuthHandler,
 	dashboardHandler *handler.DashboardHandler,
 	auditLogHandler *handler.AuditLogHandler,
+	privacyHandler *handler.PrivacyHandler,
+	invitationHandler *handler.InvitationHandler,
+	emailVerificationHandler *handler.EmailVerificationHandler,
+	userAttributeHandler *handler.UserAttributeHandler,
+	groupHandler *handler.GroupHandler,
+	webhookHandler *handler.WebhookHandler,
+	auditLogStreamHandler *handler.AuditLogStreamHandler,
+	auditRetentionHandler *handler.AuditRetentionHandler,
+	auditArchiveHandler *handler.AuditArchiveHandler,
+	auditPartitionHandler *handler.AuditPartitionHandler,
+	legalHoldHandler *handler.LegalHoldHandler,
+	securityAlertHandler *handler.SecurityAlertHandler,
 	authMiddleware *middleware.AuthMiddleware,
 	rbacMiddleware *middleware.RBACMiddleware,
+	auditMiddleware *middleware.AuditMiddleware,
 ) *gin.Engine {
 	// 本番環境ではリリースモードに設定
 	if cfg.Server.Env == "production" {
@@ -213,16 +693,155 @@ func setupRouter(
 		{
 			// 一覧取得と詳細取得は全ての認証済みユーザーが可能
 			users.GET("", userHandler.List)
-			users.GET("/:id", userHandler.GetByID)
+			users.GET("/:id", auditMiddleware.AuditReadOfOthers(model.ResourceTypeUser, "id"), userHandler.GetByID)
+
+			// 削除済みユーザーの一覧取得と復元は users:delete 権限が必要
+			users.GET("/deleted", rbacMiddleware.RequirePermission("users:delete"), userHandler.ListDeleted)
+			users.POST("/:id/restore", rbacMiddleware.RequirePermission("users:delete"), userHandler.Restore)
 
-			// 作成は admin のみ
-			users.POST("", rbacMiddleware.RequireRole("admin"), userHandler.Create)
+			// 個人データの開示・匿名化は users:privacy 権限が必要
+			users.GET("/:id/personal-data", rbacMiddleware.RequirePermission("users:privacy"), privacyHandler.Export)
+			users.POST("/:id/anonymize", rbacMiddleware.RequirePermission("users:privacy"), privacyHandler.Anonymize)
 
-			// 更新は admin と manager のみ
-			users.PUT("/:id", rbacMiddleware.RequireAnyRole("admin", "manager"), userHandler.Update)
+			// 作成と招待は users:create 権限が必要
+			users.POST("", rbacMiddleware.RequirePermission("users:create"), userHandler.Create)
+			users.POST("/invite", rbacMiddleware.RequirePermission("users:create"), invitationHandler.Invite)
 
-			// 削除は admin のみ
-			users.DELETE("/:id", rbacMiddleware.RequireRole("admin"), userHandler.Delete)
+			// 更新は users:write 権限が必要（admin と manager）
+			users.PUT("/:id", rbacMiddleware.RequirePermission("users:write"), userHandler.Update)
+			users.PATCH("/:id", rbacMiddleware.RequirePermission("users:write"), userHandler.Patch)
+			users.POST("/:id/email-verification/resend", rbacMiddleware.RequirePermission("users:write"), emailVerificationHandler.Resend)
+
+			// 削除は users:delete 権限が必要
+			users.DELETE("/:id", rbacMiddleware.RequirePermission("users:delete"), userHandler.Delete)
+		}
+
+		// ユーザー追加属性の定義（参照は全ての認証済みユーザー、変更は settings:write 権限が必要）
+		userAttributes := api.Group("/user-attributes")
+		userAttributes.Use(authMiddleware.RequireAuth())
+		{
+			userAttributes.GET("", userAttributeHandler.List)
+			userAttributes.POST("", rbacMiddleware.RequirePermission("settings:write"), userAttributeHandler.Create)
+			userAttributes.PATCH("/:id", rbacMiddleware.RequirePermission("settings:write"), userAttributeHandler.Update)
+			userAttributes.DELETE("/:id", rbacMiddleware.RequirePermission("settings:write"), userAttributeHandler.Delete)
+		}
+
+		// グループ関連（参照は全ての認証済みユーザー、変更とメンバー管理は groups:write 権限が必要）
+		groups := api.Group("/groups")
+		groups.Use(authMiddleware.RequireAuth())
+		{
+			groups.GET("", groupHandler.List)
+			groups.GET("/:id", groupHandler.GetByID)
+			groups.GET("/:id/members", groupHandler.ListMembers)
+
+			groups.POST("", rbacMiddleware.RequirePermission("groups:write"), groupHandler.Create)
+			groups.PATCH("/:id", rbacMiddleware.RequirePermission("groups:write"), groupHandler.Update)
+			groups.DELETE("/:id", rbacMiddleware.RequirePermission("groups:write"), groupHandler.Delete)
+			groups.POST("/:id/members", rbacMiddleware.RequirePermission("groups:write"), groupHandler.AddMembers)
+			groups.DELETE("/:id/members/:user_id", rbacMiddleware.RequirePermission("groups:write"), groupHandler.RemoveMember)
+		}
+
+		// Webhook関連（webhooks:manage 権限が必要）
+		webhooks := api.Group("/webhooks")
+		webhooks.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("webhooks:manage"))
+		{
+			webhooks.GET("", webhookHandler.List)
+			webhooks.POST("", webhookHandler.Create)
+			webhooks.GET("/:id", webhookHandler.GetByID)
+			webhooks.PATCH("/:id", webhookHandler.Update)
+			webhooks.DELETE("/:id", webhookHandler.Delete)
+
+			// 配信ログとデッドレターの一括再送
+			webhooks.GET("/:id/deliveries", webhookHandler.ListDeliveries)
+			webhooks.POST("/:id/deliveries/replay", webhookHandler.ReplayDeadDeliveries)
+		}
+
+		// Webhook配信の詳細と再送（webhooks:manage 権限が必要）
+		webhookDeliveries := api.Group("/webhook-deliveries")
+		webhookDeliveries.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("webhooks:manage"))
+		{
+			webhookDeliveries.GET("/:id", webhookHandler.GetDelivery)
+			webhookDeliveries.POST("/:id/replay", webhookHandler.ReplayDelivery)
+		}
+
+		// 監査ログの保持期間ポリシー（audit_logs:manage 権限が必要）
+		auditRetention := api.Group("/audit-retention-policies")
+		auditRetention.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("audit_logs:manage"))
+		{
+			auditRetention.GET("", auditRetentionHandler.List)
+			auditRetention.POST("", auditRetentionHandler.Create)
+			auditRetention.GET("/dry-run", auditRetentionHandler.DryRun)
+			auditRetention.POST("/run", auditRetentionHandler.Run)
+			auditRetention.GET("/:id", auditRetentionHandler.GetByID)
+			auditRetention.PATCH("/:id", auditRetentionHandler.Update)
+			auditRetention.DELETE("/:id", auditRetentionHandler.Delete)
+		}
+
+		// 監査ログのアーカイブ（audit_logs:manage 権限が必要）
+		auditArchives := api.Group("/audit-archives")
+		auditArchives.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("audit_logs:manage"))
+		{
+			auditArchives.GET("", auditArchiveHandler.List)
+			auditArchives.POST("/run", auditArchiveHandler.Run)
+			auditArchives.GET("/:id", auditArchiveHandler.GetByID)
+			auditArchives.POST("/:id/import", auditArchiveHandler.Import)
+			auditArchives.DELETE("/:id/import", auditArchiveHandler.DeleteImported)
+			auditArchives.GET("/:id/logs", auditArchiveHandler.ListImported)
+		}
+
+		// 監査ログのパーティション（audit_logs:manage 権限が必要）
+		auditPartitions := api.Group("/audit-partitions")
+		auditPartitions.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("audit_logs:manage"))
+		{
+			auditPartitions.GET("", auditPartitionHandler.List)
+			auditPartitions.POST("/maintain", auditPartitionHandler.Maintain)
+		}
+
+		// 訴訟ホールド（legal_holds:manage 権限が必要）
+		legalHolds := api.Group("/legal-holds")
+		legalHolds.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("legal_holds:manage"))
+		{
+			legalHolds.GET("", legalHoldHandler.List)
+			legalHolds.GET("/:id", legalHoldHandler.GetByID)
+			legalHolds.POST("", legalHoldHandler.Place)
+			legalHolds.POST("/:id/release", legalHoldHandler.Release)
+		}
+
+		// セキュリティアラート（security_alerts:manage 権限が必要）
+		securityAlerts := api.Group("/security-alerts")
+		securityAlerts.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("security_alerts:manage"))
+		{
+			securityAlerts.GET("", securityAlertHandler.List)
+			securityAlerts.GET("/:id", securityAlertHandler.GetByID)
+			securityAlerts.POST("/:id/acknowledge", securityAlertHandler.Acknowledge)
+		}
+
+		// 招待関連
+		invitations := api.Group("/invitations")
+		{
+			// 受諾は招待されたユーザーが行うため認証不要（招待トークンで検証）
+			invitations.POST("/accept", invitationHandler.Accept)
+
+			// 一覧取得・再送・取り消しは users:create 権限が必要
+			admin := invitations.Group("")
+			admin.Use(authMiddleware.RequireAuth(), rbacMiddleware.RequirePermission("users:create"))
+			{
+				admin.GET("", invitationHandler.List)
+				admin.POST("/:id/resend", invitationHandler.Resend)
+				admin.POST("/:id/revoke", invitationHandler.Revoke)
+			}
+		}
+
+		// メールアドレス確認（確認リンクから開かれるため認証不要、確認トークンで検証）
+		api.POST("/email/verify", emailVerificationHandler.Confirm)
+
+		// ログイン中のユーザー自身の情報（認証が必要）
+		me := api.Group("/me")
+		me.Use(authMiddleware.RequireAuth())
+		{
+			me.GET("", userHandler.GetMe)
+			me.PATCH("", userHandler.UpdateMe)
+			me.POST("/email-verification/resend", emailVerificationHandler.ResendMe)
 		}
 
 		// ダッシュボード関連（認証が必要）
@@ -232,24 +851,33 @@ func setupRouter(
 			dashboard.GET("/overview", dashboardHandler.Overview)
 		}
 
-		// 監査ログ関連（認証が必要、admin のみ）
+		// 監査ログ関連（認証が必要、管理操作は audit_logs:manage 権限が必要）
 		auditLogs := api.Group("/audit-logs")
 		auditLogs.Use(authMiddleware.RequireAuth()) // 全ての監査ログエンドポイントで認証が必要
 		{
 			// 一覧取得は全ての認証済みユーザーが可能
-			auditLogs.GET("", auditLogHandler.List)
-			auditLogs.GET("/:id", auditLogHandler.GetByID)
-			auditLogs.GET("/user/:user_id", auditLogHandler.ListByUserID)
-			auditLogs.GET("/resource", auditLogHandler.ListByResource)
-			auditLogs.GET("/action", auditLogHandler.ListByAction)
-			auditLogs.GET("/date-range", auditLogHandler.ListByDateRange)
-			auditLogs.GET("/statistics", auditLogHandler.GetStatistics)
+			// GET /audit-logs と stream は audit_logs:manage 権限がない場合は自身が実行した監査ログのみ
+			auditLogs.GET("", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, ""), auditLogHandler.List)
+			auditLogs.GET("/:id", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, "id"), auditLogHandler.GetByID)
+			auditLogs.GET("/user/:user_id", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, ""), auditLogHandler.ListByUserID)
+			auditLogs.GET("/resource", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, ""), auditLogHandler.ListByResource)
+			auditLogs.GET("/action", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, ""), auditLogHandler.ListByAction)
+			auditLogs.GET("/date-range", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, ""), auditLogHandler.ListByDateRange)
+			auditLogs.GET("/statistics", auditMiddleware.AuditRead(model.ResourceTypeAuditLog, ""), auditLogHandler.GetStatistics)
+			if cfg.Audit.StreamEnabled {
+				auditLogs.GET("/stream", auditLogStreamHandler.Stream)
+			}
+
+			// 改ざん検証と書き込み状況（audit_logs:manage 権限が必要）
+			auditLogs.GET("/verify", rbacMiddleware.RequirePermission("audit_logs:manage"), auditLogHandler.VerifyChain)
+			auditLogs.GET("/writer-stats", rbacMiddleware.RequirePermission("audit_logs:manage"), auditLogHandler.WriterStats)
+			auditLogs.GET("/sink-stats", rbacMiddleware.RequirePermission("audit_logs:manage"), auditLogHandler.SinkStats)
 
 			// 作成（内部使用）
 			auditLogs.POST("", auditLogHandler.Create)
 
-			// 削除（admin のみ）
-			auditLogs.DELETE("/delete-old", rbacMiddleware.RequireRole("admin"), auditLogHandler.DeleteOldLogs)
+			// 削除（audit_logs:manage 権限が必要）
+			auditLogs.DELETE("/delete-old", rbacMiddleware.RequirePermission("audit_logs:manage"), auditLogHandler.DeleteOldLogs)
 		}
 	}
 
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/config"
+	"github.com/varubogu/effisio/backend/internal/handler"
+	"github.com/varubogu/effisio/backend/internal/middleware"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// newTestRouter は権限チェックの検証に必要なハンドラーだけを用意したルーターを返します
+// グループ作成はリクエストボディの検証で止まるため、サービスは不要です
+func newTestRouter(jwtService *util.JWTService) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	logger := zap.NewNop()
+	audit := middleware.NewAuditMiddleware(nil, middleware.AuditAccessConfig{}, logger)
+
+	return setupRouter(
+		&config.Config{},
+		logger,
+		nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		handler.NewGroupHandler(nil, logger),
+		nil, nil, nil, nil, nil, nil, nil,
+		middleware.NewAuthMiddleware(jwtService, logger),
+		middleware.NewRBACMiddleware(logger, audit),
+		audit,
+	)
+}
+
+func TestSetupRouter_GroupGrantedPermission(t *testing.T) {
+	jwtService := util.NewJWTService("test-secret-key", 15*time.Minute, 7*24*time.Hour)
+	router := newTestRouter(jwtService)
+
+	tests := []struct {
+		name         string
+		role         string
+		permissions  []string
+		expectedCode int
+	}{
+		{
+			name:         "group grants groups:write to a user",
+			role:         "user",
+			permissions:  append(util.GetPermissionsForRole("user"), "groups:write"),
+			expectedCode: http.StatusBadRequest, // 権限チェックを通過し、ボディの検証で拒否される
+		},
+		{
+			name:         "user without group permission",
+			role:         "user",
+			permissions:  util.GetPermissionsForRole("user"),
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "admin role permissions",
+			role:         "admin",
+			permissions:  util.GetPermissionsForRole("admin"),
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwtService.GenerateAccessToken(7, "taro", tt.role, tt.permissions)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/groups", strings.NewReader("{}"))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+		})
+	}
+}
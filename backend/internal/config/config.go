@@ -1,18 +1,26 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 // Config はアプリケーション全体の設定を保持します
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	JWT      JWTConfig
-	Log      LogConfig
+	Server    ServerConfig
+	Database  DatabaseConfig
+	Redis     RedisConfig
+	JWT       JWTConfig
+	Log       LogConfig
+	User      UserConfig
+	Mail      MailConfig
+	Scheduler SchedulerConfig
+	Audit     AuditConfig
+	Webhook   WebhookConfig
+	Security  SecurityConfig
 }
 
 // ServerConfig はサーバー関連の設定です
@@ -55,6 +63,146 @@ type JWTConfig struct {
 	RefreshTokenCookieDomain string
 }
 
+// UserConfig はユーザー管理関連の設定です
+type UserConfig struct {
+	DeletedRetention time.Duration // 削除済みユーザーを物理削除するまでの保持期間
+	PurgeInterval    time.Duration // 物理削除ジョブの実行間隔
+	InvitationTTL    time.Duration // 招待リンクの有効期間
+	InvitationURL    string        // 招待受諾画面のURL（トークンをクエリに付与して送信）
+
+	EmailVerificationTTL time.Duration // メールアドレス確認リンクの有効期間
+	EmailVerificationURL string        // メールアドレス確認画面のURL（トークンをクエリに付与して送信）
+
+	DormantAfter         time.Duration // 利用がない場合に無効化するまでの期間（0で無効）
+	DormantWarningBefore time.Duration // 無効化を予告するメールを送る時期（無効化のどれだけ前か）
+	LifecycleInterval    time.Duration // 休眠・利用期限切れの判定ジョブの実行間隔
+}
+
+// SchedulerConfig はバックグラウンドジョブのスケジューラ関連の設定です
+type SchedulerConfig struct {
+	Enabled       bool
+	LockKey       int64         // リーダー選出に使用するアドバイザリーロックのキー（レプリカ間で共通）
+	RetryInterval time.Duration // リーダーでないレプリカがロックの取得を再試行する間隔
+	CheckInterval time.Duration // リーダーがロックを保持しているか確認する間隔
+}
+
+// MailConfig はメール送信関連の設定です
+type MailConfig struct {
+	Driver       string // log（ログ出力のみ）または smtp
+	From         string
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+}
+
+// WebhookConfig はWebhook送信関連の設定です
+type WebhookConfig struct {
+	Enabled       bool          // 配信ジョブを実行するか（無効でも配信はキューに登録されます）
+	PollInterval  time.Duration // 送信待ちの配信を確認する間隔
+	BatchSize     int           // 1回の確認で送信する最大件数
+	Timeout       time.Duration // 1回の送信のタイムアウト
+	MaxAttempts   int           // デッドレターにするまでの最大送信回数
+	RetryInterval time.Duration // 再試行の初回の待ち時間（以降は倍々に延ばす）
+	MaxRetryDelay time.Duration // 再試行の待ち時間の上限
+}
+
+// SecurityConfig は不審なログインの検知関連の設定です
+type SecurityConfig struct {
+	DetectionEnabled bool          // ログインの試行ごとに不審なログインを検知するか
+	HistoryWindow    time.Duration // 新しいIPアドレス・User-Agentと移動の判定に使う過去のログインの期間
+	AlertDedupWindow time.Duration // 同じ未対応のアラートがある場合に、新たに作成しない期間
+
+	SprayingWindow    time.Duration // パスワードスプレーの判定に使うログイン失敗の期間
+	SprayingThreshold int           // 1つのIPアドレスからログインに失敗したアカウント数の閾値（0の場合は判定しない）
+	SprayingActions   []string      // パスワードスプレーを検知した場合の対応
+	IPLockoutDuration time.Duration // パスワードスプレーの送信元からのログインを拒否する期間（lockout の場合）
+
+	NewIPEnabled        bool     // 新しいIPアドレスからのログインを判定するか
+	NewIPActions        []string // 新しいIPアドレスからのログインを検知した場合の対応
+	NewUserAgentEnabled bool     // 新しいUser-Agentからのログインを判定するか
+	NewUserAgentActions []string // 新しいUser-Agentからのログインを検知した場合の対応
+
+	GeoIPFile           string   // GeoIPデータベースのCSVファイル（未設定の場合は移動を判定しない）
+	TravelMaxSpeedKmh   int      // 移動できるとみなす最大の速度（km/h）
+	TravelMinDistanceKm int      // 移動を判定する最小の距離（km）
+	TravelActions       []string // 移動できない2つのIPアドレスからのログインを検知した場合の対応
+}
+
+// AuditConfig は監査ログ関連の設定です
+type AuditConfig struct {
+	HashKey         string // ハッシュチェーンの鍵（未設定の場合はJWTのシークレットを使用）
+	HashKeyFallback bool   // AUDIT_HASH_KEY が未設定またはJWTのシークレットと同じか（本番環境では起動しない）
+
+	AsyncEnabled  bool          // キューを経由して非同期にまとめて書き込むか
+	QueueSize     int           // キューに保持できる件数
+	BatchSize     int           // 1回の書き込みでまとめる最大件数
+	FlushInterval time.Duration // バッチが満たなくても書き込む間隔
+	Backpressure  string        // キューが満杯の場合の動作（block, drop, sync）
+	BlockTimeout  time.Duration // block の場合に空きを待つ最大時間
+	DrainTimeout  time.Duration // シャットダウン時にキューを書き込み終えるまで待つ最大時間
+
+	SinkBufferSize    int           // 外部転送先ごとに保持できる未送信の件数
+	SinkMaxRetries    int           // 外部転送に失敗した場合の再試行回数
+	SinkRetryInterval time.Duration // 外部転送の最初の再試行までの間隔（再試行ごとに倍にする）
+
+	SyslogEnabled       bool     // syslogへ転送するか
+	SyslogNetwork       string   // udp, tcp, tls
+	SyslogAddress       string   // syslogサーバーのアドレス（host:port）
+	SyslogFormat        string   // rfc5424 または cef
+	SyslogCAFile        string   // tls の場合にサーバー証明書の検証に使用するCA証明書（未設定の場合はシステムのルート証明書）
+	SyslogActions       []string // 転送するアクション（空の場合はすべて）
+	SyslogResourceTypes []string // 転送するリソース種別（空の場合はすべて）
+	SyslogStatuses      []string // 転送するステータス（空の場合はすべて）
+
+	FileEnabled       bool     // JSON Lines形式のファイルへ出力するか
+	FilePath          string   // 出力先のファイル
+	FileMaxSizeMB     int      // ローテーションするサイズ（MB）
+	FileMaxBackups    int      // 保持する古いファイルの数
+	FileActions       []string // 出力するアクション（空の場合はすべて）
+	FileResourceTypes []string // 出力するリソース種別（空の場合はすべて）
+	FileStatuses      []string // 出力するステータス（空の場合はすべて）
+
+	StreamEnabled      bool          // 監査ログのストリーム（SSE）を提供するか
+	StreamHeartbeat    time.Duration // 接続を維持するためのハートビートの間隔（取りこぼし確認も兼ねる）
+	StreamWriteTimeout time.Duration // 1回の送信のタイムアウト（超えた遅いクライアントは切断）
+	StreamMaxClients   int           // 1台あたりの最大同時接続数
+	StreamBatchSize    int           // 1回の問い合わせで送信する最大件数
+
+	RetentionEnabled    bool          // 保持期間ポリシーを定期的に実行するか
+	RetentionInterval   time.Duration // 保持期間ポリシーを実行する間隔
+	RetentionMinDays    int           // ポリシーに指定できる保持期間の下限（日）
+	RetentionBatchSize  int           // 1回のトランザクションで削除する最大件数
+	RetentionBatchPause time.Duration // 削除の間隔
+
+	ArchiveEnabled      bool          // 古い監査ログを定期的にアーカイブするか
+	ArchiveInterval     time.Duration // アーカイブを実行する間隔
+	ArchiveAfterDays    int           // 作成からこの日数を過ぎた監査ログをアーカイブする
+	ArchiveSegmentSize  int           // 1つのセグメントに含める最大件数
+	ArchiveMaxSegments  int           // 1回の実行で作成する最大セグメント数（0の場合は無制限）
+	ArchiveSegmentPause time.Duration // セグメントの間隔
+	ArchiveStorage      string        // 保存先（local, s3）
+	ArchiveDir          string        // local の場合の保存先ディレクトリ
+	ArchiveS3Endpoint   string        // s3 の場合のエンドポイント（例: https://s3.ap-northeast-1.amazonaws.com）
+	ArchiveS3Region     string        // s3 の場合のリージョン
+	ArchiveS3Bucket     string        // s3 の場合のバケット
+	ArchiveS3Prefix     string        // s3 の場合のキーのプレフィックス
+	ArchiveS3AccessKey  string        // s3 の場合のアクセスキー
+	ArchiveS3SecretKey  string        // s3 の場合のシークレットキー
+	ArchiveS3PathStyle  bool          // バケットをパスで指定するか（MinIO等のS3互換ストレージ）
+
+	PartitionEnabled         bool          // 月ごとのパーティションを定期的に管理するか
+	PartitionInterval        time.Duration // パーティションを管理する間隔
+	PartitionPremakeMonths   int           // 当月に加えて事前に作成する月数
+	PartitionRetentionMonths int           // 当月より前にこの月数を過ぎたパーティションを切り離す（0の場合は切り離さない）
+	PartitionDropDetached    bool          // 切り離したパーティションを削除するか
+
+	ReadEnabled         bool          // 機密性の高いリソースの参照を記録するか
+	DenialEnabled       bool          // 認可の拒否を記録するか
+	ReadDedupWindow     time.Duration // 同じ利用者による同じ参照を再び記録するまでの期間
+	ReadDedupMaxEntries int           // 重複の判定のために保持する参照の最大数
+}
+
 // LogConfig はログ関連の設定です
 type LogConfig struct {
 	Level      string
@@ -62,8 +210,23 @@ type LogConfig struct {
 	OutputPath string
 }
 
+// DSN はPostgreSQLの接続文字列を返します
+func (c DatabaseConfig) DSN() string {
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host,
+		c.Port,
+		c.User,
+		c.Password,
+		c.Name,
+		c.SSLMode,
+	)
+}
+
 // Load は環境変数から設定を読み込みます
 func Load() *Config {
+	jwtSecret := getEnv("JWT_SECRET", "your-secret-key-change-this")
+
 	return &Config{
 		Server: ServerConfig{
 			Env:          getEnv("ENV", "development"),
@@ -89,7 +252,7 @@ func Load() *Config {
 			DB:       getIntEnv("REDIS_DB", 0),
 		},
 		JWT: JWTConfig{
-			Secret:                   getEnv("JWT_SECRET", "your-secret-key-change-this"),
+			Secret:                   jwtSecret,
 			AccessTokenExpiration:    getDurationEnv("JWT_ACCESS_TOKEN_EXPIRATION", 15*time.Minute),
 			RefreshTokenExpiration:   getDurationEnv("JWT_REFRESH_TOKEN_EXPIRATION", 7*24*time.Hour),
 			RefreshTokenRotation:     getBoolEnv("JWT_REFRESH_TOKEN_ROTATION", true),
@@ -102,6 +265,131 @@ func Load() *Config {
 			Format:     getEnv("LOG_FORMAT", "json"),
 			OutputPath: getEnv("LOG_OUTPUT_PATH", "stdout"),
 		},
+		User: UserConfig{
+			DeletedRetention: getDurationEnv("USER_DELETED_RETENTION", 30*24*time.Hour),
+			PurgeInterval:    getDurationEnv("USER_PURGE_INTERVAL", time.Hour),
+			InvitationTTL:    getDurationEnv("USER_INVITATION_TTL", 72*time.Hour),
+			InvitationURL:    getEnv("USER_INVITATION_URL", "http://localhost:3000/invitations/accept"),
+
+			EmailVerificationTTL: getDurationEnv("USER_EMAIL_VERIFICATION_TTL", 24*time.Hour),
+			EmailVerificationURL: getEnv("USER_EMAIL_VERIFICATION_URL", "http://localhost:3000/email/verify"),
+
+			DormantAfter:         getDurationEnv("USER_DORMANT_AFTER", 90*24*time.Hour),
+			DormantWarningBefore: getDurationEnv("USER_DORMANT_WARNING_BEFORE", 7*24*time.Hour),
+			LifecycleInterval:    getDurationEnv("USER_LIFECYCLE_INTERVAL", time.Hour),
+		},
+		Mail: MailConfig{
+			Driver:       getEnv("MAIL_DRIVER", "log"),
+			From:         getEnv("MAIL_FROM", "noreply@effisio.com"),
+			SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:     getEnv("SMTP_PORT", "587"),
+			SMTPUsername: getEnv("SMTP_USERNAME", ""),
+			SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:       getBoolEnv("SCHEDULER_ENABLED", true),
+			LockKey:       int64(getIntEnv("SCHEDULER_LOCK_KEY", 7340001)),
+			RetryInterval: getDurationEnv("SCHEDULER_RETRY_INTERVAL", 30*time.Second),
+			CheckInterval: getDurationEnv("SCHEDULER_CHECK_INTERVAL", 10*time.Second),
+		},
+		Audit: AuditConfig{
+			HashKey:         getEnv("AUDIT_HASH_KEY", jwtSecret),
+			HashKeyFallback: getEnv("AUDIT_HASH_KEY", jwtSecret) == jwtSecret,
+
+			AsyncEnabled:  getBoolEnv("AUDIT_ASYNC_ENABLED", true),
+			QueueSize:     getIntEnv("AUDIT_QUEUE_SIZE", 10000),
+			BatchSize:     getIntEnv("AUDIT_BATCH_SIZE", 100),
+			FlushInterval: getDurationEnv("AUDIT_FLUSH_INTERVAL", time.Second),
+			Backpressure:  getEnv("AUDIT_BACKPRESSURE", "block"),
+			BlockTimeout:  getDurationEnv("AUDIT_BLOCK_TIMEOUT", 100*time.Millisecond),
+			DrainTimeout:  getDurationEnv("AUDIT_DRAIN_TIMEOUT", 10*time.Second),
+
+			SinkBufferSize:    getIntEnv("AUDIT_SINK_BUFFER_SIZE", 1000),
+			SinkMaxRetries:    getIntEnv("AUDIT_SINK_MAX_RETRIES", 3),
+			SinkRetryInterval: getDurationEnv("AUDIT_SINK_RETRY_INTERVAL", time.Second),
+
+			SyslogEnabled:       getBoolEnv("AUDIT_SYSLOG_ENABLED", false),
+			SyslogNetwork:       getEnv("AUDIT_SYSLOG_NETWORK", "udp"),
+			SyslogAddress:       getEnv("AUDIT_SYSLOG_ADDRESS", "localhost:514"),
+			SyslogFormat:        getEnv("AUDIT_SYSLOG_FORMAT", "rfc5424"),
+			SyslogCAFile:        getEnv("AUDIT_SYSLOG_CA_FILE", ""),
+			SyslogActions:       getListEnv("AUDIT_SYSLOG_ACTIONS"),
+			SyslogResourceTypes: getListEnv("AUDIT_SYSLOG_RESOURCE_TYPES"),
+			SyslogStatuses:      getListEnv("AUDIT_SYSLOG_STATUSES"),
+
+			FileEnabled:       getBoolEnv("AUDIT_FILE_ENABLED", false),
+			FilePath:          getEnv("AUDIT_FILE_PATH", "logs/audit.jsonl"),
+			FileMaxSizeMB:     getIntEnv("AUDIT_FILE_MAX_SIZE_MB", 100),
+			FileMaxBackups:    getIntEnv("AUDIT_FILE_MAX_BACKUPS", 10),
+			FileActions:       getListEnv("AUDIT_FILE_ACTIONS"),
+			FileResourceTypes: getListEnv("AUDIT_FILE_RESOURCE_TYPES"),
+			FileStatuses:      getListEnv("AUDIT_FILE_STATUSES"),
+
+			StreamEnabled:      getBoolEnv("AUDIT_STREAM_ENABLED", true),
+			StreamHeartbeat:    getDurationEnv("AUDIT_STREAM_HEARTBEAT", 15*time.Second),
+			StreamWriteTimeout: getDurationEnv("AUDIT_STREAM_WRITE_TIMEOUT", 10*time.Second),
+			StreamMaxClients:   getIntEnv("AUDIT_STREAM_MAX_CLIENTS", 100),
+			StreamBatchSize:    getIntEnv("AUDIT_STREAM_BATCH_SIZE", 100),
+
+			RetentionEnabled:    getBoolEnv("AUDIT_RETENTION_ENABLED", true),
+			RetentionInterval:   getDurationEnv("AUDIT_RETENTION_INTERVAL", 24*time.Hour),
+			RetentionMinDays:    getIntEnv("AUDIT_RETENTION_MIN_DAYS", 30),
+			RetentionBatchSize:  getIntEnv("AUDIT_RETENTION_BATCH_SIZE", 1000),
+			RetentionBatchPause: getDurationEnv("AUDIT_RETENTION_BATCH_PAUSE", 100*time.Millisecond),
+
+			ArchiveEnabled:      getBoolEnv("AUDIT_ARCHIVE_ENABLED", false),
+			ArchiveInterval:     getDurationEnv("AUDIT_ARCHIVE_INTERVAL", 24*time.Hour),
+			ArchiveAfterDays:    getIntEnv("AUDIT_ARCHIVE_AFTER_DAYS", 365),
+			ArchiveSegmentSize:  getIntEnv("AUDIT_ARCHIVE_SEGMENT_SIZE", 10000),
+			ArchiveMaxSegments:  getIntEnv("AUDIT_ARCHIVE_MAX_SEGMENTS", 100),
+			ArchiveSegmentPause: getDurationEnv("AUDIT_ARCHIVE_SEGMENT_PAUSE", time.Second),
+			ArchiveStorage:      getEnv("AUDIT_ARCHIVE_STORAGE", "local"),
+			ArchiveDir:          getEnv("AUDIT_ARCHIVE_DIR", "archives"),
+			ArchiveS3Endpoint:   getEnv("AUDIT_ARCHIVE_S3_ENDPOINT", ""),
+			ArchiveS3Region:     getEnv("AUDIT_ARCHIVE_S3_REGION", "us-east-1"),
+			ArchiveS3Bucket:     getEnv("AUDIT_ARCHIVE_S3_BUCKET", ""),
+			ArchiveS3Prefix:     getEnv("AUDIT_ARCHIVE_S3_PREFIX", ""),
+			ArchiveS3AccessKey:  getEnv("AUDIT_ARCHIVE_S3_ACCESS_KEY", ""),
+			ArchiveS3SecretKey:  getEnv("AUDIT_ARCHIVE_S3_SECRET_KEY", ""),
+			ArchiveS3PathStyle:  getBoolEnv("AUDIT_ARCHIVE_S3_PATH_STYLE", false),
+
+			PartitionEnabled:         getBoolEnv("AUDIT_PARTITION_ENABLED", true),
+			PartitionInterval:        getDurationEnv("AUDIT_PARTITION_INTERVAL", 24*time.Hour),
+			PartitionPremakeMonths:   getIntEnv("AUDIT_PARTITION_PREMAKE_MONTHS", 3),
+			PartitionRetentionMonths: getIntEnv("AUDIT_PARTITION_RETENTION_MONTHS", 0),
+			PartitionDropDetached:    getBoolEnv("AUDIT_PARTITION_DROP_DETACHED", false),
+
+			ReadEnabled:         getBoolEnv("AUDIT_READ_ENABLED", true),
+			DenialEnabled:       getBoolEnv("AUDIT_DENIAL_ENABLED", true),
+			ReadDedupWindow:     getDurationEnv("AUDIT_READ_DEDUP_WINDOW", 5*time.Minute),
+			ReadDedupMaxEntries: getIntEnv("AUDIT_READ_DEDUP_MAX_ENTRIES", 10000),
+		},
+		Webhook: WebhookConfig{
+			Enabled:       getBoolEnv("WEBHOOK_ENABLED", true),
+			PollInterval:  getDurationEnv("WEBHOOK_POLL_INTERVAL", 5*time.Second),
+			BatchSize:     getIntEnv("WEBHOOK_BATCH_SIZE", 50),
+			Timeout:       getDurationEnv("WEBHOOK_TIMEOUT", 10*time.Second),
+			MaxAttempts:   getIntEnv("WEBHOOK_MAX_ATTEMPTS", 8),
+			RetryInterval: getDurationEnv("WEBHOOK_RETRY_INTERVAL", 30*time.Second),
+			MaxRetryDelay: getDurationEnv("WEBHOOK_MAX_RETRY_DELAY", 6*time.Hour),
+		},
+		Security: SecurityConfig{
+			DetectionEnabled:    getBoolEnv("SECURITY_DETECTION_ENABLED", true),
+			HistoryWindow:       getDurationEnv("SECURITY_HISTORY_WINDOW", 90*24*time.Hour),
+			AlertDedupWindow:    getDurationEnv("SECURITY_ALERT_DEDUP_WINDOW", time.Hour),
+			SprayingWindow:      getDurationEnv("SECURITY_SPRAYING_WINDOW", 10*time.Minute),
+			SprayingThreshold:   getIntEnv("SECURITY_SPRAYING_THRESHOLD", 10),
+			SprayingActions:     getListEnvOrDefault("SECURITY_SPRAYING_ACTIONS", []string{"notify"}),
+			IPLockoutDuration:   getDurationEnv("SECURITY_IP_LOCKOUT_DURATION", time.Hour),
+			NewIPEnabled:        getBoolEnv("SECURITY_NEW_IP_ENABLED", true),
+			NewIPActions:        getListEnvOrDefault("SECURITY_NEW_IP_ACTIONS", nil),
+			NewUserAgentEnabled: getBoolEnv("SECURITY_NEW_USER_AGENT_ENABLED", true),
+			NewUserAgentActions: getListEnvOrDefault("SECURITY_NEW_USER_AGENT_ACTIONS", nil),
+			GeoIPFile:           getEnv("SECURITY_GEOIP_FILE", ""),
+			TravelMaxSpeedKmh:   getIntEnv("SECURITY_TRAVEL_MAX_SPEED_KMH", 900),
+			TravelMinDistanceKm: getIntEnv("SECURITY_TRAVEL_MIN_DISTANCE_KM", 500),
+			TravelActions:       getListEnvOrDefault("SECURITY_TRAVEL_ACTIONS", []string{"notify"}),
+		},
 	}
 }
 
@@ -133,6 +421,26 @@ func getBoolEnv(key string, defaultValue bool) bool {
 	return defaultValue
 }
 
+// getListEnv は環境変数をカンマ区切りのリストとして取得します（未設定の場合は nil）
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getListEnvOrDefault は環境変数をカンマ区切りのリストとして取得します
+// 未設定の場合は defaultValue を返し、空を設定した場合は空のリストを返します
+func getListEnvOrDefault(key string, defaultValue []string) []string {
+	if _, ok := os.LookupEnv(key); !ok {
+		return defaultValue
+	}
+	return getListEnv(key)
+}
+
 // getDurationEnv は環境変数を時間として取得します
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// AuditArchiveHandler は監査ログのアーカイブに関するHTTPハンドラを提供します
+type AuditArchiveHandler struct {
+	service *service.AuditArchiveService
+	logger  *zap.Logger
+}
+
+// NewAuditArchiveHandler は新しいAuditArchiveHandlerを作成します
+func NewAuditArchiveHandler(service *service.AuditArchiveService, logger *zap.Logger) *AuditArchiveHandler {
+	return &AuditArchiveHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List はアーカイブの一覧を取得します
+// @Summary 監査ログのアーカイブ一覧取得
+// @Tags audit_archives
+// @Security Bearer
+// @Produce json
+// @Param page query int false "ページ番号（デフォルト: 1）"
+// @Param per_page query int false "1ページあたりの件数（デフォルト: 10）"
+// @Success 200 {object} util.PaginatedResponse
+// @Router /api/v1/audit-archives [get]
+func (h *AuditArchiveHandler) List(c *gin.Context) {
+	params := util.GetPaginationParams(c)
+
+	response, err := h.service.List(c.Request.Context(), params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, response)
+}
+
+// GetByID はIDでアーカイブを取得します
+// @Summary 監査ログのアーカイブ詳細取得
+// @Tags audit_archives
+// @Security Bearer
+// @Produce json
+// @Param id path int true "アーカイブID"
+// @Success 200 {object} model.AuditLogArchive
+// @Failure 404 {object} util.Response
+// @Router /api/v1/audit-archives/{id} [get]
+func (h *AuditArchiveHandler) GetByID(c *gin.Context) {
+	id, ok := parseArchiveID(c)
+	if !ok {
+		return
+	}
+
+	archive, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"archive": archive})
+}
+
+// Run は保存期間を過ぎた監査ログを直ちにアーカイブします
+// @Summary 監査ログのアーカイブの実行
+// @Tags audit_archives
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} model.AuditArchiveResult
+// @Router /api/v1/audit-archives/run [post]
+func (h *AuditArchiveHandler) Run(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Run(c.Request.Context(), actorID)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// Import は調査のためにアーカイブを読み戻して取り込みます
+// 同じアーカイブを再度取り込むと、前回取り込んだ監査ログを置き換えます
+// @Summary 監査ログのアーカイブの取り込み
+// @Tags audit_archives
+// @Security Bearer
+// @Produce json
+// @Param id path int true "アーカイブID"
+// @Success 200 {object} model.AuditArchiveImportResult
+// @Failure 404 {object} util.Response
+// @Failure 500 {object} util.Response "アーカイブが見つからない、または改ざんされている"
+// @Router /api/v1/audit-archives/{id}/import [post]
+func (h *AuditArchiveHandler) Import(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseArchiveID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Import(c.Request.Context(), actorID, id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// ListImported はアーカイブから取り込んだ監査ログを取得します
+// @Summary 取り込んだ監査ログの一覧取得
+// @Tags audit_archives
+// @Security Bearer
+// @Produce json
+// @Param id path int true "アーカイブID"
+// @Param page query int false "ページ番号（デフォルト: 1）"
+// @Param per_page query int false "1ページあたりの件数（デフォルト: 10）"
+// @Success 200 {object} util.PaginatedResponse
+// @Failure 404 {object} util.Response
+// @Router /api/v1/audit-archives/{id}/logs [get]
+func (h *AuditArchiveHandler) ListImported(c *gin.Context) {
+	id, ok := parseArchiveID(c)
+	if !ok {
+		return
+	}
+
+	params := util.GetPaginationParams(c)
+
+	response, err := h.service.ListImported(c.Request.Context(), id, params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, response)
+}
+
+// DeleteImported はアーカイブから取り込んだ監査ログを削除します
+// @Summary 取り込んだ監査ログの削除
+// @Tags audit_archives
+// @Security Bearer
+// @Param id path int true "アーカイブID"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Router /api/v1/audit-archives/{id}/import [delete]
+func (h *AuditArchiveHandler) DeleteImported(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseArchiveID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteImported(c.Request.Context(), actorID, id); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
+
+// parseArchiveID はパスパラメータからアーカイブIDを取得します
+func parseArchiveID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid archive ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
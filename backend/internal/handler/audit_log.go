@@ -1,26 +1,52 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"github.com/varubogu/effisio/backend/internal/middleware"
 	"github.com/varubogu/effisio/backend/internal/model"
 	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/auditsink"
 	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
+// auditLogService はAuditLogHandlerが使用する監査ログの操作です（service.AuditLogService）
+type auditLogService interface {
+	LogAction(ctx context.Context, req *model.CreateAuditLogRequest) (*model.AuditLogResponse, error)
+	GetByID(ctx context.Context, id uint) (*model.AuditLogResponse, error)
+	List(ctx context.Context, filter *model.AuditLogFilter, params *util.PaginationParams) (*util.PaginatedResponse, error)
+	ListByUserID(ctx context.Context, userID uint, params *util.PaginationParams) (*util.PaginatedResponse, error)
+	ListByResource(ctx context.Context, resourceType, resourceID string, params *util.PaginationParams) (*util.PaginatedResponse, error)
+	ListByAction(ctx context.Context, action string, params *util.PaginationParams) (*util.PaginatedResponse, error)
+	ListByDateRange(ctx context.Context, startDate, endDate time.Time, params *util.PaginationParams) (*util.PaginatedResponse, error)
+	GetStatistics(ctx context.Context, q *model.AuditStatisticsQuery) (*service.AuditStatistics, error)
+	DeleteOldLogs(ctx context.Context, days int) error
+	VerifyChain(ctx context.Context, actorID uint) (*model.AuditChainVerification, error)
+	WriterStats() *service.AuditLogWriterStats
+	SinkStats() []*auditsink.Stats
+}
+
+// auditLogViewAllPermission は他のユーザーが実行した監査ログも参照できる権限です
+const auditLogViewAllPermission = "audit_logs:manage"
+
 // AuditLogHandler は監査ログ関連のHTTPハンドラを提供します
 type AuditLogHandler struct {
-	service *service.AuditLogService
+	service auditLogService
 	logger  *zap.Logger
 }
 
 // NewAuditLogHandler は新しいAuditLogHandlerを作成します
-func NewAuditLogHandler(service *service.AuditLogService, logger *zap.Logger) *AuditLogHandler {
+func NewAuditLogHandler(service auditLogService, logger *zap.Logger) *AuditLogHandler {
 	return &AuditLogHandler{
 		service: service,
 		logger:  logger,
@@ -28,24 +54,67 @@ func NewAuditLogHandler(service *service.AuditLogService, logger *zap.Logger) *A
 }
 
 // List は監査ログ一覧を取得します
+// 検索条件は組み合わせて指定でき、すべての条件に一致する監査ログを返します
+// audit_logs:manage 権限がない場合は自身が実行した監査ログのみを返します
 // @Summary 監査ログ一覧取得
 // @Tags audit_logs
 // @Security Bearer
 // @Param page query int false "ページ番号（デフォルト: 1）"
 // @Param per_page query int false "1ページあたりの件数（デフォルト: 10）"
+// @Param user_id query int false "実行したユーザーID"
+// @Param action query string false "アクション（カンマ区切りで複数指定可）"
+// @Param resource_type query string false "リソースタイプ"
+// @Param resource_id query string false "リソースID"
+// @Param status query string false "ステータス（success, failed）"
+// @Param ip_address query string false "IPアドレスまたはCIDR（例: 10.0.0.0/8）"
+// @Param from query string false "開始日時（RFC3339形式または日付）"
+// @Param to query string false "終了日時（RFC3339形式または日付、日付の場合はその日の終わりまで）"
+// @Param q query string false "エラーメッセージの部分一致"
+// @Param change query []string false "変更内容の値の一致（例: after.role:admin、複数指定可）" collectionFormat(multi)
+// @Param changed query []string false "変更前後で値が異なる項目（例: role、複数指定可）" collectionFormat(multi)
+// @Param compact query bool false "変更内容を省略し、変更された項目の一覧（changed_fields）のみを返す"
 // @Success 200 {object} util.PaginatedResponse
+// @Failure 400 {object} util.ErrorResponse
 // @Failure 401 {object} util.ErrorResponse
+// @Failure 403 {object} util.ErrorResponse "他のユーザーの監査ログを指定した"
 // @Router /api/v1/audit-logs [get]
 func (h *AuditLogHandler) List(c *gin.Context) {
+	viewerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, err.Error(), nil)
+		return
+	}
+	if err := service.RestrictAuditLogFilter(filter, viewerID, middleware.HasPermission(c, auditLogViewAllPermission)); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+	compact, ok := parseCompact(c)
+	if !ok {
+		return
+	}
+
 	params := util.GetPaginationParams(c)
 
-	response, err := h.service.List(c.Request.Context(), params)
+	response, err := h.service.List(c.Request.Context(), filter, params)
 	if err != nil {
 		h.logger.Error("Failed to list audit logs", zap.Error(err))
 		util.HandleError(c, err)
 		return
 	}
 
+	if compact {
+		if auditLogs, ok := response.Data.([]*model.AuditLogResponse); ok {
+			for _, auditLog := range auditLogs {
+				auditLog.Compact()
+			}
+		}
+	}
+
 	util.Paginated(c, response)
 }
 
@@ -54,6 +123,7 @@ func (h *AuditLogHandler) List(c *gin.Context) {
 // @Tags audit_logs
 // @Security Bearer
 // @Param id path int true "監査ログID"
+// @Param compact query bool false "変更内容を省略し、変更された項目の一覧（changed_fields）のみを返す"
 // @Success 200 {object} model.AuditLogResponse
 // @Failure 404 {object} util.ErrorResponse
 // @Router /api/v1/audit-logs/{id} [get]
@@ -61,7 +131,11 @@ func (h *AuditLogHandler) GetByID(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest, "Invalid audit log ID", nil)
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid audit log ID", nil)
+		return
+	}
+	compact, ok := parseCompact(c)
+	if !ok {
 		return
 	}
 
@@ -70,11 +144,15 @@ func (h *AuditLogHandler) GetByID(c *gin.Context) {
 		util.HandleError(c, err)
 		return
 	}
+	if compact {
+		auditLog.Compact()
+	}
 
 	util.Success(c, auditLog)
 }
 
 // ListByUserID はユーザーの監査ログを取得します
+// Deprecated: GET /api/v1/audit-logs に user_id を指定してください
 // @Summary ユーザーの監査ログ一覧取得
 // @Tags audit_logs
 // @Security Bearer
@@ -88,7 +166,7 @@ func (h *AuditLogHandler) ListByUserID(c *gin.Context) {
 	userIDStr := c.Param("user_id")
 	userID, err := strconv.ParseUint(userIDStr, 10, 32)
 	if err != nil {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest, "Invalid user ID", nil)
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
 		return
 	}
 
@@ -104,6 +182,7 @@ func (h *AuditLogHandler) ListByUserID(c *gin.Context) {
 }
 
 // ListByResource はリソースの監査ログを取得します
+// Deprecated: GET /api/v1/audit-logs に resource_type と resource_id を指定してください
 // @Summary リソースの監査ログ一覧取得
 // @Tags audit_logs
 // @Security Bearer
@@ -119,7 +198,7 @@ func (h *AuditLogHandler) ListByResource(c *gin.Context) {
 	resourceID := c.Query("resource_id")
 
 	if resourceType == "" || resourceID == "" {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest,
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter,
 			"resource_type and resource_id query parameters are required", nil)
 		return
 	}
@@ -136,6 +215,7 @@ func (h *AuditLogHandler) ListByResource(c *gin.Context) {
 }
 
 // ListByAction はアクションで監査ログを取得します
+// Deprecated: GET /api/v1/audit-logs に action を指定してください
 // @Summary アクション別の監査ログ一覧取得
 // @Tags audit_logs
 // @Security Bearer
@@ -148,7 +228,7 @@ func (h *AuditLogHandler) ListByResource(c *gin.Context) {
 func (h *AuditLogHandler) ListByAction(c *gin.Context) {
 	action := c.Query("action")
 	if action == "" {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest,
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter,
 			"action query parameter is required", nil)
 		return
 	}
@@ -165,6 +245,7 @@ func (h *AuditLogHandler) ListByAction(c *gin.Context) {
 }
 
 // ListByDateRange は日付範囲で監査ログを取得します
+// Deprecated: GET /api/v1/audit-logs に from と to を指定してください
 // @Summary 日付範囲の監査ログ一覧取得
 // @Tags audit_logs
 // @Security Bearer
@@ -180,21 +261,21 @@ func (h *AuditLogHandler) ListByDateRange(c *gin.Context) {
 	endDateStr := c.Query("end_date")
 
 	if startDateStr == "" || endDateStr == "" {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest,
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter,
 			"start_date and end_date query parameters are required", nil)
 		return
 	}
 
 	startDate, err := time.Parse(time.RFC3339, startDateStr)
 	if err != nil {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest,
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter,
 			"Invalid start_date format (use RFC3339)", nil)
 		return
 	}
 
 	endDate, err := time.Parse(time.RFC3339, endDateStr)
 	if err != nil {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest,
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter,
 			"Invalid end_date format (use RFC3339)", nil)
 		return
 	}
@@ -210,15 +291,32 @@ func (h *AuditLogHandler) ListByDateRange(c *gin.Context) {
 	util.Paginated(c, response)
 }
 
-// GetStatistics は監査ログの統計情報を取得します
+// GetStatistics は監査ログの件数の推移を集計します
+// 集計対象は GET /audit-logs と同じ検索条件で絞り込めます
+// 系列は内訳ごとに該当する監査ログがない集計単位も0で埋めて返すため、そのままグラフに使えます
 // @Summary 監査ログ統計情報取得
 // @Tags audit_logs
 // @Security Bearer
+// @Param from query string false "開始日時（RFC3339形式または日付、デフォルト: 集計単位に応じた直近の期間）"
+// @Param to query string false "終了日時（RFC3339形式または日付、デフォルト: 現在）"
+// @Param bucket query string false "集計単位（hour, day, week、デフォルト: day）"
+// @Param group_by query string false "内訳（action, resource_type, status, actor をカンマ区切りで指定）"
+// @Param tz query string false "集計単位の区切りに使うタイムゾーン（例: Asia/Tokyo、デフォルト: UTC）"
+// @Param top query int false "失敗した操作の多いユーザーとIPアドレスの件数（デフォルト: 10、最大: 100）"
+// @Param action query string false "アクション（カンマ区切りで複数指定可）"
+// @Param status query string false "ステータス（success, failed）"
 // @Success 200 {object} service.AuditStatistics
+// @Failure 400 {object} util.ErrorResponse
 // @Failure 401 {object} util.ErrorResponse
 // @Router /api/v1/audit-logs/statistics [get]
 func (h *AuditLogHandler) GetStatistics(c *gin.Context) {
-	stats, err := h.service.GetStatistics(c.Request.Context())
+	query, err := parseAuditStatisticsQuery(c)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, err.Error(), nil)
+		return
+	}
+
+	stats, err := h.service.GetStatistics(c.Request.Context(), query)
 	if err != nil {
 		h.logger.Error("Failed to get audit log statistics", zap.Error(err))
 		util.HandleError(c, err)
@@ -239,7 +337,7 @@ func (h *AuditLogHandler) GetStatistics(c *gin.Context) {
 func (h *AuditLogHandler) Create(c *gin.Context) {
 	var req model.CreateAuditLogRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest, "Invalid request body", nil)
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid request body", nil)
 		return
 	}
 
@@ -249,20 +347,67 @@ func (h *AuditLogHandler) Create(c *gin.Context) {
 	// ユーザーエージェントを取得
 	req.UserAgent = c.GetHeader("User-Agent")
 
+	// 作成した監査ログを返すため、キューを経由せず書き込む
+	req.Durable = true
+
 	auditLog, err := h.service.LogAction(c.Request.Context(), &req)
 	if err != nil {
 		util.HandleError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, util.SuccessResponse{
-		Code:    http.StatusCreated,
-		Message: "Audit log created",
-		Data:    auditLog,
-	})
+	util.Created(c, auditLog)
+}
+
+// VerifyChain は監査ログのハッシュチェーンを検証します
+// @Summary 監査ログの改ざん検証
+// @Description ハッシュチェーンをたどり、最初に見つかった不整合（書き換え・削除）を報告します
+// @Tags audit_logs
+// @Security Bearer
+// @Success 200 {object} model.AuditChainVerification
+// @Failure 403 {object} util.ErrorResponse
+// @Router /api/v1/audit-logs/verify [get]
+func (h *AuditLogHandler) VerifyChain(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.VerifyChain(c.Request.Context(), actorID)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// WriterStats は監査ログの非同期書き込みの統計情報を取得します
+// @Summary 監査ログ書き込みキューの統計
+// @Description キューの滞留件数、書き込み・破棄・失敗件数を返します
+// @Tags audit_logs
+// @Security Bearer
+// @Success 200 {object} service.AuditLogWriterStats
+// @Failure 403 {object} util.ErrorResponse
+// @Router /api/v1/audit-logs/writer-stats [get]
+func (h *AuditLogHandler) WriterStats(c *gin.Context) {
+	util.Success(c, h.service.WriterStats())
+}
+
+// SinkStats は監査ログの外部転送（SIEM連携）の統計情報を取得します
+// @Summary 監査ログ外部転送の統計
+// @Description 転送先ごとの未送信件数、送信・破棄・失敗・再試行件数を返します
+// @Tags audit_logs
+// @Security Bearer
+// @Success 200 {array} auditsink.Stats
+// @Failure 403 {object} util.ErrorResponse
+// @Router /api/v1/audit-logs/sink-stats [get]
+func (h *AuditLogHandler) SinkStats(c *gin.Context) {
+	util.Success(c, h.service.SinkStats())
 }
 
 // DeleteOldLogs は古い監査ログを削除します
+// Deprecated: アクションやステータスごとに保持期間を指定できる保持期間ポリシー（/audit-retention-policies）を使用してください
 // @Summary 古い監査ログ削除
 // @Tags audit_logs
 // @Security Bearer
@@ -274,7 +419,7 @@ func (h *AuditLogHandler) DeleteOldLogs(c *gin.Context) {
 	daysStr := c.DefaultQuery("days", "90")
 	days, err := strconv.Atoi(daysStr)
 	if err != nil {
-		util.Error(c, http.StatusBadRequest, util.ErrCodeBadRequest, "Invalid days value", nil)
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid days value", nil)
 		return
 	}
 
@@ -285,3 +430,161 @@ func (h *AuditLogHandler) DeleteOldLogs(c *gin.Context) {
 
 	util.Success(c, gin.H{"message": "Old audit logs deleted"})
 }
+
+// parseCompact はクエリパラメータから変更内容を省略するかを取得します
+func parseCompact(c *gin.Context) (bool, bool) {
+	compact, err := strconv.ParseBool(c.DefaultQuery("compact", "false"))
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid compact value", nil)
+		return false, false
+	}
+	return compact, true
+}
+
+// parseAuditLogFilter はクエリパラメータから監査ログの検索条件を取得します
+func parseAuditLogFilter(c *gin.Context) (*model.AuditLogFilter, error) {
+	filter := &model.AuditLogFilter{
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		Status:       c.Query("status"),
+		Query:        c.Query("q"),
+	}
+
+	if userID := c.Query("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 32)
+		if err != nil {
+			return nil, errors.New("invalid user_id")
+		}
+		uid := uint(id)
+		filter.UserID = &uid
+	}
+
+	if actions := c.Query("action"); actions != "" {
+		for _, action := range strings.Split(actions, ",") {
+			if action = strings.TrimSpace(action); action != "" {
+				filter.Actions = append(filter.Actions, action)
+			}
+		}
+	}
+
+	if filter.Status != "" && filter.Status != model.AuditStatusSuccess && filter.Status != model.AuditStatusFailed {
+		return nil, errors.New("status must be success or failed")
+	}
+
+	if ip := c.Query("ip_address"); ip != "" {
+		if strings.Contains(ip, "/") {
+			if _, _, err := net.ParseCIDR(ip); err != nil {
+				return nil, errors.New("invalid ip_address CIDR")
+			}
+		} else if net.ParseIP(ip) == nil {
+			return nil, errors.New("invalid ip_address")
+		}
+		filter.IPAddress = ip
+	}
+
+	if from := c.Query("from"); from != "" {
+		t, err := parseAuditLogTime(from, false)
+		if err != nil {
+			return nil, errors.New("invalid from format (use RFC3339 or YYYY-MM-DD)")
+		}
+		filter.From = &t
+	}
+	if to := c.Query("to"); to != "" {
+		t, err := parseAuditLogTime(to, true)
+		if err != nil {
+			return nil, errors.New("invalid to format (use RFC3339 or YYYY-MM-DD)")
+		}
+		filter.To = &t
+	}
+
+	for _, expr := range c.QueryArray("change") {
+		match, err := model.ParseAuditChangeMatch(expr)
+		if err != nil {
+			return nil, err
+		}
+		filter.ChangeMatches = append(filter.ChangeMatches, match)
+	}
+	for _, field := range c.QueryArray("changed") {
+		if err := model.ValidateAuditChangedField(field); err != nil {
+			return nil, err
+		}
+		filter.ChangedFields = append(filter.ChangedFields, field)
+	}
+
+	return filter, nil
+}
+
+// parseAuditLogTime はRFC3339形式または日付（YYYY-MM-DD）の日時を解析します
+// 日付のみで endOfDay が true の場合は、その日の終わりを返します
+func parseAuditLogTime(value string, endOfDay bool) (time.Time, error) {
+	return parseAuditLogTimeIn(value, endOfDay, time.UTC)
+}
+
+// parseAuditLogTimeIn は parseAuditLogTime と同じですが、日付のみの場合は loc の日付として解析します
+func parseAuditLogTimeIn(value string, endOfDay bool, loc *time.Location) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, loc)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+// parseAuditStatisticsQuery はクエリパラメータから監査ログの統計の条件を取得します
+func parseAuditStatisticsQuery(c *gin.Context) (*model.AuditStatisticsQuery, error) {
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		return nil, err
+	}
+
+	query := &model.AuditStatisticsQuery{
+		Filter: filter,
+		Bucket: c.DefaultQuery("bucket", model.AuditBucketDay),
+	}
+	if err := model.ValidateAuditBucket(query.Bucket); err != nil {
+		return nil, err
+	}
+
+	if groupBy := c.Query("group_by"); groupBy != "" {
+		for _, dimension := range strings.Split(groupBy, ",") {
+			if dimension = strings.TrimSpace(dimension); dimension != "" {
+				query.GroupBy = append(query.GroupBy, dimension)
+			}
+		}
+	}
+
+	query.Location = time.UTC
+	if tz := c.Query("tz"); tz != "" {
+		// Local はサーバーの設定に依存するため受け付けない
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			return nil, errors.New("invalid tz")
+		}
+		query.Location = loc
+
+		// 日付のみの指定はタイムゾーンの日付として扱う（形式は parseAuditLogFilter で検証済み）
+		if from := c.Query("from"); from != "" {
+			t, _ := parseAuditLogTimeIn(from, false, loc)
+			query.Filter.From = &t
+		}
+		if to := c.Query("to"); to != "" {
+			t, _ := parseAuditLogTimeIn(to, true, loc)
+			query.Filter.To = &t
+		}
+	}
+
+	if top := c.Query("top"); top != "" {
+		n, err := strconv.Atoi(top)
+		if err != nil || n < 1 || n > service.MaxAuditStatisticsTopN {
+			return nil, fmt.Errorf("top must be between 1 and %d", service.MaxAuditStatisticsTopN)
+		}
+		query.TopN = n
+	}
+
+	return query, nil
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// AuditPartitionHandler は監査ログのパーティションに関するHTTPハンドラを提供します
+type AuditPartitionHandler struct {
+	service *service.AuditPartitionService
+	logger  *zap.Logger
+}
+
+// NewAuditPartitionHandler は新しいAuditPartitionHandlerを作成します
+func NewAuditPartitionHandler(service *service.AuditPartitionService, logger *zap.Logger) *AuditPartitionHandler {
+	return &AuditPartitionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List はパーティションの一覧を取得します
+// @Summary 監査ログのパーティション一覧取得
+// @Tags audit_partitions
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} model.AuditLogPartition
+// @Router /api/v1/audit-partitions [get]
+func (h *AuditPartitionHandler) List(c *gin.Context) {
+	partitions, err := h.service.List(c.Request.Context())
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"partitions": partitions})
+}
+
+// Maintain は将来の月のパーティションの作成と保持期間を過ぎたパーティションの切り離しを直ちに実行します
+// @Summary 監査ログのパーティションの管理の実行
+// @Tags audit_partitions
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} model.AuditPartitionResult
+// @Router /api/v1/audit-partitions/maintain [post]
+func (h *AuditPartitionHandler) Maintain(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Maintain(c.Request.Context(), actorID)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
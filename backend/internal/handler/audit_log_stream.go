@@ -0,0 +1,215 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/middleware"
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// sseRetry はクライアントが再接続するまでの待ち時間（ミリ秒）です
+const sseRetry = 3000
+
+// AuditLogStreamHandler は監査ログのストリーム配信（Server-Sent Events）のHTTPハンドラを提供します
+type AuditLogStreamHandler struct {
+	service      *service.AuditLogStreamService
+	heartbeat    time.Duration
+	writeTimeout time.Duration
+	logger       *zap.Logger
+}
+
+// NewAuditLogStreamHandler は新しいAuditLogStreamHandlerを作成します
+func NewAuditLogStreamHandler(service *service.AuditLogStreamService, heartbeat, writeTimeout time.Duration, logger *zap.Logger) *AuditLogStreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	if writeTimeout <= 0 {
+		writeTimeout = 10 * time.Second
+	}
+	return &AuditLogStreamHandler{
+		service:      service,
+		heartbeat:    heartbeat,
+		writeTimeout: writeTimeout,
+		logger:       logger,
+	}
+}
+
+// Stream は追加された監査ログを Server-Sent Events で送信します
+// 検索条件と閲覧できる範囲は GET /audit-logs と同じで、audit_logs:manage 権限がない場合は自身が実行した監査ログのみを受け取れます
+// 各イベントのIDは監査ログのIDで、再接続時に Last-Event-ID を送ると続きから受信できます
+// @Summary 監査ログのストリーム
+// @Tags audit_logs
+// @Security Bearer
+// @Produce text/event-stream
+// @Param Last-Event-ID header int false "最後に受信した監査ログID（続きから受信）"
+// @Param last_event_id query int false "最後に受信した監査ログID（ヘッダーを送れない場合）"
+// @Param user_id query int false "実行したユーザーID"
+// @Param action query string false "アクション（カンマ区切りで複数指定可）"
+// @Param status query string false "ステータス（success, failed）"
+// @Success 200 {string} string "text/event-stream"
+// @Failure 400 {object} util.ErrorResponse
+// @Failure 403 {object} util.ErrorResponse "他のユーザーの監査ログを指定した"
+// @Failure 503 {object} util.ErrorResponse "同時接続数の上限に達している"
+// @Router /api/v1/audit-logs/stream [get]
+func (h *AuditLogStreamHandler) Stream(c *gin.Context) {
+	viewerID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	filter, err := parseAuditLogFilter(c)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, err.Error(), nil)
+		return
+	}
+	if err := h.service.Authorize(filter, viewerID, middleware.HasPermission(c, auditLogViewAllPermission)); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	lastEventID, err := parseLastEventID(c)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, err.Error(), nil)
+		return
+	}
+
+	// 開始位置を決める前に購読し、その間に追加された監査ログを取りこぼさないようにする
+	wake, unsubscribe, err := h.service.Subscribe()
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+	defer unsubscribe()
+
+	ctx := c.Request.Context()
+	afterID, err := h.service.StartID(ctx, lastEventID)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // リバースプロキシでのバッファリングを無効化
+	c.Status(http.StatusOK)
+
+	stream := &sseWriter{
+		w:            c.Writer,
+		rc:           http.NewResponseController(c.Writer),
+		writeTimeout: h.writeTimeout,
+	}
+	if err := stream.send(fmt.Sprintf("retry: %d\n\n", sseRetry)); err != nil {
+		return
+	}
+
+	h.logger.Info("Audit log stream opened", zap.Uint("user_id", viewerID), zap.Uint("after_id", afterID))
+	defer h.logger.Info("Audit log stream closed", zap.Uint("user_id", viewerID), zap.Uint("last_event_id", afterID))
+
+	// 通知を取りこぼした場合に備え、ハートビートのたびにも新しい監査ログを確認する
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		if err := h.sendPending(c, stream, filter, &afterID); err != nil {
+			if errors.Is(err, errStreamWrite) {
+				// 受信が追いつかないクライアントは切断し、Last-Event-ID で再接続させる
+				h.logger.Info("Audit log stream client is too slow or gone", zap.Uint("user_id", viewerID), zap.Error(err))
+			} else {
+				h.logger.Error("Failed to fetch audit logs for stream", zap.Error(err))
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				// シャットダウン
+				return
+			}
+		case <-heartbeat.C:
+			if err := stream.send(": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendPending は afterID より新しい監査ログをすべて送信し、afterID を最後に送信したIDに進めます
+func (h *AuditLogStreamHandler) sendPending(c *gin.Context, stream *sseWriter, filter *model.AuditLogFilter, afterID *uint) error {
+	for {
+		auditLogs, err := h.service.Next(c.Request.Context(), filter, *afterID)
+		if err != nil {
+			return err
+		}
+
+		for _, auditLog := range auditLogs {
+			data, err := json.Marshal(auditLog)
+			if err != nil {
+				return err
+			}
+			if err := stream.send(fmt.Sprintf("id: %d\nevent: audit_log\ndata: %s\n\n", auditLog.ID, data)); err != nil {
+				return err
+			}
+			*afterID = auditLog.ID
+		}
+
+		if len(auditLogs) < h.service.BatchSize() {
+			return nil
+		}
+	}
+}
+
+// errStreamWrite はクライアントへの送信に失敗したことを表します
+var errStreamWrite = errors.New("failed to write to audit log stream")
+
+// sseWriter はイベントを送信するたびにフラッシュし、送信に時間がかかるクライアントを切断します
+type sseWriter struct {
+	w            gin.ResponseWriter
+	rc           *http.ResponseController
+	writeTimeout time.Duration
+}
+
+// send はイベントを送信します
+// サーバーの WriteTimeout で長時間の接続が切られないよう、送信ごとに書き込みの期限を延長します
+func (s *sseWriter) send(event string) error {
+	// 期限を設定できない場合はサーバー全体の WriteTimeout に従う
+	_ = s.rc.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+
+	if _, err := s.w.WriteString(event); err != nil {
+		return fmt.Errorf("%w: %v", errStreamWrite, err)
+	}
+	s.w.Flush()
+	return nil
+}
+
+// parseLastEventID は Last-Event-ID ヘッダー（または last_event_id クエリパラメータ）を取得します
+// 指定がない場合は nil を返します
+func parseLastEventID(c *gin.Context) (*uint, error) {
+	value := c.GetHeader("Last-Event-ID")
+	if value == "" {
+		value = c.Query("last_event_id")
+	}
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, errors.New("invalid Last-Event-ID")
+	}
+	lastEventID := uint(id)
+	return &lastEventID, nil
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -14,6 +15,8 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/auditsink"
 	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
@@ -22,7 +25,7 @@ type MockAuditLogService struct {
 	mock.Mock
 }
 
-func (m *MockAuditLogService) LogAction(ctx interface{}, req *model.CreateAuditLogRequest) (*model.AuditLogResponse, error) {
+func (m *MockAuditLogService) LogAction(ctx context.Context, req *model.CreateAuditLogRequest) (*model.AuditLogResponse, error) {
 	args := m.Called(ctx, req)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -30,7 +33,7 @@ func (m *MockAuditLogService) LogAction(ctx interface{}, req *model.CreateAuditL
 	return args.Get(0).(*model.AuditLogResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) GetByID(ctx interface{}, id uint) (*model.AuditLogResponse, error) {
+func (m *MockAuditLogService) GetByID(ctx context.Context, id uint) (*model.AuditLogResponse, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -38,15 +41,15 @@ func (m *MockAuditLogService) GetByID(ctx interface{}, id uint) (*model.AuditLog
 	return args.Get(0).(*model.AuditLogResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) List(ctx interface{}, params *util.PaginationParams) (*util.PaginatedResponse, error) {
-	args := m.Called(ctx, params)
+func (m *MockAuditLogService) List(ctx context.Context, filter *model.AuditLogFilter, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	args := m.Called(ctx, filter, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*util.PaginatedResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) ListByUserID(ctx interface{}, userID uint, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+func (m *MockAuditLogService) ListByUserID(ctx context.Context, userID uint, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	args := m.Called(ctx, userID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -54,7 +57,7 @@ func (m *MockAuditLogService) ListByUserID(ctx interface{}, userID uint, params
 	return args.Get(0).(*util.PaginatedResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) ListByResource(ctx interface{}, resourceType, resourceID string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+func (m *MockAuditLogService) ListByResource(ctx context.Context, resourceType, resourceID string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	args := m.Called(ctx, resourceType, resourceID, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -62,7 +65,7 @@ func (m *MockAuditLogService) ListByResource(ctx interface{}, resourceType, reso
 	return args.Get(0).(*util.PaginatedResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) ListByAction(ctx interface{}, action string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+func (m *MockAuditLogService) ListByAction(ctx context.Context, action string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	args := m.Called(ctx, action, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -70,7 +73,7 @@ func (m *MockAuditLogService) ListByAction(ctx interface{}, action string, param
 	return args.Get(0).(*util.PaginatedResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) ListByDateRange(ctx interface{}, startDate, endDate time.Time, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+func (m *MockAuditLogService) ListByDateRange(ctx context.Context, startDate, endDate time.Time, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	args := m.Called(ctx, startDate, endDate, params)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
@@ -78,18 +81,34 @@ func (m *MockAuditLogService) ListByDateRange(ctx interface{}, startDate, endDat
 	return args.Get(0).(*util.PaginatedResponse), args.Error(1)
 }
 
-func (m *MockAuditLogService) GetStatistics(ctx interface{}) (interface{}, error) {
-	args := m.Called(ctx)
+func (m *MockAuditLogService) GetStatistics(ctx context.Context, q *model.AuditStatisticsQuery) (*service.AuditStatistics, error) {
+	args := m.Called(ctx, q)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0), args.Error(1)
+	return args.Get(0).(*service.AuditStatistics), args.Error(1)
 }
 
-func (m *MockAuditLogService) DeleteOldLogs(ctx interface{}, days int) error {
+func (m *MockAuditLogService) DeleteOldLogs(ctx context.Context, days int) error {
 	return m.Called(ctx, days).Error(0)
 }
 
+func (m *MockAuditLogService) VerifyChain(ctx context.Context, actorID uint) (*model.AuditChainVerification, error) {
+	args := m.Called(ctx, actorID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AuditChainVerification), args.Error(1)
+}
+
+func (m *MockAuditLogService) WriterStats() *service.AuditLogWriterStats {
+	return nil
+}
+
+func (m *MockAuditLogService) SinkStats() []*auditsink.Stats {
+	return nil
+}
+
 func getHandlerLogger() *zap.Logger {
 	logger, _ := zap.NewProduction()
 	return logger
@@ -113,16 +132,16 @@ func TestAuditLogHandler_List(t *testing.T) {
 	}
 
 	paginatedResp := &util.PaginatedResponse{
-		Status:  200,
-		Message: "OK",
-		Data:    logs,
-		Total:   1,
-		Page:    1,
-		PerPage: 10,
-		Pages:   1,
+		Data: logs,
+		Pagination: util.PaginationInfo{
+			Page:       1,
+			PerPage:    10,
+			Total:      1,
+			TotalPages: 1,
+		},
 	}
 
-	mockService.On("List", mock.Anything, mock.Anything).Return(paginatedResp, nil)
+	mockService.On("List", mock.Anything, mock.Anything, mock.Anything).Return(paginatedResp, nil)
 
 	handler := NewAuditLogHandler(mockService, getHandlerLogger())
 
@@ -130,6 +149,8 @@ func TestAuditLogHandler_List(t *testing.T) {
 	req := httptest.NewRequest("GET", "/api/v1/audit-logs", nil)
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
+	c.Set("user_id", uint(1))
+	c.Set("permissions", []string{"audit_logs:manage"})
 
 	handler.List(c)
 
@@ -137,6 +158,63 @@ func TestAuditLogHandler_List(t *testing.T) {
 	mockService.AssertExpectations(t)
 }
 
+func TestAuditLogHandler_List_RestrictsViewer(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	uintPtr := func(v uint) *uint { return &v }
+	tests := []struct {
+		name        string
+		query       string
+		permissions []string
+		wantStatus  int
+		wantUserID  *uint
+	}{
+		{
+			name:        "Group-granted permission sees all users",
+			query:       "",
+			permissions: []string{"tasks:read", "audit_logs:manage"},
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "Without permission limited to own logs",
+			query:       "",
+			permissions: []string{"tasks:read"},
+			wantStatus:  http.StatusOK,
+			wantUserID:  uintPtr(3),
+		},
+		{
+			name:        "Without permission cannot filter by other user",
+			query:       "?user_id=2",
+			permissions: []string{"tasks:read"},
+			wantStatus:  http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuditLogService)
+			if tt.wantStatus == http.StatusOK {
+				mockService.On("List", mock.Anything, mock.MatchedBy(func(filter *model.AuditLogFilter) bool {
+					return assert.ObjectsAreEqual(tt.wantUserID, filter.UserID)
+				}), mock.Anything).Return(&util.PaginatedResponse{Data: []*model.AuditLogResponse{}}, nil)
+			}
+
+			handler := NewAuditLogHandler(mockService, getHandlerLogger())
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/api/v1/audit-logs"+tt.query, nil)
+			c.Set("user_id", uint(3))
+			c.Set("permissions", tt.permissions)
+
+			handler.List(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
 func TestAuditLogHandler_GetByID(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -226,9 +304,9 @@ func TestAuditLogHandler_Create(t *testing.T) {
 	w := httptest.NewRecorder()
 	req := httptest.NewRequest("POST", "/api/v1/audit-logs", bytes.NewBuffer(body))
 	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.168.1.1:12345"
 	c, _ := gin.CreateTestContext(w)
 	c.Request = req
-	c.RemoteIP = func() string { return "192.168.1.1" }
 
 	handler.Create(c)
 
@@ -241,20 +319,20 @@ func TestAuditLogHandler_GetStatistics(t *testing.T) {
 
 	mockService := new(MockAuditLogService)
 
-	stats := &map[string]interface{}{
-		"total_logs": int64(100),
-		"by_action": map[string]int64{
+	stats := &service.AuditStatistics{
+		TotalLogs: 100,
+		ByAction: map[string]int64{
 			"create": 50,
 			"update": 30,
 		},
-		"by_status": map[string]int64{
+		ByStatus: map[string]int64{
 			"success": 95,
 			"failed":  5,
 		},
-		"success_rate": 0.95,
+		SuccessRate: 0.95,
 	}
 
-	mockService.On("GetStatistics", mock.Anything).Return(stats, nil)
+	mockService.On("GetStatistics", mock.Anything, mock.Anything).Return(stats, nil)
 
 	handler := NewAuditLogHandler(mockService, getHandlerLogger())
 
@@ -294,10 +372,7 @@ func TestAuditLogHandler_ListByResource(t *testing.T) {
 	mockService := new(MockAuditLogService)
 
 	paginatedResp := &util.PaginatedResponse{
-		Status:  200,
-		Message: "OK",
-		Data:    []*model.AuditLogResponse{},
-		Total:   0,
+		Data: []*model.AuditLogResponse{},
 	}
 
 	mockService.On("ListByResource", mock.Anything, model.ResourceTypeUser, "user-123", mock.Anything).
@@ -322,10 +397,7 @@ func TestAuditLogHandler_ListByAction(t *testing.T) {
 	mockService := new(MockAuditLogService)
 
 	paginatedResp := &util.PaginatedResponse{
-		Status:  200,
-		Message: "OK",
-		Data:    []*model.AuditLogResponse{},
-		Total:   0,
+		Data: []*model.AuditLogResponse{},
 	}
 
 	mockService.On("ListByAction", mock.Anything, model.ActionCreate, mock.Anything).
@@ -0,0 +1,228 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// AuditRetentionHandler は監査ログの保持期間ポリシーに関するHTTPハンドラを提供します
+type AuditRetentionHandler struct {
+	service *service.AuditRetentionService
+	logger  *zap.Logger
+}
+
+// NewAuditRetentionHandler は新しいAuditRetentionHandlerを作成します
+func NewAuditRetentionHandler(service *service.AuditRetentionService, logger *zap.Logger) *AuditRetentionHandler {
+	return &AuditRetentionHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List は保持期間ポリシーの一覧を取得します
+// @Summary 保持期間ポリシー一覧取得
+// @Tags audit_retention
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} model.AuditRetentionPolicy
+// @Router /api/v1/audit-retention-policies [get]
+func (h *AuditRetentionHandler) List(c *gin.Context) {
+	policies, err := h.service.List(c.Request.Context())
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"policies": policies})
+}
+
+// GetByID はIDで保持期間ポリシーを取得します
+// @Summary 保持期間ポリシー詳細取得
+// @Tags audit_retention
+// @Security Bearer
+// @Produce json
+// @Param id path int true "ポリシーID"
+// @Success 200 {object} model.AuditRetentionPolicy
+// @Failure 404 {object} util.Response
+// @Router /api/v1/audit-retention-policies/{id} [get]
+func (h *AuditRetentionHandler) GetByID(c *gin.Context) {
+	id, ok := parseRetentionPolicyID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"policy": policy})
+}
+
+// Create は保持期間ポリシーを作成します
+// @Summary 保持期間ポリシー作成
+// @Tags audit_retention
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.CreateAuditRetentionPolicyRequest true "保持期間ポリシー作成リクエスト"
+// @Success 201 {object} model.AuditRetentionPolicy
+// @Failure 400 {object} util.Response "保持期間が下限より短い"
+// @Failure 409 {object} util.Response "同じ名前のポリシーが存在する"
+// @Router /api/v1/audit-retention-policies [post]
+func (h *AuditRetentionHandler) Create(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateAuditRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	policy, err := h.service.Create(c.Request.Context(), actorID, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Created(c, gin.H{"policy": policy})
+}
+
+// Update は保持期間ポリシーを更新します
+// @Summary 保持期間ポリシー更新
+// @Tags audit_retention
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "ポリシーID"
+// @Param request body model.UpdateAuditRetentionPolicyRequest true "保持期間ポリシー更新リクエスト"
+// @Success 200 {object} model.AuditRetentionPolicy
+// @Failure 400 {object} util.Response "保持期間が下限より短い"
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "同じ名前のポリシーが存在する"
+// @Router /api/v1/audit-retention-policies/{id} [patch]
+func (h *AuditRetentionHandler) Update(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseRetentionPolicyID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateAuditRetentionPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	policy, err := h.service.Update(c.Request.Context(), actorID, id, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"policy": policy})
+}
+
+// Delete は保持期間ポリシーを削除します
+// @Summary 保持期間ポリシー削除
+// @Tags audit_retention
+// @Security Bearer
+// @Param id path int true "ポリシーID"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Router /api/v1/audit-retention-policies/{id} [delete]
+func (h *AuditRetentionHandler) Delete(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseRetentionPolicyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), actorID, id); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
+
+// DryRun は有効な保持期間ポリシーを実行した場合に削除される件数をポリシーごとに返します
+// @Summary 保持期間ポリシーのドライラン
+// @Tags audit_retention
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} model.AuditRetentionResult
+// @Router /api/v1/audit-retention-policies/dry-run [get]
+func (h *AuditRetentionHandler) DryRun(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Run(c.Request.Context(), actorID, true)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// Run は有効な保持期間ポリシーを直ちに実行し、保持期間を過ぎた監査ログを削除します
+// dry_run=true の場合は削除せず、削除される件数を返します
+// @Summary 保持期間ポリシーの実行
+// @Tags audit_retention
+// @Security Bearer
+// @Produce json
+// @Param dry_run query bool false "削除せずに件数のみ返す"
+// @Success 200 {object} model.AuditRetentionResult
+// @Router /api/v1/audit-retention-policies/run [post]
+func (h *AuditRetentionHandler) Run(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(c.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid dry_run value", nil)
+		return
+	}
+
+	result, err := h.service.Run(c.Request.Context(), actorID, dryRun)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// parseRetentionPolicyID はパスパラメータから保持期間ポリシーIDを取得します
+func parseRetentionPolicyID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid retention policy ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -34,15 +34,17 @@ func NewAuthHandler(authService *service.AuthService, logger *zap.Logger) *AuthH
 // @Success 200 {object} util.Response{data=service.LoginResponse} "ログイン成功"
 // @Failure 400 {object} util.Response "バリデーションエラー"
 // @Failure 401 {object} util.Response "認証エラー"
-// @Failure 403 {object} util.Response "アカウントが無効"
+// @Failure 403 {object} util.Response "アカウントが無効、不審なログインによる停止、または送信元のIPアドレスからのログインの拒否"
 // @Failure 500 {object} util.Response "サーバーエラー"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req service.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		util.ValidationError(c, err)
+		util.ValidationError(c, util.ParseValidationErrors(err))
 		return
 	}
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
 
 	response, err := h.authService.Login(c.Request.Context(), &req)
 	if err != nil {
@@ -68,7 +70,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	var req service.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		util.ValidationError(c, err)
+		util.ValidationError(c, util.ParseValidationErrors(err))
 		return
 	}
 
@@ -95,7 +97,7 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 func (h *AuthHandler) Logout(c *gin.Context) {
 	var req service.RefreshTokenRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		util.ValidationError(c, err)
+		util.ValidationError(c, util.ParseValidationErrors(err))
 		return
 	}
 
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// EmailVerificationHandler はメールアドレス確認に関するHTTPハンドラを提供します
+type EmailVerificationHandler struct {
+	service *service.EmailVerificationService
+	logger  *zap.Logger
+}
+
+// NewEmailVerificationHandler は新しいEmailVerificationHandlerを作成します
+func NewEmailVerificationHandler(service *service.EmailVerificationService, logger *zap.Logger) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Confirm は確認トークンを検証してメールアドレスを確認済みにします
+// @Summary メールアドレス確認
+// @Tags email-verification
+// @Accept json
+// @Produce json
+// @Param request body model.VerifyEmailRequest true "確認リクエスト"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} util.Response "トークンが不正または期限切れ"
+// @Failure 409 {object} util.Response "メールアドレスが使用済み"
+// @Router /api/v1/email/verify [post]
+func (h *EmailVerificationHandler) Confirm(c *gin.Context) {
+	var req model.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	user, err := h.service.Confirm(c.Request.Context(), req.Token)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": user})
+}
+
+// Resend は指定したユーザーに確認メールを再送します
+// @Summary 確認メール再送
+// @Tags email-verification
+// @Security Bearer
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Success 200 {object} model.UserResponse
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "確認済み"
+// @Router /api/v1/users/{id}/email-verification/resend [post]
+func (h *EmailVerificationHandler) Resend(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+		return
+	}
+
+	user, err := h.service.Resend(c.Request.Context(), actorID, uint(id))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": user})
+}
+
+// ResendMe はログイン中のユーザー自身に確認メールを再送します
+// @Summary 自分宛ての確認メール再送
+// @Tags me
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} model.UserResponse
+// @Failure 409 {object} util.Response "確認済み"
+// @Router /api/v1/me/email-verification/resend [post]
+func (h *EmailVerificationHandler) ResendMe(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.Resend(c.Request.Context(), userID, userID)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": user})
+}
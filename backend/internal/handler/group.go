@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// GroupHandler はユーザーグループに関するHTTPハンドラを提供します
+type GroupHandler struct {
+	service *service.GroupService
+	logger  *zap.Logger
+}
+
+// NewGroupHandler は新しいGroupHandlerを作成します
+func NewGroupHandler(service *service.GroupService, logger *zap.Logger) *GroupHandler {
+	return &GroupHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List はグループ一覧を取得します
+// @Summary グループ一覧取得
+// @Tags groups
+// @Security Bearer
+// @Produce json
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Router /api/v1/groups [get]
+func (h *GroupHandler) List(c *gin.Context) {
+	params := util.GetPaginationParams(c)
+	result, err := h.service.List(c.Request.Context(), params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// GetByID はIDでグループを取得します
+// @Summary グループ詳細取得
+// @Tags groups
+// @Security Bearer
+// @Produce json
+// @Param id path int true "グループID"
+// @Success 200 {object} model.Group
+// @Failure 404 {object} util.Response
+// @Router /api/v1/groups/{id} [get]
+func (h *GroupHandler) GetByID(c *gin.Context) {
+	id, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	group, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"group": group})
+}
+
+// Create はグループを作成します
+// @Summary グループ作成
+// @Tags groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.CreateGroupRequest true "グループ作成リクエスト"
+// @Success 201 {object} model.Group
+// @Failure 409 {object} util.Response "グループ名が使用済み"
+// @Router /api/v1/groups [post]
+func (h *GroupHandler) Create(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	group, err := h.service.Create(c.Request.Context(), actorID, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Created(c, gin.H{"group": group})
+}
+
+// Update はグループを更新します
+// @Summary グループ更新
+// @Tags groups
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "グループID"
+// @Param request body model.UpdateGroupRequest true "グループ更新リクエスト"
+// @Success 200 {object} model.Group
+// @Failure 400 {object} util.Response "親グループの指定が循環している"
+// @Failure 404 {object} util.Response
+// @Router /api/v1/groups/{id} [patch]
+func (h *GroupHandler) Update(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var req model.UpdateGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	group, err := h.service.Update(c.Request.Context(), actorID, id, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"group": group})
+}
+
+// Delete はグループを削除します
+// @Summary グループ削除
+// @Tags groups
+// @Security Bearer
+// @Param id path int true "グループID"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "子グループが存在する"
+// @Router /api/v1/groups/{id} [delete]
+func (h *GroupHandler) Delete(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), actorID, id); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
+
+// ListMembers はグループのメンバー一覧を取得します
+// @Summary グループメンバー一覧取得
+// @Tags groups
+// @Security Bearer
+// @Produce json
+// @Param id path int true "グループID"
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Router /api/v1/groups/{id}/members [get]
+func (h *GroupHandler) ListMembers(c *gin.Context) {
+	id, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	params := util.GetPaginationParams(c)
+	result, err := h.service.ListMembers(c.Request.Context(), id, params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// AddMembers はグループにメンバーを追加します
+// @Summary グループメンバー追加
+// @Tags groups
+// @Security Bearer
+// @Accept json
+// @Param id path int true "グループID"
+// @Param request body model.GroupMembersRequest true "追加するユーザー"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Router /api/v1/groups/{id}/members [post]
+func (h *GroupHandler) AddMembers(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	var req model.GroupMembersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	if err := h.service.AddMembers(c.Request.Context(), actorID, id, &req); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
+
+// RemoveMember はグループからメンバーを削除します
+// @Summary グループメンバー削除
+// @Tags groups
+// @Security Bearer
+// @Param id path int true "グループID"
+// @Param user_id path int true "ユーザーID"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Router /api/v1/groups/{id}/members/{user_id} [delete]
+func (h *GroupHandler) RemoveMember(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseGroupID(c)
+	if !ok {
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.service.RemoveMember(c.Request.Context(), actorID, id, uint(userID)); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
+
+// parseGroupID はパスパラメータからグループIDを取得します
+// 形式が不正な場合は400を返して false を返します
+func parseGroupID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid group ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
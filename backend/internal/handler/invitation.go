@@ -0,0 +1,174 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// InvitationHandler はユーザー招待に関するHTTPハンドラを提供します
+type InvitationHandler struct {
+	service *service.InvitationService
+	logger  *zap.Logger
+}
+
+// NewInvitationHandler は新しいInvitationHandlerを作成します
+func NewInvitationHandler(service *service.InvitationService, logger *zap.Logger) *InvitationHandler {
+	return &InvitationHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Invite はユーザーを招待します
+// @Summary ユーザー招待
+// @Tags invitations
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.InviteUserRequest true "招待リクエスト"
+// @Success 201 {object} model.InviteUserResponse
+// @Failure 409 {object} util.Response "ユーザー名またはメールアドレスが使用済み"
+// @Router /api/v1/users/invite [post]
+func (h *InvitationHandler) Invite(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.InviteUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	result, err := h.service.Invite(c.Request.Context(), actorID, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Created(c, result)
+}
+
+// List は招待一覧を取得します
+// @Summary 招待一覧取得
+// @Tags invitations
+// @Security Bearer
+// @Produce json
+// @Param status query string false "ステータス（pending, accepted, revoked, expired）"
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Router /api/v1/invitations [get]
+func (h *InvitationHandler) List(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", model.InvitationStatusPending, model.InvitationStatusAccepted, model.InvitationStatusRevoked, model.InvitationStatusExpired:
+	default:
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid status", nil)
+		return
+	}
+
+	params := util.GetPaginationParams(c)
+	result, err := h.service.List(c.Request.Context(), params, status)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// Resend は招待メールを再送します
+// @Summary 招待再送
+// @Tags invitations
+// @Security Bearer
+// @Produce json
+// @Param id path int true "招待ID"
+// @Success 200 {object} model.InvitationResponse
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "受諾済みまたは取り消し済み"
+// @Router /api/v1/invitations/{id}/resend [post]
+func (h *InvitationHandler) Resend(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid invitation ID", nil)
+		return
+	}
+
+	invitation, err := h.service.Resend(c.Request.Context(), actorID, uint(id))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"invitation": invitation})
+}
+
+// Revoke は招待を取り消します
+// @Summary 招待取り消し
+// @Tags invitations
+// @Security Bearer
+// @Produce json
+// @Param id path int true "招待ID"
+// @Success 200 {object} model.InvitationResponse
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "受諾済みまたは取り消し済み"
+// @Router /api/v1/invitations/{id}/revoke [post]
+func (h *InvitationHandler) Revoke(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid invitation ID", nil)
+		return
+	}
+
+	invitation, err := h.service.Revoke(c.Request.Context(), actorID, uint(id))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"invitation": invitation})
+}
+
+// Accept は招待を受諾してアカウントを有効化します（認証不要）
+// @Summary 招待受諾
+// @Tags invitations
+// @Accept json
+// @Produce json
+// @Param request body model.AcceptInvitationRequest true "招待受諾リクエスト"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} util.Response "トークンが不正・期限切れ・使用済み"
+// @Router /api/v1/invitations/accept [post]
+func (h *InvitationHandler) Accept(c *gin.Context) {
+	var req model.AcceptInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	user, err := h.service.Accept(c.Request.Context(), &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": user})
+}
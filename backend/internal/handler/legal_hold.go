@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// LegalHoldHandler は訴訟ホールドに関するHTTPハンドラを提供します
+type LegalHoldHandler struct {
+	service *service.LegalHoldService
+	logger  *zap.Logger
+}
+
+// NewLegalHoldHandler は新しいLegalHoldHandlerを作成します
+func NewLegalHoldHandler(service *service.LegalHoldService, logger *zap.Logger) *LegalHoldHandler {
+	return &LegalHoldHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List は訴訟ホールドの一覧を取得します
+// @Summary 訴訟ホールド一覧取得
+// @Tags legal_holds
+// @Security Bearer
+// @Produce json
+// @Param include_released query bool false "解除済みのホールドを含める"
+// @Success 200 {array} model.LegalHold
+// @Router /api/v1/legal-holds [get]
+func (h *LegalHoldHandler) List(c *gin.Context) {
+	includeReleased, err := strconv.ParseBool(c.DefaultQuery("include_released", "false"))
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid include_released value", nil)
+		return
+	}
+
+	holds, err := h.service.List(c.Request.Context(), includeReleased)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"holds": holds})
+}
+
+// GetByID はIDで訴訟ホールドを取得します
+// @Summary 訴訟ホールド詳細取得
+// @Tags legal_holds
+// @Security Bearer
+// @Produce json
+// @Param id path int true "ホールドID"
+// @Success 200 {object} model.LegalHold
+// @Failure 404 {object} util.Response
+// @Router /api/v1/legal-holds/{id} [get]
+func (h *LegalHoldHandler) GetByID(c *gin.Context) {
+	id, ok := parseLegalHoldID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"hold": hold})
+}
+
+// Place は訴訟ホールドを設定します
+// @Summary 訴訟ホールド設定
+// @Tags legal_holds
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.PlaceLegalHoldRequest true "訴訟ホールド設定リクエスト"
+// @Success 201 {object} model.LegalHold
+// @Failure 400 {object} util.Response "対象の条件が指定されていない"
+// @Failure 404 {object} util.Response "対象または責任者のユーザーが存在しない"
+// @Router /api/v1/legal-holds [post]
+func (h *LegalHoldHandler) Place(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.PlaceLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	hold, err := h.service.Place(c.Request.Context(), actorID, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Created(c, gin.H{"hold": hold})
+}
+
+// Release は訴訟ホールドを解除します
+// @Summary 訴訟ホールド解除
+// @Tags legal_holds
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "ホールドID"
+// @Param request body model.ReleaseLegalHoldRequest true "訴訟ホールド解除リクエスト"
+// @Success 200 {object} model.LegalHold
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "既に解除されている"
+// @Router /api/v1/legal-holds/{id}/release [post]
+func (h *LegalHoldHandler) Release(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseLegalHoldID(c)
+	if !ok {
+		return
+	}
+
+	var req model.ReleaseLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	hold, err := h.service.Release(c.Request.Context(), actorID, id, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"hold": hold})
+}
+
+// parseLegalHoldID はパスパラメータから訴訟ホールドIDを取得します
+func parseLegalHoldID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid legal hold ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
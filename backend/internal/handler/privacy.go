@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// PrivacyHandler は個人データの開示・消去請求に関するHTTPハンドラを提供します
+type PrivacyHandler struct {
+	service *service.PrivacyService
+	logger  *zap.Logger
+}
+
+// NewPrivacyHandler は新しいPrivacyHandlerを作成します
+func NewPrivacyHandler(service *service.PrivacyService, logger *zap.Logger) *PrivacyHandler {
+	return &PrivacyHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// Export はユーザーの個人データを出力します
+// @Summary 個人データ出力（開示請求）
+// @Tags privacy
+// @Security Bearer
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Success 200 {object} service.PersonalDataExport
+// @Failure 404 {object} util.Response
+// @Router /api/v1/users/{id}/personal-data [get]
+func (h *PrivacyHandler) Export(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+		return
+	}
+
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	export, err := h.service.Export(c.Request.Context(), actorID, uint(id))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	// ダウンロードとして扱えるようにファイル名を指定
+	c.Header("Content-Disposition", "attachment; filename=personal-data-"+c.Param("id")+".json")
+	util.Success(c, export)
+}
+
+// Anonymize はユーザーの個人情報を匿名化します
+// @Summary 個人データ匿名化（消去請求）
+// @Tags privacy
+// @Security Bearer
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Success 200 {object} service.AnonymizeResult
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "訴訟ホールドの対象"
+// @Router /api/v1/users/{id}/anonymize [post]
+func (h *PrivacyHandler) Anonymize(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+		return
+	}
+
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.service.Anonymize(c.Request.Context(), actorID, uint(id))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
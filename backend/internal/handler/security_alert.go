@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// SecurityAlertHandler はセキュリティアラートに関するHTTPハンドラを提供します
+type SecurityAlertHandler struct {
+	service *service.SecurityAlertService
+	logger  *zap.Logger
+}
+
+// NewSecurityAlertHandler は新しいSecurityAlertHandlerを作成します
+func NewSecurityAlertHandler(service *service.SecurityAlertService, logger *zap.Logger) *SecurityAlertHandler {
+	return &SecurityAlertHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List はセキュリティアラートの一覧を取得します
+// @Summary セキュリティアラート一覧取得
+// @Tags security_alerts
+// @Security Bearer
+// @Produce json
+// @Param status query string false "状態（open, acknowledged）"
+// @Param severity query string false "重要度（low, medium, high）"
+// @Param rule query string false "検知ルール（password_spraying, new_ip, new_user_agent, impossible_travel）"
+// @Param user_id query int false "対象のユーザーID"
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Failure 400 {object} util.Response
+// @Router /api/v1/security-alerts [get]
+func (h *SecurityAlertHandler) List(c *gin.Context) {
+	filter := &model.SecurityAlertFilter{
+		Status:   c.Query("status"),
+		Severity: c.Query("severity"),
+		Rule:     c.Query("rule"),
+	}
+	switch filter.Status {
+	case "", model.SecurityAlertStatusOpen, model.SecurityAlertStatusAcknowledged:
+	default:
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid alert status", nil)
+		return
+	}
+	if filter.Severity != "" && !model.IsValidSecuritySeverity(filter.Severity) {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid alert severity", nil)
+		return
+	}
+	if filter.Rule != "" && !model.IsValidSecurityRule(filter.Rule) {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid alert rule", nil)
+		return
+	}
+	if value := c.Query("user_id"); value != "" {
+		userID, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+			return
+		}
+		id := uint(userID)
+		filter.UserID = &id
+	}
+
+	params := util.GetPaginationParams(c)
+	result, err := h.service.List(c.Request.Context(), filter, params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// GetByID はIDでセキュリティアラートを取得します
+// @Summary セキュリティアラート詳細取得
+// @Tags security_alerts
+// @Security Bearer
+// @Produce json
+// @Param id path int true "アラートID"
+// @Success 200 {object} model.SecurityAlert
+// @Failure 404 {object} util.Response
+// @Router /api/v1/security-alerts/{id} [get]
+func (h *SecurityAlertHandler) GetByID(c *gin.Context) {
+	id, ok := parseSecurityAlertID(c)
+	if !ok {
+		return
+	}
+
+	alert, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"alert": alert})
+}
+
+// Acknowledge はセキュリティアラートを確認済みにします
+// @Summary セキュリティアラート確認
+// @Tags security_alerts
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "アラートID"
+// @Param request body model.AcknowledgeSecurityAlertRequest false "確認リクエスト"
+// @Success 200 {object} model.SecurityAlert
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "既に確認済み"
+// @Router /api/v1/security-alerts/{id}/acknowledge [post]
+func (h *SecurityAlertHandler) Acknowledge(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseSecurityAlertID(c)
+	if !ok {
+		return
+	}
+
+	// メモは任意のため、本文のないリクエストも受け付ける
+	var req model.AcknowledgeSecurityAlertRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	alert, err := h.service.Acknowledge(c.Request.Context(), actorID, id, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"alert": alert})
+}
+
+// parseSecurityAlertID はパスパラメータからセキュリティアラートIDを取得します
+func parseSecurityAlertID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid security alert ID", nil)
+		return 0, false
+	}
+	return uint(id), true
+}
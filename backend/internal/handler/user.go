@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"go.uber.org/zap"
 
 	"github.com/varubogu/effisio/backend/internal/model"
@@ -32,11 +37,12 @@ func NewUserHandler(service *service.UserService, logger *zap.Logger) *UserHandl
 // @Produce json
 // @Param page query int false "ページ番号" default(1)
 // @Param per_page query int false "1ページあたりの件数" default(10)
+// @Param attr[key] query string false "追加属性の値で絞り込み（例: attr[cost_center]=CC-100）"
 // @Success 200 {object} util.PaginatedResponse
 // @Router /api/v1/users [get]
 func (h *UserHandler) List(c *gin.Context) {
 	params := util.GetPaginationParams(c)
-	result, err := h.service.List(c.Request.Context(), params)
+	result, err := h.service.List(c.Request.Context(), params, c.QueryMap("attr"))
 	if err != nil {
 		util.HandleError(c, err)
 		return
@@ -66,6 +72,7 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	c.Header("ETag", user.ETag)
 	util.Success(c, gin.H{"user": user})
 }
 
@@ -78,13 +85,18 @@ func (h *UserHandler) GetByID(c *gin.Context) {
 // @Success 201 {object} model.UserResponse
 // @Router /api/v1/users [post]
 func (h *UserHandler) Create(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
 	var req model.CreateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.ValidationError(c, util.ParseValidationErrors(err))
 		return
 	}
 
-	user, err := h.service.Create(c.Request.Context(), &req)
+	user, err := h.service.Create(c.Request.Context(), actorID, &req)
 	if err != nil {
 		util.HandleError(c, err)
 		return
@@ -99,8 +111,11 @@ func (h *UserHandler) Create(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path int true "ユーザーID"
+// @Param If-Match header string true "取得時のETag"
 // @Param request body model.UpdateUserRequest true "ユーザー更新リクエスト"
 // @Success 200 {object} model.UserResponse
+// @Failure 412 {object} util.Response "他のリクエストにより更新済み"
+// @Failure 428 {object} util.Response "If-Matchヘッダーが必要"
 // @Router /api/v1/users/{id} [put]
 func (h *UserHandler) Update(c *gin.Context) {
 	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -109,18 +124,85 @@ func (h *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
 	var req model.UpdateUserRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		util.ValidationError(c, util.ParseValidationErrors(err))
 		return
 	}
 
-	user, err := h.service.Update(c.Request.Context(), uint(id), &req)
+	user, err := h.service.Update(c.Request.Context(), actorID, uint(id), &req, version)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	c.Header("ETag", user.ETag)
+	util.Success(c, gin.H{"user": user})
+}
+
+// Patch はJSON Merge PatchまたはJSON Patchでユーザー情報を部分更新します
+// @Summary ユーザー部分更新
+// @Tags users
+// @Accept application/merge-patch+json
+// @Accept application/json-patch+json
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Param If-Match header string true "取得時のETag"
+// @Param request body model.UserPatchDocument true "パッチ（RFC 7396 または RFC 6902）"
+// @Success 200 {object} model.UserResponse
+// @Failure 400 {object} util.Response "パッチの形式が不正、または適用結果が不正"
+// @Failure 409 {object} util.Response "JSON Patchのtest操作が不一致"
+// @Failure 412 {object} util.Response "他のリクエストにより更新済み"
+// @Failure 415 {object} util.Response "未対応のContent-Type"
+// @Failure 428 {object} util.Response "If-Matchヘッダーが必要"
+// @Router /api/v1/users/{id} [patch]
+func (h *UserHandler) Patch(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, 400, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+		return
+	}
+
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	contentType := c.ContentType()
+	if contentType != util.ContentTypeMergePatch && contentType != util.ContentTypeJSONPatch {
+		c.Header("Accept-Patch", util.ContentTypeMergePatch+", "+util.ContentTypeJSONPatch)
+		util.Error(c, http.StatusUnsupportedMediaType, util.ErrCodeInvalidParameter, "Unsupported patch content type", nil)
+		return
+	}
+
+	version, ok := requireIfMatch(c)
+	if !ok {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Failed to read request body", nil)
+		return
+	}
+
+	user, err := h.service.Patch(c.Request.Context(), actorID, uint(id), contentType, patch, version)
 	if err != nil {
 		util.HandleError(c, err)
 		return
 	}
 
+	c.Header("ETag", user.ETag)
 	util.Success(c, gin.H{"user": user})
 }
 
@@ -139,10 +221,169 @@ func (h *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := h.service.Delete(c.Request.Context(), uint(id)); err != nil {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), actorID, uint(id)); err != nil {
 		util.HandleError(c, err)
 		return
 	}
 
 	util.NoContent(c)
 }
+
+// ListDeleted は削除済みユーザー一覧を取得します
+// @Summary 削除済みユーザー一覧取得
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Router /api/v1/users/deleted [get]
+func (h *UserHandler) ListDeleted(c *gin.Context) {
+	params := util.GetPaginationParams(c)
+	result, err := h.service.ListDeleted(c.Request.Context(), params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// Restore は削除済みユーザーを復元します
+// @Summary ユーザー復元
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "ユーザーID"
+// @Success 200 {object} model.UserResponse
+// @Failure 404 {object} util.Response
+// @Failure 409 {object} util.Response "ユーザー名またはメールアドレスが再利用済み"
+// @Router /api/v1/users/{id}/restore [post]
+func (h *UserHandler) Restore(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, 400, util.ErrCodeInvalidParameter, "Invalid user ID", nil)
+		return
+	}
+
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.service.Restore(c.Request.Context(), actorID, uint(id))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": user})
+}
+
+// GetMe はログイン中のユーザー自身の情報を取得します
+// @Summary ログイン中のユーザー情報取得
+// @Tags me
+// @Security Bearer
+// @Produce json
+// @Success 200 {object} model.MeResponse
+// @Failure 401 {object} util.Response
+// @Router /api/v1/me [get]
+func (h *UserHandler) GetMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Error(c, http.StatusUnauthorized, util.ErrCodeUnauthorized, "authentication required", nil)
+		return
+	}
+
+	me, err := h.service.GetMe(c.Request.Context(), userID.(uint))
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": me})
+}
+
+// UpdateMe はログイン中のユーザー自身の情報を更新します
+// 変更が許可されていない項目が含まれる場合は400を返します
+// @Summary ログイン中のユーザー情報更新
+// @Tags me
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.UpdateMeRequest true "更新リクエスト"
+// @Success 200 {object} model.MeResponse
+// @Failure 400 {object} util.Response
+// @Failure 401 {object} util.Response
+// @Failure 412 {object} util.Response "他のリクエストにより更新済み"
+// @Router /api/v1/me [patch]
+func (h *UserHandler) UpdateMe(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Error(c, http.StatusUnauthorized, util.ErrCodeUnauthorized, "authentication required", nil)
+		return
+	}
+
+	// 許可されていない項目を黙って無視しないよう、未知のフィールドはエラーにする
+	var req model.UpdateMeRequest
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeValidationError, "Invalid request body or field not allowed", nil)
+		return
+	}
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	me, err := h.service.UpdateMe(c.Request.Context(), userID.(uint), &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"user": me})
+}
+
+// requireIfMatch はIf-Matchヘッダーから更新対象のバージョンを取得します
+// ヘッダーがない場合は428、形式が不正な場合は400、弱いエンティティタグなど一致し得ない場合は412を返して false を返します
+func requireIfMatch(c *gin.Context) (uint, bool) {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		util.Error(c, http.StatusPreconditionRequired, util.ErrCodePreconditionRequired, "If-Match header is required", nil)
+		return 0, false
+	}
+
+	version, err := model.ParseETag(ifMatch)
+	if errors.Is(err, model.ErrETagNeverMatches) {
+		util.Error(c, http.StatusPreconditionFailed, util.ErrCodeVersionConflict, "If-Match does not match the current version", nil)
+		return 0, false
+	}
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid If-Match header", nil)
+		return 0, false
+	}
+	return version, true
+}
+
+// currentUserID は認証ミドルウェアが設定したログイン中のユーザーIDを取得します
+// 取得できない場合は401を返して false を返します
+func currentUserID(c *gin.Context) (uint, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		util.Error(c, http.StatusUnauthorized, util.ErrCodeUnauthorized, "authentication required", nil)
+		return 0, false
+	}
+	id, ok := userID.(uint)
+	if !ok {
+		util.Error(c, http.StatusUnauthorized, util.ErrCodeUnauthorized, "authentication required", nil)
+		return 0, false
+	}
+	return id, true
+}
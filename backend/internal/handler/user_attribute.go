@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// UserAttributeHandler はユーザー追加属性の定義に関するHTTPハンドラを提供します
+type UserAttributeHandler struct {
+	service *service.UserAttributeService
+	logger  *zap.Logger
+}
+
+// NewUserAttributeHandler は新しいUserAttributeHandlerを作成します
+func NewUserAttributeHandler(service *service.UserAttributeService, logger *zap.Logger) *UserAttributeHandler {
+	return &UserAttributeHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List は属性定義の一覧を取得します
+// @Summary ユーザー追加属性の定義一覧取得
+// @Tags user-attributes
+// @Security Bearer
+// @Produce json
+// @Success 200 {array} model.UserAttributeDefinition
+// @Router /api/v1/user-attributes [get]
+func (h *UserAttributeHandler) List(c *gin.Context) {
+	definitions, err := h.service.List(c.Request.Context())
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"attributes": definitions})
+}
+
+// Create は属性定義を作成します
+// @Summary ユーザー追加属性の定義作成
+// @Tags user-attributes
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.CreateUserAttributeDefinitionRequest true "属性定義作成リクエスト"
+// @Success 201 {object} model.UserAttributeDefinition
+// @Failure 409 {object} util.Response "キーが使用済み"
+// @Router /api/v1/user-attributes [post]
+func (h *UserAttributeHandler) Create(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateUserAttributeDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	definition, err := h.service.Create(c.Request.Context(), actorID, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Created(c, gin.H{"attribute": definition})
+}
+
+// Update は属性定義を更新します
+// @Summary ユーザー追加属性の定義更新
+// @Tags user-attributes
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "属性定義ID"
+// @Param request body model.UpdateUserAttributeDefinitionRequest true "属性定義更新リクエスト"
+// @Success 200 {object} model.UserAttributeDefinition
+// @Failure 404 {object} util.Response
+// @Router /api/v1/user-attributes/{id} [patch]
+func (h *UserAttributeHandler) Update(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid attribute ID", nil)
+		return
+	}
+
+	var req model.UpdateUserAttributeDefinitionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	definition, err := h.service.Update(c.Request.Context(), actorID, uint(id), &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"attribute": definition})
+}
+
+// Delete は属性定義を削除します
+// @Summary ユーザー追加属性の定義削除
+// @Tags user-attributes
+// @Security Bearer
+// @Param id path int true "属性定義ID"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Router /api/v1/user-attributes/{id} [delete]
+func (h *UserAttributeHandler) Delete(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid attribute ID", nil)
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), actorID, uint(id)); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
@@ -0,0 +1,298 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/service"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// WebhookHandler はWebhookの送信先と配信に関するHTTPハンドラを提供します
+type WebhookHandler struct {
+	service *service.WebhookService
+	logger  *zap.Logger
+}
+
+// NewWebhookHandler は新しいWebhookHandlerを作成します
+func NewWebhookHandler(service *service.WebhookService, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		logger:  logger,
+	}
+}
+
+// List はWebhookの送信先一覧を取得します
+// @Summary Webhook一覧取得
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Router /api/v1/webhooks [get]
+func (h *WebhookHandler) List(c *gin.Context) {
+	params := util.GetPaginationParams(c)
+	result, err := h.service.List(c.Request.Context(), params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// GetByID はIDでWebhookの送信先を取得します
+// @Summary Webhook詳細取得
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetByID(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.GetByID(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"webhook": subscription})
+}
+
+// Create はWebhookの送信先を作成します
+// シークレットはこのレスポンスでのみ返します
+// @Summary Webhook作成
+// @Tags webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param request body model.CreateWebhookRequest true "Webhook作成リクエスト"
+// @Success 201 {object} model.CreatedWebhookResponse
+// @Failure 400 {object} util.Response "イベント種別が不正"
+// @Router /api/v1/webhooks [post]
+func (h *WebhookHandler) Create(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	created, err := h.service.Create(c.Request.Context(), actorID, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Created(c, gin.H{"webhook": created})
+}
+
+// Update はWebhookの送信先を更新します
+// @Summary Webhook更新
+// @Tags webhooks
+// @Security Bearer
+// @Accept json
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Param request body model.UpdateWebhookRequest true "Webhook更新リクエスト"
+// @Success 200 {object} model.WebhookSubscription
+// @Failure 400 {object} util.Response "イベント種別が不正"
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhooks/{id} [patch]
+func (h *WebhookHandler) Update(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	var req model.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		util.ValidationError(c, util.ParseValidationErrors(err))
+		return
+	}
+
+	subscription, err := h.service.Update(c.Request.Context(), actorID, id, &req)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"webhook": subscription})
+}
+
+// Delete はWebhookの送信先を削除します
+// @Summary Webhook削除
+// @Tags webhooks
+// @Security Bearer
+// @Param id path int true "WebhookID"
+// @Success 204
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.Delete(c.Request.Context(), actorID, id); err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.NoContent(c)
+}
+
+// ListDeliveries はWebhookの配信ログを取得します
+// @Summary Webhook配信一覧取得
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Param status query string false "配信ステータス（pending, succeeded, dead）"
+// @Param page query int false "ページ番号" default(1)
+// @Param per_page query int false "1ページあたりの件数" default(10)
+// @Success 200 {object} util.PaginatedResponse
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", model.WebhookDeliveryPending, model.WebhookDeliverySucceeded, model.WebhookDeliveryDead:
+	default:
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, "Invalid delivery status", nil)
+		return
+	}
+
+	params := util.GetPaginationParams(c)
+	result, err := h.service.ListDeliveries(c.Request.Context(), id, status, params)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Paginated(c, result)
+}
+
+// ReplayDeadDeliveries はWebhookのデッドレターをすべて再送します
+// @Summary Webhookデッドレター一括再送
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "WebhookID"
+// @Success 200 {object} model.ReplayWebhookDeliveriesResponse
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhooks/{id}/deliveries/replay [post]
+func (h *WebhookHandler) ReplayDeadDeliveries(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseWebhookID(c, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	result, err := h.service.ReplayDeadDeliveries(c.Request.Context(), actorID, id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, result)
+}
+
+// GetDelivery は配信と送信履歴を取得します
+// @Summary Webhook配信詳細取得
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "配信ID"
+// @Success 200 {object} model.WebhookDeliveryDetailResponse
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhook-deliveries/{id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, ok := parseWebhookID(c, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(c.Request.Context(), id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"delivery": delivery})
+}
+
+// ReplayDelivery は配信を再送します
+// 送信済みやデッドレターの配信も送信待ちに戻し、同じ本文を再送します
+// @Summary Webhook配信再送
+// @Tags webhooks
+// @Security Bearer
+// @Produce json
+// @Param id path int true "配信ID"
+// @Success 200 {object} model.WebhookDelivery
+// @Failure 404 {object} util.Response
+// @Router /api/v1/webhook-deliveries/{id}/replay [post]
+func (h *WebhookHandler) ReplayDelivery(c *gin.Context) {
+	actorID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	id, ok := parseWebhookID(c, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.ReplayDelivery(c.Request.Context(), actorID, id)
+	if err != nil {
+		util.HandleError(c, err)
+		return
+	}
+
+	util.Success(c, gin.H{"delivery": delivery})
+}
+
+// parseWebhookID はパスパラメータからIDを取得します
+// 形式が不正な場合は400を返して false を返します
+func parseWebhookID(c *gin.Context, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		util.Error(c, http.StatusBadRequest, util.ErrCodeInvalidParameter, message, nil)
+		return 0, false
+	}
+	return uint(id), true
+}
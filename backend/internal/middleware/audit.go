@@ -0,0 +1,241 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+const (
+	// auditReadListID は一覧の参照を記録する場合のリソースIDです
+	auditReadListID = "list"
+	// auditResourceIDMaxLength は監査ログのリソースIDの最大長です
+	auditResourceIDMaxLength = 50
+)
+
+// 認可の要件の種類
+const (
+	denialRequirementPermission = "permission"
+	denialRequirementRole       = "role"
+)
+
+// AuditLogger は監査ログを記録します（service.AuditLogService が実装します）
+type AuditLogger interface {
+	LogAction(ctx context.Context, req *model.CreateAuditLogRequest) (*model.AuditLogResponse, error)
+}
+
+// AuditAccessConfig はリソースの参照と認可の拒否の監査ログに関する設定です
+type AuditAccessConfig struct {
+	ReadEnabled         bool          // 機密性の高いリソースの参照を記録するか
+	DenialEnabled       bool          // 認可の拒否を記録するか
+	ReadDedupWindow     time.Duration // 同じ利用者による同じ参照を再び記録するまでの期間（0以下の場合は毎回記録）
+	ReadDedupMaxEntries int           // 重複の判定のために保持する参照の最大数
+}
+
+// AuditMiddleware はリソースの参照と認可の拒否を監査ログに記録するミドルウェアを提供します
+type AuditMiddleware struct {
+	auditLogger AuditLogger
+	config      AuditAccessConfig
+	logger      *zap.Logger
+	reads       *readDeduper
+}
+
+// NewAuditMiddleware は新しいAuditMiddlewareを作成します
+func NewAuditMiddleware(auditLogger AuditLogger, config AuditAccessConfig, logger *zap.Logger) *AuditMiddleware {
+	return &AuditMiddleware{
+		auditLogger: auditLogger,
+		config:      config,
+		logger:      logger,
+		reads:       newReadDeduper(config.ReadDedupWindow, config.ReadDedupMaxEntries),
+	}
+}
+
+// AuditRead はリソースの参照が成功した場合に監査ログに記録します
+// idParam のパスパラメータをリソースIDとし、idParam が空の場合は一覧の参照として記録します
+// このミドルウェアは RequireAuth の後に使用する必要があります
+func (m *AuditMiddleware) AuditRead(resourceType, idParam string) gin.HandlerFunc {
+	return m.auditRead(resourceType, idParam, false)
+}
+
+// AuditReadOfOthers は AuditRead と同様に記録しますが、本人のリソース（idParam が本人のユーザーID）の参照は記録しません
+func (m *AuditMiddleware) AuditReadOfOthers(resourceType, idParam string) gin.HandlerFunc {
+	return m.auditRead(resourceType, idParam, true)
+}
+
+func (m *AuditMiddleware) auditRead(resourceType, idParam string, skipSelf bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if !m.config.ReadEnabled {
+			return
+		}
+		// 失敗したリクエストではリソースを参照できていないため記録しない
+		if status := c.Writer.Status(); status < http.StatusOK || status >= http.StatusMultipleChoices {
+			return
+		}
+		userID, ok := contextUserID(c)
+		if !ok {
+			return
+		}
+
+		resourceID := auditReadListID
+		if idParam != "" {
+			resourceID = c.Param(idParam)
+		}
+		if skipSelf && resourceID == strconv.FormatUint(uint64(userID), 10) {
+			return
+		}
+
+		// 同じ利用者が同じ条件で繰り返し参照した場合は、期間内の最初の1回だけ記録する
+		key := fmt.Sprintf("%d|%s|%s?%s", userID, c.Request.Method, c.Request.URL.Path, c.Request.URL.RawQuery)
+		if !m.reads.allow(key) {
+			return
+		}
+
+		m.log(c, &model.CreateAuditLogRequest{
+			UserID:       userID,
+			Action:       model.ActionRead,
+			ResourceType: resourceType,
+			ResourceID:   truncateResourceID(resourceID),
+			Changes: model.AuditLogChanges{
+				After: map[string]interface{}{
+					"method": c.Request.Method,
+					"route":  c.FullPath(),
+					"path":   c.Request.URL.Path,
+					"query":  c.Request.URL.RawQuery,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		})
+	}
+}
+
+// recordDenial は認可の拒否を、ルート、必要な権限またはロール、利用者とともに監査ログに記録します
+func (m *AuditMiddleware) recordDenial(c *gin.Context, requirement string, required []string) {
+	if !m.config.DenialEnabled {
+		return
+	}
+	userID, ok := contextUserID(c)
+	if !ok {
+		return
+	}
+
+	route := c.FullPath()
+	username, _ := c.Get("username")
+	role, _ := c.Get("role")
+	m.log(c, &model.CreateAuditLogRequest{
+		UserID:       userID,
+		Action:       model.ActionAccessDenied,
+		ResourceType: model.ResourceTypeRoute,
+		ResourceID:   truncateResourceID(route),
+		Changes: model.AuditLogChanges{
+			After: map[string]interface{}{
+				"method":      c.Request.Method,
+				"route":       route,
+				"path":        c.Request.URL.Path,
+				"requirement": requirement,
+				"required":    required,
+				"username":    username,
+				"role":        role,
+			},
+		},
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: "insufficient permissions",
+	})
+}
+
+// log はリクエストの送信元を設定して監査ログに記録します（記録に失敗してもレスポンスには影響させない）
+func (m *AuditMiddleware) log(c *gin.Context, req *model.CreateAuditLogRequest) {
+	req.IPAddress = c.ClientIP()
+	req.UserAgent = c.Request.UserAgent()
+	if _, err := m.auditLogger.LogAction(c.Request.Context(), req); err != nil {
+		m.logger.Error("Failed to record access audit log",
+			zap.String("action", req.Action),
+			zap.String("resource_type", req.ResourceType),
+			zap.String("resource_id", req.ResourceID),
+			zap.Error(err),
+		)
+	}
+}
+
+// contextUserID は RequireAuth が設定したユーザーIDを返します
+func contextUserID(c *gin.Context) (uint, bool) {
+	value, exists := c.Get("user_id")
+	if !exists {
+		return 0, false
+	}
+	userID, ok := value.(uint)
+	return userID, ok && userID != 0
+}
+
+// truncateResourceID はリソースIDを監査ログに保存できる長さに切り詰めます
+func truncateResourceID(resourceID string) string {
+	if len(resourceID) > auditResourceIDMaxLength {
+		return resourceID[:auditResourceIDMaxLength]
+	}
+	return resourceID
+}
+
+// readDeduper は同じ参照が期間内に記録済みかを判定します
+// 判定はプロセスごとに行うため、複数台で動かす場合は台数分まで記録されることがあります
+type readDeduper struct {
+	mu         sync.Mutex
+	window     time.Duration
+	maxEntries int
+	seen       map[string]time.Time // 参照ごとに最後に記録した日時
+	now        func() time.Time
+}
+
+// newReadDeduper は新しいreadDeduperを作成します
+func newReadDeduper(window time.Duration, maxEntries int) *readDeduper {
+	if maxEntries <= 0 {
+		maxEntries = 10000
+	}
+	return &readDeduper{
+		window:     window,
+		maxEntries: maxEntries,
+		seen:       make(map[string]time.Time),
+		now:        time.Now,
+	}
+}
+
+// allow は key の参照を記録すべきかを返し、記録すべき場合は記録した日時を保持します
+func (d *readDeduper) allow(key string) bool {
+	if d.window <= 0 {
+		return true
+	}
+
+	now := d.now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.seen[key]; ok && now.Sub(last) < d.window {
+		return false
+	}
+	if len(d.seen) >= d.maxEntries {
+		d.evict(now)
+	}
+	d.seen[key] = now
+	return true
+}
+
+// evict は期間を過ぎた参照を削除します
+// それでも上限に達している場合は、記録の漏れより重複を許容するためすべて削除します
+func (d *readDeduper) evict(now time.Time) {
+	for key, last := range d.seen {
+		if now.Sub(last) >= d.window {
+			delete(d.seen, key)
+		}
+	}
+	if len(d.seen) >= d.maxEntries {
+		d.seen = make(map[string]time.Time)
+	}
+}
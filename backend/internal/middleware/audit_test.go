@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// fakeAuditLogger は記録された監査ログを保持します
+type fakeAuditLogger struct {
+	requests []*model.CreateAuditLogRequest
+}
+
+func (l *fakeAuditLogger) LogAction(ctx context.Context, req *model.CreateAuditLogRequest) (*model.AuditLogResponse, error) {
+	l.requests = append(l.requests, req)
+	return &model.AuditLogResponse{}, nil
+}
+
+// withPrincipal は RequireAuth の代わりに利用者をコンテキストに設定します
+func withPrincipal(userID uint, role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("username", "testuser")
+		c.Set("role", role)
+		c.Set("permissions", []string{"users:read"})
+		c.Next()
+	}
+}
+
+func serve(router *gin.Engine, method, path string) int {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w.Code
+}
+
+func TestReadDeduper_Allow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	d := newReadDeduper(5*time.Minute, 2)
+	d.now = func() time.Time { return now }
+
+	assert.True(t, d.allow("a"))
+	assert.False(t, d.allow("a"))
+	assert.True(t, d.allow("b"))
+
+	// 期間を過ぎると再び記録する（上限に達しているため、期間を過ぎた b は破棄される）
+	now = now.Add(5 * time.Minute)
+	assert.True(t, d.allow("a"))
+	assert.Len(t, d.seen, 1)
+
+	assert.True(t, d.allow("c"))
+	assert.False(t, d.allow("a"))
+
+	// 期間内の参照だけで上限に達した場合は、すべて破棄する
+	assert.True(t, d.allow("d"))
+	assert.Len(t, d.seen, 1)
+}
+
+func TestReadDeduper_Disabled(t *testing.T) {
+	d := newReadDeduper(0, 0)
+	assert.True(t, d.allow("a"))
+	assert.True(t, d.allow("a"))
+	assert.Empty(t, d.seen)
+}
+
+func TestAuditMiddleware_AuditReadOfOthers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditLogger := &fakeAuditLogger{}
+	audit := NewAuditMiddleware(auditLogger, AuditAccessConfig{ReadEnabled: true, ReadDedupWindow: time.Minute}, getTestLogger())
+
+	router := gin.New()
+	router.GET("/users/:id", withPrincipal(1, "user"), audit.AuditReadOfOthers(model.ResourceTypeUser, "id"), func(c *gin.Context) {
+		if c.Param("id") == "404" {
+			c.Status(http.StatusNotFound)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/users/1"))
+	assert.Empty(t, auditLogger.requests, "本人のプロフィールの参照は記録しない")
+
+	assert.Equal(t, http.StatusNotFound, serve(router, "GET", "/users/404"))
+	assert.Empty(t, auditLogger.requests, "失敗した参照は記録しない")
+
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/users/2"))
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/users/2"))
+	require.Len(t, auditLogger.requests, 1, "期間内の同じ参照は1回だけ記録する")
+
+	req := auditLogger.requests[0]
+	assert.Equal(t, uint(1), req.UserID)
+	assert.Equal(t, model.ActionRead, req.Action)
+	assert.Equal(t, model.ResourceTypeUser, req.ResourceType)
+	assert.Equal(t, "2", req.ResourceID)
+	assert.Equal(t, model.AuditStatusSuccess, req.Status)
+
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/users/3"))
+	assert.Len(t, auditLogger.requests, 2)
+}
+
+func TestAuditMiddleware_AuditRead_Disabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditLogger := &fakeAuditLogger{}
+	audit := NewAuditMiddleware(auditLogger, AuditAccessConfig{}, getTestLogger())
+
+	router := gin.New()
+	router.GET("/audit-logs", withPrincipal(1, "admin"), audit.AuditRead(model.ResourceTypeAuditLog, ""), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusOK, serve(router, "GET", "/audit-logs"))
+	assert.Empty(t, auditLogger.requests)
+}
+
+func TestRBACMiddleware_RecordsDenial(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	auditLogger := &fakeAuditLogger{}
+	audit := NewAuditMiddleware(auditLogger, AuditAccessConfig{DenialEnabled: true}, getTestLogger())
+	rbac := NewRBACMiddleware(getTestLogger(), audit)
+
+	router := gin.New()
+	router.DELETE("/users/:id", withPrincipal(7, "user"), rbac.RequireRole("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/reports", withPrincipal(7, "user"), rbac.RequireAnyPermission("reports:read", "reports:admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusForbidden, serve(router, "DELETE", "/users/5"))
+	assert.Equal(t, http.StatusForbidden, serve(router, "GET", "/reports"))
+	require.Len(t, auditLogger.requests, 2)
+
+	req := auditLogger.requests[0]
+	assert.Equal(t, uint(7), req.UserID)
+	assert.Equal(t, model.ActionAccessDenied, req.Action)
+	assert.Equal(t, model.ResourceTypeRoute, req.ResourceType)
+	assert.Equal(t, "/users/:id", req.ResourceID)
+	assert.Equal(t, model.AuditStatusFailed, req.Status)
+
+	after := req.Changes.After
+	assert.Equal(t, "/users/5", after["path"])
+	assert.Equal(t, denialRequirementRole, after["requirement"])
+	assert.Equal(t, []string{"admin"}, after["required"])
+	assert.Equal(t, "user", after["role"])
+
+	after = auditLogger.requests[1].Changes.After
+	assert.Equal(t, denialRequirementPermission, after["requirement"])
+	assert.Equal(t, []string{"reports:read", "reports:admin"}, after["required"])
+}
+
+func TestRBACMiddleware_WithoutAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	rbac := NewRBACMiddleware(getTestLogger(), nil)
+
+	router := gin.New()
+	router.GET("/admin", withPrincipal(7, "user"), rbac.RequireRole("admin"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	assert.Equal(t, http.StatusForbidden, serve(router, "GET", "/admin"))
+}
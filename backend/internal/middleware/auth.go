@@ -27,7 +27,7 @@ func NewAuthMiddleware(jwtService *util.JWTService, logger *zap.Logger) *AuthMid
 func (m *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Authorization ヘッダーからトークンを取得
-		tokenString, err := m.jwtService.ExtractTokenFromAuthHeader(c.GetHeader("Authorization"))
+		tokenString, err := util.ExtractTokenFromAuthHeader(c.GetHeader("Authorization"))
 		if err != nil {
 			m.logger.Warn("Missing or invalid authorization header", zap.Error(err))
 			util.Error(c, http.StatusUnauthorized, util.ErrCodeUnauthorized, "authentication required", nil)
@@ -71,7 +71,7 @@ func (m *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 			return
 		}
 
-		tokenString, err := m.jwtService.ExtractTokenFromAuthHeader(authHeader)
+		tokenString, err := util.ExtractTokenFromAuthHeader(authHeader)
 		if err != nil {
 			// トークンの形式が不正な場合はスキップ
 			c.Next()
@@ -96,6 +96,43 @@ func TestAuthMiddleware_RequireAuth_InvalidToken(t *testing.T) {
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
 
+func TestAuthMiddleware_RequireAuth_RejectsNonAccessTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	jwtService := getTestJWTService()
+	authMiddleware := NewAuthMiddleware(jwtService, getTestLogger())
+
+	// 同じ秘密鍵で署名されていても、メール確認・招待トークンでは認証できない
+	verificationToken, err := jwtService.GenerateEmailVerificationToken(1, "user@example.com", "verify-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	invitationToken, err := jwtService.GenerateInvitationToken(1, "invite-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Email verification token", verificationToken},
+		{"Invitation token", invitationToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/protected", authMiddleware.RequireAuth(), func(c *gin.Context) {
+				t.Error("handler should not be called")
+			})
+
+			req := httptest.NewRequest("GET", "/protected", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+		})
+	}
+}
+
 func TestAuthMiddleware_RequireAuth_MalformedAuthHeader(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
@@ -157,7 +194,7 @@ func TestAuthMiddleware_OptionalAuth_WithoutToken(t *testing.T) {
 	authMiddleware := NewAuthMiddleware(jwtService, getTestLogger())
 
 	router.GET("/optional", authMiddleware.OptionalAuth(), func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
+		_, exists := c.Get("user_id")
 		assert.False(t, exists) // Should not be set
 
 		c.JSON(http.StatusOK, gin.H{"authenticated": false})
@@ -179,7 +216,7 @@ func TestAuthMiddleware_OptionalAuth_WithInvalidToken(t *testing.T) {
 	authMiddleware := NewAuthMiddleware(jwtService, getTestLogger())
 
 	router.GET("/optional", authMiddleware.OptionalAuth(), func(c *gin.Context) {
-		userID, exists := c.Get("user_id")
+		_, exists := c.Get("user_id")
 		assert.False(t, exists) // Should not be set
 
 		c.JSON(http.StatusOK, gin.H{"authenticated": false})
@@ -12,12 +12,14 @@ import (
 // RBACMiddleware はロールベースアクセス制御ミドルウェアを提供します
 type RBACMiddleware struct {
 	logger *zap.Logger
+	audit  *AuditMiddleware // nil の場合は拒否を監査ログに記録しない
 }
 
 // NewRBACMiddleware は新しいRBACMiddlewareを作成します
-func NewRBACMiddleware(logger *zap.Logger) *RBACMiddleware {
+func NewRBACMiddleware(logger *zap.Logger, audit *AuditMiddleware) *RBACMiddleware {
 	return &RBACMiddleware{
 		logger: logger,
+		audit:  audit,
 	}
 }
 
@@ -49,6 +51,7 @@ func (m *RBACMiddleware) RequirePermission(permission string) gin.HandlerFunc {
 				zap.String("required_permission", permission),
 				zap.Strings("user_permissions", permList),
 			)
+			m.recordDenial(c, denialRequirementPermission, []string{permission})
 			util.Error(c, http.StatusForbidden, util.ErrCodeInsufficientPermission, "insufficient permissions", nil)
 			c.Abort()
 			return
@@ -58,6 +61,18 @@ func (m *RBACMiddleware) RequirePermission(permission string) gin.HandlerFunc {
 	}
 }
 
+// HasPermission はユーザーが指定された権限を持つかを返します
+// RequirePermission と同じく、トークンの permissions（ロールの権限とグループで付与された権限）で判定します
+// このヘルパーは RequireAuth の後に使用する必要があります
+func HasPermission(c *gin.Context, permission string) bool {
+	permissions, exists := c.Get("permissions")
+	if !exists {
+		return false
+	}
+	permList, ok := permissions.([]string)
+	return ok && contains(permList, permission)
+}
+
 // RequireAnyPermission は指定された権限のいずれかを持つユーザーのみアクセスを許可します
 func (m *RBACMiddleware) RequireAnyPermission(permissions ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -93,6 +108,7 @@ func (m *RBACMiddleware) RequireAnyPermission(permissions ...string) gin.Handler
 				zap.Strings("required_permissions", permissions),
 				zap.Strings("user_permissions", permList),
 			)
+			m.recordDenial(c, denialRequirementPermission, permissions)
 			util.Error(c, http.StatusForbidden, util.ErrCodeInsufficientPermission, "insufficient permissions", nil)
 			c.Abort()
 			return
@@ -128,6 +144,7 @@ func (m *RBACMiddleware) RequireRole(role string) gin.HandlerFunc {
 				zap.String("required_role", role),
 				zap.String("user_role", roleStr),
 			)
+			m.recordDenial(c, denialRequirementRole, []string{role})
 			util.Error(c, http.StatusForbidden, util.ErrCodeInsufficientPermission, "insufficient permissions", nil)
 			c.Abort()
 			return
@@ -171,6 +188,7 @@ func (m *RBACMiddleware) RequireAnyRole(roles ...string) gin.HandlerFunc {
 				zap.Strings("required_roles", roles),
 				zap.String("user_role", roleStr),
 			)
+			m.recordDenial(c, denialRequirementRole, roles)
 			util.Error(c, http.StatusForbidden, util.ErrCodeInsufficientPermission, "insufficient permissions", nil)
 			c.Abort()
 			return
@@ -180,6 +198,14 @@ func (m *RBACMiddleware) RequireAnyRole(roles ...string) gin.HandlerFunc {
 	}
 }
 
+// recordDenial は認可の拒否を監査ログに記録します
+func (m *RBACMiddleware) recordDenial(c *gin.Context, requirement string, required []string) {
+	if m.audit == nil {
+		return
+	}
+	m.audit.recordDenial(c, requirement, required)
+}
+
 // contains はスライスに指定された文字列が含まれているかチェックします
 func contains(slice []string, item string) bool {
 	for _, s := range slice {
@@ -0,0 +1,63 @@
+package model
+
+import "time"
+
+// アーカイブの状態
+const (
+	AuditArchiveStatusVerified = "verified" // 保存先に書き込んで検証済み（監査ログは未削除）
+	AuditArchiveStatusPurged   = "purged"   // アーカイブした監査ログを削除済み
+)
+
+// AuditLogArchive は保存先に書き出した監査ログのセグメントのマニフェストです
+// セグメントは監査ログをID順に1行ずつJSONで書き出し（NDJSON）、gzipで圧縮したファイルです
+type AuditLogArchive struct {
+	ID             uint       `gorm:"primarykey" json:"id"`
+	Storage        string     `gorm:"not null;size:20" json:"storage"`          // 保存先の種類（local, s3）
+	Key            string     `gorm:"not null;size:255;uniqueIndex" json:"key"` // 保存先でのキー
+	FirstID        uint       `gorm:"not null" json:"first_id"`                 // セグメントの最初の監査ログのID
+	LastID         uint       `gorm:"not null" json:"last_id"`                  // セグメントの最後の監査ログのID
+	FirstCreatedAt time.Time  `gorm:"not null" json:"first_created_at"`         // セグメントの最も古い監査ログの作成日時
+	LastCreatedAt  time.Time  `gorm:"not null" json:"last_created_at"`          // セグメントの最も新しい監査ログの作成日時
+	RowCount       int        `gorm:"not null" json:"row_count"`                // セグメントの監査ログの件数
+	Size           int64      `gorm:"not null" json:"size"`                     // 圧縮後のバイト数
+	Checksum       string     `gorm:"not null;size:64" json:"checksum"`         // 圧縮後のファイルのSHA-256（16進数）
+	Status         string     `gorm:"not null;size:20;index" json:"status"`     // verified, purged
+	PurgedCount    int64      `gorm:"not null;default:0" json:"purged_count"`   // 削除した監査ログの件数（ホールド中の監査ログは削除しない）
+	ArchivedBy     uint       `gorm:"not null" json:"archived_by"`              // アーカイブを実行したユーザー
+	CreatedAt      time.Time  `json:"created_at"`                               // 書き込みを検証した日時
+	PurgedAt       *time.Time `json:"purged_at,omitempty"`                      // 監査ログを削除した日時
+	ImportedAt     *time.Time `json:"imported_at,omitempty"`                    // 調査用に最後に取り込んだ日時
+}
+
+// TableName はテーブル名を指定します
+func (AuditLogArchive) TableName() string {
+	return "audit_log_archives"
+}
+
+// ArchivedAuditLog は調査のためにアーカイブから取り込んだ監査ログです
+// 元の監査ログとは別のテーブルに保持するため、ハッシュチェーンや保持期間ポリシーの対象になりません
+type ArchivedAuditLog struct {
+	ArchiveID uint `gorm:"primaryKey;autoIncrement:false" json:"archive_id"`
+	AuditLog  `gorm:"embedded"`
+}
+
+// TableName はテーブル名を指定します
+func (ArchivedAuditLog) TableName() string {
+	return "archived_audit_logs"
+}
+
+// AuditArchiveResult はアーカイブの実行結果です
+type AuditArchiveResult struct {
+	RanAt    time.Time          `json:"ran_at"`
+	Cutoff   time.Time          `json:"cutoff"`   // この日時より前に作成された監査ログをアーカイブした
+	Archived int64              `json:"archived"` // アーカイブした監査ログの件数
+	Purged   int64              `json:"purged"`   // 削除した監査ログの件数（前回削除できなかったセグメントを含む）
+	Archives []*AuditLogArchive `json:"archives"` // 作成または削除を再開したセグメント
+	Error    string             `json:"error,omitempty"`
+}
+
+// AuditArchiveImportResult はアーカイブを取り込んだ結果です
+type AuditArchiveImportResult struct {
+	Archive  *AuditLogArchive `json:"archive"`
+	Imported int              `json:"imported"` // 取り込んだ監査ログの件数
+}
@@ -1,11 +1,12 @@
 package model
 
 import (
-	"database/sql/driver"
 	"encoding/json"
 	"time"
 
 	"gorm.io/datatypes"
+
+	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
 // AuditLog は監査ログモデルです
@@ -15,12 +16,16 @@ type AuditLog struct {
 	Action        string          `gorm:"not null;size:50;index" json:"action"`
 	ResourceType  string          `gorm:"not null;size:50;index" json:"resource_type"`
 	ResourceID    string          `gorm:"not null;size:50;index" json:"resource_id"`
-	Changes       datatypes.JSONType `gorm:"type:jsonb;not null" json:"changes"`
+	Changes       datatypes.JSON `gorm:"type:jsonb;not null" json:"changes"`
 	IPAddress     string          `gorm:"size:45" json:"ip_address"`
 	UserAgent     string          `gorm:"type:text" json:"user_agent"`
 	Status        string          `gorm:"not null;size:20;default:'success';index" json:"status"`
 	ErrorMessage  string          `gorm:"type:text" json:"error_message"`
 	CreatedAt     time.Time       `gorm:"autoCreateTime" json:"created_at"`
+	PrevHash      string          `gorm:"not null;size:64;default:''" json:"prev_hash"` // 直前の監査ログのハッシュ
+	Hash          string          `gorm:"not null;size:64;default:''" json:"hash"`      // 改ざん検知用のハッシュ（導入前の記録は空）
+	RedactedHash       string `gorm:"not null;size:64;default:''" json:"redacted_hash,omitempty"`       // 匿名化後の内容のハッシュ（匿名化していない場合は空）
+	RedactionSignature string `gorm:"not null;size:64;default:''" json:"redaction_signature,omitempty"` // 匿名化の記録の署名
 }
 
 // TableName はテーブル名を指定します
@@ -30,20 +35,49 @@ func (AuditLog) TableName() string {
 
 // アクション定数
 const (
-	ActionCreate = "create"
-	ActionRead   = "read"
-	ActionUpdate = "update"
-	ActionDelete = "delete"
-	ActionLogin  = "login"
-	ActionLogout = "logout"
+	ActionCreate       = "create"
+	ActionRead         = "read"
+	ActionUpdate       = "update"
+	ActionDelete       = "delete"
+	ActionLogin        = "login"
+	ActionLogout       = "logout"
+	ActionRestore      = "restore"
+	ActionPurge        = "purge"
+	ActionExport       = "export"
+	ActionAnonymize    = "anonymize"
+	ActionInvite       = "invite"
+	ActionAccept       = "accept"
+	ActionResend       = "resend"
+	ActionRevoke       = "revoke"
+	ActionVerify       = "verify"
+	ActionAddMember    = "add_member"
+	ActionRemoveMember = "remove_member"
+	ActionArchive      = "archive"
+	ActionImport       = "import"
+	ActionDetach       = "detach"
+	ActionAccessDenied = "access_denied"
+	ActionHold         = "hold"
+	ActionRelease      = "release"
+	ActionAcknowledge  = "acknowledge"
+	ActionLockout      = "lockout"
 )
 
 // リソースタイプ定数
 const (
-	ResourceTypeUser         = "user"
-	ResourceTypeRole         = "role"
-	ResourceTypeOrganization = "organization"
-	ResourceTypeAuditLog     = "audit_log"
+	ResourceTypeUser                 = "user"
+	ResourceTypeRole                 = "role"
+	ResourceTypeOrganization         = "organization"
+	ResourceTypeAuditLog             = "audit_log"
+	ResourceTypeInvitation           = "invitation"
+	ResourceTypeUserAttribute        = "user_attribute"
+	ResourceTypeGroup                = "group"
+	ResourceTypeWebhook              = "webhook"
+	ResourceTypeAuditRetentionPolicy = "audit_retention_policy"
+	ResourceTypeAuditLogArchive      = "audit_log_archive"
+	ResourceTypeAuditLogPartition    = "audit_log_partition"
+	ResourceTypeRoute                = "route"
+	ResourceTypeLegalHold            = "legal_hold"
+	ResourceTypeSecurityAlert        = "security_alert"
 )
 
 // ステータス定数
@@ -53,17 +87,23 @@ const (
 )
 
 // AuditLogChanges は変更内容を表現します
+// Diff は Before と After の間で変更された項目です（作成・削除等、片方が空の場合は記録しません）
 type AuditLogChanges struct {
 	Before map[string]interface{} `json:"before"`
 	After  map[string]interface{} `json:"after"`
+	Diff   []util.FieldChange     `json:"diff,omitempty"`
 }
 
 // MarshalJSON は AuditLogChanges を JSON にマーシャルします
 func (a AuditLogChanges) MarshalJSON() ([]byte, error) {
-	return json.Marshal(map[string]interface{}{
+	changes := map[string]interface{}{
 		"before": a.Before,
 		"after":  a.After,
-	})
+	}
+	if len(a.Diff) > 0 {
+		changes["diff"] = a.Diff
+	}
+	return json.Marshal(changes)
 }
 
 // UnmarshalJSON は JSON から AuditLogChanges をアンマーシャルします
@@ -79,14 +119,84 @@ func (a *AuditLogChanges) UnmarshalJSON(data []byte) error {
 	if after, ok := raw["after"].(map[string]interface{}); ok {
 		a.After = after
 	}
+	if diff, ok := raw["diff"].([]interface{}); ok {
+		// 形式の異なる差分は無視する（変更前後の値は残っている）
+		if data, err := json.Marshal(diff); err == nil {
+			json.Unmarshal(data, &a.Diff)
+		}
+	}
 
 	return nil
 }
 
+// Prepare は保存する変更内容を返します
+// 変更前後の値をJSONの値に揃えて変更された項目（Diff）を計算し、パスワードやトークン等の秘密情報を置き換えます
+func (a AuditLogChanges) Prepare() (AuditLogChanges, error) {
+	before, err := normalizeChangeValues(a.Before)
+	if err != nil {
+		return a, err
+	}
+	after, err := normalizeChangeValues(a.After)
+	if err != nil {
+		return a, err
+	}
+
+	prepared := AuditLogChanges{Before: before, After: after, Diff: a.Diff}
+	if prepared.Diff == nil && len(before) > 0 && len(after) > 0 {
+		// 秘密情報の変更も変更されたことは残すよう、置き換える前に比較する
+		prepared.Diff, err = util.DiffJSON(before, after)
+		if err != nil {
+			return a, err
+		}
+	}
+	return prepared.Redacted(), nil
+}
+
+// Redacted は秘密情報の値を置き換えた変更内容を返します
+func (a AuditLogChanges) Redacted() AuditLogChanges {
+	redacted := AuditLogChanges{}
+	if a.Before != nil {
+		redacted.Before, _ = util.RedactSecrets(a.Before).(map[string]interface{})
+	}
+	if a.After != nil {
+		redacted.After, _ = util.RedactSecrets(a.After).(map[string]interface{})
+	}
+	if a.Diff != nil {
+		redacted.Diff = util.RedactFieldChanges(a.Diff)
+	}
+	return redacted
+}
+
+// ChangedFields は変更された項目の位置の一覧を返します
+// Diff を記録していない監査ログ（導入前の記録や作成・削除）は、変更前後の値から計算します
+func (a AuditLogChanges) ChangedFields() []string {
+	if a.Diff != nil {
+		return util.ChangedFieldPaths(a.Diff)
+	}
+	diff, err := util.DiffJSON(a.Before, a.After)
+	if err != nil {
+		return []string{}
+	}
+	return util.ChangedFieldPaths(diff)
+}
+
+// normalizeChangeValues は変更前後の値をJSONの値（map[string]interface{} 等）に変換します
+func normalizeChangeValues(values map[string]interface{}) (map[string]interface{}, error) {
+	if values == nil {
+		return nil, nil
+	}
+	normalized, err := util.NormalizeJSON(values)
+	if err != nil {
+		return nil, err
+	}
+	result, _ := normalized.(map[string]interface{})
+	return result, nil
+}
+
 // CreateAuditLogRequest は監査ログ作成リクエストです
 type CreateAuditLogRequest struct {
 	UserID       uint                   `json:"user_id" binding:"required"`
-	Action       string                 `json:"action" binding:"required,oneof=create read update delete login logout"`
+	Action       string                 `json:"action" binding:"required,oneof=create read update delete login logout restore purge export anonymize invite accept resend revoke verify add_member remove_member archive import detach access_denied hold release acknowledge lockout"`
 	ResourceType string                 `json:"resource_type" binding:"required"`
 	ResourceID   string                 `json:"resource_id" binding:"required"`
 	Changes      AuditLogChanges        `json:"changes"`
@@ -94,6 +204,7 @@ type CreateAuditLogRequest struct {
 	UserAgent    string                 `json:"user_agent"`
 	Status       string                 `json:"status" binding:"required,oneof=success failed"`
 	ErrorMessage string                 `json:"error_message"`
+	Durable      bool                   `json:"-"` // 非同期書き込みを使わず、永続化を待つ（重要なセキュリティイベント用）
 }
 
 // AuditLogResponse は監査ログレスポンスです
@@ -103,12 +214,14 @@ type AuditLogResponse struct {
 	Action        string          `json:"action"`
 	ResourceType  string          `json:"resource_type"`
 	ResourceID    string          `json:"resource_id"`
-	Changes       AuditLogChanges `json:"changes"`
+	Changes       *AuditLogChanges `json:"changes,omitempty"` // 簡易表示（compact）の場合は省略
+	ChangedFields []string         `json:"changed_fields"`
 	IPAddress     string          `json:"ip_address"`
 	UserAgent     string          `json:"user_agent"`
 	Status        string          `json:"status"`
 	ErrorMessage  string          `json:"error_message"`
 	CreatedAt     time.Time       `json:"created_at"`
+	Hash          string          `json:"hash,omitempty"`
 }
 
 // ToResponse は AuditLog をレスポンスに変換します
@@ -122,17 +235,124 @@ func (a *AuditLog) ToResponse() *AuditLogResponse {
 		}
 	}
 
+	// 導入前の記録に秘密情報が含まれていても返さない（変更された項目は置き換える前に判定する）
+	changedFields := changes.ChangedFields()
+	changes = changes.Redacted()
+
 	return &AuditLogResponse{
-		ID:           a.ID,
-		UserID:       a.UserID,
-		Action:       a.Action,
-		ResourceType: a.ResourceType,
-		ResourceID:   a.ResourceID,
-		Changes:      changes,
-		IPAddress:    a.IPAddress,
-		UserAgent:    a.UserAgent,
-		Status:       a.Status,
-		ErrorMessage: a.ErrorMessage,
-		CreatedAt:    a.CreatedAt,
+		ID:            a.ID,
+		UserID:        a.UserID,
+		Action:        a.Action,
+		ResourceType:  a.ResourceType,
+		ResourceID:    a.ResourceID,
+		Changes:       &changes,
+		ChangedFields: changedFields,
+		IPAddress:     a.IPAddress,
+		UserAgent:     a.UserAgent,
+		Status:        a.Status,
+		ErrorMessage:  a.ErrorMessage,
+		CreatedAt:     a.CreatedAt,
+		Hash:          a.Hash,
 	}
 }
+
+// Compact は変更内容を省略し、変更された項目の一覧のみを返すようにします
+func (r *AuditLogResponse) Compact() {
+	r.Changes = nil
+}
+
+// ChainPayload はハッシュチェーンの計算対象となる正規化した内容を返します
+// changes はJSONBに保存するとキー順や空白が変わるため、デコードし直してから並べます
+func (a *AuditLog) ChainPayload() ([]byte, error) {
+	var changes interface{}
+	if len(a.Changes) > 0 {
+		if err := json.Unmarshal(a.Changes, &changes); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal([]interface{}{
+		a.ID,
+		a.PrevHash,
+		a.CreatedAt.UTC().Format(time.RFC3339Nano),
+		a.UserID,
+		a.Action,
+		a.ResourceType,
+		a.ResourceID,
+		changes,
+		a.IPAddress,
+		a.UserAgent,
+		a.Status,
+		a.ErrorMessage,
+	})
+}
+
+// RedactionPayload は匿名化の記録の署名の対象となる正規化した内容を返します
+// 元のハッシュと匿名化後の内容のハッシュを結び付けるため、チェーンを再計算せずに匿名化した内容を検証できます
+func (a *AuditLog) RedactionPayload() []byte {
+	payload, _ := json.Marshal([]interface{}{
+		a.ID,
+		a.Hash,
+		a.RedactedHash,
+	})
+	return payload
+}
+
+// AuditLogCheckpoint は古い監査ログを削除した時点のハッシュチェーンの状態です
+// 削除後に残った最初の監査ログは、チェックポイントに記録したハッシュから検証を再開します
+type AuditLogCheckpoint struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	LastDeletedID uint      `gorm:"not null" json:"last_deleted_id"`
+	LastHash      string    `gorm:"not null;size:64" json:"last_hash"`
+	DeletedCount  int64     `gorm:"not null" json:"deleted_count"`
+	DeletedBefore time.Time `gorm:"not null" json:"deleted_before"`
+	Signature     string    `gorm:"not null;size:64" json:"signature"` // 鍵付きハッシュによる署名
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TableName はテーブル名を指定します
+func (AuditLogCheckpoint) TableName() string {
+	return "audit_log_checkpoints"
+}
+
+// SignedPayload は署名の対象となる正規化した内容を返します
+func (c *AuditLogCheckpoint) SignedPayload() []byte {
+	payload, _ := json.Marshal([]interface{}{
+		c.LastDeletedID,
+		c.LastHash,
+		c.DeletedCount,
+		c.DeletedBefore.UTC().Format(time.RFC3339Nano),
+	})
+	return payload
+}
+
+// ハッシュチェーンの不整合の種類
+const (
+	ChainBreakCheckpointSignature = "checkpoint_signature_invalid" // チェックポイントが改ざんされている
+	ChainBreakPrevHash            = "prev_hash_mismatch"           // 直前の記録が削除・挿入されている
+	ChainBreakHash                = "hash_mismatch"                // 記録の内容が書き換えられている
+	ChainBreakMissingHash         = "missing_hash"                 // ハッシュが消去されている
+	ChainBreakTombstoneSignature  = "tombstone_signature_invalid"  // 削除した監査ログの墓標が改ざんされている
+	ChainBreakRedactionSignature  = "redaction_signature_invalid"  // 匿名化の記録が改ざんされている
+)
+
+// AuditChainBreak はハッシュチェーンで最初に見つかった不整合です
+type AuditChainBreak struct {
+	ID       uint   `json:"id"` // 不整合を検出した監査ログのID（チェックポイントの場合は0）
+	Reason   string `json:"reason"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// AuditChainVerification はハッシュチェーンの検証結果です
+type AuditChainVerification struct {
+	Valid          bool                `json:"valid"`
+	CheckedCount   int64               `json:"checked_count"`
+	LegacyCount    int64               `json:"legacy_count"`    // ハッシュチェーン導入前の記録（検証対象外）
+	TombstoneCount int64               `json:"tombstone_count"` // 保持期間ポリシーで削除した監査ログの墓標
+	RedactedCount  int64               `json:"redacted_count"`  // 個人情報を匿名化した監査ログ（匿名化の記録で検証）
+	HeadID         uint                `json:"head_id"`         // 検証した最後の監査ログのID
+	HeadHash       string              `json:"head_hash"`       // 末尾の削除を検知できるよう外部に控えておく値
+	Checkpoint     *AuditLogCheckpoint `json:"checkpoint,omitempty"`
+	BrokenLink     *AuditChainBreak    `json:"broken_link,omitempty"`
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// AuditLogFilter は監査ログの検索条件です
+// 指定した条件はすべてAND条件で結合し、未指定の条件は絞り込みに使いません
+type AuditLogFilter struct {
+	UserID        *uint               // 実行したユーザー
+	Actions       []string            // アクション（いずれかに一致）
+	ResourceType  string              // リソースタイプ
+	ResourceID    string              // リソースID
+	Status        string              // ステータス
+	IPAddress     string              // IPアドレス（CIDR表記の場合は範囲内）
+	From          *time.Time          // 作成日時の下限（含む）
+	To            *time.Time          // 作成日時の上限（含む）
+	Query         string              // エラーメッセージの部分一致（大文字小文字を区別しない）
+	ChangeMatches []*AuditChangeMatch // 変更内容の値の一致
+	ChangedFields []string            // 変更前後で値が異なる項目
+}
+
+// MaxAuditChangeConditions は1回の検索で指定できる変更内容の条件の最大数です
+const MaxAuditChangeConditions = 10
+
+// AuditChangeMatch は変更内容（changes）の指定したパスの値が一致する条件です
+// 例: "after.role:admin" は変更後のロールが admin の監査ログに一致します
+type AuditChangeMatch struct {
+	Path  []string    // before または after から始まるキーのパス
+	Value interface{} // 一致する値
+}
+
+// auditChangeKeyPattern は変更内容のキーとして指定できる文字列です
+var auditChangeKeyPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ParseAuditChangeMatch は "パス:値" 形式の条件を解析します
+// パスは before または after から始まるドット区切りのキーで、値はJSONとして解釈できる場合
+// （数値、true/false、null、引用符付きの文字列）はその値、それ以外は文字列として扱います
+func ParseAuditChangeMatch(expr string) (*AuditChangeMatch, error) {
+	path, raw, ok := strings.Cut(expr, ":")
+	if !ok {
+		return nil, fmt.Errorf("change condition must be in the form path:value: %q", expr)
+	}
+
+	keys := strings.Split(path, ".")
+	if len(keys) < 2 || (keys[0] != "before" && keys[0] != "after") {
+		return nil, fmt.Errorf("change path must start with before or after: %q", path)
+	}
+	for _, key := range keys[1:] {
+		if !auditChangeKeyPattern.MatchString(key) {
+			return nil, fmt.Errorf("invalid change path: %q", path)
+		}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		value = raw
+	}
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		return nil, errors.New("change value must be a scalar")
+	}
+
+	return &AuditChangeMatch{Path: keys, Value: value}, nil
+}
+
+// Containment は条件をJSONBの包含演算子（@>）で比較するドキュメントとして返します
+// 例: after.role:admin は {"after":{"role":"admin"}} になります
+func (m *AuditChangeMatch) Containment() map[string]interface{} {
+	var doc interface{} = m.Value
+	for i := len(m.Path) - 1; i >= 0; i-- {
+		doc = map[string]interface{}{m.Path[i]: doc}
+	}
+	return doc.(map[string]interface{})
+}
+
+// ValidateAuditChangedField は変更の有無を調べる項目名が有効かチェックします
+func ValidateAuditChangedField(field string) error {
+	if !auditChangeKeyPattern.MatchString(field) {
+		return fmt.Errorf("invalid changed field: %q", field)
+	}
+	return nil
+}
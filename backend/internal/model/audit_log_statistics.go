@@ -0,0 +1,112 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 統計の集計単位
+const (
+	AuditBucketHour = "hour"
+	AuditBucketDay  = "day"
+	AuditBucketWeek = "week" // 月曜始まり（PostgreSQLの date_trunc と同じ）
+)
+
+// 統計の内訳に指定できる項目
+const (
+	AuditDimensionAction       = "action"
+	AuditDimensionResourceType = "resource_type"
+	AuditDimensionStatus       = "status"
+	AuditDimensionActor        = "actor" // 実行したユーザー
+)
+
+// auditDimensionColumns は内訳の項目と集計に使うカラムの対応です
+var auditDimensionColumns = map[string]string{
+	AuditDimensionAction:       "action",
+	AuditDimensionResourceType: "resource_type",
+	AuditDimensionStatus:       "status",
+	AuditDimensionActor:        "user_id",
+}
+
+// AuditStatisticsQuery は監査ログの時系列統計の条件です
+type AuditStatisticsQuery struct {
+	Filter   *AuditLogFilter // 集計対象の条件（From と To は必須）
+	Bucket   string          // 集計単位
+	Location *time.Location  // 集計単位の区切りに使うタイムゾーン
+	GroupBy  []string        // 内訳の項目
+	TopN     int             // 失敗した操作の上位として返す件数
+}
+
+// ValidateAuditBucket は集計単位が正しいか検証します
+func ValidateAuditBucket(bucket string) error {
+	switch bucket {
+	case AuditBucketHour, AuditBucketDay, AuditBucketWeek:
+		return nil
+	}
+	return errors.New("bucket must be hour, day or week")
+}
+
+// AuditDimensionColumn は内訳の項目に対応するカラム名を返します
+func AuditDimensionColumn(dimension string) (string, error) {
+	column, ok := auditDimensionColumns[dimension]
+	if !ok {
+		return "", fmt.Errorf("unknown group_by dimension: %s", dimension)
+	}
+	return column, nil
+}
+
+// TruncateAuditBucket は日時を集計単位の開始日時に切り捨てます
+func TruncateAuditBucket(t time.Time, bucket string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch bucket {
+	case AuditBucketHour:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, loc)
+	case AuditBucketWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// NextAuditBucket は次の集計単位の開始日時を返します
+func NextAuditBucket(start time.Time, bucket string, loc *time.Location) time.Time {
+	switch bucket {
+	case AuditBucketHour:
+		// 夏時間の終わりで同じ時刻が繰り返される場合も、次の区切りに進める
+		next := TruncateAuditBucket(start.Add(time.Hour), bucket, loc)
+		if !next.After(start) {
+			next = TruncateAuditBucket(start.Add(2*time.Hour), bucket, loc)
+		}
+		return next
+	case AuditBucketWeek:
+		return start.AddDate(0, 0, 7)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// AuditLogBucketCount は集計単位と内訳ごとの監査ログの件数です
+// 集計に含めなかった内訳の項目はゼロ値になります
+type AuditLogBucketCount struct {
+	Bucket       time.Time // 集計単位の開始日時（集計したタイムゾーンの壁時計の時刻）
+	Action       string
+	ResourceType string
+	Status       string
+	UserID       uint
+	Count        int64
+}
+
+// AuditLogActorCount はユーザーごとの監査ログの件数です
+type AuditLogActorCount struct {
+	UserID   uint   `json:"user_id"`
+	Username string `json:"username"`
+	Count    int64  `json:"count"`
+}
+
+// AuditLogIPCount はIPアドレスごとの監査ログの件数です
+type AuditLogIPCount struct {
+	IPAddress string `json:"ip_address"`
+	Count     int64  `json:"count"`
+}
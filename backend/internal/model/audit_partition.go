@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// AuditLogPartition は監査ログのパーティション（作成日時の1か月分）です
+type AuditLogPartition struct {
+	Name          string     `json:"name"`
+	From          *time.Time `json:"from,omitempty"` // この日時以降に作成された監査ログを含む（デフォルトパーティションは空）
+	To            *time.Time `json:"to,omitempty"`   // この日時より前に作成された監査ログを含む（デフォルトパーティションは空）
+	Default       bool       `json:"default"`        // どのパーティションの範囲にも含まれない監査ログを受け止めるパーティション
+	EstimatedRows int64      `json:"estimated_rows"` // 統計情報による推定件数
+	TotalBytes    int64      `json:"total_bytes"`    // インデックスを含むサイズ
+}
+
+// AuditPartitionMonth は日時を含むパーティションの月の初日（UTC）を返します
+func AuditPartitionMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// AuditPartitionResult はパーティションの管理の実行結果です
+type AuditPartitionResult struct {
+	RanAt    time.Time  `json:"ran_at"`
+	Cutoff   *time.Time `json:"cutoff,omitempty"` // この日時より前のパーティションを切り離す対象とした（保持期間を設定していない場合は空）
+	Created  []string   `json:"created"`          // 作成したパーティション
+	Detached []string   `json:"detached"`         // 切り離したパーティション
+	Dropped  bool       `json:"dropped"`          // 切り離したパーティションを削除したか
+	Held     string     `json:"held,omitempty"`   // 訴訟ホールドの対象を含むため切り離さなかったパーティション
+	Error    string     `json:"error,omitempty"`
+}
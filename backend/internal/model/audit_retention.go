@@ -0,0 +1,137 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditRetentionPolicy は監査ログの保持期間のポリシーです
+// アクション・リソースタイプ・ステータスが一致し、保持期間を過ぎた監査ログを削除します
+// 空の条件はすべてに一致します。複数のポリシーに一致する監査ログは、最も長い保持期間に従います
+type AuditRetentionPolicy struct {
+	ID            uint      `gorm:"primarykey" json:"id"`
+	Name          string    `gorm:"not null;size:100;uniqueIndex" json:"name"`
+	Action        string    `gorm:"not null;size:50;default:''" json:"action"`
+	ResourceType  string    `gorm:"not null;size:50;default:''" json:"resource_type"`
+	Status        string    `gorm:"not null;size:20;default:''" json:"status"`
+	RetentionDays int       `gorm:"not null" json:"retention_days"`
+	Enabled       bool      `gorm:"not null;default:true" json:"enabled"`
+	Description   string    `gorm:"type:text" json:"description"`
+	CreatedBy     uint      `gorm:"not null" json:"created_by"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (AuditRetentionPolicy) TableName() string {
+	return "audit_retention_policies"
+}
+
+// Cutoff は now の時点で保持期間を過ぎたとみなす作成日時の境界を返します（この日時より前が対象）
+func (p *AuditRetentionPolicy) Cutoff(now time.Time) time.Time {
+	return now.AddDate(0, 0, -p.RetentionDays)
+}
+
+// Overlaps は2つのポリシーの両方に一致する監査ログが存在しうるかを返します
+func (p *AuditRetentionPolicy) Overlaps(other *AuditRetentionPolicy) bool {
+	compatible := func(a, b string) bool {
+		return a == "" || b == "" || a == b
+	}
+	return compatible(p.Action, other.Action) &&
+		compatible(p.ResourceType, other.ResourceType) &&
+		compatible(p.Status, other.Status)
+}
+
+// CreateAuditRetentionPolicyRequest は保持期間ポリシー作成リクエストです
+type CreateAuditRetentionPolicyRequest struct {
+	Name          string `json:"name" binding:"required,max=100"`
+	Action        string `json:"action" binding:"max=50"`
+	ResourceType  string `json:"resource_type" binding:"max=50"`
+	Status        string `json:"status" binding:"omitempty,oneof=success failed"`
+	RetentionDays int    `json:"retention_days" binding:"required,min=1"`
+	Enabled       *bool  `json:"enabled"`
+	Description   string `json:"description"`
+}
+
+// UpdateAuditRetentionPolicyRequest は保持期間ポリシー更新リクエストです
+type UpdateAuditRetentionPolicyRequest struct {
+	Name          *string `json:"name" binding:"omitempty,max=100"`
+	Action        *string `json:"action" binding:"omitempty,max=50"`
+	ResourceType  *string `json:"resource_type" binding:"omitempty,max=50"`
+	Status        *string `json:"status"` // 空文字で条件を解除
+	RetentionDays *int    `json:"retention_days" binding:"omitempty,min=1"`
+	Enabled       *bool   `json:"enabled"`
+	Description   *string `json:"description"`
+}
+
+// AuditRetentionPolicyResult はポリシーごとの削除件数です
+type AuditRetentionPolicyResult struct {
+	PolicyID uint      `json:"policy_id"`
+	Name     string    `json:"name"`
+	Cutoff   time.Time `json:"cutoff"`
+	Count    int64     `json:"count"` // 削除した件数（ドライランの場合は削除される件数）
+	Error    string    `json:"error,omitempty"`
+}
+
+// AuditRetentionResult は保持期間ポリシーの実行結果です
+type AuditRetentionResult struct {
+	DryRun   bool                          `json:"dry_run"`
+	RanAt    time.Time                     `json:"ran_at"`
+	Total    int64                         `json:"total"`
+	Policies []*AuditRetentionPolicyResult `json:"policies"`
+}
+
+// AuditHoldScope は削除・匿名化してはならない監査ログの範囲です（訴訟ホールド）
+// 指定した条件をすべて満たす監査ログが対象で、空の条件はすべてに一致します
+type AuditHoldScope struct {
+	UserID       *uint
+	ResourceType string
+	ResourceID   string
+	From         *time.Time
+	To           *time.Time
+}
+
+// Matches は監査ログがホールドの範囲に含まれるかを返します
+func (s *AuditHoldScope) Matches(log *AuditLog) bool {
+	if s.UserID != nil && log.UserID != *s.UserID {
+		return false
+	}
+	if s.ResourceType != "" && log.ResourceType != s.ResourceType {
+		return false
+	}
+	if s.ResourceID != "" && log.ResourceID != s.ResourceID {
+		return false
+	}
+	if s.From != nil && log.CreatedAt.Before(*s.From) {
+		return false
+	}
+	if s.To != nil && log.CreatedAt.After(*s.To) {
+		return false
+	}
+	return true
+}
+
+// AuditLogTombstone は保持期間ポリシーやアーカイブで削除した監査ログのハッシュです
+// 削除した監査ログの位置でハッシュチェーンをつなぎ、残った監査ログの検証を続けられるようにします
+type AuditLogTombstone struct {
+	ID        uint      `gorm:"primarykey;autoIncrement:false" json:"id"` // 削除した監査ログのID
+	PrevHash  string    `gorm:"not null;size:64" json:"prev_hash"`
+	Hash      string    `gorm:"not null;size:64" json:"hash"`
+	Signature string    `gorm:"not null;size:64" json:"signature"` // 鍵付きハッシュによる署名
+	PurgedAt  time.Time `gorm:"not null" json:"purged_at"`
+}
+
+// TableName はテーブル名を指定します
+func (AuditLogTombstone) TableName() string {
+	return "audit_log_tombstones"
+}
+
+// SignedPayload は署名の対象となる正規化した内容を返します
+func (t *AuditLogTombstone) SignedPayload() []byte {
+	payload, _ := json.Marshal([]interface{}{
+		t.ID,
+		t.PrevHash,
+		t.Hash,
+	})
+	return payload
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+)
+
+// Group はユーザーグループモデルです
+// グループは入れ子にでき、子グループのメンバーは親グループのメンバーとしても扱われます
+type Group struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Name        string     `gorm:"uniqueIndex;not null;size:100" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	ParentID    *uint      `gorm:"index" json:"parent_id"`
+	Permissions StringList `gorm:"type:jsonb;not null;default:'[]'" json:"permissions"` // メンバーに付与する権限
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (Group) TableName() string {
+	return "groups"
+}
+
+// GroupMember はグループとユーザーの所属関係です
+type GroupMember struct {
+	GroupID   uint      `gorm:"primaryKey" json:"group_id"`
+	UserID    uint      `gorm:"primaryKey;index" json:"user_id"`
+	AddedBy   uint      `gorm:"not null" json:"added_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName はテーブル名を指定します
+func (GroupMember) TableName() string {
+	return "group_members"
+}
+
+// CreateGroupRequest はグループ作成リクエストです
+type CreateGroupRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Description string   `json:"description"`
+	ParentID    *uint    `json:"parent_id"`
+	Permissions []string `json:"permissions" binding:"omitempty,dive,required"`
+}
+
+// UpdateGroupRequest はグループ更新リクエストです
+// parent_id に 0 を指定すると最上位のグループになります
+type UpdateGroupRequest struct {
+	Name        *string   `json:"name" binding:"omitempty,max=100"`
+	Description *string   `json:"description"`
+	ParentID    *uint     `json:"parent_id"`
+	Permissions *[]string `json:"permissions" binding:"omitempty,dive,required"`
+}
+
+// GroupMembersRequest はグループへのメンバー追加リクエストです
+type GroupMembersRequest struct {
+	UserIDs []uint `json:"user_ids" binding:"required,min=1,dive,required"`
+}
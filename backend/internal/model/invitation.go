@@ -0,0 +1,107 @@
+package model
+
+import (
+	"time"
+)
+
+// Invitation はユーザー招待モデルです
+// 招待されたユーザーは招待を受諾してパスワードを設定するまで pending 状態になります
+type Invitation struct {
+	ID         uint       `gorm:"primarykey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"user_id"`
+	Email      string     `gorm:"not null;size:255" json:"email"`
+	TokenID    string     `gorm:"uniqueIndex;not null;size:255" json:"-"` // 再送時に再発行し、古いリンクを無効化する
+	Status     string     `gorm:"not null;size:20;default:'pending';index" json:"status"`
+	InvitedBy  uint       `gorm:"not null" json:"invited_by"`
+	ExpiresAt  time.Time  `gorm:"not null" json:"expires_at"`
+	SentCount  int        `gorm:"not null;default:0" json:"sent_count"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (Invitation) TableName() string {
+	return "invitations"
+}
+
+// 招待ステータス定数
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// CurrentStatus は有効期限を考慮した現在のステータスを返します
+// 期限切れはバッチで更新しないため、pending のまま期限を過ぎたものは expired として扱います
+func (i *Invitation) CurrentStatus(now time.Time) string {
+	if i.Status == InvitationStatusPending && !now.Before(i.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return i.Status
+}
+
+// CanResend は招待を再送できるかチェックします（期限切れは再送により延長できます）
+func (i *Invitation) CanResend(now time.Time) bool {
+	status := i.CurrentStatus(now)
+	return status == InvitationStatusPending || status == InvitationStatusExpired
+}
+
+// InviteUserRequest はユーザー招待リクエストです
+// パスワードは招待されたユーザー自身が受諾時に設定します
+type InviteUserRequest struct {
+	Username   string `json:"username" binding:"required,min=3,max=50,alphanum"`
+	Email      string `json:"email" binding:"required,email"`
+	FullName   string `json:"full_name" binding:"max=100"`
+	Department string `json:"department" binding:"max=100"`
+	Role       string `json:"role" binding:"required,oneof=admin manager user viewer"`
+}
+
+// AcceptInvitationRequest は招待受諾リクエストです
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=8,max=72"`
+}
+
+// InvitationResponse は招待レスポンスです
+type InvitationResponse struct {
+	ID         uint       `json:"id"`
+	UserID     uint       `json:"user_id"`
+	Email      string     `json:"email"`
+	Status     string     `json:"status"`
+	InvitedBy  uint       `json:"invited_by"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	SentCount  int        `json:"sent_count"`
+	LastSentAt *time.Time `json:"last_sent_at"`
+	AcceptedAt *time.Time `json:"accepted_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ToResponse はInvitationをInvitationResponseに変換します
+func (i *Invitation) ToResponse() *InvitationResponse {
+	return &InvitationResponse{
+		ID:         i.ID,
+		UserID:     i.UserID,
+		Email:      i.Email,
+		Status:     i.CurrentStatus(time.Now()),
+		InvitedBy:  i.InvitedBy,
+		ExpiresAt:  i.ExpiresAt,
+		SentCount:  i.SentCount,
+		LastSentAt: i.LastSentAt,
+		AcceptedAt: i.AcceptedAt,
+		RevokedAt:  i.RevokedAt,
+		CreatedAt:  i.CreatedAt,
+	}
+}
+
+// InviteUserResponse はユーザー招待のレスポンスです
+// MailSent が false の場合は招待は作成済みのため、再送で送り直せます
+type InviteUserResponse struct {
+	User       *UserResponse       `json:"user"`
+	Invitation *InvitationResponse `json:"invitation"`
+	MailSent   bool                `json:"mail_sent"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// LegalHold は調査等のため、ユーザーと監査ログの削除・匿名化を禁止する訴訟ホールドです
+// ユーザー・リソース・期間の指定した条件をすべて満たす監査ログが対象で、空の条件はすべてに一致します
+// ユーザーを指定した場合は、ユーザー自身（削除済みユーザーの物理削除と匿名化）も対象です
+type LegalHold struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	UserID        *uint      `json:"user_id"`
+	ResourceType  string     `gorm:"not null;size:50;default:''" json:"resource_type"`
+	ResourceID    string     `gorm:"not null;size:50;default:''" json:"resource_id"`
+	From          *time.Time `gorm:"column:range_from" json:"from"`
+	To            *time.Time `gorm:"column:range_to" json:"to"`
+	Reason        string     `gorm:"type:text;not null" json:"reason"`
+	OwnerID       uint       `gorm:"not null" json:"owner_id"` // ホールドの責任者
+	PlacedBy      uint       `gorm:"not null" json:"placed_by"`
+	ReleasedAt    *time.Time `json:"released_at"`
+	ReleasedBy    *uint      `json:"released_by"`
+	ReleaseReason string     `gorm:"type:text;not null;default:''" json:"release_reason"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (LegalHold) TableName() string {
+	return "legal_holds"
+}
+
+// Active はホールドが解除されていないかを返します
+func (h *LegalHold) Active() bool {
+	return h.ReleasedAt == nil
+}
+
+// AuditHoldScope はホールドの対象となる監査ログの範囲を返します
+func (h *LegalHold) AuditHoldScope() *AuditHoldScope {
+	return &AuditHoldScope{
+		UserID:       h.UserID,
+		ResourceType: h.ResourceType,
+		ResourceID:   h.ResourceID,
+		From:         h.From,
+		To:           h.To,
+	}
+}
+
+// PlaceLegalHoldRequest は訴訟ホールドの設定リクエストです
+type PlaceLegalHoldRequest struct {
+	UserID       *uint      `json:"user_id"`
+	ResourceType string     `json:"resource_type" binding:"max=50"`
+	ResourceID   string     `json:"resource_id" binding:"max=50"`
+	From         *time.Time `json:"from"`
+	To           *time.Time `json:"to"`
+	Reason       string     `json:"reason" binding:"required"`
+	OwnerID      *uint      `json:"owner_id"` // 省略した場合は設定したユーザー
+}
+
+// ReleaseLegalHoldRequest は訴訟ホールドの解除リクエストです
+type ReleaseLegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
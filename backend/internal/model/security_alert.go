@@ -0,0 +1,93 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// SecurityAlert はログインの監査ログから検知した不審なログインのアラートです
+// 管理者が確認（acknowledge）するまで未対応として残ります
+type SecurityAlert struct {
+	ID              uint           `gorm:"primarykey" json:"id"`
+	Rule            string         `gorm:"not null;size:50" json:"rule"`
+	Severity        string         `gorm:"not null;size:20" json:"severity"`
+	UserID          *uint          `json:"user_id"` // 特定のユーザーに関するアラートの場合のみ
+	Username        string         `gorm:"not null;size:50;default:''" json:"username"`
+	IPAddress       string         `gorm:"not null;size:45;default:''" json:"ip_address"`
+	UserAgent       string         `gorm:"type:text;not null;default:''" json:"user_agent"`
+	Details         datatypes.JSON `gorm:"type:jsonb;not null" json:"details"`              // ルールごとの検知の根拠
+	Actions         StringList     `gorm:"type:jsonb;not null;default:'[]'" json:"actions"` // 実行した対応（lockout, notify）
+	AcknowledgedAt  *time.Time     `json:"acknowledged_at"`
+	AcknowledgedBy  *uint          `json:"acknowledged_by"`
+	AcknowledgeNote string         `gorm:"type:text;not null;default:''" json:"acknowledge_note"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+// TableName はテーブル名を指定します
+func (SecurityAlert) TableName() string {
+	return "security_alerts"
+}
+
+// Acknowledged はアラートが確認済みかを返します
+func (a *SecurityAlert) Acknowledged() bool {
+	return a.AcknowledgedAt != nil
+}
+
+// 検知ルール
+const (
+	SecurityRulePasswordSpraying = "password_spraying" // 1つのIPアドレスから複数のアカウントへのログイン失敗
+	SecurityRuleNewIP            = "new_ip"            // ユーザーが過去にログインしていないIPアドレスからのログイン
+	SecurityRuleNewUserAgent     = "new_user_agent"    // ユーザーが過去にログインしていないUser-Agentからのログイン
+	SecurityRuleImpossibleTravel = "impossible_travel" // 移動できない距離と時間で離れた2つのIPアドレスからのログイン
+)
+
+// IsValidSecurityRule は検知ルールが有効かチェックします
+func IsValidSecurityRule(rule string) bool {
+	switch rule {
+	case SecurityRulePasswordSpraying, SecurityRuleNewIP, SecurityRuleNewUserAgent, SecurityRuleImpossibleTravel:
+		return true
+	}
+	return false
+}
+
+// 重要度
+const (
+	SecuritySeverityLow    = "low"
+	SecuritySeverityMedium = "medium"
+	SecuritySeverityHigh   = "high"
+)
+
+// IsValidSecuritySeverity は重要度が有効かチェックします
+func IsValidSecuritySeverity(severity string) bool {
+	switch severity {
+	case SecuritySeverityLow, SecuritySeverityMedium, SecuritySeverityHigh:
+		return true
+	}
+	return false
+}
+
+// 検知時の対応
+const (
+	SecurityActionLockout = "lockout" // ユーザーを停止する（パスワードスプレーの場合はIPアドレスからのログインを拒否する）
+	SecurityActionNotify  = "notify"  // Webhookの security.alert イベントで通知する
+)
+
+// アラートの状態（一覧の絞り込みに使用します）
+const (
+	SecurityAlertStatusOpen         = "open"
+	SecurityAlertStatusAcknowledged = "acknowledged"
+)
+
+// SecurityAlertFilter はアラート一覧の絞り込み条件です
+type SecurityAlertFilter struct {
+	Status   string
+	Severity string
+	Rule     string
+	UserID   *uint
+}
+
+// AcknowledgeSecurityAlertRequest はアラートの確認リクエストです
+type AcknowledgeSecurityAlertRequest struct {
+	Note string `json:"note"`
+}
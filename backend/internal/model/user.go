@@ -1,6 +1,10 @@
 package model
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -8,18 +12,26 @@ import (
 
 // User はユーザーモデルです
 type User struct {
-	ID           uint           `gorm:"primarykey" json:"id"`
-	Username     string         `gorm:"uniqueIndex;not null;size:50" json:"username"`
-	Email        string         `gorm:"uniqueIndex;not null;size:255" json:"email"`
-	FullName     string         `gorm:"size:100" json:"full_name"`
-	Department   string         `gorm:"size:100" json:"department"`
-	PasswordHash string         `gorm:"not null;size:255;column:password_hash" json:"-"` // JSONには含めない
-	Role         string         `gorm:"not null;size:20;default:'user'" json:"role"`
-	Status       string         `gorm:"not null;size:20;default:'active'" json:"status"`
-	LastLogin    *time.Time     `json:"last_login"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `gorm:"index" json:"-"` // ソフトデリート
+	ID               uint           `gorm:"primarykey" json:"id"`
+	Username         string         `gorm:"uniqueIndex:idx_users_username,where:deleted_at IS NULL;not null;size:50" json:"username"`
+	Email            string         `gorm:"uniqueIndex:idx_users_email,where:deleted_at IS NULL;not null;size:255" json:"email"`
+	EmailVerified    bool           `gorm:"not null;default:false" json:"email_verified"`
+	PendingEmail     string         `gorm:"size:255" json:"pending_email"` // 確認待ちの新しいメールアドレス
+	EmailTokenID     string         `gorm:"size:255" json:"-"`             // メール確認トークンID（再送時に再発行）
+	FullName         string         `gorm:"size:100" json:"full_name"`
+	Department       string         `gorm:"size:100" json:"department"`
+	PasswordHash     string         `gorm:"not null;size:255;column:password_hash" json:"-"` // JSONには含めない
+	Role             string         `gorm:"not null;size:20;default:'user'" json:"role"`
+	Status           string         `gorm:"not null;size:20;default:'active'" json:"status"`
+	Attributes       UserAttributes `gorm:"type:jsonb;not null;default:'{}'" json:"attributes"` // 管理者が定義した追加属性
+	LastLogin        *time.Time     `json:"last_login"`
+	AccountExpiresAt *time.Time     `json:"account_expires_at"`                // アカウントの利用期限（過ぎると自動で無効化）
+	StatusChangedAt  *time.Time     `json:"-"`                                 // 休眠判定の起点（再有効化直後に再び無効化しないため）
+	DormantWarnedAt  *time.Time     `json:"-"`                                 // 休眠による無効化を予告した日時
+	Version          uint           `gorm:"not null;default:1" json:"version"` // 楽観的排他制御用
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"` // ソフトデリート
 }
 
 // TableName はテーブル名を指定します
@@ -32,6 +44,7 @@ const (
 	UserStatusActive    = "active"
 	UserStatusInactive  = "inactive"
 	UserStatusSuspended = "suspended"
+	UserStatusPending   = "pending" // 招待済みで未受諾（パスワード未設定）
 )
 
 // ロール定数
@@ -42,6 +55,68 @@ const (
 	RoleViewer  = "viewer"
 )
 
+// SystemUserID は定期実行などユーザー操作によらない処理の実行者として監査ログに記録するユーザーIDです
+const SystemUserID uint = 1
+
+// ETag はユーザーのバージョンを表すエンティティタグを返します
+func (u *User) ETag() string {
+	return fmt.Sprintf(`"%d"`, u.Version)
+}
+
+// AnyVersion は If-Match: * を表すバージョンです
+// バージョンは1から始まるため、0を「現在のバージョンであれば何でもよい」の意味で使います
+const AnyVersion uint = 0
+
+// ErrETagNeverMatches は現在のバージョンと一致し得ないエンティティタグを表します
+// If-Match は強い比較で判定するため、弱いエンティティタグ（W/"..."）は常に不一致です
+var ErrETagNeverMatches = errors.New("entity tag never matches")
+
+// ParseETag はIf-Matchヘッダーの値からバージョンを取り出します
+// "*" の場合は AnyVersion を返します
+func ParseETag(value string) (uint, error) {
+	value = strings.TrimSpace(value)
+	if value == "*" {
+		return AnyVersion, nil
+	}
+
+	weak := strings.HasPrefix(value, "W/")
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errors.New("invalid entity tag")
+	}
+
+	version, err := strconv.ParseUint(value[1:len(value)-1], 10, 32)
+	if err != nil {
+		return 0, errors.New("invalid entity tag")
+	}
+	if weak || uint(version) == AnyVersion {
+		return 0, ErrETagNeverMatches
+	}
+	return uint(version), nil
+}
+
+// SetStatus はステータスを変更し、変更日時を記録します
+// 休眠の予告は無効になるため取り消します
+func (u *User) SetStatus(status string, now time.Time) {
+	if u.Status == status {
+		return
+	}
+	u.Status = status
+	u.StatusChangedAt = &now
+	u.DormantWarnedAt = nil
+}
+
+// IsExpired はアカウントの利用期限を過ぎているかを返します
+func (u *User) IsExpired(now time.Time) bool {
+	return u.AccountExpiresAt != nil && !now.Before(*u.AccountExpiresAt)
+}
+
+// CanLogin はログインできる状態（有効かつ利用期限内）かを返します
+// 期限切れのアカウントは定期ジョブで無効化されるまでの間もログインできません
+func (u *User) CanLogin(now time.Time) bool {
+	return u.Status == UserStatusActive && !u.IsExpired(now)
+}
+
 // IsValidStatus はステータスが有効かチェックします
 func IsValidStatus(status string) bool {
 	return status == UserStatusActive || status == UserStatusInactive || status == UserStatusSuspended
@@ -54,12 +129,14 @@ func IsValidRole(role string) bool {
 
 // CreateUserRequest はユーザー作成リクエストです
 type CreateUserRequest struct {
-	Username   string `json:"username" binding:"required,min=3,max=50,alphanum"`
-	Email      string `json:"email" binding:"required,email"`
-	FullName   string `json:"full_name" binding:"max=100"`
-	Department string `json:"department" binding:"max=100"`
-	Password   string `json:"password" binding:"required,min=8,max=72"`
-	Role       string `json:"role" binding:"required,oneof=admin manager user viewer"`
+	Username         string         `json:"username" binding:"required,min=3,max=50,alphanum"`
+	Email            string         `json:"email" binding:"required,email"`
+	FullName         string         `json:"full_name" binding:"max=100"`
+	Department       string         `json:"department" binding:"max=100"`
+	Password         string         `json:"password" binding:"required,min=8,max=72"`
+	Role             string         `json:"role" binding:"required,oneof=admin manager user viewer"`
+	Attributes       UserAttributes `json:"attributes"`
+	AccountExpiresAt *time.Time     `json:"account_expires_at"` // アカウントの利用期限（省略時は無期限）
 }
 
 // UpdateUserRequest はユーザー更新リクエストです
@@ -69,34 +146,118 @@ type UpdateUserRequest struct {
 	Department *string `json:"department" binding:"omitempty,max=100"`
 	Role       *string `json:"role" binding:"omitempty,oneof=admin manager user viewer"`
 	Status     *string `json:"status" binding:"omitempty,oneof=active inactive suspended"`
+	// Attributes は指定したキーのみ更新します（null を指定したキーは削除します）
+	Attributes UserAttributes `json:"attributes"`
+	// AccountExpiresAt は利用期限を設定します（解除する場合はPATCHで null を指定します）
+	AccountExpiresAt *time.Time `json:"account_expires_at"`
+}
+
+// UserPatchDocument はPATCHでユーザーを部分更新する際の対象ドキュメントです
+// パッチはこのドキュメントに適用され、適用後の内容がそのまま検証されます
+type UserPatchDocument struct {
+	Email            string         `json:"email" binding:"required,email,max=255"`
+	FullName         string         `json:"full_name" binding:"max=100"`
+	Department       string         `json:"department" binding:"max=100"`
+	Role             string         `json:"role" binding:"required,oneof=admin manager user viewer"`
+	Status           string         `json:"status" binding:"required,oneof=active inactive suspended"`
+	Attributes       UserAttributes `json:"attributes"`
+	AccountExpiresAt *time.Time     `json:"account_expires_at"` // null で無期限
+}
+
+// PatchDocument はユーザーの更新可能な項目をパッチ対象のドキュメントとして返します
+func (u *User) PatchDocument() *UserPatchDocument {
+	return &UserPatchDocument{
+		Email:            u.Email,
+		FullName:         u.FullName,
+		Department:       u.Department,
+		Role:             u.Role,
+		Status:           u.Status,
+		Attributes:       u.Attributes.Clone(),
+		AccountExpiresAt: u.AccountExpiresAt,
+	}
+}
+
+// UpdateMeRequest はログイン中のユーザーが自身の情報を更新するリクエストです
+// ユーザー自身が変更できる項目のみを定義します
+type UpdateMeRequest struct {
+	FullName *string `json:"full_name" binding:"omitempty,max=100"`
+}
+
+// VerifyEmailRequest はメールアドレス確認リクエストです
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
 }
 
 // UserResponse はユーザーレスポンスです（パスワードを除外）
 type UserResponse struct {
-	ID         uint       `json:"id"`
-	Username   string     `json:"username"`
-	Email      string     `json:"email"`
-	FullName   string     `json:"full_name"`
-	Department string     `json:"department"`
-	Role       string     `json:"role"`
-	Status     string     `json:"status"`
-	LastLogin  *time.Time `json:"last_login"`
-	CreatedAt  time.Time  `json:"created_at"`
-	UpdatedAt  time.Time  `json:"updated_at"`
+	ID               uint           `json:"id"`
+	Username         string         `json:"username"`
+	Email            string         `json:"email"`
+	EmailVerified    bool           `json:"email_verified"`
+	PendingEmail     string         `json:"pending_email,omitempty"`
+	FullName         string         `json:"full_name"`
+	Department       string         `json:"department"`
+	Role             string         `json:"role"`
+	Status           string         `json:"status"`
+	Attributes       UserAttributes `json:"attributes"`
+	AccountExpiresAt *time.Time     `json:"account_expires_at"`
+	LastLogin        *time.Time     `json:"last_login"`
+	Version          uint           `json:"version"`
+	ETag             string         `json:"etag"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        time.Time      `json:"updated_at"`
+}
+
+// DeletedUserResponse は削除済みユーザーのレスポンスです
+type DeletedUserResponse struct {
+	UserResponse
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// MeResponse はログイン中のユーザー自身の情報です
+type MeResponse struct {
+	UserResponse
+	Permissions []string `json:"permissions"`
 }
 
 // ToResponse はUserをUserResponseに変換します
 func (u *User) ToResponse() *UserResponse {
 	return &UserResponse{
-		ID:         u.ID,
-		Username:   u.Username,
-		Email:      u.Email,
-		FullName:   u.FullName,
-		Department: u.Department,
-		Role:       u.Role,
-		Status:     u.Status,
-		LastLogin:  u.LastLogin,
-		CreatedAt:  u.CreatedAt,
-		UpdatedAt:  u.UpdatedAt,
+		ID:               u.ID,
+		Username:         u.Username,
+		Email:            u.Email,
+		EmailVerified:    u.EmailVerified,
+		PendingEmail:     u.PendingEmail,
+		FullName:         u.FullName,
+		Department:       u.Department,
+		Role:             u.Role,
+		Status:           u.Status,
+		Attributes:       u.Attributes.Clone(),
+		AccountExpiresAt: u.AccountExpiresAt,
+		LastLogin:        u.LastLogin,
+		Version:          u.Version,
+		ETag:             u.ETag(),
+		CreatedAt:        u.CreatedAt,
+		UpdatedAt:        u.UpdatedAt,
+	}
+}
+
+// EmailVerificationTarget は確認メールの送信先を返します
+// メールアドレス変更中は新しいアドレス、未確認の場合は現在のアドレスで、確認済みの場合は空文字を返します
+func (u *User) EmailVerificationTarget() string {
+	if u.PendingEmail != "" {
+		return u.PendingEmail
+	}
+	if !u.EmailVerified {
+		return u.Email
+	}
+	return ""
+}
+
+// ToDeletedResponse は削除済みUserをDeletedUserResponseに変換します
+func (u *User) ToDeletedResponse() *DeletedUserResponse {
+	return &DeletedUserResponse{
+		UserResponse: *u.ToResponse(),
+		DeletedAt:    u.DeletedAt.Time,
 	}
 }
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// UserAttributeDefinition は管理者が定義するユーザーの追加属性のスキーマです
+// 値は users.attributes（JSONB）にキーごとに保存されます
+type UserAttributeDefinition struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Key         string     `gorm:"uniqueIndex;not null;size:50" json:"key"`
+	Label       string     `gorm:"not null;size:100" json:"label"`
+	Type        string     `gorm:"not null;size:20" json:"type"`
+	Required    bool       `gorm:"not null;default:false" json:"required"`
+	EnumValues  StringList `gorm:"type:jsonb;not null;default:'[]'" json:"enum_values"`
+	Pattern     string     `gorm:"size:255" json:"pattern"` // string型の値に適用する正規表現
+	Description string     `gorm:"type:text" json:"description"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (UserAttributeDefinition) TableName() string {
+	return "user_attribute_definitions"
+}
+
+// 属性の型定数
+const (
+	AttributeTypeString  = "string"
+	AttributeTypeNumber  = "number"
+	AttributeTypeBoolean = "boolean"
+	AttributeTypeDate    = "date" // YYYY-MM-DD 形式の文字列
+	AttributeTypeEnum    = "enum"
+)
+
+// UserAttributes はユーザーの追加属性の値です（キーは属性定義のキー）
+type UserAttributes map[string]interface{}
+
+// Value はJSONBとして保存するための値を返します
+func (a UserAttributes) Value() (driver.Value, error) {
+	if a == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(a)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan はJSONBの値を読み込みます
+func (a *UserAttributes) Scan(value interface{}) error {
+	data, err := jsonBytes(value)
+	if err != nil {
+		return err
+	}
+	attributes := make(UserAttributes)
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &attributes); err != nil {
+			return err
+		}
+	}
+	*a = attributes
+	return nil
+}
+
+// Clone は属性のコピーを返します（監査ログの更新前の値の保存に使用します）
+func (a UserAttributes) Clone() UserAttributes {
+	cloned := make(UserAttributes, len(a))
+	for key, value := range a {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// StringList は文字列の配列をJSONBとして保存する型です
+type StringList []string
+
+// Value はJSONBとして保存するための値を返します
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan はJSONBの値を読み込みます
+func (l *StringList) Scan(value interface{}) error {
+	data, err := jsonBytes(value)
+	if err != nil {
+		return err
+	}
+	list := StringList{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+	}
+	*l = list
+	return nil
+}
+
+// jsonBytes はデータベースから読み込んだJSONの値をバイト列に変換します
+func jsonBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	default:
+		return nil, errors.New("unsupported JSON column value")
+	}
+}
+
+// CreateUserAttributeDefinitionRequest は属性定義作成リクエストです
+type CreateUserAttributeDefinitionRequest struct {
+	Key         string   `json:"key" binding:"required,max=50"`
+	Label       string   `json:"label" binding:"required,max=100"`
+	Type        string   `json:"type" binding:"required,oneof=string number boolean date enum"`
+	Required    bool     `json:"required"`
+	EnumValues  []string `json:"enum_values" binding:"omitempty,dive,required,max=100"`
+	Pattern     string   `json:"pattern" binding:"max=255"`
+	Description string   `json:"description"`
+}
+
+// UpdateUserAttributeDefinitionRequest は属性定義更新リクエストです
+// 保存済みの値と矛盾しないよう、キーと型は変更できません
+type UpdateUserAttributeDefinitionRequest struct {
+	Label       *string   `json:"label" binding:"omitempty,max=100"`
+	Required    *bool     `json:"required"`
+	EnumValues  *[]string `json:"enum_values" binding:"omitempty,dive,required,max=100"`
+	Pattern     *string   `json:"pattern" binding:"omitempty,max=255"`
+	Description *string   `json:"description"`
+}
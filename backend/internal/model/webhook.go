@@ -0,0 +1,153 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// WebhookSubscription はWebhookの送信先です
+// 登録したイベントが発生すると、Secret で署名したリクエストを URL に送信します
+type WebhookSubscription struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	Name        string     `gorm:"not null;size:100" json:"name"`
+	URL         string     `gorm:"not null;type:text" json:"url"`
+	Events      StringList `gorm:"type:jsonb;not null;default:'[]'" json:"events"` // 送信するイベント種別（"*" はすべて）
+	Secret      string     `gorm:"not null;size:255" json:"-"`                     // 署名用のシークレット（作成時のみ返す）
+	Active      bool       `gorm:"not null;default:true" json:"active"`
+	Description string     `gorm:"type:text" json:"description"`
+	CreatedBy   uint       `gorm:"not null" json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (WebhookSubscription) TableName() string {
+	return "webhook_subscriptions"
+}
+
+// Subscribes はイベント種別が送信対象かを返します
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, event := range s.Events {
+		if event == WebhookEventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhookのイベント種別
+const (
+	WebhookEventAll             = "*"
+	WebhookEventUserCreated     = "user.created"
+	WebhookEventUserUpdated     = "user.updated"
+	WebhookEventUserSuspended   = "user.suspended"
+	WebhookEventUserDeleted     = "user.deleted"
+	WebhookEventAuthLoginFailed = "auth.login_failed"
+	WebhookEventSecurityAlert   = "security.alert"
+)
+
+// IsValidWebhookEvent はイベント種別が有効かチェックします
+func IsValidWebhookEvent(event string) bool {
+	switch event {
+	case WebhookEventAll, WebhookEventUserCreated, WebhookEventUserUpdated, WebhookEventUserSuspended,
+		WebhookEventUserDeleted, WebhookEventAuthLoginFailed, WebhookEventSecurityAlert:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery は送信先ごとの配信です（送信キューを兼ねます）
+// 送信に失敗した場合は NextAttemptAt まで待って再試行し、上限に達するとデッドレターになります
+type WebhookDelivery struct {
+	ID             uint           `gorm:"primarykey" json:"id"`
+	SubscriptionID uint           `gorm:"not null;index" json:"subscription_id"`
+	EventID        string         `gorm:"not null;size:36" json:"event_id"`
+	EventType      string         `gorm:"not null;size:50" json:"event_type"`
+	Payload        datatypes.JSON `gorm:"type:jsonb;not null" json:"payload"` // 送信する本文（再送時も同じ内容を送る）
+	Status         string         `gorm:"not null;size:20;default:'pending'" json:"status"`
+	Attempts       int            `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time      `gorm:"not null" json:"next_attempt_at"`
+	LockedUntil    *time.Time     `json:"-"` // 送信中の配信を他のインスタンスが取得しないためのリース
+	LastStatusCode int            `gorm:"not null;default:0" json:"last_status_code"`
+	LastError      string         `gorm:"type:text" json:"last_error"`
+	DeliveredAt    *time.Time     `json:"delivered_at"`
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+}
+
+// TableName はテーブル名を指定します
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// 配信ステータス定数
+const (
+	WebhookDeliveryPending   = "pending"   // 送信待ち（再試行待ちを含む）
+	WebhookDeliverySucceeded = "succeeded" // 送信済み
+	WebhookDeliveryDead      = "dead"      // 再試行の上限に達した（デッドレター）
+)
+
+// WebhookDeliveryAttempt は配信の送信履歴です
+type WebhookDeliveryAttempt struct {
+	ID           uint      `gorm:"primarykey" json:"id"`
+	DeliveryID   uint      `gorm:"not null;index" json:"delivery_id"`
+	Attempt      int       `gorm:"not null" json:"attempt"`
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"` // 接続できなかった場合は0
+	Error        string    `gorm:"type:text" json:"error"`
+	ResponseBody string    `gorm:"type:text" json:"response_body"` // 先頭のみ保存
+	DurationMs   int64     `gorm:"not null;default:0" json:"duration_ms"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// TableName はテーブル名を指定します
+func (WebhookDeliveryAttempt) TableName() string {
+	return "webhook_delivery_attempts"
+}
+
+// WebhookEvent は送信するWebhookの本文です
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// CreateWebhookRequest はWebhook作成リクエストです
+// secret を省略した場合は自動生成します
+type CreateWebhookRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	URL         string   `json:"url" binding:"required,url"`
+	Events      []string `json:"events" binding:"required,min=1,dive,required"`
+	Secret      string   `json:"secret" binding:"omitempty,min=16,max=255"`
+	Active      *bool    `json:"active"`
+	Description string   `json:"description"`
+}
+
+// UpdateWebhookRequest はWebhook更新リクエストです
+type UpdateWebhookRequest struct {
+	Name        *string   `json:"name" binding:"omitempty,max=100"`
+	URL         *string   `json:"url" binding:"omitempty,url"`
+	Events      *[]string `json:"events" binding:"omitempty,min=1,dive,required"`
+	Secret      *string   `json:"secret" binding:"omitempty,min=16,max=255"`
+	Active      *bool     `json:"active"`
+	Description *string   `json:"description"`
+}
+
+// CreatedWebhookResponse はWebhook作成レスポンスです
+// シークレットは作成時のみ返します
+type CreatedWebhookResponse struct {
+	*WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+// WebhookDeliveryDetailResponse は配信の詳細レスポンスです
+type WebhookDeliveryDetailResponse struct {
+	*WebhookDelivery
+	AttemptLog []*WebhookDeliveryAttempt `json:"attempt_log"`
+}
+
+// ReplayWebhookDeliveriesResponse はデッドレターの一括再送レスポンスです
+type ReplayWebhookDeliveriesResponse struct {
+	Replayed int64 `json:"replayed"`
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
@@ -11,69 +12,80 @@ import (
 )
 
 // AuditLogRepository は監査ログのデータアクセスを提供します
+// 監査ログは作成時にハッシュチェーンで連結され、直接の書き換えや削除を検知できます
 type AuditLogRepository struct {
-	db *gorm.DB
+	db    *gorm.DB
+	chain *util.HashChain
 }
 
 // NewAuditLogRepository は新しいAuditLogRepositoryを作成します
-func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+func NewAuditLogRepository(db *gorm.DB, chain *util.HashChain) *AuditLogRepository {
 	return &AuditLogRepository{
-		db: db,
+		db:    db,
+		chain: chain,
 	}
 }
 
 // Create は監査ログを作成します
+// ID・作成日時・ハッシュはチェーンのロックを取得した後に採番するため、同時に作成しても順序が入れ替わりません
+// TxManager のトランザクション内で呼び出した場合は、そのトランザクションのコミットまでチェーンのロックを保持します
 func (r *AuditLogRepository) Create(ctx context.Context, auditLog *model.AuditLog) error {
-	return r.db.WithContext(ctx).Create(auditLog).Error
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+		return r.appendToChain(tx, auditLog)
+	})
+}
+
+// CreateBatch は複数の監査ログをまとめて作成します
+// チェーンのロックを1回だけ取得し、1つのトランザクションで渡された順にチェーンへ追加します
+func (r *AuditLogRepository) CreateBatch(ctx context.Context, auditLogs []*model.AuditLog) error {
+	if len(auditLogs) == 0 {
+		return nil
+	}
+
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+		for _, auditLog := range auditLogs {
+			if err := r.appendToChain(tx, auditLog); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // FindByID はIDで監査ログを取得します
 func (r *AuditLogRepository) FindByID(ctx context.Context, id uint) (*model.AuditLog, error) {
 	var auditLog model.AuditLog
-	if err := r.db.WithContext(ctx).First(&auditLog, id).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).First(&auditLog, id).Error; err != nil {
 		return nil, err
 	}
 	return &auditLog, nil
 }
 
-// FindAll は監査ログ一覧を取得します（ページネーション付き）
-func (r *AuditLogRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
+// FindByFilter は条件に一致する監査ログを新しい順に取得します（ページネーション付き）
+func (r *AuditLogRepository) FindByFilter(ctx context.Context, filter *model.AuditLogFilter, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
 	var auditLogs []*model.AuditLog
 	var total int64
 
-	// 件数を取得
-	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).Count(&total).Error; err != nil {
+	query, err := applyAuditLogFilter(dbWithContext(ctx, r.db).Model(&model.AuditLog{}), filter)
+	if err != nil {
 		return nil, 0, err
 	}
 
-	// ページネーションでデータを取得
-	if err := r.db.WithContext(ctx).
-		Order("created_at DESC").
-		Offset(params.Offset).
-		Limit(params.PerPage).
-		Find(&auditLogs).Error; err != nil {
-		return nil, 0, err
-	}
-
-	return auditLogs, total, nil
-}
-
-// FindByUserID はユーザーIDで監査ログを取得します
-func (r *AuditLogRepository) FindByUserID(ctx context.Context, userID uint, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	var auditLogs []*model.AuditLog
-	var total int64
-
 	// 件数を取得
-	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).
-		Where("user_id = ?", userID).
-		Count(&total).Error; err != nil {
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// ページネーションでデータを取得
-	if err := r.db.WithContext(ctx).
-		Where("user_id = ?", userID).
+	if err := query.
 		Order("created_at DESC").
+		Order("id DESC").
 		Offset(params.Offset).
 		Limit(params.PerPage).
 		Find(&auditLogs).Error; err != nil {
@@ -83,129 +95,211 @@ func (r *AuditLogRepository) FindByUserID(ctx context.Context, userID uint, para
 	return auditLogs, total, nil
 }
 
-// FindByResourceID はリソースIDで監査ログを取得します
-func (r *AuditLogRepository) FindByResourceID(ctx context.Context, resourceType, resourceID string, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
+// FindAfter は afterID より新しく条件に一致する監査ログを古い順に最大 limit 件取得します
+// IDはチェーンのロックを取得してから採番するため、コミット順とIDの順序は一致します
+func (r *AuditLogRepository) FindAfter(ctx context.Context, filter *model.AuditLogFilter, afterID uint, limit int) ([]*model.AuditLog, error) {
 	var auditLogs []*model.AuditLog
-	var total int64
 
-	// 件数を取得
-	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).
-		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+	query, err := applyAuditLogFilter(dbWithContext(ctx, r.db).Model(&model.AuditLog{}), filter)
+	if err != nil {
+		return nil, err
 	}
 
-	// ページネーションでデータを取得
-	if err := r.db.WithContext(ctx).
-		Where("resource_type = ? AND resource_id = ?", resourceType, resourceID).
-		Order("created_at DESC").
-		Offset(params.Offset).
-		Limit(params.PerPage).
-		Find(&auditLogs).Error; err != nil {
-		return nil, 0, err
-	}
+	err = query.
+		Where("id > ?", afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&auditLogs).Error
+	return auditLogs, err
+}
 
-	return auditLogs, total, nil
+// LatestID は最新の監査ログのIDを返します（監査ログがない場合は0）
+func (r *AuditLogRepository) LatestID(ctx context.Context) (uint, error) {
+	var id uint
+	err := dbWithContext(ctx, r.db).Model(&model.AuditLog{}).Select("COALESCE(MAX(id), 0)").Scan(&id).Error
+	return id, err
 }
 
-// FindByAction はアクションで監査ログを取得します
-func (r *AuditLogRepository) FindByAction(ctx context.Context, action string, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
+// FindByActorOrSubject はユーザーが実行者または対象となっている監査ログを全て取得します
+// resourceIDs には対象ユーザーを表すリソースID（ユーザー名など）を指定します
+func (r *AuditLogRepository) FindByActorOrSubject(ctx context.Context, userID uint, resourceIDs []string) ([]*model.AuditLog, error) {
 	var auditLogs []*model.AuditLog
-	var total int64
+	err := dbWithContext(ctx, r.db).
+		Where("user_id = ? OR (resource_type = ? AND resource_id IN ?)", userID, model.ResourceTypeUser, resourceIDs).
+		Order("created_at ASC").
+		Find(&auditLogs).Error
+	return auditLogs, err
+}
 
-	// 件数を取得
-	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).
-		Where("action = ?", action).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
-	}
+// FindFailedLoginAccounts は since 以降に ipAddress からログインに失敗したアカウント（ユーザー名）を最大 limit 件取得します
+func (r *AuditLogRepository) FindFailedLoginAccounts(ctx context.Context, ipAddress string, since time.Time, limit int) ([]string, error) {
+	var accounts []string
+	err := dbWithContext(ctx, r.db).Model(&model.AuditLog{}).
+		Distinct("resource_id").
+		Where("action = ? AND status = ? AND ip_address = ? AND created_at >= ?",
+			model.ActionLogin, model.AuditStatusFailed, ipAddress, since).
+		Limit(limit).
+		Pluck("resource_id", &accounts).Error
+	return accounts, err
+}
 
-	// ページネーションでデータを取得
-	if err := r.db.WithContext(ctx).
-		Where("action = ?", action).
-		Order("created_at DESC").
-		Offset(params.Offset).
-		Limit(params.PerPage).
-		Find(&auditLogs).Error; err != nil {
-		return nil, 0, err
+// ExistsSuccessfulLogin は since 以降にユーザーがログインに成功した監査ログが存在するかを返します
+// ipAddress・userAgent を指定した場合は、その送信元からのログインに限定します
+func (r *AuditLogRepository) ExistsSuccessfulLogin(ctx context.Context, userID uint, ipAddress, userAgent string, since time.Time) (bool, error) {
+	query := dbWithContext(ctx, r.db).Model(&model.AuditLog{}).
+		Where("user_id = ? AND action = ? AND status = ? AND created_at >= ?",
+			userID, model.ActionLogin, model.AuditStatusSuccess, since)
+	if ipAddress != "" {
+		query = query.Where("ip_address = ?", ipAddress)
+	}
+	if userAgent != "" {
+		query = query.Where("user_agent = ?", userAgent)
 	}
 
-	return auditLogs, total, nil
+	var ids []uint
+	if err := query.Limit(1).Pluck("id", &ids).Error; err != nil {
+		return false, err
+	}
+	return len(ids) > 0, nil
 }
 
-// FindByDateRange は日付範囲で監査ログを取得します
-func (r *AuditLogRepository) FindByDateRange(ctx context.Context, startDate, endDate time.Time, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	var auditLogs []*model.AuditLog
-	var total int64
-
-	// 件数を取得
-	if err := r.db.WithContext(ctx).Model(&model.AuditLog{}).
-		Where("created_at >= ? AND created_at <= ?", startDate, endDate).
-		Count(&total).Error; err != nil {
-		return nil, 0, err
+// FindLastSuccessfulLogin は since 以降にユーザーがIPアドレス付きでログインに成功した最新の監査ログを取得します
+func (r *AuditLogRepository) FindLastSuccessfulLogin(ctx context.Context, userID uint, since time.Time) (*model.AuditLog, error) {
+	var auditLog model.AuditLog
+	err := dbWithContext(ctx, r.db).
+		Where("user_id = ? AND action = ? AND status = ? AND ip_address <> '' AND created_at >= ?",
+			userID, model.ActionLogin, model.AuditStatusSuccess, since).
+		Order("created_at DESC").
+		First(&auditLog).Error
+	if err != nil {
+		return nil, err
 	}
+	return &auditLog, nil
+}
 
-	// ページネーションでデータを取得
-	if err := r.db.WithContext(ctx).
-		Where("created_at >= ? AND created_at <= ?", startDate, endDate).
-		Order("created_at DESC").
-		Offset(params.Offset).
-		Limit(params.PerPage).
-		Find(&auditLogs).Error; err != nil {
-		return nil, 0, err
+// UpdatePersonalData は監査ログの個人情報を含むカラムを更新します
+// 監査証跡の構造を保つため、その他のカラムは変更しません
+// ハッシュチェーンは再計算せず、匿名化後の内容のハッシュを元のハッシュと結び付けた署名付きの記録を残します
+// そのためチェーンのロックは不要で、件数が多くても監査ログの書き込みを止めません
+// 作成日時で対象のパーティションに絞り込むため、auditLogs は取得した監査ログをそのまま渡してください
+func (r *AuditLogRepository) UpdatePersonalData(ctx context.Context, auditLogs []*model.AuditLog) error {
+	if len(auditLogs) == 0 {
+		return nil
 	}
 
-	return auditLogs, total, nil
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, auditLog := range auditLogs {
+			if err := r.sealRedaction(auditLog); err != nil {
+				return err
+			}
+			if err := tx.Model(&model.AuditLog{}).
+				Where("id = ? AND created_at = ?", auditLog.ID, auditLog.CreatedAt).
+				Updates(map[string]interface{}{
+					"resource_id":         auditLog.ResourceID,
+					"changes":             auditLog.Changes,
+					"ip_address":          auditLog.IPAddress,
+					"user_agent":          auditLog.UserAgent,
+					"redacted_hash":       auditLog.RedactedHash,
+					"redaction_signature": auditLog.RedactionSignature,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // DeleteOldLogs は古い監査ログを削除します（指定日数より古いもの）
-func (r *AuditLogRepository) DeleteOldLogs(ctx context.Context, days int) error {
+// ハッシュチェーンが途切れないよう、対象のうち最大のID以下をまとめて削除し、
+// 削除した最後の監査ログのハッシュを署名付きのチェックポイントとして残します
+// 訴訟ホールドの対象は削除しません。対象がある場合は、最も古い対象より前をチェックポイントにまとめ、
+// それより後の監査ログはホールドの対象を除いて墓標に置き換えて削除します
+func (r *AuditLogRepository) DeleteOldLogs(ctx context.Context, days int, holds []*model.AuditHoldScope) error {
 	cutoffDate := time.Now().AddDate(0, 0, -days)
-	return r.db.WithContext(ctx).
-		Where("created_at < ?", cutoffDate).
-		Delete(&model.AuditLog{}).Error
-}
-
-// CountByAction はアクション別の集計を取得します
-func (r *AuditLogRepository) CountByAction(ctx context.Context) (map[string]int64, error) {
-	var results []struct {
-		Action string
-		Count  int64
-	}
-
-	if err := r.db.WithContext(ctx).
-		Model(&model.AuditLog{}).
-		Select("action, COUNT(*) as count").
-		Group("action").
-		Scan(&results).Error; err != nil {
-		return nil, err
-	}
-
-	counts := make(map[string]int64)
-	for _, r := range results {
-		counts[r.Action] = r.Count
-	}
-	return counts, nil
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+
+		var last model.AuditLog
+		err := tx.Where("created_at < ?", cutoffDate).Order("id DESC").Take(&last).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		holdCond, holdArgs := matchHolds(holds)
+		if holdCond == "" {
+			return r.deleteThroughCheckpoint(tx, &last, cutoffDate)
+		}
+
+		// 削除の対象に含まれる最も古いホールドの対象（ない場合は0）
+		var heldID uint
+		if err := tx.Model(&model.AuditLog{}).
+			Select("COALESCE(MIN(id), 0)").
+			Where("id <= ? AND ("+holdCond+")", append([]interface{}{last.ID}, holdArgs...)...).
+			Scan(&heldID).Error; err != nil {
+			return err
+		}
+		if heldID == 0 {
+			return r.deleteThroughCheckpoint(tx, &last, cutoffDate)
+		}
+
+		var before model.AuditLog
+		err = tx.Where("id < ?", heldID).Order("id DESC").Take(&before).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err == nil {
+			if err := r.deleteThroughCheckpoint(tx, &before, cutoffDate); err != nil {
+				return err
+			}
+		}
+
+		afterID := heldID
+		for {
+			var expired []*model.AuditLog
+			if err := tx.Select("id", "prev_hash", "hash", "created_at").
+				Where("id > ? AND created_at < ? AND NOT ("+holdCond+")", append([]interface{}{afterID, cutoffDate}, holdArgs...)...).
+				Order("id ASC").
+				Limit(chainBatchSize).
+				Find(&expired).Error; err != nil {
+				return err
+			}
+			if len(expired) == 0 {
+				return nil
+			}
+			if _, err := r.purge(tx, expired); err != nil {
+				return err
+			}
+			if len(expired) < chainBatchSize {
+				return nil
+			}
+			afterID = expired[len(expired)-1].ID
+		}
+	})
 }
 
-// CountByStatus はステータス別の集計を取得します
-func (r *AuditLogRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
-	var results []struct {
-		Status string
-		Count  int64
+// deleteThroughCheckpoint は last 以下のIDの監査ログを削除し、last のハッシュを署名付きのチェックポイントとして残します
+// 呼び出し元でチェーンのロックを取得してください
+func (r *AuditLogRepository) deleteThroughCheckpoint(tx *gorm.DB, last *model.AuditLog, deletedBefore time.Time) error {
+	result := tx.Where("id <= ?", last.ID).Delete(&model.AuditLog{})
+	if result.Error != nil {
+		return result.Error
 	}
-
-	if err := r.db.WithContext(ctx).
-		Model(&model.AuditLog{}).
-		Select("status, COUNT(*) as count").
-		Group("status").
-		Scan(&results).Error; err != nil {
-		return nil, err
+	// チェックポイント以前の墓標は検証に使わなくなるため削除する
+	if err := tx.Where("id <= ?", last.ID).Delete(&model.AuditLogTombstone{}).Error; err != nil {
+		return err
 	}
 
-	counts := make(map[string]int64)
-	for _, r := range results {
-		counts[r.Status] = r.Count
+	checkpoint := &model.AuditLogCheckpoint{
+		LastDeletedID: last.ID,
+		LastHash:      last.Hash,
+		DeletedCount:  result.RowsAffected,
+		DeletedBefore: deletedBefore.UTC().Truncate(time.Microsecond),
 	}
-	return counts, nil
+	checkpoint.Signature = r.chain.Sign(checkpoint.SignedPayload())
+	return tx.Create(checkpoint).Error
 }
@@ -0,0 +1,208 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// ErrArchivedLogChanged はアーカイブした後に監査ログの内容またはハッシュが変わったことを表します
+var ErrArchivedLogChanged = errors.New("audit log changed after it was archived")
+
+// archiveImportBatchSize はアーカイブを取り込む際に1回で挿入する件数です
+const archiveImportBatchSize = 500
+
+// archiveCondition はアーカイブする監査ログの条件を組み立てます（訴訟ホールドの対象を除く）
+func archiveCondition(cutoff time.Time, holds []*model.AuditHoldScope) (string, []interface{}) {
+	cond, args := excludeHolds(holds)
+	switch cond {
+	case "FALSE":
+		return cond, nil
+	case "":
+		return "created_at < ?", []interface{}{cutoff}
+	}
+	return "created_at < ? AND " + cond, append([]interface{}{cutoff}, args...)
+}
+
+// FindArchivable は cutoff より前に作成された監査ログを古い順に最大 limit 件取得します
+func (r *AuditLogRepository) FindArchivable(ctx context.Context, cutoff time.Time, holds []*model.AuditHoldScope, limit int) ([]*model.AuditLog, error) {
+	cond, args := archiveCondition(cutoff, holds)
+
+	var auditLogs []*model.AuditLog
+	err := dbWithContext(ctx, r.db).
+		Where(cond, args...).
+		Order("id ASC").
+		Limit(limit).
+		Find(&auditLogs).Error
+	return auditLogs, err
+}
+
+// DeleteArchived はアーカイブした監査ログを削除し、削除した件数を返します
+// アーカイブ後に内容が変わった監査ログがある場合は何も削除せずにエラーを返します
+// 訴訟ホールドの対象になった監査ログは削除しません
+func (r *AuditLogRepository) DeleteArchived(ctx context.Context, archived []*model.AuditLog, holds []*model.AuditHoldScope) (int64, error) {
+	if len(archived) == 0 {
+		return 0, nil
+	}
+
+	hashes := make(map[uint]string, len(archived))
+	ids := make([]uint, len(archived))
+	for i, auditLog := range archived {
+		hashes[auditLog.ID] = auditLog.Hash
+		ids[i] = auditLog.ID
+	}
+	holdCond, holdArgs := excludeHolds(holds)
+
+	var deleted int64
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+
+		query := tx.Select("id", "prev_hash", "hash", "created_at").Where("id IN ?", ids)
+		if holdCond != "" {
+			query = query.Where(holdCond, holdArgs...)
+		}
+		var current []*model.AuditLog
+		if err := query.Order("id ASC").Find(&current).Error; err != nil {
+			return err
+		}
+		for _, auditLog := range current {
+			if auditLog.Hash != hashes[auditLog.ID] {
+				return fmt.Errorf("%w: id %d", ErrArchivedLogChanged, auditLog.ID)
+			}
+		}
+		if len(current) == 0 {
+			return nil
+		}
+
+		purged, err := r.purge(tx, current)
+		deleted = purged
+		return err
+	})
+	return deleted, err
+}
+
+// VerifyHash は監査ログのハッシュが内容と直前のハッシュに一致するかを返します（導入前の記録は true）
+// 匿名化した監査ログは、署名付きの匿名化の記録が匿名化後の内容に一致するかで判定します
+func (r *AuditLogRepository) VerifyHash(auditLog *model.AuditLog) bool {
+	if auditLog.Hash == "" {
+		return true
+	}
+	payload, err := auditLog.ChainPayload()
+	if err != nil {
+		return false
+	}
+	contentHash := r.chain.Link(auditLog.PrevHash, payload)
+	return contentHash == auditLog.Hash || verifyRedaction(r.chain, auditLog, contentHash)
+}
+
+// AuditLogArchiveRepository は監査ログのアーカイブのマニフェストと取り込んだ監査ログのデータアクセスを提供します
+type AuditLogArchiveRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditLogArchiveRepository は新しいAuditLogArchiveRepositoryを作成します
+func NewAuditLogArchiveRepository(db *gorm.DB) *AuditLogArchiveRepository {
+	return &AuditLogArchiveRepository{
+		db: db,
+	}
+}
+
+// FindAll はアーカイブを新しい順に取得します
+func (r *AuditLogArchiveRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.AuditLogArchive, int64, error) {
+	var archives []*model.AuditLogArchive
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Model(&model.AuditLogArchive{})
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("id DESC").
+		Find(&archives).Error
+
+	return archives, total, err
+}
+
+// FindByStatus は指定した状態のアーカイブを古い順に取得します
+func (r *AuditLogArchiveRepository) FindByStatus(ctx context.Context, status string) ([]*model.AuditLogArchive, error) {
+	var archives []*model.AuditLogArchive
+	err := dbWithContext(ctx, r.db).Where("status = ?", status).Order("id ASC").Find(&archives).Error
+	return archives, err
+}
+
+// FindByID はIDでアーカイブを取得します
+func (r *AuditLogArchiveRepository) FindByID(ctx context.Context, id uint) (*model.AuditLogArchive, error) {
+	var archive model.AuditLogArchive
+	if err := dbWithContext(ctx, r.db).First(&archive, id).Error; err != nil {
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// Create はアーカイブを作成します
+func (r *AuditLogArchiveRepository) Create(ctx context.Context, archive *model.AuditLogArchive) error {
+	return dbWithContext(ctx, r.db).Create(archive).Error
+}
+
+// Update はアーカイブを更新します
+func (r *AuditLogArchiveRepository) Update(ctx context.Context, archive *model.AuditLogArchive) error {
+	return dbWithContext(ctx, r.db).Save(archive).Error
+}
+
+// Delete はアーカイブを削除します（取り込んだ監査ログも削除されます）
+func (r *AuditLogArchiveRepository) Delete(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Delete(&model.AuditLogArchive{}, id).Error
+}
+
+// ReplaceImported はアーカイブから取り込んだ監査ログを置き換えます（同じアーカイブを再度取り込んでも重複しません）
+func (r *AuditLogArchiveRepository) ReplaceImported(ctx context.Context, archiveID uint, auditLogs []*model.AuditLog) error {
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("archive_id = ?", archiveID).Delete(&model.ArchivedAuditLog{}).Error; err != nil {
+			return err
+		}
+		if len(auditLogs) == 0 {
+			return nil
+		}
+
+		rows := make([]*model.ArchivedAuditLog, len(auditLogs))
+		for i, auditLog := range auditLogs {
+			rows[i] = &model.ArchivedAuditLog{ArchiveID: archiveID, AuditLog: *auditLog}
+		}
+		return tx.CreateInBatches(rows, archiveImportBatchSize).Error
+	})
+}
+
+// FindImported はアーカイブから取り込んだ監査ログをID順に取得します
+func (r *AuditLogArchiveRepository) FindImported(ctx context.Context, archiveID uint, params *util.PaginationParams) ([]*model.ArchivedAuditLog, int64, error) {
+	var auditLogs []*model.ArchivedAuditLog
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Model(&model.ArchivedAuditLog{}).Where("archive_id = ?", archiveID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("id ASC").
+		Find(&auditLogs).Error
+
+	return auditLogs, total, err
+}
+
+// DeleteImported はアーカイブから取り込んだ監査ログを削除します
+func (r *AuditLogArchiveRepository) DeleteImported(ctx context.Context, archiveID uint) error {
+	return dbWithContext(ctx, r.db).Where("archive_id = ?", archiveID).Delete(&model.ArchivedAuditLog{}).Error
+}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestArchiveCondition(t *testing.T) {
+	cutoff := time.Date(2025, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Without holds", func(t *testing.T) {
+		cond, args := archiveCondition(cutoff, nil)
+
+		assert.Equal(t, "created_at < ?", cond)
+		assert.Equal(t, []interface{}{cutoff}, args)
+	})
+
+	t.Run("Holds are excluded", func(t *testing.T) {
+		userID := uint(7)
+		cond, args := archiveCondition(cutoff, []*model.AuditHoldScope{{UserID: &userID}})
+
+		assert.Equal(t, "created_at < ? AND NOT (user_id = ?)", cond)
+		assert.Equal(t, []interface{}{cutoff, userID}, args)
+	})
+
+	t.Run("Unscoped hold keeps everything", func(t *testing.T) {
+		cond, args := archiveCondition(cutoff, []*model.AuditHoldScope{{}})
+
+		assert.Equal(t, "FALSE", cond)
+		assert.Empty(t, args)
+	})
+}
@@ -0,0 +1,364 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// chainBatchSize はハッシュチェーンを検証・再計算する際に一度に読み込む件数です
+const chainBatchSize = 1000
+
+// lockChain はハッシュチェーンを更新するトランザクションを直列化します
+// トランザクション単位のアドバイザリーロックのため、コミットまたはロールバックで解放されます
+func lockChain(tx *gorm.DB) error {
+	return tx.Exec("SELECT pg_advisory_xact_lock(hashtext('audit_logs_chain'))").Error
+}
+
+// appendToChain はチェーンの末尾に監査ログを追加します（lockChain を取得したトランザクションで呼び出してください）
+func (r *AuditLogRepository) appendToChain(tx *gorm.DB, auditLog *model.AuditLog) error {
+	prevHash, err := r.chainHead(tx, 0)
+	if err != nil {
+		return err
+	}
+
+	// ロック取得後に採番することで、IDと作成日時の順序をチェーンの順序と一致させる
+	var next struct {
+		ID  uint
+		Now time.Time
+	}
+	if err := tx.Raw("SELECT nextval(pg_get_serial_sequence('audit_logs', 'id')) AS id, clock_timestamp() AS now").
+		Scan(&next).Error; err != nil {
+		return err
+	}
+
+	auditLog.ID = next.ID
+	auditLog.CreatedAt = next.Now.UTC().Truncate(time.Microsecond)
+	auditLog.PrevHash = prevHash
+	payload, err := auditLog.ChainPayload()
+	if err != nil {
+		return err
+	}
+	auditLog.Hash = r.chain.Link(prevHash, payload)
+
+	return tx.Create(auditLog).Error
+}
+
+// chainHead は指定したIDの直前の監査ログのハッシュを返します（beforeID が0の場合は末尾）
+// 直前の監査ログが保持期間ポリシーで削除済みの場合は墓標のハッシュを、
+// 古い監査ログの削除で残っていない場合は最新のチェックポイントのハッシュを返します
+func (r *AuditLogRepository) chainHead(tx *gorm.DB, beforeID uint) (string, error) {
+	logQuery := tx.Model(&model.AuditLog{})
+	tombstoneQuery := tx.Model(&model.AuditLogTombstone{})
+	if beforeID > 0 {
+		logQuery = logQuery.Where("id < ?", beforeID)
+		tombstoneQuery = tombstoneQuery.Where("id < ?", beforeID)
+	}
+
+	var heads []struct {
+		ID   uint
+		Hash string
+	}
+	if err := logQuery.Select("id, hash").Order("id DESC").Limit(1).Scan(&heads).Error; err != nil {
+		return "", err
+	}
+	var tombstones []struct {
+		ID   uint
+		Hash string
+	}
+	if err := tombstoneQuery.Select("id, hash").Order("id DESC").Limit(1).Scan(&tombstones).Error; err != nil {
+		return "", err
+	}
+	if len(tombstones) > 0 && (len(heads) == 0 || tombstones[0].ID > heads[0].ID) {
+		heads = tombstones
+	}
+	if len(heads) > 0 {
+		return heads[0].Hash, nil
+	}
+
+	checkpoint, err := findLatestCheckpoint(tx)
+	if err != nil || checkpoint == nil {
+		return "", err
+	}
+	return checkpoint.LastHash, nil
+}
+
+// sealRedaction は匿名化した監査ログの内容のハッシュを計算し、元のハッシュと結び付けて署名します
+// ハッシュチェーン導入前の記録（ハッシュが空）は検証の対象外のため何もしません
+func (r *AuditLogRepository) sealRedaction(auditLog *model.AuditLog) error {
+	if auditLog.Hash == "" {
+		return nil
+	}
+
+	payload, err := auditLog.ChainPayload()
+	if err != nil {
+		return err
+	}
+	auditLog.RedactedHash = r.chain.Link(auditLog.PrevHash, payload)
+	auditLog.RedactionSignature = r.chain.Sign(auditLog.RedactionPayload())
+	return nil
+}
+
+// verifyRedaction は匿名化の記録の署名が正しく、匿名化後の内容のハッシュが contentHash に一致するかを返します
+func verifyRedaction(chain *util.HashChain, auditLog *model.AuditLog, contentHash string) bool {
+	return auditLog.RedactedHash == contentHash &&
+		chain.VerifySignature(auditLog.RedactionPayload(), auditLog.RedactionSignature)
+}
+
+// chainEntry はハッシュチェーンの要素です（監査ログまたは墓標のいずれか）
+type chainEntry struct {
+	log       *model.AuditLog
+	tombstone *model.AuditLogTombstone
+}
+
+// id は要素のIDを返します
+func (e chainEntry) id() uint {
+	if e.tombstone != nil {
+		return e.tombstone.ID
+	}
+	return e.log.ID
+}
+
+// loadChainEntries は afterID より後の監査ログを最大 chainBatchSize 件と、その範囲の墓標をID順に並べて返します
+// 続きの監査ログがある可能性がある場合は more が true になります
+func loadChainEntries(tx *gorm.DB, afterID uint) (entries []chainEntry, more bool, err error) {
+	var auditLogs []*model.AuditLog
+	if err := tx.Where("id > ?", afterID).Order("id ASC").Limit(chainBatchSize).Find(&auditLogs).Error; err != nil {
+		return nil, false, err
+	}
+	more = len(auditLogs) == chainBatchSize
+
+	tombstoneQuery := tx.Where("id > ?", afterID)
+	if more {
+		tombstoneQuery = tombstoneQuery.Where("id <= ?", auditLogs[len(auditLogs)-1].ID)
+	}
+	var tombstones []*model.AuditLogTombstone
+	if err := tombstoneQuery.Order("id ASC").Find(&tombstones).Error; err != nil {
+		return nil, false, err
+	}
+
+	return mergeChainEntries(auditLogs, tombstones), more, nil
+}
+
+// mergeChainEntries はID順の監査ログと墓標をID順に並べます
+func mergeChainEntries(auditLogs []*model.AuditLog, tombstones []*model.AuditLogTombstone) []chainEntry {
+	entries := make([]chainEntry, 0, len(auditLogs)+len(tombstones))
+	i, j := 0, 0
+	for i < len(auditLogs) || j < len(tombstones) {
+		if j == len(tombstones) || (i < len(auditLogs) && auditLogs[i].ID < tombstones[j].ID) {
+			entries = append(entries, chainEntry{log: auditLogs[i]})
+			i++
+		} else {
+			entries = append(entries, chainEntry{tombstone: tombstones[j]})
+			j++
+		}
+	}
+	return entries
+}
+
+// findLatestCheckpoint は最新のチェックポイントを返します（存在しない場合は nil）
+func findLatestCheckpoint(tx *gorm.DB) (*model.AuditLogCheckpoint, error) {
+	var checkpoint model.AuditLogCheckpoint
+	err := tx.Order("id DESC").Take(&checkpoint).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &checkpoint, nil
+}
+
+// VerifyChain は最新のチェックポイントから末尾までハッシュチェーンをたどり、最初の不整合を報告します
+// 末尾の記録の削除はチェーンからは検知できないため、結果の HeadHash を外部に控えて比較してください
+// 検証中に保持期間ポリシーで削除されても不整合と判定しないよう、1つのスナップショットで読み込みます
+func (r *AuditLogRepository) VerifyChain(ctx context.Context) (*model.AuditChainVerification, error) {
+	var result *model.AuditChainVerification
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		checkpoint, err := findLatestCheckpoint(tx)
+		if err != nil {
+			return err
+		}
+
+		verifier := newChainVerifier(r.chain, checkpoint)
+		var afterID uint
+		if checkpoint != nil {
+			afterID = checkpoint.LastDeletedID
+		}
+
+		for verifier.result.BrokenLink == nil {
+			entries, more, err := loadChainEntries(tx, afterID)
+			if err != nil {
+				return err
+			}
+
+			for _, entry := range entries {
+				afterID = entry.id()
+				if !verifier.checkEntry(entry) {
+					break
+				}
+			}
+
+			if !more {
+				break
+			}
+		}
+
+		result = verifier.result
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// chainVerifier はハッシュチェーンを先頭から順に検証します
+type chainVerifier struct {
+	chain      *util.HashChain
+	prevHash   string
+	seenHashed bool
+	result     *model.AuditChainVerification
+}
+
+// newChainVerifier は新しいchainVerifierを作成します
+// チェックポイントの署名が不正な場合は、監査ログを検証する前に不整合として記録します
+func newChainVerifier(chain *util.HashChain, checkpoint *model.AuditLogCheckpoint) *chainVerifier {
+	v := &chainVerifier{
+		chain:  chain,
+		result: &model.AuditChainVerification{Valid: true, Checkpoint: checkpoint},
+	}
+	if checkpoint == nil {
+		return v
+	}
+
+	if !chain.VerifySignature(checkpoint.SignedPayload(), checkpoint.Signature) {
+		v.fail(&model.AuditChainBreak{Reason: model.ChainBreakCheckpointSignature})
+		return v
+	}
+	v.prevHash = checkpoint.LastHash
+	v.seenHashed = checkpoint.LastHash != ""
+	return v
+}
+
+// checkEntry はハッシュチェーンの要素を1件検証し、不整合がなければ true を返します
+func (v *chainVerifier) checkEntry(entry chainEntry) bool {
+	if entry.tombstone != nil {
+		return v.checkTombstone(entry.tombstone)
+	}
+	return v.check(entry.log)
+}
+
+// checkTombstone は墓標を1件検証し、不整合がなければ true を返します
+// 削除した監査ログの内容は検証できないため、署名と前後のハッシュのつながりのみを確認します
+func (v *chainVerifier) checkTombstone(tombstone *model.AuditLogTombstone) bool {
+	if v.result.BrokenLink != nil {
+		return false
+	}
+
+	if !v.chain.VerifySignature(tombstone.SignedPayload(), tombstone.Signature) {
+		v.fail(&model.AuditChainBreak{ID: tombstone.ID, Reason: model.ChainBreakTombstoneSignature})
+		return false
+	}
+	if tombstone.PrevHash != v.prevHash {
+		v.fail(&model.AuditChainBreak{
+			ID:       tombstone.ID,
+			Reason:   model.ChainBreakPrevHash,
+			Expected: v.prevHash,
+			Actual:   tombstone.PrevHash,
+		})
+		return false
+	}
+
+	v.seenHashed = true
+	v.prevHash = tombstone.Hash
+	v.result.TombstoneCount++
+	return true
+}
+
+// check は監査ログを1件検証し、不整合がなければ true を返します
+func (v *chainVerifier) check(auditLog *model.AuditLog) bool {
+	if v.result.BrokenLink != nil {
+		return false
+	}
+
+	if auditLog.Hash == "" {
+		// 導入前の記録はチェーンの先頭にのみ存在する
+		if v.seenHashed {
+			v.fail(&model.AuditChainBreak{ID: auditLog.ID, Reason: model.ChainBreakMissingHash})
+			return false
+		}
+		v.result.LegacyCount++
+		v.prevHash = ""
+		return true
+	}
+
+	if auditLog.PrevHash != v.prevHash {
+		v.fail(&model.AuditChainBreak{
+			ID:       auditLog.ID,
+			Reason:   model.ChainBreakPrevHash,
+			Expected: v.prevHash,
+			Actual:   auditLog.PrevHash,
+		})
+		return false
+	}
+
+	payload, err := auditLog.ChainPayload()
+	if err != nil {
+		v.fail(&model.AuditChainBreak{ID: auditLog.ID, Reason: model.ChainBreakHash, Actual: auditLog.Hash})
+		return false
+	}
+	if expected := v.chain.Link(v.prevHash, payload); expected != auditLog.Hash {
+		if !v.checkRedaction(auditLog, expected) {
+			return false
+		}
+		v.result.RedactedCount++
+	}
+
+	v.seenHashed = true
+	v.prevHash = auditLog.Hash
+	v.result.CheckedCount++
+	v.result.HeadID = auditLog.ID
+	v.result.HeadHash = auditLog.Hash
+	return true
+}
+
+// checkRedaction は内容がハッシュに一致しない監査ログについて、署名付きの匿名化の記録で検証します
+// 匿名化後の内容のハッシュが一致する場合は true を返し、そうでない場合は不整合として記録します
+func (v *chainVerifier) checkRedaction(auditLog *model.AuditLog, contentHash string) bool {
+	if auditLog.RedactedHash == "" {
+		v.fail(&model.AuditChainBreak{
+			ID:       auditLog.ID,
+			Reason:   model.ChainBreakHash,
+			Expected: contentHash,
+			Actual:   auditLog.Hash,
+		})
+		return false
+	}
+	if !v.chain.VerifySignature(auditLog.RedactionPayload(), auditLog.RedactionSignature) {
+		v.fail(&model.AuditChainBreak{ID: auditLog.ID, Reason: model.ChainBreakRedactionSignature})
+		return false
+	}
+	if auditLog.RedactedHash != contentHash {
+		v.fail(&model.AuditChainBreak{
+			ID:       auditLog.ID,
+			Reason:   model.ChainBreakHash,
+			Expected: contentHash,
+			Actual:   auditLog.RedactedHash,
+		})
+		return false
+	}
+	return true
+}
+
+// fail は最初の不整合を記録します
+func (v *chainVerifier) fail(broken *model.AuditChainBreak) {
+	v.result.Valid = false
+	v.result.BrokenLink = broken
+}
@@ -0,0 +1,302 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// sealedLogs はハッシュチェーンで連結した監査ログを作成します
+func sealedLogs(t *testing.T, chain *util.HashChain, prevHash string, ids ...uint) []*model.AuditLog {
+	t.Helper()
+
+	created := time.Date(2024, 4, 1, 9, 0, 0, 123456000, time.UTC)
+	auditLogs := make([]*model.AuditLog, 0, len(ids))
+	for _, id := range ids {
+		auditLog := &model.AuditLog{
+			ID:           id,
+			UserID:       1,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   "taro",
+			Changes:      []byte(`{"before":{"status":"active"},"after":{"status":"inactive"}}`),
+			Status:       model.AuditStatusSuccess,
+			CreatedAt:    created.Add(time.Duration(id) * time.Second),
+			PrevHash:     prevHash,
+		}
+		payload, err := auditLog.ChainPayload()
+		require.NoError(t, err)
+		auditLog.Hash = chain.Link(prevHash, payload)
+		prevHash = auditLog.Hash
+		auditLogs = append(auditLogs, auditLog)
+	}
+	return auditLogs
+}
+
+// verify は監査ログを順に検証して結果を返します
+func verify(chain *util.HashChain, checkpoint *model.AuditLogCheckpoint, auditLogs []*model.AuditLog) *model.AuditChainVerification {
+	verifier := newChainVerifier(chain, checkpoint)
+	for _, auditLog := range auditLogs {
+		if !verifier.check(auditLog) {
+			break
+		}
+	}
+	return verifier.result
+}
+
+func TestChainVerifier(t *testing.T) {
+	chain := util.NewHashChain("test-key")
+
+	t.Run("Intact chain", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(3), result.CheckedCount)
+		assert.Equal(t, uint(3), result.HeadID)
+		assert.Equal(t, auditLogs[2].Hash, result.HeadHash)
+	})
+
+	t.Run("Edited row", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+		auditLogs[1].Status = model.AuditStatusFailed
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.False(t, result.Valid)
+		require.NotNil(t, result.BrokenLink)
+		assert.Equal(t, uint(2), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakHash, result.BrokenLink.Reason)
+	})
+
+	t.Run("Deleted row", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+
+		result := verify(chain, nil, []*model.AuditLog{auditLogs[0], auditLogs[2]})
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(3), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakPrevHash, result.BrokenLink.Reason)
+		assert.Equal(t, auditLogs[0].Hash, result.BrokenLink.Expected)
+	})
+
+	t.Run("Rehashed without the key", func(t *testing.T) {
+		auditLogs := sealedLogs(t, util.NewHashChain("attacker-key"), "", 1)
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, model.ChainBreakHash, result.BrokenLink.Reason)
+	})
+
+	t.Run("Legacy rows before the chain", func(t *testing.T) {
+		legacy := &model.AuditLog{ID: 1}
+		auditLogs := append([]*model.AuditLog{legacy}, sealedLogs(t, chain, "", 2, 3)...)
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(1), result.LegacyCount)
+		assert.Equal(t, int64(2), result.CheckedCount)
+	})
+
+	t.Run("Hash removed inside the chain", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+		auditLogs[1].Hash = ""
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(2), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakMissingHash, result.BrokenLink.Reason)
+	})
+}
+
+func TestChainVerifierWithCheckpoint(t *testing.T) {
+	chain := util.NewHashChain("test-key")
+	all := sealedLogs(t, chain, "", 1, 2, 3, 4)
+
+	checkpoint := &model.AuditLogCheckpoint{
+		LastDeletedID: 2,
+		LastHash:      all[1].Hash,
+		DeletedCount:  2,
+		DeletedBefore: time.Date(2024, 4, 1, 9, 0, 3, 0, time.UTC),
+	}
+	checkpoint.Signature = chain.Sign(checkpoint.SignedPayload())
+
+	t.Run("Chain resumes from the checkpoint", func(t *testing.T) {
+		result := verify(chain, checkpoint, all[2:])
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(2), result.CheckedCount)
+	})
+
+	t.Run("Rows deleted after the checkpoint", func(t *testing.T) {
+		result := verify(chain, checkpoint, all[3:])
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(4), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakPrevHash, result.BrokenLink.Reason)
+	})
+
+	t.Run("Forged checkpoint", func(t *testing.T) {
+		forged := *checkpoint
+		forged.LastDeletedID = 3
+		forged.LastHash = all[2].Hash
+
+		result := verify(chain, &forged, all[3:])
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(0), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakCheckpointSignature, result.BrokenLink.Reason)
+	})
+}
+
+// tombstoneFor は監査ログを削除した際の署名付き墓標を作成します
+func tombstoneFor(chain *util.HashChain, auditLog *model.AuditLog) *model.AuditLogTombstone {
+	tombstone := &model.AuditLogTombstone{
+		ID:       auditLog.ID,
+		PrevHash: auditLog.PrevHash,
+		Hash:     auditLog.Hash,
+		PurgedAt: time.Date(2026, 10, 18, 3, 0, 0, 0, time.UTC),
+	}
+	tombstone.Signature = chain.Sign(tombstone.SignedPayload())
+	return tombstone
+}
+
+func TestChainVerifierWithTombstones(t *testing.T) {
+	chain := util.NewHashChain("test-key")
+	all := sealedLogs(t, chain, "", 1, 2, 3, 4)
+
+	verifyEntries := func(entries []chainEntry) *model.AuditChainVerification {
+		verifier := newChainVerifier(chain, nil)
+		for _, entry := range entries {
+			if !verifier.checkEntry(entry) {
+				break
+			}
+		}
+		return verifier.result
+	}
+
+	t.Run("Purged rows are bridged by tombstones", func(t *testing.T) {
+		entries := mergeChainEntries(
+			[]*model.AuditLog{all[0], all[3]},
+			[]*model.AuditLogTombstone{tombstoneFor(chain, all[1]), tombstoneFor(chain, all[2])},
+		)
+
+		result := verifyEntries(entries)
+
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(2), result.CheckedCount)
+		assert.Equal(t, int64(2), result.TombstoneCount)
+		assert.Equal(t, uint(4), result.HeadID)
+	})
+
+	t.Run("Forged tombstone", func(t *testing.T) {
+		forged := tombstoneFor(chain, all[1])
+		forged.Hash = all[2].Hash
+
+		result := verifyEntries(mergeChainEntries([]*model.AuditLog{all[0], all[3]}, []*model.AuditLogTombstone{forged}))
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(2), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakTombstoneSignature, result.BrokenLink.Reason)
+	})
+
+	t.Run("Row deleted without a tombstone", func(t *testing.T) {
+		entries := mergeChainEntries([]*model.AuditLog{all[0], all[3]}, []*model.AuditLogTombstone{tombstoneFor(chain, all[1])})
+
+		result := verifyEntries(entries)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(4), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakPrevHash, result.BrokenLink.Reason)
+	})
+}
+
+// redact は監査ログの個人情報を匿名化し、署名付きの匿名化の記録を残します
+func redact(t *testing.T, repo *AuditLogRepository, auditLog *model.AuditLog) {
+	t.Helper()
+
+	auditLog.ResourceID = "anonymized-7"
+	auditLog.IPAddress = ""
+	require.NoError(t, repo.sealRedaction(auditLog))
+}
+
+func TestChainVerifierWithRedactions(t *testing.T) {
+	chain := util.NewHashChain("test-key")
+	repo := NewAuditLogRepository(nil, chain)
+
+	t.Run("Redacted row keeps the chain intact", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+		original := auditLogs[1].Hash
+		redact(t, repo, auditLogs[1])
+
+		result := verify(chain, nil, auditLogs)
+
+		// 後続の監査ログのハッシュは再計算しない
+		assert.Equal(t, original, auditLogs[1].Hash)
+		assert.True(t, result.Valid)
+		assert.Equal(t, int64(3), result.CheckedCount)
+		assert.Equal(t, int64(1), result.RedactedCount)
+		assert.True(t, repo.VerifyHash(auditLogs[1]))
+	})
+
+	t.Run("Edited after redaction", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+		redact(t, repo, auditLogs[1])
+		auditLogs[1].Status = model.AuditStatusFailed
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(2), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakHash, result.BrokenLink.Reason)
+		assert.False(t, repo.VerifyHash(auditLogs[1]))
+	})
+
+	t.Run("Redaction forged without the key", func(t *testing.T) {
+		auditLogs := sealedLogs(t, chain, "", 1, 2, 3)
+		redact(t, NewAuditLogRepository(nil, util.NewHashChain("attacker-key")), auditLogs[1])
+
+		result := verify(chain, nil, auditLogs)
+
+		assert.False(t, result.Valid)
+		assert.Equal(t, uint(2), result.BrokenLink.ID)
+		assert.Equal(t, model.ChainBreakRedactionSignature, result.BrokenLink.Reason)
+		assert.False(t, repo.VerifyHash(auditLogs[1]))
+	})
+}
+
+func TestMergeChainEntries(t *testing.T) {
+	entries := mergeChainEntries(
+		[]*model.AuditLog{{ID: 1}, {ID: 4}, {ID: 5}},
+		[]*model.AuditLogTombstone{{ID: 2}, {ID: 3}, {ID: 6}},
+	)
+
+	ids := make([]uint, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.id()
+	}
+	assert.Equal(t, []uint{1, 2, 3, 4, 5, 6}, ids)
+	assert.NotNil(t, entries[1].tombstone)
+	assert.NotNil(t, entries[3].log)
+}
+
+func TestAuditLogChainPayloadIgnoresJSONFormatting(t *testing.T) {
+	a := &model.AuditLog{ID: 1, Changes: []byte(`{"before":{"a":1,"b":"x"},"after":{}}`)}
+	b := &model.AuditLog{ID: 1, Changes: []byte(`{"after": {}, "before": {"b": "x", "a": 1}}`)}
+
+	payloadA, err := a.ChainPayload()
+	require.NoError(t, err)
+	payloadB, err := b.ChainPayload()
+	require.NoError(t, err)
+
+	assert.Equal(t, payloadA, payloadB)
+}
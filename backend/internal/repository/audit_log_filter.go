@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// applyAuditLogFilter は検索条件をクエリに追加します
+// 各条件は検索用のインデックス（000014_add_audit_log_search_indexes）を使える形で組み立てます
+func applyAuditLogFilter(query *gorm.DB, filter *model.AuditLogFilter) (*gorm.DB, error) {
+	if filter == nil {
+		return query, nil
+	}
+
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+	if len(filter.Actions) == 1 {
+		query = query.Where("action = ?", filter.Actions[0])
+	} else if len(filter.Actions) > 1 {
+		query = query.Where("action IN ?", filter.Actions)
+	}
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		query = query.Where("resource_id = ?", filter.ResourceID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.IPAddress != "" {
+		if strings.Contains(filter.IPAddress, "/") {
+			// 記録のないIPアドレス（空文字）は inet に変換できないため除外する
+			query = query.Where("NULLIF(ip_address, '')::inet <<= ?::inet", filter.IPAddress)
+		} else {
+			query = query.Where("ip_address = ?", filter.IPAddress)
+		}
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+	if filter.Query != "" {
+		query = query.Where(`error_message ILIKE ? ESCAPE '\'`, "%"+escapeLike(filter.Query)+"%")
+	}
+
+	// 同じパスに異なる値を指定した場合も正しく判定できるよう、条件ごとに包含演算子で比較する
+	for _, match := range filter.ChangeMatches {
+		doc, err := json.Marshal(match.Containment())
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where("changes @> ?::jsonb", string(doc))
+	}
+	for _, field := range filter.ChangedFields {
+		query = query.Where("(changes->'before'->?) IS DISTINCT FROM (changes->'after'->?)", field, field)
+	}
+
+	return query, nil
+}
+
+// escapeLike はLIKEのパターンで特別な意味を持つ文字をエスケープします
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestParseAuditChangeMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		expr        string
+		containment string
+	}{
+		{"String value", "after.role:admin", `{"after":{"role":"admin"}}`},
+		{"Nested path", "after.attributes.employee_no:E-001", `{"after":{"attributes":{"employee_no":"E-001"}}}`},
+		{"Number value", "before.id:42", `{"before":{"id":42}}`},
+		{"Boolean value", "after.active:false", `{"after":{"active":false}}`},
+		{"Quoted number as string", `after.employee_no:"42"`, `{"after":{"employee_no":"42"}}`},
+		{"Value containing colon", "after.note:a:b", `{"after":{"note":"a:b"}}`},
+		{"Empty value", "after.department:", `{"after":{"department":""}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			match, err := model.ParseAuditChangeMatch(tt.expr)
+			require.NoError(t, err)
+
+			doc, err := json.Marshal(match.Containment())
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.containment, string(doc))
+		})
+	}
+}
+
+func TestParseAuditChangeMatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"Missing value", "after.role"},
+		{"Missing key", "after:admin"},
+		{"Unknown root", "changes.role:admin"},
+		{"Invalid key", "after.ro'le:admin"},
+		{"Empty key", "after..role:admin"},
+		{"Object value", `after.role:{"a":1}`},
+		{"Array value", `after.role:["admin"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := model.ParseAuditChangeMatch(tt.expr)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestValidateAuditChangedField(t *testing.T) {
+	assert.NoError(t, model.ValidateAuditChangedField("role"))
+	assert.NoError(t, model.ValidateAuditChangedField("account_expires_at"))
+	assert.Error(t, model.ValidateAuditChangedField(""))
+	assert.Error(t, model.ValidateAuditChangedField("role'--"))
+	assert.Error(t, model.ValidateAuditChangedField("after.role"))
+}
+
+func TestEscapeLike(t *testing.T) {
+	assert.Equal(t, "invalid credentials", escapeLike("invalid credentials"))
+	assert.Equal(t, `100\%`, escapeLike("100%"))
+	assert.Equal(t, `user\_not\_found`, escapeLike("user_not_found"))
+	assert.Equal(t, `C:\\path`, escapeLike(`C:\path`))
+}
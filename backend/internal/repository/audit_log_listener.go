@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// AuditLogNotifyChannel は監査ログの追加を通知するチャネル名です（000015 のトリガーで送信）
+const AuditLogNotifyChannel = "audit_log_created"
+
+// AuditLogListener は監査ログの追加をPostgreSQLの LISTEN/NOTIFY で受け取ります
+// 通知はデータベースのトリガーが送るため、どのレプリカで書き込んだ監査ログも受け取れます
+type AuditLogListener struct {
+	listener *pq.Listener
+	logger   *zap.Logger
+}
+
+// NewAuditLogListener は新しいAuditLogListenerを作成し、通知の受信を開始します
+// 接続が切れた場合は自動的に再接続します
+func NewAuditLogListener(dsn string, logger *zap.Logger) (*AuditLogListener, error) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logger.Warn("Audit log listener connection error", zap.Error(err))
+		}
+	})
+	if err := listener.Listen(AuditLogNotifyChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	return &AuditLogListener{
+		listener: listener,
+		logger:   logger,
+	}, nil
+}
+
+// Run はコンテキストがキャンセルされるまで、通知を受け取るたびに notify を呼び出します
+// 再接続した場合は切断中の通知を取りこぼしている可能性があるため、その場合も notify を呼び出します
+func (l *AuditLogListener) Run(ctx context.Context, notify func()) {
+	ping := time.NewTicker(time.Minute)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.listener.Notify:
+			notify()
+		case <-ping.C:
+			// 通知がない間も接続が生きているか確認する（切れていれば再接続される）
+			if err := l.listener.Ping(); err != nil {
+				l.logger.Warn("Audit log listener ping failed", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Close は通知の受信を終了します
+func (l *AuditLogListener) Close() error {
+	return l.listener.Close()
+}
@@ -0,0 +1,289 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+const (
+	// auditPartitionPrefix は月ごとのパーティションのテーブル名の接頭辞です（audit_logs_pYYYYMM）
+	auditPartitionPrefix = "audit_logs_p"
+	// auditDefaultPartition はどのパーティションの範囲にも含まれない監査ログを受け止めるパーティションです
+	auditDefaultPartition = "audit_logs_default"
+)
+
+var (
+	// ErrPartitionNotFound は指定したパーティションが存在しないことを表します
+	ErrPartitionNotFound = errors.New("audit log partition not found")
+	// ErrPartitionHeld はパーティションに訴訟ホールドの対象の監査ログが含まれることを表します
+	ErrPartitionHeld = errors.New("audit log partition contains logs under legal hold")
+)
+
+// auditPartitionName は月のパーティションのテーブル名を返します
+func auditPartitionName(month time.Time) string {
+	return auditPartitionPrefix + model.AuditPartitionMonth(month).Format("200601")
+}
+
+// parseAuditPartitionName はテーブル名から月のパーティションの範囲を返します
+// 月ごとのパーティションの名前でない場合は ok が false になります
+func parseAuditPartitionName(name string) (from, to time.Time, ok bool) {
+	suffix := strings.TrimPrefix(name, auditPartitionPrefix)
+	if suffix == name || len(suffix) != len("200601") {
+		return time.Time{}, time.Time{}, false
+	}
+	from, err := time.Parse("200601", suffix)
+	if err != nil {
+		return time.Time{}, time.Time{}, false
+	}
+	return from, from.AddDate(0, 1, 0), true
+}
+
+// matchHolds は訴訟ホールドのいずれかの対象となる監査ログの条件を返します（ホールドがない場合は空）
+// 範囲を限定しないホールドがある場合は、すべての監査ログが対象となるため "TRUE" を返します
+func matchHolds(holds []*model.AuditHoldScope) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, hold := range holds {
+		cond, condArgs := auditHoldMatch(hold)
+		if cond == "" {
+			return "TRUE", nil
+		}
+		conditions = append(conditions, "("+cond+")")
+		args = append(args, condArgs...)
+	}
+	return strings.Join(conditions, " OR "), args
+}
+
+// auditPartitionRow はパーティションの一覧を取得するためのクエリ結果です
+type auditPartitionRow struct {
+	Name          string
+	EstimatedRows int64
+	TotalBytes    int64
+}
+
+// ListPartitions は監査ログのパーティションを古い順に取得します（デフォルトパーティションは最後）
+func (r *AuditLogRepository) ListPartitions(ctx context.Context) ([]*model.AuditLogPartition, error) {
+	var rows []auditPartitionRow
+	if err := dbWithContext(ctx, r.db).Raw(`
+		SELECT c.relname AS name,
+		       GREATEST(c.reltuples, 0)::bigint AS estimated_rows,
+		       pg_total_relation_size(c.oid) AS total_bytes
+		  FROM pg_inherits i
+		  JOIN pg_class c ON c.oid = i.inhrelid
+		 WHERE i.inhparent = 'audit_logs'::regclass
+		 ORDER BY c.relname`).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	partitions := make([]*model.AuditLogPartition, 0, len(rows))
+	var defaultPartition *model.AuditLogPartition
+	for _, row := range rows {
+		partition := &model.AuditLogPartition{
+			Name:          row.Name,
+			EstimatedRows: row.EstimatedRows,
+			TotalBytes:    row.TotalBytes,
+		}
+		if row.Name == auditDefaultPartition {
+			partition.Default = true
+			defaultPartition = partition
+			continue
+		}
+		if from, to, ok := parseAuditPartitionName(row.Name); ok {
+			partition.From = &from
+			partition.To = &to
+		}
+		partitions = append(partitions, partition)
+	}
+	if defaultPartition != nil {
+		partitions = append(partitions, defaultPartition)
+	}
+	return partitions, nil
+}
+
+// CreatePartition は month を含む月のパーティションを作成し、作成したかどうかを返します（既にある場合は false）
+// デフォルトパーティションにその月の監査ログがある場合は、新しいパーティションに移してから接続します
+func (r *AuditLogRepository) CreatePartition(ctx context.Context, month time.Time) (bool, error) {
+	from := model.AuditPartitionMonth(month)
+	to := from.AddDate(0, 1, 0)
+	name := pq.QuoteIdentifier(auditPartitionName(from))
+	bounds := fmt.Sprintf("FOR VALUES FROM ('%s') TO ('%s')", from.Format("2006-01-02"), to.Format("2006-01-02"))
+
+	created := false
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var exists bool
+		if err := tx.Raw("SELECT to_regclass(?) IS NOT NULL", auditPartitionName(from)).Scan(&exists).Error; err != nil {
+			return err
+		}
+		if exists {
+			return nil
+		}
+
+		var stray bool
+		if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM "+auditDefaultPartition+" WHERE created_at >= ? AND created_at < ?)", from, to).
+			Scan(&stray).Error; err != nil {
+			return err
+		}
+
+		if !stray {
+			if err := tx.Exec("CREATE TABLE " + name + " PARTITION OF audit_logs " + bounds).Error; err != nil {
+				return err
+			}
+			created = true
+			return nil
+		}
+
+		// デフォルトパーティションに範囲が重なる監査ログがあると PARTITION OF で作成できないため、移してから接続する
+		// 移す間に同じ月の監査ログが書き込まれないよう、チェーンのロックを取得する
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+		statements := []struct {
+			sql  string
+			args []interface{}
+		}{
+			{"CREATE TABLE " + name + " (LIKE audit_logs INCLUDING DEFAULTS INCLUDING CONSTRAINTS)", nil},
+			{"INSERT INTO " + name + " SELECT * FROM " + auditDefaultPartition + " WHERE created_at >= ? AND created_at < ?", []interface{}{from, to}},
+			{"DELETE FROM " + auditDefaultPartition + " WHERE created_at >= ? AND created_at < ?", []interface{}{from, to}},
+			{"ALTER TABLE audit_logs ATTACH PARTITION " + name + " " + bounds, nil},
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement.sql, statement.args...).Error; err != nil {
+				return err
+			}
+		}
+		created = true
+		return nil
+	})
+	return created, err
+}
+
+// DetachPartition はパーティションを監査ログのテーブルから切り離し、切り離した監査ログの件数を返します
+// drop の場合は切り離したテーブルを削除します
+// 残った監査ログのハッシュチェーンを検証できるよう、残った最も古い監査ログより前の監査ログは
+// 署名付きのチェックポイントに、それ以降の監査ログは墓標に置き換えます
+// 訴訟ホールドの対象の監査ログを含むパーティションは切り離さずに ErrPartitionHeld を返します
+func (r *AuditLogRepository) DetachPartition(ctx context.Context, partitionName string, holds []*model.AuditHoldScope, drop bool) (int64, error) {
+	from, to, ok := parseAuditPartitionName(partitionName)
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrPartitionNotFound, partitionName)
+	}
+	name := pq.QuoteIdentifier(partitionName)
+
+	var detached int64
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+
+		var exists bool
+		if err := tx.Raw(`
+			SELECT EXISTS (
+				SELECT 1 FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+				 WHERE i.inhparent = 'audit_logs'::regclass AND c.relname = ?
+			)`, partitionName).Scan(&exists).Error; err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%w: %s", ErrPartitionNotFound, partitionName)
+		}
+
+		if cond, args := matchHolds(holds); cond != "" {
+			var held bool
+			if err := tx.Raw("SELECT EXISTS (SELECT 1 FROM "+name+" WHERE "+cond+")", args...).Scan(&held).Error; err != nil {
+				return err
+			}
+			if held {
+				return fmt.Errorf("%w: %s", ErrPartitionHeld, partitionName)
+			}
+		}
+
+		if err := tx.Table(name).Count(&detached).Error; err != nil {
+			return err
+		}
+		if detached > 0 {
+			if err := r.sealPartition(tx, from, to, detached); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Exec("ALTER TABLE audit_logs DETACH PARTITION " + name).Error; err != nil {
+			return err
+		}
+		if drop {
+			return tx.Exec("DROP TABLE " + name).Error
+		}
+		return nil
+	})
+	return detached, err
+}
+
+// sealPartition は切り離すパーティションの監査ログをハッシュチェーンから外します（lockChain を取得したトランザクションで呼び出してください）
+// 残った監査ログで最も小さいIDより前の監査ログはまとめてチェックポイントにし、
+// それより後の監査ログ（より古いパーティションやデフォルトパーティションに監査ログが残っている場合）は墓標にします
+func (r *AuditLogRepository) sealPartition(tx *gorm.DB, from, to time.Time, count int64) error {
+	inPartition := "created_at >= ? AND created_at < ?"
+
+	// 残った監査ログがない場合は0
+	var keptID uint
+	if err := tx.Model(&model.AuditLog{}).
+		Select("COALESCE(MIN(id), 0)").
+		Where("NOT ("+inPartition+")", from, to).
+		Scan(&keptID).Error; err != nil {
+		return err
+	}
+
+	if keptID > 0 {
+		var later []*model.AuditLog
+		if err := tx.Select("id", "prev_hash", "hash").
+			Where(inPartition+" AND id > ?", from, to, keptID).
+			Order("id ASC").
+			Find(&later).Error; err != nil {
+			return err
+		}
+		if err := r.createTombstones(tx, later); err != nil {
+			return err
+		}
+	}
+
+	var last model.AuditLog
+	lastQuery := tx.Select("id", "hash").Where(inPartition, from, to)
+	if keptID > 0 {
+		lastQuery = lastQuery.Where("id < ?", keptID)
+	}
+	err := lastQuery.Order("id DESC").Take(&last).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	latest, err := findLatestCheckpoint(tx)
+	if err != nil {
+		return err
+	}
+	if latest != nil && latest.LastDeletedID >= last.ID {
+		return nil
+	}
+
+	// チェックポイント以前の墓標は検証に使わなくなるため削除する
+	if err := tx.Where("id <= ?", last.ID).Delete(&model.AuditLogTombstone{}).Error; err != nil {
+		return err
+	}
+	checkpoint := &model.AuditLogCheckpoint{
+		LastDeletedID: last.ID,
+		LastHash:      last.Hash,
+		DeletedCount:  count,
+		DeletedBefore: to,
+	}
+	checkpoint.Signature = r.chain.Sign(checkpoint.SignedPayload())
+	return tx.Create(checkpoint).Error
+}
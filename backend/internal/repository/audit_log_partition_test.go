@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestAuditPartitionName(t *testing.T) {
+	tests := []struct {
+		name     string
+		month    time.Time
+		expected string
+	}{
+		{"First day of month", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), "audit_logs_p202501"},
+		{"Last moment of month", time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), "audit_logs_p202512"},
+		{"Converted to UTC", time.Date(2025, 3, 1, 5, 0, 0, 0, time.FixedZone("JST", 9*60*60)), "audit_logs_p202502"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, auditPartitionName(tt.month))
+		})
+	}
+}
+
+func TestParseAuditPartitionName(t *testing.T) {
+	tests := []struct {
+		name         string
+		partition    string
+		expectedFrom time.Time
+		expectedTo   time.Time
+		expectedOK   bool
+	}{
+		{"Monthly partition", "audit_logs_p202510", time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), true},
+		{"Year boundary", "audit_logs_p202512", time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), true},
+		{"Default partition", "audit_logs_default", time.Time{}, time.Time{}, false},
+		{"Invalid month", "audit_logs_p202513", time.Time{}, time.Time{}, false},
+		{"Other table", "users_p202510", time.Time{}, time.Time{}, false},
+		{"Extra suffix", "audit_logs_p20251001", time.Time{}, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, ok := parseAuditPartitionName(tt.partition)
+
+			assert.Equal(t, tt.expectedOK, ok)
+			assert.Equal(t, tt.expectedFrom, from)
+			assert.Equal(t, tt.expectedTo, to)
+		})
+	}
+}
+
+func TestMatchHolds(t *testing.T) {
+	userID := uint(7)
+
+	t.Run("Without holds", func(t *testing.T) {
+		cond, args := matchHolds(nil)
+
+		assert.Empty(t, cond)
+		assert.Empty(t, args)
+	})
+
+	t.Run("Any hold matches", func(t *testing.T) {
+		cond, args := matchHolds([]*model.AuditHoldScope{
+			{UserID: &userID},
+			{ResourceType: model.ResourceTypeRole, ResourceID: "admin"},
+		})
+
+		assert.Equal(t, "(user_id = ?) OR (resource_type = ? AND resource_id = ?)", cond)
+		assert.Equal(t, []interface{}{userID, model.ResourceTypeRole, "admin"}, args)
+	})
+
+	t.Run("Unscoped hold matches everything", func(t *testing.T) {
+		cond, args := matchHolds([]*model.AuditHoldScope{{UserID: &userID}, {}})
+
+		assert.Equal(t, "TRUE", cond)
+		assert.Empty(t, args)
+	})
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// CountByBucket は集計単位と内訳ごとに監査ログの件数を集計します
+// アクションとステータスは全体の内訳に使うため、指定の有無にかかわらず常に集計します
+// created_at はUTCで保存しているため、指定したタイムゾーンの壁時計の時刻に変換してから切り捨てます
+func (r *AuditLogRepository) CountByBucket(ctx context.Context, q *model.AuditStatisticsQuery) ([]*model.AuditLogBucketCount, error) {
+	columns := []string{"action", "status"}
+	for _, dimension := range q.GroupBy {
+		column, err := model.AuditDimensionColumn(dimension)
+		if err != nil {
+			return nil, err
+		}
+		if column != "action" && column != "status" {
+			columns = append(columns, column)
+		}
+	}
+
+	query, err := applyAuditLogFilter(dbWithContext(ctx, r.db).Model(&model.AuditLog{}), q.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	selectColumns := "date_trunc(?, (created_at AT TIME ZONE 'UTC') AT TIME ZONE ?) AS bucket, COUNT(*) AS count"
+	for _, column := range columns {
+		selectColumns += ", " + column
+	}
+	// プレースホルダを含む式は GROUP BY で同じ式と判定されないため、別名で指定する
+	query = query.Select(selectColumns, q.Bucket, q.Location.String()).Group("bucket")
+	for _, column := range columns {
+		query = query.Group(column)
+	}
+
+	var results []*model.AuditLogBucketCount
+	if err := query.Order("bucket").Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// TopFailedActors は条件に一致する失敗した操作を、実行したユーザーごとに件数の多い順に取得します
+func (r *AuditLogRepository) TopFailedActors(ctx context.Context, filter *model.AuditLogFilter, limit int) ([]*model.AuditLogActorCount, error) {
+	query, err := applyAuditLogFilter(dbWithContext(ctx, r.db).Model(&model.AuditLog{}), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*model.AuditLogActorCount
+	if err := query.
+		Select("user_id, COUNT(*) AS count").
+		Where("status = ?", model.AuditStatusFailed).
+		Group("user_id").
+		Order("count DESC").
+		Order("user_id").
+		Limit(limit).
+		Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	// 削除済みのユーザーも含めてユーザー名を付ける（監査ログの検索条件と混ざらないよう別に取得する）
+	userIDs := make([]uint, len(results))
+	for i, result := range results {
+		userIDs[i] = result.UserID
+	}
+	var users []*model.User
+	if err := dbWithContext(ctx, r.db).Unscoped().Select("id", "username").Where("id IN ?", userIDs).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	usernames := make(map[uint]string, len(users))
+	for _, user := range users {
+		usernames[user.ID] = user.Username
+	}
+	for _, result := range results {
+		result.Username = usernames[result.UserID]
+	}
+	return results, nil
+}
+
+// TopFailedIPs は条件に一致する失敗した操作を、IPアドレスごとに件数の多い順に取得します
+func (r *AuditLogRepository) TopFailedIPs(ctx context.Context, filter *model.AuditLogFilter, limit int) ([]*model.AuditLogIPCount, error) {
+	query, err := applyAuditLogFilter(dbWithContext(ctx, r.db).Model(&model.AuditLog{}), filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []*model.AuditLogIPCount
+	err = query.
+		Select("ip_address, COUNT(*) AS count").
+		Where("status = ?", model.AuditStatusFailed).
+		Where("ip_address <> ''").
+		Group("ip_address").
+		Order("count DESC").
+		Order("ip_address").
+		Limit(limit).
+		Scan(&results).Error
+	return results, err
+}
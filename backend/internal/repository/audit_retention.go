@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// retentionCondition は保持期間ポリシーで削除する監査ログの条件を組み立てます
+// policy に一致して保持期間を過ぎた監査ログのうち、より長い保持期間のポリシーで保持すべきものと
+// 訴訟ホールドの対象を除きます
+func retentionCondition(policy *model.AuditRetentionPolicy, policies []*model.AuditRetentionPolicy, holds []*model.AuditHoldScope, now time.Time) (string, []interface{}) {
+	conditions := []string{"created_at < ?"}
+	args := []interface{}{policy.Cutoff(now)}
+
+	if cond, condArgs := retentionPolicyMatch(policy); cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, condArgs...)
+	}
+
+	for _, other := range policies {
+		if other.ID == policy.ID || other.RetentionDays <= policy.RetentionDays || !policy.Overlaps(other) {
+			continue
+		}
+		cond, condArgs := retentionPolicyMatch(other)
+		if cond == "" {
+			conditions = append(conditions, "NOT (created_at >= ?)")
+		} else {
+			conditions = append(conditions, "NOT ("+cond+" AND created_at >= ?)")
+			args = append(args, condArgs...)
+		}
+		args = append(args, other.Cutoff(now))
+	}
+
+	if cond, holdArgs := excludeHolds(holds); cond == "FALSE" {
+		return cond, nil
+	} else if cond != "" {
+		conditions = append(conditions, cond)
+		args = append(args, holdArgs...)
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// excludeHolds は訴訟ホールドの対象を除く条件を返します（ホールドがない場合は空）
+// 範囲を限定しないホールドがある場合は、すべての監査ログを保持するため "FALSE" を返します
+func excludeHolds(holds []*model.AuditHoldScope) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	for _, hold := range holds {
+		cond, condArgs := auditHoldMatch(hold)
+		if cond == "" {
+			return "FALSE", nil
+		}
+		conditions = append(conditions, "NOT ("+cond+")")
+		args = append(args, condArgs...)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// retentionPolicyMatch はポリシーの対象となる監査ログの条件を返します（すべてに一致する場合は空）
+func retentionPolicyMatch(policy *model.AuditRetentionPolicy) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if policy.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, policy.Action)
+	}
+	if policy.ResourceType != "" {
+		conditions = append(conditions, "resource_type = ?")
+		args = append(args, policy.ResourceType)
+	}
+	if policy.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, policy.Status)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// auditHoldMatch は訴訟ホールドの対象となる監査ログの条件を返します（すべてに一致する場合は空）
+func auditHoldMatch(hold *model.AuditHoldScope) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if hold.UserID != nil {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, *hold.UserID)
+	}
+	if hold.ResourceType != "" {
+		conditions = append(conditions, "resource_type = ?")
+		args = append(args, hold.ResourceType)
+	}
+	if hold.ResourceID != "" {
+		conditions = append(conditions, "resource_id = ?")
+		args = append(args, hold.ResourceID)
+	}
+	if hold.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *hold.From)
+	}
+	if hold.To != nil {
+		conditions = append(conditions, "created_at <= ?")
+		args = append(args, *hold.To)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// CountExpired は保持期間ポリシーで削除される監査ログの件数を返します
+func (r *AuditLogRepository) CountExpired(ctx context.Context, policy *model.AuditRetentionPolicy, policies []*model.AuditRetentionPolicy, holds []*model.AuditHoldScope, now time.Time) (int64, error) {
+	cond, args := retentionCondition(policy, policies, holds, now)
+
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.AuditLog{}).Where(cond, args...).Count(&count).Error
+	return count, err
+}
+
+// DeleteExpired は保持期間ポリシーで削除する監査ログを古い順に最大 limit 件削除し、削除した件数を返します
+// 残った監査ログのハッシュチェーンを検証できるよう、削除した監査ログのハッシュを署名付きの墓標として残します
+// チェーンのロックは削除の間だけ保持するため、limit を小さくすると監査ログの書き込みを待たせる時間が短くなります
+func (r *AuditLogRepository) DeleteExpired(ctx context.Context, policy *model.AuditRetentionPolicy, policies []*model.AuditRetentionPolicy, holds []*model.AuditHoldScope, now time.Time, limit int) (int64, error) {
+	cond, args := retentionCondition(policy, policies, holds, now)
+
+	var deleted int64
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := lockChain(tx); err != nil {
+			return err
+		}
+
+		var expired []*model.AuditLog
+		if err := tx.Select("id", "prev_hash", "hash", "created_at").
+			Where(cond, args...).
+			Order("id ASC").
+			Limit(limit).
+			Find(&expired).Error; err != nil {
+			return err
+		}
+		if len(expired) == 0 {
+			return nil
+		}
+
+		purged, err := r.purge(tx, expired)
+		deleted = purged
+		return err
+	})
+	return deleted, err
+}
+
+// purge は監査ログを削除し、ハッシュチェーンに含まれる監査ログを署名付きの墓標に置き換えます
+// 作成日時の範囲で対象のパーティションに絞り込むため、auditLogs には作成日時を含めてください
+// 呼び出し元でチェーンのロックを取得してください
+func (r *AuditLogRepository) purge(tx *gorm.DB, auditLogs []*model.AuditLog) (int64, error) {
+	ids := make([]uint, len(auditLogs))
+	from, to := auditLogs[0].CreatedAt, auditLogs[0].CreatedAt
+	for i, auditLog := range auditLogs {
+		ids[i] = auditLog.ID
+		if auditLog.CreatedAt.Before(from) {
+			from = auditLog.CreatedAt
+		}
+		if auditLog.CreatedAt.After(to) {
+			to = auditLog.CreatedAt
+		}
+	}
+
+	if err := r.createTombstones(tx, auditLogs); err != nil {
+		return 0, err
+	}
+	result := tx.Where("id IN ? AND created_at BETWEEN ? AND ?", ids, from, to).Delete(&model.AuditLog{})
+	return result.RowsAffected, result.Error
+}
+
+// createTombstones はハッシュチェーンに含まれる監査ログを署名付きの墓標として記録します
+func (r *AuditLogRepository) createTombstones(tx *gorm.DB, auditLogs []*model.AuditLog) error {
+	tombstones := make([]*model.AuditLogTombstone, 0, len(auditLogs))
+	purgedAt := time.Now().UTC().Truncate(time.Microsecond)
+	for _, auditLog := range auditLogs {
+		if auditLog.Hash == "" {
+			// 導入前の記録はチェーンに含まれない
+			continue
+		}
+		tombstone := &model.AuditLogTombstone{
+			ID:       auditLog.ID,
+			PrevHash: auditLog.PrevHash,
+			Hash:     auditLog.Hash,
+			PurgedAt: purgedAt,
+		}
+		tombstone.Signature = r.chain.Sign(tombstone.SignedPayload())
+		tombstones = append(tombstones, tombstone)
+	}
+
+	if len(tombstones) == 0 {
+		return nil
+	}
+	return tx.CreateInBatches(&tombstones, chainBatchSize).Error
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// AuditRetentionPolicyRepository は監査ログの保持期間ポリシーのデータアクセスを提供します
+type AuditRetentionPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditRetentionPolicyRepository は新しいAuditRetentionPolicyRepositoryを作成します
+func NewAuditRetentionPolicyRepository(db *gorm.DB) *AuditRetentionPolicyRepository {
+	return &AuditRetentionPolicyRepository{
+		db: db,
+	}
+}
+
+// FindAll はポリシーをすべて取得します
+func (r *AuditRetentionPolicyRepository) FindAll(ctx context.Context) ([]*model.AuditRetentionPolicy, error) {
+	var policies []*model.AuditRetentionPolicy
+	err := dbWithContext(ctx, r.db).Order("id ASC").Find(&policies).Error
+	return policies, err
+}
+
+// FindEnabled は有効なポリシーをすべて取得します
+func (r *AuditRetentionPolicyRepository) FindEnabled(ctx context.Context) ([]*model.AuditRetentionPolicy, error) {
+	var policies []*model.AuditRetentionPolicy
+	err := dbWithContext(ctx, r.db).Where("enabled = ?", true).Order("id ASC").Find(&policies).Error
+	return policies, err
+}
+
+// FindByID はIDでポリシーを取得します
+func (r *AuditRetentionPolicyRepository) FindByID(ctx context.Context, id uint) (*model.AuditRetentionPolicy, error) {
+	var policy model.AuditRetentionPolicy
+	if err := dbWithContext(ctx, r.db).First(&policy, id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// ExistsByName は同じ名前のポリシーが存在するかを返します（excludeID のポリシーは除く）
+func (r *AuditRetentionPolicyRepository) ExistsByName(ctx context.Context, name string, excludeID uint) (bool, error) {
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.AuditRetentionPolicy{}).
+		Where("name = ? AND id <> ?", name, excludeID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create はポリシーを作成します
+func (r *AuditRetentionPolicyRepository) Create(ctx context.Context, policy *model.AuditRetentionPolicy) error {
+	return dbWithContext(ctx, r.db).Create(policy).Error
+}
+
+// Update はポリシーを更新します
+func (r *AuditRetentionPolicyRepository) Update(ctx context.Context, policy *model.AuditRetentionPolicy) error {
+	return dbWithContext(ctx, r.db).Save(policy).Error
+}
+
+// Delete はポリシーを削除します
+func (r *AuditRetentionPolicyRepository) Delete(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Delete(&model.AuditRetentionPolicy{}, id).Error
+}
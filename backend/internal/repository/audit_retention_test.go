@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestRetentionCondition(t *testing.T) {
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	logins := &model.AuditRetentionPolicy{ID: 1, Action: model.ActionLogin, RetentionDays: 90}
+	failedLogins := &model.AuditRetentionPolicy{ID: 2, Action: model.ActionLogin, Status: model.AuditStatusFailed, RetentionDays: 365}
+	roles := &model.AuditRetentionPolicy{ID: 3, Action: model.ActionUpdate, ResourceType: model.ResourceTypeRole, RetentionDays: 2555}
+	everything := &model.AuditRetentionPolicy{ID: 4, RetentionDays: 730}
+	policies := []*model.AuditRetentionPolicy{logins, failedLogins, roles, everything}
+
+	t.Run("Longer overlapping policies are excluded", func(t *testing.T) {
+		cond, args := retentionCondition(logins, policies, nil, now)
+
+		assert.Equal(t, "created_at < ? AND action = ? AND NOT (action = ? AND status = ? AND created_at >= ?) AND NOT (created_at >= ?)", cond)
+		assert.Equal(t, []interface{}{
+			now.AddDate(0, 0, -90), model.ActionLogin,
+			model.ActionLogin, model.AuditStatusFailed, now.AddDate(0, 0, -365),
+			now.AddDate(0, 0, -730),
+		}, args)
+	})
+
+	t.Run("Longest policy has no exclusions", func(t *testing.T) {
+		cond, args := retentionCondition(roles, policies, nil, now)
+
+		assert.Equal(t, "created_at < ? AND action = ? AND resource_type = ?", cond)
+		assert.Equal(t, []interface{}{now.AddDate(0, 0, -2555), model.ActionUpdate, model.ResourceTypeRole}, args)
+	})
+
+	t.Run("Catch-all policy defers to longer specific policies", func(t *testing.T) {
+		cond, args := retentionCondition(everything, policies, nil, now)
+
+		assert.Equal(t, "created_at < ? AND NOT (action = ? AND resource_type = ? AND created_at >= ?)", cond)
+		assert.Equal(t, []interface{}{now.AddDate(0, 0, -730), model.ActionUpdate, model.ResourceTypeRole, now.AddDate(0, 0, -2555)}, args)
+	})
+
+	t.Run("Holds are excluded", func(t *testing.T) {
+		userID := uint(7)
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		holds := []*model.AuditHoldScope{
+			{UserID: &userID},
+			{ResourceType: model.ResourceTypeUser, ResourceID: "taro", From: &from},
+		}
+
+		cond, args := retentionCondition(logins, []*model.AuditRetentionPolicy{logins}, holds, now)
+
+		assert.Equal(t, "created_at < ? AND action = ? AND NOT (user_id = ?) AND NOT (resource_type = ? AND resource_id = ? AND created_at >= ?)", cond)
+		assert.Equal(t, []interface{}{now.AddDate(0, 0, -90), model.ActionLogin, userID, model.ResourceTypeUser, "taro", from}, args)
+	})
+
+	t.Run("Unscoped hold keeps everything", func(t *testing.T) {
+		cond, args := retentionCondition(logins, policies, []*model.AuditHoldScope{{}}, now)
+
+		assert.Equal(t, "FALSE", cond)
+		assert.Empty(t, args)
+	})
+}
+
+func TestAuditRetentionPolicyOverlaps(t *testing.T) {
+	logins := &model.AuditRetentionPolicy{Action: model.ActionLogin}
+	failed := &model.AuditRetentionPolicy{Status: model.AuditStatusFailed}
+	updates := &model.AuditRetentionPolicy{Action: model.ActionUpdate}
+
+	assert.True(t, logins.Overlaps(failed))
+	assert.True(t, logins.Overlaps(&model.AuditRetentionPolicy{}))
+	assert.False(t, logins.Overlaps(updates))
+}
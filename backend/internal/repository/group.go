@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// GroupRepository はユーザーグループのデータアクセスを提供します
+type GroupRepository struct {
+	db *gorm.DB
+}
+
+// NewGroupRepository は新しいGroupRepositoryを作成します
+func NewGroupRepository(db *gorm.DB) *GroupRepository {
+	return &GroupRepository{
+		db: db,
+	}
+}
+
+// FindAll はグループを取得します（ページネーション付き）
+func (r *GroupRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.Group, int64, error) {
+	var groups []*model.Group
+	var total int64
+
+	// 総件数を取得
+	if err := dbWithContext(ctx, r.db).Model(&model.Group{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きで取得
+	err := dbWithContext(ctx, r.db).
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("id ASC").
+		Find(&groups).Error
+
+	return groups, total, err
+}
+
+// FindByID はIDでグループを取得します
+func (r *GroupRepository) FindByID(ctx context.Context, id uint) (*model.Group, error) {
+	var group model.Group
+	if err := dbWithContext(ctx, r.db).First(&group, id).Error; err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// ExistsByName はグループ名の存在確認をします
+func (r *GroupRepository) ExistsByName(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.Group{}).Where("name = ?", name).Count(&count).Error
+	return count > 0, err
+}
+
+// CountChildren は子グループの件数を取得します
+func (r *GroupRepository) CountChildren(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.Group{}).Where("parent_id = ?", id).Count(&count).Error
+	return count, err
+}
+
+// FindAncestorIDs は親グループを順にたどったIDを取得します（自身は含みません）
+func (r *GroupRepository) FindAncestorIDs(ctx context.Context, id uint) ([]uint, error) {
+	var ids []uint
+	err := dbWithContext(ctx, r.db).Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT parent_id AS id FROM groups WHERE id = ? AND parent_id IS NOT NULL
+			UNION
+			SELECT g.parent_id FROM groups g JOIN ancestors a ON g.id = a.id WHERE g.parent_id IS NOT NULL
+		)
+		SELECT id FROM ancestors`, id).
+		Scan(&ids).Error
+	return ids, err
+}
+
+// Create はグループを作成します
+func (r *GroupRepository) Create(ctx context.Context, group *model.Group) error {
+	return dbWithContext(ctx, r.db).Create(group).Error
+}
+
+// Update はグループを更新します
+func (r *GroupRepository) Update(ctx context.Context, group *model.Group) error {
+	return dbWithContext(ctx, r.db).Save(group).Error
+}
+
+// Delete はグループと所属関係を削除します
+func (r *GroupRepository) Delete(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.GroupMember{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Group{}, id).Error
+	})
+}
+
+// FindMembers はグループに直接所属するユーザーを取得します（ページネーション付き）
+func (r *GroupRepository) FindMembers(ctx context.Context, groupID uint, params *util.PaginationParams) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Model(&model.User{}).
+		Joins("JOIN group_members ON group_members.user_id = users.id").
+		Where("group_members.group_id = ?", groupID)
+
+	// 総件数を取得
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きで取得
+	err := query.
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("users.id ASC").
+		Find(&users).Error
+
+	return users, total, err
+}
+
+// FindMemberIDs はグループに直接所属するユーザーのうち、指定したIDのものを取得します
+func (r *GroupRepository) FindMemberIDs(ctx context.Context, groupID uint, userIDs []uint) ([]uint, error) {
+	var ids []uint
+	err := dbWithContext(ctx, r.db).Model(&model.GroupMember{}).
+		Where("group_id = ? AND user_id IN ?", groupID, userIDs).
+		Pluck("user_id", &ids).Error
+	return ids, err
+}
+
+// AddMembers はグループにユーザーを追加します（所属済みのユーザーは無視します）
+func (r *GroupRepository) AddMembers(ctx context.Context, members []*model.GroupMember) error {
+	if len(members) == 0 {
+		return nil
+	}
+	return dbWithContext(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&members).Error
+}
+
+// RemoveMember はグループからユーザーを削除します
+// 所属していない場合は gorm.ErrRecordNotFound を返します
+func (r *GroupRepository) RemoveMember(ctx context.Context, groupID, userID uint) error {
+	result := dbWithContext(ctx, r.db).
+		Where("group_id = ? AND user_id = ?", groupID, userID).
+		Delete(&model.GroupMember{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindPermissionsByUserID はユーザーが所属するグループとその親グループに付与された権限を取得します
+func (r *GroupRepository) FindPermissionsByUserID(ctx context.Context, userID uint) ([]string, error) {
+	var permissions []string
+	err := dbWithContext(ctx, r.db).Raw(`
+		WITH RECURSIVE user_groups AS (
+			SELECT g.id, g.parent_id, g.permissions
+			FROM groups g JOIN group_members m ON m.group_id = g.id
+			WHERE m.user_id = ?
+			UNION
+			SELECT p.id, p.parent_id, p.permissions
+			FROM groups p JOIN user_groups ug ON p.id = ug.parent_id
+		)
+		SELECT DISTINCT jsonb_array_elements_text(permissions) FROM user_groups`, userID).
+		Scan(&permissions).Error
+	return permissions, err
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// InvitationRepository はユーザー招待のデータアクセスを提供します
+type InvitationRepository struct {
+	db *gorm.DB
+}
+
+// NewInvitationRepository は新しいInvitationRepositoryを作成します
+func NewInvitationRepository(db *gorm.DB) *InvitationRepository {
+	return &InvitationRepository{
+		db: db,
+	}
+}
+
+// Create は招待を作成します
+func (r *InvitationRepository) Create(ctx context.Context, invitation *model.Invitation) error {
+	return dbWithContext(ctx, r.db).Create(invitation).Error
+}
+
+// FindByID はIDで招待を取得します
+func (r *InvitationRepository) FindByID(ctx context.Context, id uint) (*model.Invitation, error) {
+	var invitation model.Invitation
+	if err := dbWithContext(ctx, r.db).First(&invitation, id).Error; err != nil {
+		return nil, err
+	}
+	return &invitation, nil
+}
+
+// FindAll は招待を取得します（ページネーション付き）
+// status を指定した場合はそのステータスの招待のみを取得します（期限切れは有効期限で判定）
+func (r *InvitationRepository) FindAll(ctx context.Context, params *util.PaginationParams, status string) ([]*model.Invitation, int64, error) {
+	var invitations []*model.Invitation
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Model(&model.Invitation{})
+	now := time.Now()
+	switch status {
+	case "":
+	case model.InvitationStatusPending:
+		query = query.Where("status = ? AND expires_at > ?", model.InvitationStatusPending, now)
+	case model.InvitationStatusExpired:
+		query = query.Where("status = ? OR (status = ? AND expires_at <= ?)",
+			model.InvitationStatusExpired, model.InvitationStatusPending, now)
+	default:
+		query = query.Where("status = ?", status)
+	}
+
+	// 総件数を取得
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きで取得
+	err := query.
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("created_at DESC").
+		Find(&invitations).Error
+
+	return invitations, total, err
+}
+
+// UpdateToken は招待トークンと有効期限を更新します（メール送信前に呼び出します）
+func (r *InvitationRepository) UpdateToken(ctx context.Context, id uint, tokenID string, expiresAt time.Time) error {
+	return dbWithContext(ctx, r.db).
+		Model(&model.Invitation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"token_id":   tokenID,
+			"status":     model.InvitationStatusPending,
+			"expires_at": expiresAt,
+		}).Error
+}
+
+// RecordSent は招待メールの送信日時と送信回数を更新します
+func (r *InvitationRepository) RecordSent(ctx context.Context, id uint, sentAt time.Time) error {
+	return dbWithContext(ctx, r.db).
+		Model(&model.Invitation{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"sent_count":   gorm.Expr("sent_count + 1"),
+			"last_sent_at": sentAt,
+		}).Error
+}
+
+// TransitionStatus は招待のステータスを from から to に変更します
+// 同時に受諾・取り消しされた場合に二重に遷移しないよう、現在のステータスを条件にします
+// 対象が存在しない、またはステータスが異なる場合は gorm.ErrRecordNotFound を返します
+func (r *InvitationRepository) TransitionStatus(ctx context.Context, id uint, from, to string, at time.Time) error {
+	fields := map[string]interface{}{
+		"status": to,
+	}
+	switch to {
+	case model.InvitationStatusAccepted:
+		fields["accepted_at"] = at
+	case model.InvitationStatusRevoked:
+		fields["revoked_at"] = at
+	}
+
+	result := dbWithContext(ctx, r.db).
+		Model(&model.Invitation{}).
+		Where("id = ? AND status = ?", id, from).
+		Updates(fields)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// LegalHoldRepository は訴訟ホールドのデータアクセスを提供します
+type LegalHoldRepository struct {
+	db *gorm.DB
+}
+
+// NewLegalHoldRepository は新しいLegalHoldRepositoryを作成します
+func NewLegalHoldRepository(db *gorm.DB) *LegalHoldRepository {
+	return &LegalHoldRepository{
+		db: db,
+	}
+}
+
+// FindAll はホールドを新しい順に取得します（includeReleased の場合は解除済みを含める）
+func (r *LegalHoldRepository) FindAll(ctx context.Context, includeReleased bool) ([]*model.LegalHold, error) {
+	var holds []*model.LegalHold
+	query := dbWithContext(ctx, r.db)
+	if !includeReleased {
+		query = query.Where("released_at IS NULL")
+	}
+	err := query.Order("id DESC").Find(&holds).Error
+	return holds, err
+}
+
+// FindActive は解除されていないホールドをすべて取得します
+func (r *LegalHoldRepository) FindActive(ctx context.Context) ([]*model.LegalHold, error) {
+	var holds []*model.LegalHold
+	err := dbWithContext(ctx, r.db).Where("released_at IS NULL").Order("id ASC").Find(&holds).Error
+	return holds, err
+}
+
+// FindByID はIDでホールドを取得します
+func (r *LegalHoldRepository) FindByID(ctx context.Context, id uint) (*model.LegalHold, error) {
+	var hold model.LegalHold
+	if err := dbWithContext(ctx, r.db).First(&hold, id).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// ExistsActiveForUser はユーザーを対象とする解除されていないホールドが存在するかを返します
+func (r *LegalHoldRepository) ExistsActiveForUser(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.LegalHold{}).
+		Where("user_id = ? AND released_at IS NULL", userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Create はホールドを作成します
+func (r *LegalHoldRepository) Create(ctx context.Context, hold *model.LegalHold) error {
+	return dbWithContext(ctx, r.db).Create(hold).Error
+}
+
+// Release は解除されていないホールドを解除し、解除したかを返します（既に解除されている場合は false）
+func (r *LegalHoldRepository) Release(ctx context.Context, hold *model.LegalHold) (bool, error) {
+	result := dbWithContext(ctx, r.db).Model(hold).
+		Where("released_at IS NULL").
+		Updates(map[string]interface{}{
+			"released_at":    hold.ReleasedAt,
+			"released_by":    hold.ReleasedBy,
+			"release_reason": hold.ReleaseReason,
+		})
+	return result.RowsAffected > 0, result.Error
+}
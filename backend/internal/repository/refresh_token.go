@@ -23,13 +23,13 @@ func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
 
 // Create はリフレッシュトークンを作成します
 func (r *RefreshTokenRepository) Create(ctx context.Context, token *model.RefreshToken) error {
-	return r.db.WithContext(ctx).Create(token).Error
+	return dbWithContext(ctx, r.db).Create(token).Error
 }
 
 // FindByTokenID はトークンIDでリフレッシュトークンを取得します
 func (r *RefreshTokenRepository) FindByTokenID(ctx context.Context, tokenID string) (*model.RefreshToken, error) {
 	var token model.RefreshToken
-	if err := r.db.WithContext(ctx).Where("token_id = ?", tokenID).First(&token).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("token_id = ?", tokenID).First(&token).Error; err != nil {
 		return nil, err
 	}
 	return &token, nil
@@ -38,15 +38,25 @@ func (r *RefreshTokenRepository) FindByTokenID(ctx context.Context, tokenID stri
 // FindByUserID はユーザーIDで有効なリフレッシュトークンを全て取得します
 func (r *RefreshTokenRepository) FindByUserID(ctx context.Context, userID uint) ([]*model.RefreshToken, error) {
 	var tokens []*model.RefreshToken
-	err := r.db.WithContext(ctx).
+	err := dbWithContext(ctx, r.db).
 		Where("user_id = ? AND revoked = ? AND expires_at > ?", userID, false, time.Now()).
 		Find(&tokens).Error
 	return tokens, err
 }
 
+// FindAllByUserID はユーザーのリフレッシュトークンを無効化・期限切れを含めて全て取得します
+func (r *RefreshTokenRepository) FindAllByUserID(ctx context.Context, userID uint) ([]*model.RefreshToken, error) {
+	var tokens []*model.RefreshToken
+	err := dbWithContext(ctx, r.db).
+		Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Find(&tokens).Error
+	return tokens, err
+}
+
 // Revoke はトークンを無効化します
 func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenID string) error {
-	return r.db.WithContext(ctx).
+	return dbWithContext(ctx, r.db).
 		Model(&model.RefreshToken{}).
 		Where("token_id = ?", tokenID).
 		Update("revoked", true).Error
@@ -54,7 +64,7 @@ func (r *RefreshTokenRepository) Revoke(ctx context.Context, tokenID string) err
 
 // RevokeAllByUserID はユーザーの全リフレッシュトークンを無効化します
 func (r *RefreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID uint) error {
-	return r.db.WithContext(ctx).
+	return dbWithContext(ctx, r.db).
 		Model(&model.RefreshToken{}).
 		Where("user_id = ?", userID).
 		Update("revoked", true).Error
@@ -62,7 +72,7 @@ func (r *RefreshTokenRepository) RevokeAllByUserID(ctx context.Context, userID u
 
 // DeleteExpired は期限切れのトークンを削除します
 func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) error {
-	return r.db.WithContext(ctx).
+	return dbWithContext(ctx, r.db).
 		Where("expires_at < ?", time.Now()).
 		Delete(&model.RefreshToken{}).Error
 }
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// SecurityAlertRepository はセキュリティアラートのデータアクセスを提供します
+type SecurityAlertRepository struct {
+	db *gorm.DB
+}
+
+// NewSecurityAlertRepository は新しいSecurityAlertRepositoryを作成します
+func NewSecurityAlertRepository(db *gorm.DB) *SecurityAlertRepository {
+	return &SecurityAlertRepository{
+		db: db,
+	}
+}
+
+// FindByFilter は条件に一致するアラートを新しい順に取得します
+func (r *SecurityAlertRepository) FindByFilter(ctx context.Context, filter *model.SecurityAlertFilter, params *util.PaginationParams) ([]*model.SecurityAlert, int64, error) {
+	var alerts []*model.SecurityAlert
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Model(&model.SecurityAlert{})
+	switch filter.Status {
+	case model.SecurityAlertStatusOpen:
+		query = query.Where("acknowledged_at IS NULL")
+	case model.SecurityAlertStatusAcknowledged:
+		query = query.Where("acknowledged_at IS NOT NULL")
+	}
+	if filter.Severity != "" {
+		query = query.Where("severity = ?", filter.Severity)
+	}
+	if filter.Rule != "" {
+		query = query.Where("rule = ?", filter.Rule)
+	}
+	if filter.UserID != nil {
+		query = query.Where("user_id = ?", *filter.UserID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("id DESC").
+		Find(&alerts).Error
+
+	return alerts, total, err
+}
+
+// FindByID はIDでアラートを取得します
+func (r *SecurityAlertRepository) FindByID(ctx context.Context, id uint) (*model.SecurityAlert, error) {
+	var alert model.SecurityAlert
+	if err := dbWithContext(ctx, r.db).First(&alert, id).Error; err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// Create はアラートを作成します
+func (r *SecurityAlertRepository) Create(ctx context.Context, alert *model.SecurityAlert) error {
+	return dbWithContext(ctx, r.db).Create(alert).Error
+}
+
+// ExistsOpen は since 以降に作成された、同じルール・ユーザー・IPアドレスの未対応のアラートが存在するかを返します
+// userID が nil の場合はユーザーを問わずIPアドレスで判定します
+func (r *SecurityAlertRepository) ExistsOpen(ctx context.Context, rule string, userID *uint, ipAddress string, since time.Time) (bool, error) {
+	query := dbWithContext(ctx, r.db).Model(&model.SecurityAlert{}).
+		Where("rule = ? AND ip_address = ? AND acknowledged_at IS NULL AND created_at >= ?", rule, ipAddress, since)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+
+	var count int64
+	err := query.Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsOpenIPLockout は since 以降に ipAddress からのログインを拒否した未対応のパスワードスプレーのアラートが存在するかを返します
+func (r *SecurityAlertRepository) ExistsOpenIPLockout(ctx context.Context, ipAddress string, since time.Time) (bool, error) {
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.SecurityAlert{}).
+		Where("rule = ? AND ip_address = ? AND acknowledged_at IS NULL AND created_at >= ?",
+			model.SecurityRulePasswordSpraying, ipAddress, since).
+		Where("actions @> ?::jsonb", `["`+model.SecurityActionLockout+`"]`).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Acknowledge は未対応のアラートを確認済みにし、更新したかを返します（既に確認済みの場合は false）
+func (r *SecurityAlertRepository) Acknowledge(ctx context.Context, alert *model.SecurityAlert) (bool, error) {
+	result := dbWithContext(ctx, r.db).Model(alert).
+		Where("acknowledged_at IS NULL").
+		Updates(map[string]interface{}{
+			"acknowledged_at":  alert.AcknowledgedAt,
+			"acknowledged_by":  alert.AcknowledgedBy,
+			"acknowledge_note": alert.AcknowledgeNote,
+		})
+	return result.RowsAffected > 0, result.Error
+}
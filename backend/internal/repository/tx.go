@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// txKey はコンテキストにトランザクションを保持するためのキーです
+type txKey struct{}
+
+// TxManager はリポジトリをまたいだトランザクション（ユニットオブワーク）を管理します
+// WithinTransaction に渡したコンテキストを使用したリポジトリの操作は、すべて同じトランザクションに参加します
+type TxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager は新しいTxManagerを作成します
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTransaction は fn をトランザクション内で実行し、fn がエラーを返した場合はロールバックします
+// 既にトランザクション内の場合はセーブポイントを作成し、外側のトランザクションに参加します
+func (m *TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return dbWithContext(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// InTransaction はコンテキストがトランザクションを保持しているかを返します
+func InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*gorm.DB)
+	return ok
+}
+
+// dbWithContext はコンテキストがトランザクションを保持していればそのトランザクションを、なければ db を返します
+func dbWithContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -9,6 +12,9 @@ import (
 	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
+// ErrVersionConflict は楽観的排他制御でバージョンが一致しなかった場合のエラーです
+var ErrVersionConflict = errors.New("version conflict")
+
 // UserRepository はユーザーデータアクセスを提供します
 type UserRepository struct {
 	db *gorm.DB
@@ -22,17 +28,29 @@ func NewUserRepository(db *gorm.DB) *UserRepository {
 }
 
 // FindAll は全てのユーザーを取得します（ページネーション付き）
-func (r *UserRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.User, int64, error) {
+// attributes を指定した場合は追加属性の値が一致するユーザーのみを取得します
+func (r *UserRepository) FindAll(ctx context.Context, params *util.PaginationParams, attributes map[string]string) ([]*model.User, int64, error) {
 	var users []*model.User
 	var total int64
 
+	query := dbWithContext(ctx, r.db).Model(&model.User{})
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// 型によらずテキストとして比較する（数値は 10、真偽値は true のように指定する）
+		query = query.Where("attributes ->> ? = ?", key, attributes[key])
+	}
+
 	// 総件数を取得
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Count(&total).Error; err != nil {
+	if err := query.Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// ページネーション付きで取得
-	err := r.db.WithContext(ctx).
+	err := query.
 		Offset(params.Offset).
 		Limit(params.PerPage).
 		Order("id ASC").
@@ -44,7 +62,16 @@ func (r *UserRepository) FindAll(ctx context.Context, params *util.PaginationPar
 // FindByID はIDでユーザーを取得します
 func (r *UserRepository) FindByID(ctx context.Context, id uint) (*model.User, error) {
 	var user model.User
-	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByIDUnscoped は削除済みを含めてIDでユーザーを取得します
+func (r *UserRepository) FindByIDUnscoped(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := dbWithContext(ctx, r.db).Unscoped().First(&user, id).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -53,7 +80,7 @@ func (r *UserRepository) FindByID(ctx context.Context, id uint) (*model.User, er
 // FindByEmail はメールアドレスでユーザーを取得します
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.User, error) {
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&user).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("email = ?", email).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -62,7 +89,7 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*model.
 // FindByUsername はユーザー名でユーザーを取得します
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*model.User, error) {
 	var user model.User
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&user).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Where("username = ?", username).First(&user).Error; err != nil {
 		return nil, err
 	}
 	return &user, nil
@@ -70,23 +97,296 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 
 // Create は新しいユーザーを作成します
 func (r *UserRepository) Create(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Create(user).Error
+	return dbWithContext(ctx, r.db).Create(user).Error
 }
 
 // Update はユーザー情報を更新します
 func (r *UserRepository) Update(ctx context.Context, user *model.User) error {
-	return r.db.WithContext(ctx).Save(user).Error
+	return dbWithContext(ctx, r.db).Save(user).Error
+}
+
+// UpdateWithVersion はバージョンが一致する場合のみユーザー情報を更新します
+// 読み込みとは別に条件付きUPDATEで判定するため、同時更新による上書きを防げます
+// 更新に成功した場合は user.Version を新しいバージョンに更新します
+func (r *UserRepository) UpdateWithVersion(ctx context.Context, user *model.User, expectedVersion uint) error {
+	result := dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND version = ?", user.ID, expectedVersion).
+		Updates(map[string]interface{}{
+			"email":              user.Email,
+			"pending_email":      user.PendingEmail,
+			"email_token_id":     user.EmailTokenID,
+			"full_name":          user.FullName,
+			"department":         user.Department,
+			"role":               user.Role,
+			"status":             user.Status,
+			"attributes":         user.Attributes,
+			"account_expires_at": user.AccountExpiresAt,
+			"status_changed_at":  user.StatusChangedAt,
+			"dormant_warned_at":  user.DormantWarnedAt,
+			"version":            gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+
+	user.Version = expectedVersion + 1
+	return nil
+}
+
+// UpdateLastLogin は最終ログイン日時のみを更新します
+// 管理者による同時更新を上書きしないよう、他のカラムやバージョンには触れません
+// ログインにより休眠状態ではなくなるため、休眠の予告も取り消します
+func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uint, lastLogin time.Time) error {
+	return dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{
+			"last_login":        lastLogin,
+			"dormant_warned_at": nil,
+		}).Error
+}
+
+// dormantSince は休眠判定の起点（作成・最終ログイン・ステータス変更のうち最も新しい日時）です
+const dormantSince = "GREATEST(created_at, COALESCE(last_login, created_at), COALESCE(status_changed_at, created_at))"
+
+// FindDormantToWarn は休眠の予告をまだ送っていない有効なユーザーのうち、
+// 指定日時以降に利用していないユーザーを取得します
+func (r *UserRepository) FindDormantToWarn(ctx context.Context, inactiveSince time.Time) ([]*model.User, error) {
+	var users []*model.User
+	err := dbWithContext(ctx, r.db).
+		Where("status = ? AND dormant_warned_at IS NULL", model.UserStatusActive).
+		Where(dormantSince+" < ?", inactiveSince).
+		Order("id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// FindDormant は指定日時以降に利用していない有効なユーザーを取得します
+// warnedBefore を指定した場合は、その日時までに予告済みのユーザーのみを対象にします
+func (r *UserRepository) FindDormant(ctx context.Context, inactiveSince time.Time, warnedBefore *time.Time) ([]*model.User, error) {
+	query := dbWithContext(ctx, r.db).
+		Where("status = ?", model.UserStatusActive).
+		Where(dormantSince+" < ?", inactiveSince)
+	if warnedBefore != nil {
+		query = query.Where("dormant_warned_at IS NOT NULL AND dormant_warned_at <= ?", *warnedBefore)
+	}
+
+	var users []*model.User
+	err := query.Order("id ASC").Find(&users).Error
+	return users, err
+}
+
+// FindExpired は利用期限を過ぎた有効なユーザーを取得します
+func (r *UserRepository) FindExpired(ctx context.Context, now time.Time) ([]*model.User, error) {
+	var users []*model.User
+	err := dbWithContext(ctx, r.db).
+		Where("status = ? AND account_expires_at IS NOT NULL AND account_expires_at <= ?", model.UserStatusActive, now).
+		Order("id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// MarkDormantWarned は休眠の予告を送信した日時を記録します
+func (r *UserRepository) MarkDormantWarned(ctx context.Context, id uint, warnedAt time.Time) error {
+	return dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		UpdateColumn("dormant_warned_at", warnedAt).Error
+}
+
+// Deactivate は有効なユーザーを無効化します
+// 読み込み後にログインやステータス変更があった場合に上書きしないよう、
+// 読み込み時点のバージョンと最終ログイン日時が一致する場合のみ更新します
+// 条件に一致しない場合は gorm.ErrRecordNotFound を返します
+func (r *UserRepository) Deactivate(ctx context.Context, user *model.User, changedAt time.Time) error {
+	query := dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND version = ? AND status = ?", user.ID, user.Version, model.UserStatusActive)
+	if user.LastLogin == nil {
+		query = query.Where("last_login IS NULL")
+	} else {
+		query = query.Where("last_login = ?", *user.LastLogin)
+	}
+
+	result := query.Updates(map[string]interface{}{
+		"status":            model.UserStatusInactive,
+		"status_changed_at": changedAt,
+		"dormant_warned_at": nil,
+		"version":           gorm.Expr("version + 1"),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	user.Status = model.UserStatusInactive
+	user.StatusChangedAt = &changedAt
+	user.DormantWarnedAt = nil
+	user.Version++
+	return nil
+}
+
+// Suspend は有効なユーザーを停止します
+// 既に有効でない場合は gorm.ErrRecordNotFound を返します
+func (r *UserRepository) Suspend(ctx context.Context, user *model.User, changedAt time.Time) error {
+	result := dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND status = ?", user.ID, model.UserStatusActive).
+		Updates(map[string]interface{}{
+			"status":            model.UserStatusSuspended,
+			"status_changed_at": changedAt,
+			"version":           gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	user.Status = model.UserStatusSuspended
+	user.StatusChangedAt = &changedAt
+	user.Version++
+	return nil
+}
+
+// Activate は招待中（pending）のユーザーにパスワードを設定して有効化します
+// 招待メールを受け取れたことでメールアドレスの所有が確認できるため、確認済みにします
+// 既に有効化済みの場合は gorm.ErrRecordNotFound を返します
+func (r *UserRepository) Activate(ctx context.Context, id uint, passwordHash string) error {
+	result := dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND status = ?", id, model.UserStatusPending).
+		Updates(map[string]interface{}{
+			"password_hash":     passwordHash,
+			"status":            model.UserStatusActive,
+			"status_changed_at": gorm.Expr("NOW()"),
+			"email_verified":    true,
+			"version":           gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateEmailToken はメール確認トークンIDを再発行します
+// 以前に送信した確認リンクは使用できなくなります
+func (r *UserRepository) UpdateEmailToken(ctx context.Context, id uint, tokenID string) error {
+	return dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ?", id).
+		UpdateColumn("email_token_id", tokenID).Error
+}
+
+// ConfirmEmail はトークンIDが一致する場合のみメールアドレスを確認済みにします
+// email が現在のアドレスと異なる場合は確認待ちのアドレスに切り替えます
+// トークンが使用済み・再発行済みの場合は gorm.ErrRecordNotFound を返します
+func (r *UserRepository) ConfirmEmail(ctx context.Context, id uint, tokenID, email string) error {
+	result := dbWithContext(ctx, r.db).
+		Model(&model.User{}).
+		Where("id = ? AND email_token_id = ? AND (email = ? OR pending_email = ?)", id, tokenID, email, email).
+		Updates(map[string]interface{}{
+			"email":          email,
+			"pending_email":  "",
+			"email_token_id": "",
+			"email_verified": true,
+			"version":        gorm.Expr("version + 1"),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
 }
 
 // Delete はユーザーを削除します（ソフトデリート）
 func (r *UserRepository) Delete(ctx context.Context, id uint) error {
-	return r.db.WithContext(ctx).Delete(&model.User{}, id).Error
+	return dbWithContext(ctx, r.db).Delete(&model.User{}, id).Error
+}
+
+// FindDeleted は削除済みユーザーを取得します（ページネーション付き）
+func (r *UserRepository) FindDeleted(ctx context.Context, params *util.PaginationParams) ([]*model.User, int64, error) {
+	var users []*model.User
+	var total int64
+
+	// 総件数を取得
+	if err := dbWithContext(ctx, r.db).Unscoped().Model(&model.User{}).
+		Where("deleted_at IS NOT NULL").
+		Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きで取得
+	err := dbWithContext(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("deleted_at DESC").
+		Find(&users).Error
+
+	return users, total, err
+}
+
+// FindDeletedByID はIDで削除済みユーザーを取得します
+func (r *UserRepository) FindDeletedByID(ctx context.Context, id uint) (*model.User, error) {
+	var user model.User
+	if err := dbWithContext(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Restore は削除済みユーザーを復元します
+func (r *UserRepository) Restore(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Unscoped().
+		Model(&model.User{}).
+		Where("id = ? AND deleted_at IS NOT NULL", id).
+		Update("deleted_at", nil).Error
+}
+
+// FindDeletedBefore は指定日時より前に削除されたユーザーを取得します
+func (r *UserRepository) FindDeletedBefore(ctx context.Context, cutoff time.Time) ([]*model.User, error) {
+	var users []*model.User
+	err := dbWithContext(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Order("id ASC").
+		Find(&users).Error
+	return users, err
+}
+
+// Purge は削除済みユーザーを物理削除します
+func (r *UserRepository) Purge(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Delete(&model.User{}, id).Error
+}
+
+// Anonymize はユーザーの個人情報を匿名化した値で上書きします（削除済みユーザーも対象）
+func (r *UserRepository) Anonymize(ctx context.Context, id uint, fields map[string]interface{}) error {
+	return dbWithContext(ctx, r.db).Unscoped().
+		Model(&model.User{}).
+		Where("id = ?", id).
+		Updates(fields).Error
 }
 
 // ExistsByEmail はメールアドレスの存在確認をします
 func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("email = ?", email).Count(&count).Error; err != nil {
 		return false, err
 	}
 	return count > 0, nil
@@ -95,7 +395,7 @@ func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool,
 // ExistsByUsername はユーザー名の存在確認をします
 func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("username = ?", username).Count(&count).Error; err != nil {
 		return false, err
 	}
 	return count > 0, nil
@@ -104,7 +404,7 @@ func (r *UserRepository) ExistsByUsername(ctx context.Context, username string)
 // CountAll は全ユーザー数を取得します
 func (r *UserRepository) CountAll(ctx context.Context) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -113,7 +413,7 @@ func (r *UserRepository) CountAll(ctx context.Context) (int64, error) {
 // CountByStatus はステータス別ユーザー数を取得します
 func (r *UserRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).Model(&model.User{}).Where("status = ?", status).Count(&count).Error; err != nil {
+	if err := dbWithContext(ctx, r.db).Model(&model.User{}).Where("status = ?", status).Count(&count).Error; err != nil {
 		return 0, err
 	}
 	return count, nil
@@ -126,7 +426,7 @@ func (r *UserRepository) CountByRole(ctx context.Context) (map[string]int64, err
 		Count int64
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := dbWithContext(ctx, r.db).
 		Model(&model.User{}).
 		Select("role, COUNT(*) as count").
 		Group("role").
@@ -152,7 +452,7 @@ func (r *UserRepository) CountByDepartment(ctx context.Context) ([]struct {
 		Count      int64
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := dbWithContext(ctx, r.db).
 		Model(&model.User{}).
 		Select("COALESCE(department, '未設定') as department, COUNT(*) as count").
 		Group("department").
@@ -174,7 +474,7 @@ func (r *UserRepository) GetLastLoginStats(ctx context.Context, days int) ([]str
 		Count int64
 	}
 
-	if err := r.db.WithContext(ctx).
+	if err := dbWithContext(ctx, r.db).
 		Model(&model.User{}).
 		Select("DATE(last_login) as date, COUNT(*) as count").
 		Where("last_login IS NOT NULL AND last_login >= NOW() - INTERVAL '?' DAY", days).
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// UserAttributeDefinitionRepository はユーザー追加属性の定義のデータアクセスを提供します
+type UserAttributeDefinitionRepository struct {
+	db *gorm.DB
+}
+
+// NewUserAttributeDefinitionRepository は新しいUserAttributeDefinitionRepositoryを作成します
+func NewUserAttributeDefinitionRepository(db *gorm.DB) *UserAttributeDefinitionRepository {
+	return &UserAttributeDefinitionRepository{
+		db: db,
+	}
+}
+
+// FindAll は全ての属性定義を取得します
+func (r *UserAttributeDefinitionRepository) FindAll(ctx context.Context) ([]*model.UserAttributeDefinition, error) {
+	var definitions []*model.UserAttributeDefinition
+	err := dbWithContext(ctx, r.db).Order("id ASC").Find(&definitions).Error
+	return definitions, err
+}
+
+// FindByID はIDで属性定義を取得します
+func (r *UserAttributeDefinitionRepository) FindByID(ctx context.Context, id uint) (*model.UserAttributeDefinition, error) {
+	var definition model.UserAttributeDefinition
+	if err := dbWithContext(ctx, r.db).First(&definition, id).Error; err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// ExistsByKey はキーの存在確認をします
+func (r *UserAttributeDefinitionRepository) ExistsByKey(ctx context.Context, key string) (bool, error) {
+	var count int64
+	err := dbWithContext(ctx, r.db).Model(&model.UserAttributeDefinition{}).Where("key = ?", key).Count(&count).Error
+	return count > 0, err
+}
+
+// Create は属性定義を作成します
+func (r *UserAttributeDefinitionRepository) Create(ctx context.Context, definition *model.UserAttributeDefinition) error {
+	return dbWithContext(ctx, r.db).Create(definition).Error
+}
+
+// Update は属性定義を更新します
+func (r *UserAttributeDefinitionRepository) Update(ctx context.Context, definition *model.UserAttributeDefinition) error {
+	return dbWithContext(ctx, r.db).Save(definition).Error
+}
+
+// Delete は属性定義を削除します
+// ユーザーに保存済みの値は削除しません（定義を再作成すれば再び参照できます）
+func (r *UserAttributeDefinitionRepository) Delete(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Delete(&model.UserAttributeDefinition{}, id).Error
+}
@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// WebhookRepository はWebhookの送信先と配信のデータアクセスを提供します
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository は新しいWebhookRepositoryを作成します
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db: db,
+	}
+}
+
+// FindAll は送信先を取得します（ページネーション付き）
+func (r *WebhookRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.WebhookSubscription, int64, error) {
+	var subscriptions []*model.WebhookSubscription
+	var total int64
+
+	// 総件数を取得
+	if err := dbWithContext(ctx, r.db).Model(&model.WebhookSubscription{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// ページネーション付きで取得
+	err := dbWithContext(ctx, r.db).
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("id ASC").
+		Find(&subscriptions).Error
+
+	return subscriptions, total, err
+}
+
+// FindActive は有効な送信先をすべて取得します
+func (r *WebhookRepository) FindActive(ctx context.Context) ([]*model.WebhookSubscription, error) {
+	var subscriptions []*model.WebhookSubscription
+	err := dbWithContext(ctx, r.db).Where("active = ?", true).Order("id ASC").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// FindByID はIDで送信先を取得します
+func (r *WebhookRepository) FindByID(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	var subscription model.WebhookSubscription
+	if err := dbWithContext(ctx, r.db).First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// Create は送信先を作成します
+func (r *WebhookRepository) Create(ctx context.Context, subscription *model.WebhookSubscription) error {
+	return dbWithContext(ctx, r.db).Create(subscription).Error
+}
+
+// Update は送信先を更新します
+func (r *WebhookRepository) Update(ctx context.Context, subscription *model.WebhookSubscription) error {
+	return dbWithContext(ctx, r.db).Save(subscription).Error
+}
+
+// Delete は送信先を削除します（配信と送信履歴も削除されます）
+func (r *WebhookRepository) Delete(ctx context.Context, id uint) error {
+	return dbWithContext(ctx, r.db).Delete(&model.WebhookSubscription{}, id).Error
+}
+
+// CreateDeliveries は配信をまとめて作成します
+// 同じ送信先に同じイベントを二重に登録しないよう、重複は無視します
+func (r *WebhookRepository) CreateDeliveries(ctx context.Context, deliveries []*model.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return dbWithContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "subscription_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+// ClaimDue は送信時刻を過ぎた配信を最大 limit 件取得し、lease の間は他のインスタンスが取得しないようにします
+// 送信中にプロセスが停止した場合も、リースが切れると再び取得されます
+func (r *WebhookRepository) ClaimDue(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	err := dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", model.WebhookDeliveryPending, now).
+			Where("locked_until IS NULL OR locked_until <= ?", now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(deliveries))
+		for i, delivery := range deliveries {
+			ids[i] = delivery.ID
+		}
+		return tx.Model(&model.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Update("locked_until", now.Add(lease)).Error
+	})
+	return deliveries, err
+}
+
+// SaveAttempt は送信結果を配信に反映し、送信履歴を追加します
+func (r *WebhookRepository) SaveAttempt(ctx context.Context, delivery *model.WebhookDelivery, attempt *model.WebhookDeliveryAttempt) error {
+	return dbWithContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.WebhookDelivery{}).
+			Where("id = ?", delivery.ID).
+			Updates(map[string]interface{}{
+				"status":           delivery.Status,
+				"attempts":         delivery.Attempts,
+				"next_attempt_at":  delivery.NextAttemptAt,
+				"locked_until":     nil,
+				"last_status_code": delivery.LastStatusCode,
+				"last_error":       delivery.LastError,
+				"delivered_at":     delivery.DeliveredAt,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Create(attempt).Error
+	})
+}
+
+// FindDeliveries は送信先の配信を新しい順に取得します（ページネーション付き）
+// status を指定した場合はそのステータスの配信のみを返します
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, subscriptionID uint, status string, params *util.PaginationParams) ([]*model.WebhookDelivery, int64, error) {
+	var deliveries []*model.WebhookDelivery
+	var total int64
+
+	query := dbWithContext(ctx, r.db).Model(&model.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Offset(params.Offset).
+		Limit(params.PerPage).
+		Order("id DESC").
+		Find(&deliveries).Error
+
+	return deliveries, total, err
+}
+
+// FindDeliveryByID はIDで配信を取得します
+func (r *WebhookRepository) FindDeliveryByID(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	var delivery model.WebhookDelivery
+	if err := dbWithContext(ctx, r.db).First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// FindAttempts は配信の送信履歴を古い順に取得します
+func (r *WebhookRepository) FindAttempts(ctx context.Context, deliveryID uint) ([]*model.WebhookDeliveryAttempt, error) {
+	var attempts []*model.WebhookDeliveryAttempt
+	err := dbWithContext(ctx, r.db).Where("delivery_id = ?", deliveryID).Order("id ASC").Find(&attempts).Error
+	return attempts, err
+}
+
+// ResetDelivery は配信を送信待ちに戻し、すぐに送信されるようにします
+// 再試行の回数は0に戻しますが、送信履歴は残します
+func (r *WebhookRepository) ResetDelivery(ctx context.Context, id uint, now time.Time) error {
+	result := dbWithContext(ctx, r.db).
+		Model(&model.WebhookDelivery{}).
+		Where("id = ?", id).
+		Updates(resetDeliveryFields(now))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// ResetDeadDeliveries は送信先のデッドレターをすべて送信待ちに戻し、件数を返します
+func (r *WebhookRepository) ResetDeadDeliveries(ctx context.Context, subscriptionID uint, now time.Time) (int64, error) {
+	result := dbWithContext(ctx, r.db).
+		Model(&model.WebhookDelivery{}).
+		Where("subscription_id = ? AND status = ?", subscriptionID, model.WebhookDeliveryDead).
+		Updates(resetDeliveryFields(now))
+	return result.RowsAffected, result.Error
+}
+
+// resetDeliveryFields は配信を送信待ちに戻すための更新内容を返します
+func resetDeliveryFields(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"status":          model.WebhookDeliveryPending,
+		"attempts":        0,
+		"next_attempt_at": now,
+		"locked_until":    nil,
+	}
+}
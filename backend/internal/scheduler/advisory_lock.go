@@ -0,0 +1,91 @@
+package scheduler
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// AdvisoryLock はPostgreSQLのアドバイザリーロックによるリーダーロックです
+// セッション単位のロックのため専用の接続を保持し、接続が切れるとロックも解放されます
+type AdvisoryLock struct {
+	db   *sql.DB
+	key  int64
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewAdvisoryLock は新しいAdvisoryLockを作成します
+// key は同じジョブを実行するレプリカ間で共通の値を指定します
+func NewAdvisoryLock(db *sql.DB, key int64) *AdvisoryLock {
+	return &AdvisoryLock{
+		db:  db,
+		key: key,
+	}
+}
+
+// TryAcquire はアドバイザリーロックの取得を試みます
+func (l *AdvisoryLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		return true, nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+// Check はロックを取得した接続が有効かを確認します
+func (l *AdvisoryLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return errors.New("advisory lock is not held")
+	}
+
+	// セッションが続いている間はロックが保持されるため、接続の生存を確認する
+	if err := l.conn.PingContext(ctx); err != nil {
+		// 接続が切れている場合はセッションとともにロックも解放されている
+		l.conn.Close()
+		l.conn = nil
+		return err
+	}
+	return nil
+}
+
+// Release はロックを解放して接続をプールに返します
+func (l *AdvisoryLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	closeErr := l.conn.Close()
+	l.conn = nil
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
@@ -0,0 +1,136 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Job は定期実行するジョブです
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// LeaderLock は複数のレプリカのうち1つだけがジョブを実行するためのロックです
+type LeaderLock interface {
+	// TryAcquire はロックの取得を試みます（他のレプリカが保持している場合は false を返します）
+	TryAcquire(ctx context.Context) (bool, error)
+	// Check はロックを保持し続けているかを確認します（接続が切れた場合等はエラーを返します）
+	Check(ctx context.Context) error
+	// Release はロックを解放します
+	Release(ctx context.Context) error
+}
+
+// Scheduler はリーダーとして選出されたレプリカでのみジョブを定期実行するスケジューラです
+// リーダーになれなかったレプリカは待機し、リーダーが停止した場合に引き継ぎます
+type Scheduler struct {
+	lock          LeaderLock
+	jobs          []Job
+	retryInterval time.Duration // リーダー選出を再試行する間隔
+	checkInterval time.Duration // リーダーがロックを保持しているか確認する間隔
+	logger        *zap.Logger
+}
+
+// NewScheduler は新しいSchedulerを作成します
+func NewScheduler(lock LeaderLock, retryInterval, checkInterval time.Duration, logger *zap.Logger) *Scheduler {
+	return &Scheduler{
+		lock:          lock,
+		retryInterval: retryInterval,
+		checkInterval: checkInterval,
+		logger:        logger,
+	}
+}
+
+// Register はジョブを登録します（Start の前に呼び出してください）
+// 実行間隔が0以下のジョブは無効として登録しません
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		s.logger.Info("Scheduled job disabled", zap.String("job", job.Name))
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start はコンテキストがキャンセルされるまでリーダー選出とジョブの実行を繰り返します
+func (s *Scheduler) Start(ctx context.Context) {
+	if len(s.jobs) == 0 {
+		return
+	}
+
+	for {
+		acquired, err := s.lock.TryAcquire(ctx)
+		if err != nil {
+			s.logger.Warn("Failed to acquire scheduler leader lock", zap.Error(err))
+		}
+		if acquired {
+			s.logger.Info("Scheduler became leader")
+			s.lead(ctx)
+			s.logger.Info("Scheduler stepped down")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.retryInterval):
+		}
+	}
+}
+
+// lead はロックを失うかコンテキストがキャンセルされるまでジョブを実行します
+func (s *Scheduler) lead(ctx context.Context) {
+	leaderCtx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			s.runJob(leaderCtx, job)
+		}(job)
+	}
+
+	ticker := time.NewTicker(s.checkInterval)
+	defer ticker.Stop()
+	for leaderCtx.Err() == nil {
+		select {
+		case <-leaderCtx.Done():
+		case <-ticker.C:
+			if err := s.lock.Check(leaderCtx); err != nil && leaderCtx.Err() == nil {
+				s.logger.Warn("Scheduler lost leader lock", zap.Error(err))
+				cancel()
+			}
+		}
+	}
+
+	// 実行中のジョブが終わってからロックを解放する（他のレプリカと同時に実行しないため）
+	cancel()
+	wg.Wait()
+
+	releaseCtx, releaseCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer releaseCancel()
+	if err := s.lock.Release(releaseCtx); err != nil {
+		s.logger.Warn("Failed to release scheduler leader lock", zap.Error(err))
+	}
+}
+
+// runJob はジョブをすぐに1回実行し、その後は実行間隔ごとに実行します
+func (s *Scheduler) runJob(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			s.logger.Error("Scheduled job failed", zap.String("job", job.Name), zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fakeLock はテスト用のLeaderLockです
+type fakeLock struct {
+	mu       sync.Mutex
+	leader   bool
+	held     bool
+	lost     bool
+	released int
+}
+
+func (l *fakeLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.leader {
+		return false, nil
+	}
+	l.held = true
+	l.lost = false
+	return true, nil
+}
+
+func (l *fakeLock) Check(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.lost {
+		return errors.New("connection lost")
+	}
+	return nil
+}
+
+func (l *fakeLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.held = false
+	l.released++
+	return nil
+}
+
+func (l *fakeLock) set(fn func(l *fakeLock)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fn(l)
+}
+
+func (l *fakeLock) get(fn func(l *fakeLock) bool) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return fn(l)
+}
+
+func TestSchedulerRunsJobsOnlyAsLeader(t *testing.T) {
+	lock := &fakeLock{}
+	s := NewScheduler(lock, 10*time.Millisecond, 10*time.Millisecond, zap.NewNop())
+
+	var runs int32
+	s.Register(Job{
+		Name:     "count",
+		Interval: 5 * time.Millisecond,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return nil
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.Start(ctx)
+	}()
+
+	// 他のレプリカがリーダーの間は実行しない
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&runs))
+
+	// リーダーになると実行する
+	lock.set(func(l *fakeLock) { l.leader = true })
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&runs) >= 2 }, time.Second, 5*time.Millisecond)
+
+	cancel()
+	<-done
+	assert.True(t, lock.get(func(l *fakeLock) bool { return !l.held && l.released == 1 }))
+}
+
+func TestSchedulerStopsWhenLockIsLost(t *testing.T) {
+	lock := &fakeLock{leader: true}
+	s := NewScheduler(lock, time.Hour, 10*time.Millisecond, zap.NewNop())
+
+	var running int32
+	s.Register(Job{
+		Name:     "wait",
+		Interval: time.Hour,
+		Run: func(ctx context.Context) error {
+			atomic.StoreInt32(&running, 1)
+			<-ctx.Done()
+			atomic.StoreInt32(&running, 0)
+			return ctx.Err()
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Start(ctx)
+
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&running) == 1 }, time.Second, 5*time.Millisecond)
+
+	// ロックを失うと実行中のジョブをキャンセルしてロックを解放する
+	lock.set(func(l *fakeLock) { l.lost = true; l.leader = false })
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&running) == 0 && lock.get(func(l *fakeLock) bool { return l.released == 1 })
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSchedulerSkipsDisabledJobs(t *testing.T) {
+	s := NewScheduler(&fakeLock{}, time.Second, time.Second, zap.NewNop())
+
+	s.Register(Job{Name: "disabled", Interval: 0, Run: func(ctx context.Context) error { return nil }})
+
+	assert.Empty(t, s.jobs)
+}
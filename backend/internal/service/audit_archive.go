@@ -0,0 +1,529 @@
+package service
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/archivestore"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// AuditArchiveConfig は監査ログのアーカイブに関する設定です
+type AuditArchiveConfig struct {
+	ArchiveAfterDays int           // 作成からこの日数を過ぎた監査ログをアーカイブする
+	SegmentSize      int           // 1つのセグメントに含める最大件数
+	MaxSegments      int           // 1回の実行で作成する最大セグメント数（0以下の場合は無制限）
+	SegmentPause     time.Duration // セグメントの間隔（監査ログの書き込みを待たせ続けないため）
+}
+
+// AuditArchiveService は監査ログのアーカイブに関するビジネスロジックを提供します
+// 古い監査ログを圧縮したセグメントとして保存先に書き出し、読み戻して検証してから削除します
+type AuditArchiveService struct {
+	repo            *repository.AuditLogArchiveRepository
+	auditLogRepo    *repository.AuditLogRepository
+	store           archivestore.Store
+	holds           AuditHoldProvider // nil の場合は訴訟ホールドを考慮しない
+	config          AuditArchiveConfig
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+	now             func() time.Time
+}
+
+// NewAuditArchiveService は新しいAuditArchiveServiceを作成します
+func NewAuditArchiveService(
+	repo *repository.AuditLogArchiveRepository,
+	auditLogRepo *repository.AuditLogRepository,
+	store archivestore.Store,
+	holds AuditHoldProvider,
+	config AuditArchiveConfig,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *AuditArchiveService {
+	if config.ArchiveAfterDays <= 0 {
+		config.ArchiveAfterDays = 365
+	}
+	if config.SegmentSize <= 0 {
+		config.SegmentSize = 10000
+	}
+	return &AuditArchiveService{
+		repo:            repo,
+		auditLogRepo:    auditLogRepo,
+		store:           store,
+		holds:           holds,
+		config:          config,
+		logger:          logger,
+		auditLogService: auditLogService,
+		now:             time.Now,
+	}
+}
+
+// List はアーカイブを新しい順に取得します
+func (s *AuditArchiveService) List(ctx context.Context, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	archives, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch audit log archives", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return util.NewPaginatedResponse(archives, total, params), nil
+}
+
+// GetByID はIDでアーカイブを取得します
+func (s *AuditArchiveService) GetByID(ctx context.Context, id uint) (*model.AuditLogArchive, error) {
+	return s.findArchive(ctx, id)
+}
+
+// Run は保存期間を過ぎた監査ログをアーカイブし、検証できたセグメントの監査ログを削除します
+// 前回の実行で削除できなかったセグメントがある場合は、先に削除を再開します
+func (s *AuditArchiveService) Run(ctx context.Context, actorID uint) (*model.AuditArchiveResult, error) {
+	now := s.now()
+	result := &model.AuditArchiveResult{
+		RanAt:    now,
+		Cutoff:   now.AddDate(0, 0, -s.config.ArchiveAfterDays),
+		Archives: []*model.AuditLogArchive{},
+	}
+
+	// ホールドを取得できない場合は、保持すべき監査ログを削除しないよう中止する
+	var holds []*model.AuditHoldScope
+	if s.holds != nil {
+		var err error
+		holds, err = s.holds.AuditHoldScopes(ctx)
+		if err != nil {
+			s.logger.Error("Failed to fetch legal holds for audit archive", zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+	}
+
+	if err := s.run(ctx, actorID, result, holds); err != nil {
+		s.logger.Error("Failed to archive audit logs", zap.Error(err))
+		result.Error = err.Error()
+	}
+
+	s.logger.Info("Audit logs archived",
+		zap.Int64("archived", result.Archived),
+		zap.Int64("purged", result.Purged),
+		zap.Int("segments", len(result.Archives)),
+	)
+	s.logRun(ctx, actorID, result)
+	return result, nil
+}
+
+// run は削除の再開とアーカイブを順に行い、最初のエラーで中止します
+func (s *AuditArchiveService) run(ctx context.Context, actorID uint, result *model.AuditArchiveResult, holds []*model.AuditHoldScope) error {
+	pending, err := s.repo.FindByStatus(ctx, model.AuditArchiveStatusVerified)
+	if err != nil {
+		return err
+	}
+	for _, archive := range pending {
+		if err := s.purgeArchive(ctx, archive, nil, holds, result); err != nil {
+			return err
+		}
+	}
+
+	for i := 0; s.config.MaxSegments <= 0 || i < s.config.MaxSegments; i++ {
+		auditLogs, err := s.auditLogRepo.FindArchivable(ctx, result.Cutoff, holds, s.config.SegmentSize)
+		if err != nil {
+			return err
+		}
+		if len(auditLogs) == 0 {
+			return nil
+		}
+
+		archive, err := s.writeSegment(ctx, actorID, auditLogs)
+		if err != nil {
+			return err
+		}
+		result.Archived += int64(archive.RowCount)
+		if err := s.purgeArchive(ctx, archive, auditLogs, holds, result); err != nil {
+			return err
+		}
+
+		if s.config.SegmentPause > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.config.SegmentPause):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeSegment は監査ログをセグメントとして保存先に書き込み、読み戻して検証してからマニフェストを記録します
+func (s *AuditArchiveService) writeSegment(ctx context.Context, actorID uint, auditLogs []*model.AuditLog) (*model.AuditLogArchive, error) {
+	data, err := encodeAuditSegment(auditLogs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit log segment: %w", err)
+	}
+
+	first, last := auditLogs[0], auditLogs[len(auditLogs)-1]
+	archive := &model.AuditLogArchive{
+		Storage:        s.store.Name(),
+		Key:            auditSegmentKey(first, last),
+		FirstID:        first.ID,
+		LastID:         last.ID,
+		FirstCreatedAt: first.CreatedAt,
+		LastCreatedAt:  first.CreatedAt,
+		RowCount:       len(auditLogs),
+		Size:           int64(len(data)),
+		Checksum:       segmentChecksum(data),
+		Status:         model.AuditArchiveStatusVerified,
+		ArchivedBy:     actorID,
+	}
+	for _, auditLog := range auditLogs {
+		if auditLog.CreatedAt.Before(archive.FirstCreatedAt) {
+			archive.FirstCreatedAt = auditLog.CreatedAt
+		}
+		if auditLog.CreatedAt.After(archive.LastCreatedAt) {
+			archive.LastCreatedAt = auditLog.CreatedAt
+		}
+	}
+
+	if err := s.store.Put(ctx, archive.Key, data); err != nil {
+		return nil, fmt.Errorf("failed to write audit log segment %s: %w", archive.Key, err)
+	}
+	if _, err := s.readSegment(ctx, archive); err != nil {
+		s.discardSegment(ctx, archive)
+		return nil, err
+	}
+	if err := s.repo.Create(ctx, archive); err != nil {
+		s.discardSegment(ctx, archive)
+		return nil, err
+	}
+
+	s.logger.Info("Audit log segment archived",
+		zap.String("key", archive.Key),
+		zap.Uint("first_id", archive.FirstID),
+		zap.Uint("last_id", archive.LastID),
+		zap.Int("rows", archive.RowCount),
+	)
+	return archive, nil
+}
+
+// purgeArchive は検証済みのセグメントに含まれる監査ログを削除し、マニフェストを削除済みにします
+// auditLogs が nil の場合は保存先からセグメントを読み戻して検証します
+func (s *AuditArchiveService) purgeArchive(ctx context.Context, archive *model.AuditLogArchive, auditLogs []*model.AuditLog, holds []*model.AuditHoldScope, result *model.AuditArchiveResult) error {
+	if auditLogs == nil {
+		var err error
+		auditLogs, err = s.readSegment(ctx, archive)
+		if err != nil {
+			return err
+		}
+	}
+
+	purged, err := s.auditLogRepo.DeleteArchived(ctx, auditLogs, holds)
+	if errors.Is(err, repository.ErrArchivedLogChanged) {
+		// アーカイブ後に匿名化等でハッシュが変わった監査ログは古い内容のまま削除せず、次のセグメントで改めてアーカイブする
+		s.logger.Warn("Audit logs changed after they were archived, discarding the segment",
+			zap.String("key", archive.Key),
+			zap.Error(err),
+		)
+		if err := s.repo.Delete(ctx, archive.ID); err != nil {
+			return err
+		}
+		s.discardSegment(ctx, archive)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete archived audit logs %s: %w", archive.Key, err)
+	}
+
+	purgedAt := s.now()
+	archive.Status = model.AuditArchiveStatusPurged
+	archive.PurgedCount = purged
+	archive.PurgedAt = &purgedAt
+	if err := s.repo.Update(ctx, archive); err != nil {
+		return err
+	}
+
+	result.Purged += purged
+	result.Archives = append(result.Archives, archive)
+	return nil
+}
+
+// readSegment は保存先からセグメントを読み込み、マニフェストと一致することを検証して監査ログを返します
+func (s *AuditArchiveService) readSegment(ctx context.Context, archive *model.AuditLogArchive) ([]*model.AuditLog, error) {
+	if archive.Storage != s.store.Name() {
+		return nil, fmt.Errorf("audit log segment %s is stored in %s, but the configured storage is %s", archive.Key, archive.Storage, s.store.Name())
+	}
+	data, err := s.store.Get(ctx, archive.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log segment %s: %w", archive.Key, err)
+	}
+	return verifyAuditSegment(archive, data)
+}
+
+// discardSegment は検証できなかったセグメントを保存先から削除します（失敗してもアーカイブ元の監査ログは残っています）
+func (s *AuditArchiveService) discardSegment(ctx context.Context, archive *model.AuditLogArchive) {
+	if err := s.store.Delete(ctx, archive.Key); err != nil {
+		s.logger.Warn("Failed to delete unverified audit log segment", zap.String("key", archive.Key), zap.Error(err))
+	}
+}
+
+// Import は調査のためにアーカイブを読み戻し、取り込み用のテーブルに保存します
+// セグメントのチェックサムと各監査ログのハッシュを検証し、改ざんされている場合は取り込みません
+func (s *AuditArchiveService) Import(ctx context.Context, actorID, id uint) (*model.AuditArchiveImportResult, error) {
+	archive, err := s.findArchive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLogs, err := s.readSegment(ctx, archive)
+	if err != nil {
+		s.logger.Error("Failed to read audit log archive", zap.Uint("id", id), zap.Error(err))
+		s.logImportFailure(ctx, actorID, archive, err)
+		if errors.Is(err, archivestore.ErrNotFound) || errors.Is(err, errSegmentCorrupted) {
+			return nil, util.NewInternalError(util.ErrCodeArchiveCorrupted, err)
+		}
+		return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+	}
+	for _, auditLog := range auditLogs {
+		if !s.auditLogRepo.VerifyHash(auditLog) {
+			err := fmt.Errorf("%w: hash of audit log %d does not match its content", errSegmentCorrupted, auditLog.ID)
+			s.logger.Error("Audit log archive has been tampered with", zap.Uint("id", id), zap.Error(err))
+			s.logImportFailure(ctx, actorID, archive, err)
+			return nil, util.NewInternalError(util.ErrCodeArchiveCorrupted, err)
+		}
+	}
+
+	if err := s.repo.ReplaceImported(ctx, archive.ID, auditLogs); err != nil {
+		s.logger.Error("Failed to import audit log archive", zap.Uint("id", id), zap.Error(err))
+		s.logImportFailure(ctx, actorID, archive, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	importedAt := s.now()
+	archive.ImportedAt = &importedAt
+	if err := s.repo.Update(ctx, archive); err != nil {
+		s.logger.Error("Failed to update audit log archive", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Audit log archive imported", zap.Uint("id", id), zap.Int("rows", len(auditLogs)))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionImport,
+			ResourceType: model.ResourceTypeAuditLogArchive,
+			ResourceID:   strconv.FormatUint(uint64(archive.ID), 10),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After: map[string]interface{}{
+					"key":      archive.Key,
+					"imported": len(auditLogs),
+				},
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return &model.AuditArchiveImportResult{Archive: archive, Imported: len(auditLogs)}, nil
+}
+
+// ListImported はアーカイブから取り込んだ監査ログを取得します
+func (s *AuditArchiveService) ListImported(ctx context.Context, id uint, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	if _, err := s.findArchive(ctx, id); err != nil {
+		return nil, err
+	}
+
+	auditLogs, total, err := s.repo.FindImported(ctx, id, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch imported audit logs", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return util.NewPaginatedResponse(auditLogs, total, params), nil
+}
+
+// DeleteImported は調査を終えたアーカイブの取り込みを削除します（保存先のセグメントは削除しません）
+func (s *AuditArchiveService) DeleteImported(ctx context.Context, actorID, id uint) error {
+	archive, err := s.findArchive(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteImported(ctx, id); err != nil {
+		s.logger.Error("Failed to delete imported audit logs", zap.Uint("id", id), zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	archive.ImportedAt = nil
+	if err := s.repo.Update(ctx, archive); err != nil {
+		s.logger.Error("Failed to update audit log archive", zap.Uint("id", id), zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionDelete,
+			ResourceType: model.ResourceTypeAuditLogArchive,
+			ResourceID:   strconv.FormatUint(uint64(archive.ID), 10),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"key": archive.Key},
+				After:  map[string]interface{}{},
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+	return nil
+}
+
+// logRun はアーカイブの実行結果を監査ログに記録します
+func (s *AuditArchiveService) logRun(ctx context.Context, actorID uint, result *model.AuditArchiveResult) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	segments := make([]interface{}, len(result.Archives))
+	for i, archive := range result.Archives {
+		segments[i] = map[string]interface{}{
+			"key":      archive.Key,
+			"first_id": archive.FirstID,
+			"last_id":  archive.LastID,
+			"checksum": archive.Checksum,
+			"purged":   archive.PurgedCount,
+		}
+	}
+
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionArchive,
+		ResourceType: model.ResourceTypeAuditLog,
+		ResourceID:   "archive",
+		Changes: model.AuditLogChanges{
+			Before: map[string]interface{}{},
+			After: map[string]interface{}{
+				"cutoff":   result.Cutoff,
+				"archived": result.Archived,
+				"purged":   result.Purged,
+				"segments": segments,
+			},
+		},
+		Status:  model.AuditStatusSuccess,
+		Durable: true,
+	}
+	if result.Error != "" {
+		auditReq.Status = model.AuditStatusFailed
+		auditReq.ErrorMessage = result.Error
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// logImportFailure はアーカイブの取り込みの失敗を監査ログに記録します
+func (s *AuditArchiveService) logImportFailure(ctx context.Context, actorID uint, archive *model.AuditLogArchive, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionImport,
+		ResourceType: model.ResourceTypeAuditLogArchive,
+		ResourceID:   strconv.FormatUint(uint64(archive.ID), 10),
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// findArchive はIDでアーカイブを取得し、存在しない場合は404エラーを返します
+func (s *AuditArchiveService) findArchive(ctx context.Context, id uint) (*model.AuditLogArchive, error) {
+	archive, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeArchiveNotFound, err)
+		}
+		s.logger.Error("Failed to fetch audit log archive", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return archive, nil
+}
+
+// errSegmentCorrupted はセグメントがマニフェストと一致しないことを表します
+var errSegmentCorrupted = errors.New("audit log segment is corrupted")
+
+// auditSegmentKey はセグメントの保存先でのキーを返します（最初の監査ログの作成年月で分けます）
+func auditSegmentKey(first, last *model.AuditLog) string {
+	created := first.CreatedAt.UTC()
+	return fmt.Sprintf("audit-logs/%04d/%02d/audit-logs-%010d-%010d.ndjson.gz", created.Year(), created.Month(), first.ID, last.ID)
+}
+
+// encodeAuditSegment は監査ログを1行ずつJSONで書き出し（NDJSON）、gzipで圧縮します
+func encodeAuditSegment(auditLogs []*model.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(zw)
+	for _, auditLog := range auditLogs {
+		if err := encoder.Encode(auditLog); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeAuditSegment はセグメントを展開して監査ログを読み込みます
+func decodeAuditSegment(data []byte) ([]*model.AuditLog, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	var auditLogs []*model.AuditLog
+	decoder := json.NewDecoder(zr)
+	for {
+		var auditLog model.AuditLog
+		err := decoder.Decode(&auditLog)
+		if errors.Is(err, io.EOF) {
+			return auditLogs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		auditLogs = append(auditLogs, &auditLog)
+	}
+}
+
+// segmentChecksum はセグメントのSHA-256を16進数で返します
+func segmentChecksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyAuditSegment はセグメントがマニフェストと一致することを検証して監査ログを返します
+func verifyAuditSegment(archive *model.AuditLogArchive, data []byte) ([]*model.AuditLog, error) {
+	if checksum := segmentChecksum(data); checksum != archive.Checksum {
+		return nil, fmt.Errorf("%w: checksum of %s is %s, expected %s", errSegmentCorrupted, archive.Key, checksum, archive.Checksum)
+	}
+	auditLogs, err := decodeAuditSegment(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", errSegmentCorrupted, archive.Key, err)
+	}
+	if len(auditLogs) == 0 || len(auditLogs) != archive.RowCount {
+		return nil, fmt.Errorf("%w: %s contains %d rows, expected %d", errSegmentCorrupted, archive.Key, len(auditLogs), archive.RowCount)
+	}
+	if auditLogs[0].ID != archive.FirstID || auditLogs[len(auditLogs)-1].ID != archive.LastID {
+		return nil, fmt.Errorf("%w: %s does not cover IDs %d-%d", errSegmentCorrupted, archive.Key, archive.FirstID, archive.LastID)
+	}
+	return auditLogs, nil
+}
@@ -0,0 +1,110 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// archiveTestLogs はアーカイブのテスト用の監査ログを作成します
+func archiveTestLogs(ids ...uint) []*model.AuditLog {
+	created := time.Date(2025, 3, 31, 23, 59, 0, 123456000, time.UTC)
+	auditLogs := make([]*model.AuditLog, 0, len(ids))
+	for _, id := range ids {
+		auditLogs = append(auditLogs, &model.AuditLog{
+			ID:           id,
+			UserID:       3,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   "taro",
+			Changes:      []byte(`{"before":{"note":"<b>旧</b>"},"after":{"note":"新"}}`),
+			IPAddress:    "203.0.113.7",
+			Status:       model.AuditStatusSuccess,
+			CreatedAt:    created.Add(time.Duration(id) * time.Minute),
+			PrevHash:     "prev",
+			Hash:         "hash",
+		})
+	}
+	return auditLogs
+}
+
+func TestAuditSegmentRoundTrip(t *testing.T) {
+	auditLogs := archiveTestLogs(10, 11, 12)
+
+	data, err := encodeAuditSegment(auditLogs)
+	require.NoError(t, err)
+
+	decoded, err := decodeAuditSegment(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 3)
+
+	for i, auditLog := range auditLogs {
+		// ハッシュの対象となる内容が変わらないため、取り込んだ監査ログのハッシュを検証できる
+		expected, err := auditLog.ChainPayload()
+		require.NoError(t, err)
+		actual, err := decoded[i].ChainPayload()
+		require.NoError(t, err)
+		assert.Equal(t, string(expected), string(actual))
+		assert.Equal(t, auditLog.Hash, decoded[i].Hash)
+	}
+}
+
+func TestVerifyAuditSegment(t *testing.T) {
+	auditLogs := archiveTestLogs(10, 11, 12)
+	data, err := encodeAuditSegment(auditLogs)
+	require.NoError(t, err)
+
+	manifest := func() *model.AuditLogArchive {
+		return &model.AuditLogArchive{
+			Key:      auditSegmentKey(auditLogs[0], auditLogs[2]),
+			FirstID:  10,
+			LastID:   12,
+			RowCount: 3,
+			Checksum: segmentChecksum(data),
+		}
+	}
+
+	t.Run("Matches the manifest", func(t *testing.T) {
+		verified, err := verifyAuditSegment(manifest(), data)
+
+		require.NoError(t, err)
+		assert.Len(t, verified, 3)
+	})
+
+	t.Run("Modified file", func(t *testing.T) {
+		modified := append([]byte{}, data...)
+		modified[len(modified)-1] ^= 0xff
+
+		_, err := verifyAuditSegment(manifest(), modified)
+
+		assert.ErrorIs(t, err, errSegmentCorrupted)
+	})
+
+	t.Run("Row count mismatch", func(t *testing.T) {
+		archive := manifest()
+		archive.RowCount = 4
+
+		_, err := verifyAuditSegment(archive, data)
+
+		assert.ErrorIs(t, err, errSegmentCorrupted)
+	})
+
+	t.Run("ID range mismatch", func(t *testing.T) {
+		archive := manifest()
+		archive.LastID = 13
+
+		_, err := verifyAuditSegment(archive, data)
+
+		assert.ErrorIs(t, err, errSegmentCorrupted)
+	})
+}
+
+func TestAuditSegmentKey(t *testing.T) {
+	auditLogs := archiveTestLogs(7, 1234567)
+
+	assert.Equal(t, "audit-logs/2025/04/audit-logs-0000000007-0001234567.ndjson.gz", auditSegmentKey(auditLogs[0], auditLogs[1]))
+}
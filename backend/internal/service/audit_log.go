@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"go.uber.org/zap"
@@ -11,40 +12,141 @@ import (
 
 	"github.com/varubogu/effisio/backend/internal/model"
 	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/auditsink"
 	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
 // AuditLogService は監査ログ関連のビジネスロジックを提供します
 type AuditLogService struct {
-	repo   *repository.AuditLogRepository
+	repo   auditLogStore
+	tx     transactor
+	writer *AuditLogWriter        // nil の場合は同期的に書き込む
+	sinks  *auditsink.Dispatcher // nil の場合は外部に転送しない
+	holds  AuditHoldProvider     // nil の場合は訴訟ホールドを考慮しない
 	logger *zap.Logger
 }
 
+// transactor は処理をトランザクション内で実行する手段です（repository.TxManager）
+type transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// auditLogStore は監査ログの永続化手段です（repository.AuditLogRepository）
+type auditLogStore interface {
+	Create(ctx context.Context, auditLog *model.AuditLog) error
+	FindByID(ctx context.Context, id uint) (*model.AuditLog, error)
+	FindByFilter(ctx context.Context, filter *model.AuditLogFilter, params *util.PaginationParams) ([]*model.AuditLog, int64, error)
+	DeleteOldLogs(ctx context.Context, days int, holds []*model.AuditHoldScope) error
+	VerifyChain(ctx context.Context) (*model.AuditChainVerification, error)
+	CountByBucket(ctx context.Context, q *model.AuditStatisticsQuery) ([]*model.AuditLogBucketCount, error)
+	TopFailedActors(ctx context.Context, filter *model.AuditLogFilter, limit int) ([]*model.AuditLogActorCount, error)
+	TopFailedIPs(ctx context.Context, filter *model.AuditLogFilter, limit int) ([]*model.AuditLogIPCount, error)
+}
+
 // NewAuditLogService は新しいAuditLogServiceを作成します
-func NewAuditLogService(repo *repository.AuditLogRepository, logger *zap.Logger) *AuditLogService {
-	return &AuditLogService{
+// writer を指定すると、Durable でない監査ログはキューを経由して非同期に書き込みます
+// sinks を指定すると、データベースに書き込んだ監査ログを外部（SIEM等）に転送します（writer の Start より前に作成してください）
+// holds を指定すると、古い監査ログの削除で訴訟ホールドの対象を削除しません
+func NewAuditLogService(repo auditLogStore, txManager transactor, writer *AuditLogWriter, sinks *auditsink.Dispatcher, holds AuditHoldProvider, logger *zap.Logger) *AuditLogService {
+	s := &AuditLogService{
 		repo:   repo,
+		tx:     txManager,
+		writer: writer,
+		sinks:  sinks,
+		holds:  holds,
 		logger: logger,
 	}
+	if writer != nil && sinks != nil {
+		writer.OnWritten(s.publish)
+	}
+	return s
 }
 
 // LogAction はアクションを記録します
 func (s *AuditLogService) LogAction(ctx context.Context, req *model.CreateAuditLogRequest) (*model.AuditLogResponse, error) {
+	auditLog, err := s.newAuditLog(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 非同期の場合、IDと作成日時は書き込み時に採番されるためレスポンスには含まれない（転送は書き込み後に行う）
+	// トランザクション内の場合は、同じトランザクションで書き込むため非同期にしない
+	if s.writer != nil && !req.Durable && !repository.InTransaction(ctx) {
+		if err := s.writer.Enqueue(ctx, auditLog); err != nil {
+			return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+		}
+		return auditLog.ToResponse(), nil
+	}
+
+	if err := s.write(ctx, auditLog); err != nil {
+		return nil, err
+	}
+	// トランザクション内の場合はロールバックされる可能性があるため転送しない
+	if !repository.InTransaction(ctx) {
+		s.publish(auditLog)
+	}
+
+	return auditLog.ToResponse(), nil
+}
+
+// RecordChange は change による変更と、change が返す成功の監査ログを1つのトランザクションで書き込みます
+// 変更と監査ログのどちらかが失敗した場合はロールバックし、failure に失敗内容を設定した監査ログを別のトランザクションで記録します
+// change には受け取ったコンテキストをリポジトリに渡し、トランザクションに参加させてください
+func (s *AuditLogService) RecordChange(ctx context.Context, failure *model.CreateAuditLogRequest, change func(ctx context.Context) (*model.CreateAuditLogRequest, error)) error {
+	var written *model.AuditLog
+	err := s.tx.WithinTransaction(ctx, func(txCtx context.Context) error {
+		success, err := change(txCtx)
+		if err != nil {
+			return err
+		}
+
+		// ロールバックされた場合に記録だけが残らないよう、キューを経由せずにこのトランザクションで書き込む
+		auditLog, err := s.newAuditLog(success)
+		if err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		if err := s.write(txCtx, auditLog); err != nil {
+			return fmt.Errorf("failed to write audit log: %w", err)
+		}
+		written = auditLog
+		return nil
+	})
+	if err == nil {
+		// コミットされた監査ログのみを転送する
+		s.publish(written)
+		return nil
+	}
+
+	if failure != nil {
+		failure.Status = model.AuditStatusFailed
+		failure.ErrorMessage = err.Error()
+		failure.Durable = true
+		s.LogAction(ctx, failure)
+	}
+	return err
+}
+
+// newAuditLog はリクエストを検証し、監査ログモデルを作成します
+func (s *AuditLogService) newAuditLog(req *model.CreateAuditLogRequest) (*model.AuditLog, error) {
 	// リクエストの検証
 	if err := s.validateCreateRequest(req); err != nil {
 		s.logger.Warn("Invalid audit log request", zap.Error(err))
-		return nil, util.NewBadRequestError(util.ErrCodeBadRequest, err)
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, err)
 	}
 
-	// JSONB形式で変更内容を保存
-	changesJSON, err := json.Marshal(req.Changes)
+	// 変更された項目を計算して秘密情報を置き換え、JSONB形式で変更内容を保存
+	changes, err := req.Changes.Prepare()
+	if err != nil {
+		s.logger.Error("Failed to prepare changes", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+	}
+	changesJSON, err := json.Marshal(changes)
 	if err != nil {
 		s.logger.Error("Failed to marshal changes", zap.Error(err))
 		return nil, util.NewInternalError(util.ErrCodeInternalError, err)
 	}
 
-	// 監査ログモデルを作成
-	auditLog := &model.AuditLog{
+	return &model.AuditLog{
 		UserID:       req.UserID,
 		Action:       req.Action,
 		ResourceType: req.ResourceType,
@@ -54,12 +156,14 @@ func (s *AuditLogService) LogAction(ctx context.Context, req *model.CreateAuditL
 		UserAgent:    req.UserAgent,
 		Status:       req.Status,
 		ErrorMessage: req.ErrorMessage,
-	}
+	}, nil
+}
 
-	// データベースに保存
-	if err := s.repo.Create(ctx, auditLog); err != nil {
+// write は監査ログをデータベースに同期的に保存します
+func (s *AuditLogService) write(ctx context.Context, auditLog *model.AuditLog) error {
+	if err := s.create(ctx, auditLog); err != nil {
 		s.logger.Error("Failed to create audit log", zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
 	s.logger.Info("Audit log created",
@@ -68,8 +172,67 @@ func (s *AuditLogService) LogAction(ctx context.Context, req *model.CreateAuditL
 		zap.String("resource_type", auditLog.ResourceType),
 		zap.String("resource_id", auditLog.ResourceID),
 	)
+	return nil
+}
 
-	return auditLog.ToResponse(), nil
+// create は監査ログを同期的に書き込みます
+func (s *AuditLogService) create(ctx context.Context, auditLog *model.AuditLog) error {
+	if s.writer != nil {
+		return s.writer.WriteSync(ctx, auditLog)
+	}
+	return s.repo.Create(ctx, auditLog)
+}
+
+// publish はデータベースに書き込んだ監査ログを外部の転送先に渡します
+func (s *AuditLogService) publish(auditLogs ...*model.AuditLog) {
+	if s.sinks == nil {
+		return
+	}
+	for _, auditLog := range auditLogs {
+		s.sinks.Publish(toSinkEvent(auditLog))
+	}
+}
+
+// toSinkEvent は監査ログを転送用のイベントに変換します
+func toSinkEvent(auditLog *model.AuditLog) *auditsink.Event {
+	return &auditsink.Event{
+		ID:           auditLog.ID,
+		Time:         auditLog.CreatedAt,
+		UserID:       auditLog.UserID,
+		Action:       auditLog.Action,
+		ResourceType: auditLog.ResourceType,
+		ResourceID:   auditLog.ResourceID,
+		Changes:      json.RawMessage(auditLog.Changes),
+		IPAddress:    auditLog.IPAddress,
+		UserAgent:    auditLog.UserAgent,
+		Status:       auditLog.Status,
+		ErrorMessage: auditLog.ErrorMessage,
+		Hash:         auditLog.Hash,
+	}
+}
+
+// SinkStats は外部転送の転送先ごとの統計情報を返します
+func (s *AuditLogService) SinkStats() []*auditsink.Stats {
+	if s.sinks == nil {
+		return []*auditsink.Stats{}
+	}
+	return s.sinks.Stats()
+}
+
+// Flush は非同期書き込みのキューに残っている監査ログを書き込み、完了を待ちます
+func (s *AuditLogService) Flush(ctx context.Context) error {
+	if s.writer == nil {
+		return nil
+	}
+	return s.writer.Flush(ctx)
+}
+
+// WriterStats は非同期書き込みのキューと破棄件数等の統計情報を返します
+func (s *AuditLogService) WriterStats() *AuditLogWriterStats {
+	if s.writer == nil {
+		return &AuditLogWriterStats{Enabled: false}
+	}
+	return s.writer.Stats()
 }
 
 // GetByID はIDで監査ログを取得します
@@ -77,7 +240,7 @@ func (s *AuditLogService) GetByID(ctx context.Context, id uint) (*model.AuditLog
 	auditLog, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, util.NewNotFoundError(util.ErrCodeNotFound, err)
+			return nil, util.NewNotFoundError(util.ErrCodeRecordNotFound, err)
 		}
 		s.logger.Error("Failed to fetch audit log", zap.Uint("id", id), zap.Error(err))
 		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
@@ -86,9 +249,35 @@ func (s *AuditLogService) GetByID(ctx context.Context, id uint) (*model.AuditLog
 	return auditLog.ToResponse(), nil
 }
 
-// List は監査ログ一覧を取得します
-func (s *AuditLogService) List(ctx context.Context, params *util.PaginationParams) (*util.PaginatedResponse, error) {
-	auditLogs, total, err := s.repo.FindAll(ctx, params)
+// RestrictAuditLogFilter は閲覧者の権限に応じて監査ログの検索条件を制限します
+// viewAll が true の場合はすべての監査ログを、それ以外は閲覧者が実行した監査ログのみを対象にします
+// 他のユーザーを指定した場合は403エラーを返します
+func RestrictAuditLogFilter(filter *model.AuditLogFilter, viewerID uint, viewAll bool) error {
+	if viewAll {
+		return nil
+	}
+	if filter.UserID != nil && *filter.UserID != viewerID {
+		return util.NewForbiddenError(util.ErrCodeInsufficientPermission, errors.New("cannot view audit logs of other users"))
+	}
+	filter.UserID = &viewerID
+	return nil
+}
+
+// List は条件に一致する監査ログ一覧を取得します
+// 条件は組み合わせて指定でき、すべての条件に一致する監査ログを返します
+func (s *AuditLogService) List(ctx context.Context, filter *model.AuditLogFilter, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	if filter == nil {
+		filter = &model.AuditLogFilter{}
+	}
+	if filter.From != nil && filter.To != nil && filter.From.After(*filter.To) {
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("from must be before to"))
+	}
+	if len(filter.ChangeMatches)+len(filter.ChangedFields) > model.MaxAuditChangeConditions {
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter,
+			fmt.Errorf("at most %d change conditions can be specified", model.MaxAuditChangeConditions))
+	}
+
+	auditLogs, total, err := s.repo.FindByFilter(ctx, filter, params)
 	if err != nil {
 		s.logger.Error("Failed to fetch audit logs", zap.Error(err))
 		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
@@ -103,140 +292,59 @@ func (s *AuditLogService) List(ctx context.Context, params *util.PaginationParam
 }
 
 // ListByUserID はユーザーIDで監査ログ一覧を取得します
+// Deprecated: List に user_id を指定してください
 func (s *AuditLogService) ListByUserID(ctx context.Context, userID uint, params *util.PaginationParams) (*util.PaginatedResponse, error) {
-	auditLogs, total, err := s.repo.FindByUserID(ctx, userID, params)
-	if err != nil {
-		s.logger.Error("Failed to fetch audit logs by user", zap.Uint("user_id", userID), zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
-	}
-
-	responses := make([]*model.AuditLogResponse, len(auditLogs))
-	for i, log := range auditLogs {
-		responses[i] = log.ToResponse()
-	}
-
-	return util.NewPaginatedResponse(responses, total, params), nil
+	return s.List(ctx, &model.AuditLogFilter{UserID: &userID}, params)
 }
 
 // ListByResource はリソースで監査ログ一覧を取得します
+// Deprecated: List に resource_type と resource_id を指定してください
 func (s *AuditLogService) ListByResource(ctx context.Context, resourceType, resourceID string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	if resourceType == "" || resourceID == "" {
-		return nil, util.NewBadRequestError(util.ErrCodeBadRequest, errors.New("resourceType and resourceID are required"))
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("resourceType and resourceID are required"))
 	}
 
-	auditLogs, total, err := s.repo.FindByResourceID(ctx, resourceType, resourceID, params)
-	if err != nil {
-		s.logger.Error("Failed to fetch audit logs by resource",
-			zap.String("resource_type", resourceType),
-			zap.String("resource_id", resourceID),
-			zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
-	}
-
-	responses := make([]*model.AuditLogResponse, len(auditLogs))
-	for i, log := range auditLogs {
-		responses[i] = log.ToResponse()
-	}
-
-	return util.NewPaginatedResponse(responses, total, params), nil
+	return s.List(ctx, &model.AuditLogFilter{ResourceType: resourceType, ResourceID: resourceID}, params)
 }
 
 // ListByAction はアクションで監査ログ一覧を取得します
+// Deprecated: List に action を指定してください
 func (s *AuditLogService) ListByAction(ctx context.Context, action string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	if action == "" {
-		return nil, util.NewBadRequestError(util.ErrCodeBadRequest, errors.New("action is required"))
-	}
-
-	auditLogs, total, err := s.repo.FindByAction(ctx, action, params)
-	if err != nil {
-		s.logger.Error("Failed to fetch audit logs by action",
-			zap.String("action", action),
-			zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("action is required"))
 	}
 
-	responses := make([]*model.AuditLogResponse, len(auditLogs))
-	for i, log := range auditLogs {
-		responses[i] = log.ToResponse()
-	}
-
-	return util.NewPaginatedResponse(responses, total, params), nil
+	return s.List(ctx, &model.AuditLogFilter{Actions: []string{action}}, params)
 }
 
 // ListByDateRange は日付範囲で監査ログ一覧を取得します
+// Deprecated: List に from と to を指定してください
 func (s *AuditLogService) ListByDateRange(ctx context.Context, startDate, endDate time.Time, params *util.PaginationParams) (*util.PaginatedResponse, error) {
 	if startDate.After(endDate) {
-		return nil, util.NewBadRequestError(util.ErrCodeBadRequest, errors.New("startDate must be before endDate"))
-	}
-
-	auditLogs, total, err := s.repo.FindByDateRange(ctx, startDate, endDate, params)
-	if err != nil {
-		s.logger.Error("Failed to fetch audit logs by date range",
-			zap.Time("start_date", startDate),
-			zap.Time("end_date", endDate),
-			zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
-	}
-
-	responses := make([]*model.AuditLogResponse, len(auditLogs))
-	for i, log := range auditLogs {
-		responses[i] = log.ToResponse()
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("startDate must be before endDate"))
 	}
 
-	return util.NewPaginatedResponse(responses, total, params), nil
-}
-
-// GetStatistics は監査ログの統計情報を取得します
-type AuditStatistics struct {
-	TotalLogs     int64            `json:"total_logs"`
-	ByAction      map[string]int64 `json:"by_action"`
-	ByStatus      map[string]int64 `json:"by_status"`
-	SuccessRate   float64          `json:"success_rate"`
+	return s.List(ctx, &model.AuditLogFilter{From: &startDate, To: &endDate}, params)
 }
 
-// GetStatistics は監査ログの統計情報を取得します
-func (s *AuditLogService) GetStatistics(ctx context.Context) (*AuditStatistics, error) {
-	// アクション別集計
-	byAction, err := s.repo.CountByAction(ctx)
-	if err != nil {
-		s.logger.Error("Failed to get action statistics", zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
-	}
-
-	// ステータス別集計
-	byStatus, err := s.repo.CountByStatus(ctx)
-	if err != nil {
-		s.logger.Error("Failed to get status statistics", zap.Error(err))
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+// DeleteOldLogs は古い監査ログを削除します（訴訟ホールドの対象は削除しません）
+func (s *AuditLogService) DeleteOldLogs(ctx context.Context, days int) error {
+	if days < 1 {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("days must be at least 1"))
 	}
 
-	// 成功率を計算
-	successRate := 0.0
-	total := int64(0)
-	for _, count := range byStatus {
-		total += count
-	}
-	if total > 0 {
-		if successCount, ok := byStatus[model.AuditStatusSuccess]; ok {
-			successRate = float64(successCount) / float64(total)
+	// ホールドを取得できない場合は、保持すべき監査ログを削除しないよう中止する
+	var holds []*model.AuditHoldScope
+	if s.holds != nil {
+		var err error
+		holds, err = s.holds.AuditHoldScopes(ctx)
+		if err != nil {
+			s.logger.Error("Failed to fetch legal holds for deleting old audit logs", zap.Error(err))
+			return util.NewInternalError(util.ErrCodeDatabaseError, err)
 		}
 	}
 
-	return &AuditStatistics{
-		TotalLogs:   total,
-		ByAction:    byAction,
-		ByStatus:    byStatus,
-		SuccessRate: successRate,
-	}, nil
-}
-
-// DeleteOldLogs は古い監査ログを削除します
-func (s *AuditLogService) DeleteOldLogs(ctx context.Context, days int) error {
-	if days < 1 {
-		return util.NewBadRequestError(util.ErrCodeBadRequest, errors.New("days must be at least 1"))
-	}
-
-	if err := s.repo.DeleteOldLogs(ctx, days); err != nil {
+	if err := s.repo.DeleteOldLogs(ctx, days, holds); err != nil {
 		s.logger.Error("Failed to delete old audit logs", zap.Int("days", days), zap.Error(err))
 		return util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
@@ -245,6 +353,43 @@ func (s *AuditLogService) DeleteOldLogs(ctx context.Context, days int) error {
 	return nil
 }
 
+// VerifyChain は監査ログのハッシュチェーンを検証し、最初に見つかった不整合を報告します
+// 検証の実施と結果も監査ログに記録します
+func (s *AuditLogService) VerifyChain(ctx context.Context, actorID uint) (*model.AuditChainVerification, error) {
+	result, err := s.repo.VerifyChain(ctx)
+	if err != nil {
+		s.logger.Error("Failed to verify audit log chain", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionVerify,
+		ResourceType: model.ResourceTypeAuditLog,
+		ResourceID:   "chain",
+		Changes: model.AuditLogChanges{
+			Before: map[string]interface{}{},
+			After: map[string]interface{}{
+				"checked_count": result.CheckedCount,
+				"head_id":       result.HeadID,
+				"head_hash":     result.HeadHash,
+			},
+		},
+		Status:  model.AuditStatusSuccess,
+		Durable: true,
+	}
+	if !result.Valid {
+		s.logger.Error("Audit log chain is broken",
+			zap.Uint("id", result.BrokenLink.ID),
+			zap.String("reason", result.BrokenLink.Reason))
+		auditReq.Status = model.AuditStatusFailed
+		auditReq.ErrorMessage = fmt.Sprintf("chain broken at id %d: %s", result.BrokenLink.ID, result.BrokenLink.Reason)
+	}
+	s.LogAction(ctx, auditReq)
+
+	return result, nil
+}
+
 // validateCreateRequest はリクエストを検証します
 func (s *AuditLogService) validateCreateRequest(req *model.CreateAuditLogRequest) error {
 	if req.UserID == 0 {
@@ -269,12 +414,31 @@ func (s *AuditLogService) validateCreateRequest(req *model.CreateAuditLogRequest
 
 	// アクションの有効性チェック
 	validActions := map[string]bool{
-		model.ActionCreate: true,
-		model.ActionRead:   true,
-		model.ActionUpdate: true,
-		model.ActionDelete: true,
-		model.ActionLogin:  true,
-		model.ActionLogout: true,
+		model.ActionCreate:       true,
+		model.ActionRead:         true,
+		model.ActionUpdate:       true,
+		model.ActionDelete:       true,
+		model.ActionLogin:        true,
+		model.ActionLogout:       true,
+		model.ActionRestore:      true,
+		model.ActionPurge:        true,
+		model.ActionExport:       true,
+		model.ActionAnonymize:    true,
+		model.ActionInvite:       true,
+		model.ActionAccept:       true,
+		model.ActionResend:       true,
+		model.ActionRevoke:       true,
+		model.ActionVerify:       true,
+		model.ActionAddMember:    true,
+		model.ActionRemoveMember: true,
+		model.ActionArchive:      true,
+		model.ActionImport:       true,
+		model.ActionDetach:       true,
+		model.ActionAccessDenied: true,
+		model.ActionHold:         true,
+		model.ActionRelease:      true,
+		model.ActionAcknowledge:  true,
+		model.ActionLockout:      true,
 	}
 	if !validActions[req.Action] {
 		return errors.New("invalid action")
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// AuditPartitionConfig は監査ログのパーティションの管理に関する設定です
+type AuditPartitionConfig struct {
+	PremakeMonths   int  // 当月に加えて事前に作成する月数
+	RetentionMonths int  // 当月より前にこの月数を過ぎたパーティションを切り離す（0以下の場合は切り離さない）
+	DropDetached    bool // 切り離したパーティションを削除するか
+}
+
+// AuditPartitionService は監査ログの月ごとのパーティションを管理します
+// 将来の月のパーティションを事前に作成し、保持期間を過ぎたパーティションを切り離します
+type AuditPartitionService struct {
+	auditLogRepo    *repository.AuditLogRepository
+	holds           AuditHoldProvider // nil の場合は訴訟ホールドを考慮しない
+	config          AuditPartitionConfig
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+	now             func() time.Time
+}
+
+// NewAuditPartitionService は新しいAuditPartitionServiceを作成します
+func NewAuditPartitionService(
+	auditLogRepo *repository.AuditLogRepository,
+	holds AuditHoldProvider,
+	config AuditPartitionConfig,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *AuditPartitionService {
+	if config.PremakeMonths < 0 {
+		config.PremakeMonths = 0
+	}
+	return &AuditPartitionService{
+		auditLogRepo:    auditLogRepo,
+		holds:           holds,
+		config:          config,
+		logger:          logger,
+		auditLogService: auditLogService,
+		now:             time.Now,
+	}
+}
+
+// List はパーティションを古い順に取得します
+func (s *AuditPartitionService) List(ctx context.Context) ([]*model.AuditLogPartition, error) {
+	partitions, err := s.auditLogRepo.ListPartitions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch audit log partitions", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return partitions, nil
+}
+
+// Maintain は当月から PremakeMonths か月先までのパーティションを作成し、保持期間を過ぎたパーティションを古い順に切り離します
+// 訴訟ホールドの対象を含むパーティションに達した場合は、それ以降のパーティションを切り離しません
+func (s *AuditPartitionService) Maintain(ctx context.Context, actorID uint) (*model.AuditPartitionResult, error) {
+	now := s.now()
+	result := &model.AuditPartitionResult{
+		RanAt:    now,
+		Created:  []string{},
+		Detached: []string{},
+		Dropped:  s.config.DropDetached,
+	}
+
+	// ホールドを取得できない場合は、保持すべき監査ログを切り離さないよう中止する
+	var holds []*model.AuditHoldScope
+	if s.holds != nil && s.config.RetentionMonths > 0 {
+		var err error
+		holds, err = s.holds.AuditHoldScopes(ctx)
+		if err != nil {
+			s.logger.Error("Failed to fetch legal holds for audit log partitions", zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+	}
+
+	if err := s.maintain(ctx, actorID, result, holds); err != nil {
+		s.logger.Error("Failed to maintain audit log partitions", zap.Error(err))
+		result.Error = err.Error()
+	}
+
+	s.logger.Info("Audit log partitions maintained",
+		zap.Strings("created", result.Created),
+		zap.Strings("detached", result.Detached),
+		zap.String("held", result.Held),
+	)
+	return result, nil
+}
+
+// maintain はパーティションの作成と切り離しを順に行い、最初のエラーで中止します
+func (s *AuditPartitionService) maintain(ctx context.Context, actorID uint, result *model.AuditPartitionResult, holds []*model.AuditHoldScope) error {
+	month := model.AuditPartitionMonth(result.RanAt)
+	for i := 0; i <= s.config.PremakeMonths; i++ {
+		target := month.AddDate(0, i, 0)
+		created, err := s.auditLogRepo.CreatePartition(ctx, target)
+		if err != nil {
+			return fmt.Errorf("failed to create audit log partition for %s: %w", target.Format("2006-01"), err)
+		}
+		if created {
+			result.Created = append(result.Created, target.Format("2006-01"))
+		}
+	}
+
+	if s.config.RetentionMonths <= 0 {
+		return nil
+	}
+
+	cutoff := month.AddDate(0, -s.config.RetentionMonths, 0)
+	result.Cutoff = &cutoff
+	partitions, err := s.auditLogRepo.ListPartitions(ctx)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		if partition.Default || partition.To == nil || partition.To.After(cutoff) {
+			continue
+		}
+
+		detached, err := s.auditLogRepo.DetachPartition(ctx, partition.Name, holds, s.config.DropDetached)
+		if errors.Is(err, repository.ErrPartitionHeld) {
+			s.logger.Warn("Audit log partition contains logs under legal hold, keeping it", zap.String("partition", partition.Name))
+			result.Held = partition.Name
+			return nil
+		}
+		if err != nil {
+			s.logDetach(ctx, actorID, partition, 0, err)
+			return fmt.Errorf("failed to detach audit log partition %s: %w", partition.Name, err)
+		}
+
+		s.logger.Info("Audit log partition detached",
+			zap.String("partition", partition.Name),
+			zap.Int64("rows", detached),
+			zap.Bool("dropped", s.config.DropDetached),
+		)
+		result.Detached = append(result.Detached, partition.Name)
+		s.logDetach(ctx, actorID, partition, detached, nil)
+	}
+	return nil
+}
+
+// logDetach はパーティションの切り離しを監査ログに記録します
+func (s *AuditPartitionService) logDetach(ctx context.Context, actorID uint, partition *model.AuditLogPartition, detached int64, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionDetach,
+		ResourceType: model.ResourceTypeAuditLogPartition,
+		ResourceID:   partition.Name,
+		Changes: model.AuditLogChanges{
+			Before: map[string]interface{}{
+				"from": partition.From,
+				"to":   partition.To,
+			},
+			After: map[string]interface{}{
+				"detached": detached,
+				"dropped":  s.config.DropDetached,
+			},
+		},
+		Status:  model.AuditStatusSuccess,
+		Durable: true,
+	}
+	if err != nil {
+		auditReq.Status = model.AuditStatusFailed
+		auditReq.ErrorMessage = err.Error()
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+const (
+	// maxAuditStatisticsBuckets は1回の集計で返す集計単位の最大数です
+	maxAuditStatisticsBuckets = 1000
+	// maxAuditStatisticsSeries は返す系列の最大数です（超えた分は件数の少ない系列から省略）
+	maxAuditStatisticsSeries = 100
+	// defaultAuditStatisticsTopN は失敗した操作の上位として返すデフォルトの件数です
+	defaultAuditStatisticsTopN = 10
+	// MaxAuditStatisticsTopN は失敗した操作の上位として返す最大の件数です
+	MaxAuditStatisticsTopN = 100
+)
+
+// defaultAuditStatisticsRanges は期間を指定しない場合に集計する期間です（集計単位ごと）
+var defaultAuditStatisticsRanges = map[string]time.Duration{
+	model.AuditBucketHour: 24 * time.Hour,
+	model.AuditBucketDay:  30 * 24 * time.Hour,
+	model.AuditBucketWeek: 12 * 7 * 24 * time.Hour,
+}
+
+// AuditStatistics は監査ログの統計情報です
+// Series の Counts は Buckets と同じ順序で、該当する監査ログがない集計単位は0になります
+type AuditStatistics struct {
+	From            time.Time                   `json:"from"`
+	To              time.Time                   `json:"to"`
+	Bucket          string                      `json:"bucket"`
+	Timezone        string                      `json:"timezone"`
+	GroupBy         []string                    `json:"group_by"`
+	TotalLogs       int64                       `json:"total_logs"`
+	ByAction        map[string]int64            `json:"by_action"`
+	ByStatus        map[string]int64            `json:"by_status"`
+	SuccessRate     float64                     `json:"success_rate"`
+	Buckets         []time.Time                 `json:"buckets"`
+	Series          []*AuditStatisticsSeries    `json:"series"`
+	Truncated       bool                        `json:"truncated"` // 系列の数が上限を超えて省略した場合は true
+	TopFailedActors []*model.AuditLogActorCount `json:"top_failed_actors"`
+	TopFailedIPs    []*model.AuditLogIPCount    `json:"top_failed_ips"`
+}
+
+// AuditStatisticsSeries は内訳ごとの件数の推移です
+type AuditStatisticsSeries struct {
+	Group  map[string]string `json:"group"` // 内訳の項目と値（actor はユーザーID）
+	Total  int64             `json:"total"`
+	Counts []int64           `json:"counts"`
+}
+
+// GetStatistics は期間内の監査ログを集計単位と内訳ごとに集計します
+// 期間を指定しない場合は、集計単位に応じた直近の期間（hour: 24時間、day: 30日、week: 12週）を集計します
+func (s *AuditLogService) GetStatistics(ctx context.Context, q *model.AuditStatisticsQuery) (*AuditStatistics, error) {
+	if err := normalizeAuditStatisticsQuery(q, time.Now()); err != nil {
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, err)
+	}
+
+	buckets, err := auditStatisticsBuckets(*q.Filter.From, *q.Filter.To, q.Bucket, q.Location)
+	if err != nil {
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, err)
+	}
+
+	rows, err := s.repo.CountByBucket(ctx, q)
+	if err != nil {
+		s.logger.Error("Failed to aggregate audit logs", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	topActors, err := s.repo.TopFailedActors(ctx, q.Filter, q.TopN)
+	if err != nil {
+		s.logger.Error("Failed to aggregate failed audit logs by actor", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	topIPs, err := s.repo.TopFailedIPs(ctx, q.Filter, q.TopN)
+	if err != nil {
+		s.logger.Error("Failed to aggregate failed audit logs by IP address", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	stats := buildAuditStatistics(rows, buckets, q.GroupBy, q.Location)
+	stats.From = q.Filter.From.In(q.Location)
+	stats.To = q.Filter.To.In(q.Location)
+	stats.Bucket = q.Bucket
+	stats.Timezone = q.Location.String()
+	stats.GroupBy = q.GroupBy
+	stats.TopFailedActors = topActors
+	stats.TopFailedIPs = topIPs
+	return stats, nil
+}
+
+// normalizeAuditStatisticsQuery は統計の条件を検証し、未指定の項目にデフォルト値を設定します
+func normalizeAuditStatisticsQuery(q *model.AuditStatisticsQuery, now time.Time) error {
+	if q.Filter == nil {
+		q.Filter = &model.AuditLogFilter{}
+	}
+	if q.Bucket == "" {
+		q.Bucket = model.AuditBucketDay
+	}
+	if err := model.ValidateAuditBucket(q.Bucket); err != nil {
+		return err
+	}
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+
+	if q.Filter.To == nil {
+		q.Filter.To = &now
+	}
+	if q.Filter.From == nil {
+		from := q.Filter.To.Add(-defaultAuditStatisticsRanges[q.Bucket])
+		q.Filter.From = &from
+	}
+	if q.Filter.From.After(*q.Filter.To) {
+		return errors.New("from must be before to")
+	}
+	if len(q.Filter.ChangeMatches)+len(q.Filter.ChangedFields) > model.MaxAuditChangeConditions {
+		return fmt.Errorf("at most %d change conditions can be specified", model.MaxAuditChangeConditions)
+	}
+
+	seen := make(map[string]bool, len(q.GroupBy))
+	for _, dimension := range q.GroupBy {
+		if _, err := model.AuditDimensionColumn(dimension); err != nil {
+			return err
+		}
+		if seen[dimension] {
+			return fmt.Errorf("duplicate group_by dimension: %s", dimension)
+		}
+		seen[dimension] = true
+	}
+
+	if q.TopN <= 0 {
+		q.TopN = defaultAuditStatisticsTopN
+	}
+	if q.TopN > MaxAuditStatisticsTopN {
+		q.TopN = MaxAuditStatisticsTopN
+	}
+	return nil
+}
+
+// auditStatisticsBuckets は期間に含まれる集計単位の開始日時を古い順に返します
+func auditStatisticsBuckets(from, to time.Time, bucket string, loc *time.Location) ([]time.Time, error) {
+	var buckets []time.Time
+	for start := model.TruncateAuditBucket(from, bucket, loc); !start.After(to); start = model.NextAuditBucket(start, bucket, loc) {
+		if len(buckets) == maxAuditStatisticsBuckets {
+			return nil, fmt.Errorf("too many buckets (at most %d), narrow the range or use a larger bucket", maxAuditStatisticsBuckets)
+		}
+		buckets = append(buckets, start)
+	}
+	return buckets, nil
+}
+
+// buildAuditStatistics は集計結果を内訳ごとの系列にまとめ、該当しない集計単位を0で埋めます
+func buildAuditStatistics(rows []*model.AuditLogBucketCount, buckets []time.Time, groupBy []string, loc *time.Location) *AuditStatistics {
+	stats := &AuditStatistics{
+		ByAction: make(map[string]int64),
+		ByStatus: make(map[string]int64),
+		Buckets:  buckets,
+	}
+
+	index := make(map[int64]int, len(buckets))
+	for i, bucket := range buckets {
+		index[bucket.Unix()] = i
+	}
+
+	seriesByKey := make(map[string]*AuditStatisticsSeries)
+	keys := make(map[*AuditStatisticsSeries]string)
+	for _, row := range rows {
+		stats.TotalLogs += row.Count
+		stats.ByAction[row.Action] += row.Count
+		stats.ByStatus[row.Status] += row.Count
+
+		// データベースはタイムゾーンの壁時計の時刻を返すため、同じタイムゾーンの日時として解釈する
+		b := row.Bucket
+		i, ok := index[time.Date(b.Year(), b.Month(), b.Day(), b.Hour(), 0, 0, 0, loc).Unix()]
+		if !ok {
+			continue
+		}
+
+		group := auditStatisticsGroup(row, groupBy)
+		key := auditStatisticsKey(group, groupBy)
+		series, ok := seriesByKey[key]
+		if !ok {
+			series = &AuditStatisticsSeries{Group: group, Counts: make([]int64, len(buckets))}
+			seriesByKey[key] = series
+			keys[series] = key
+			stats.Series = append(stats.Series, series)
+		}
+		series.Counts[i] += row.Count
+		series.Total += row.Count
+	}
+
+	if total := stats.TotalLogs; total > 0 {
+		stats.SuccessRate = float64(stats.ByStatus[model.AuditStatusSuccess]) / float64(total)
+	}
+
+	sort.Slice(stats.Series, func(i, j int) bool {
+		if stats.Series[i].Total != stats.Series[j].Total {
+			return stats.Series[i].Total > stats.Series[j].Total
+		}
+		return keys[stats.Series[i]] < keys[stats.Series[j]]
+	})
+	if len(stats.Series) > maxAuditStatisticsSeries {
+		stats.Series = stats.Series[:maxAuditStatisticsSeries]
+		stats.Truncated = true
+	}
+	if stats.Series == nil {
+		stats.Series = []*AuditStatisticsSeries{}
+	}
+	return stats
+}
+
+// auditStatisticsGroup は集計結果の行から内訳の項目と値を取り出します
+func auditStatisticsGroup(row *model.AuditLogBucketCount, groupBy []string) map[string]string {
+	group := make(map[string]string, len(groupBy))
+	for _, dimension := range groupBy {
+		switch dimension {
+		case model.AuditDimensionAction:
+			group[dimension] = row.Action
+		case model.AuditDimensionResourceType:
+			group[dimension] = row.ResourceType
+		case model.AuditDimensionStatus:
+			group[dimension] = row.Status
+		case model.AuditDimensionActor:
+			group[dimension] = strconv.FormatUint(uint64(row.UserID), 10)
+		}
+	}
+	return group
+}
+
+// auditStatisticsKey は内訳の値から系列を識別するキーを作成します
+func auditStatisticsKey(group map[string]string, groupBy []string) string {
+	values := make([]string, len(groupBy))
+	for i, dimension := range groupBy {
+		values[i] = group[dimension]
+	}
+	return strings.Join(values, "\x00")
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestAuditStatisticsBuckets(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		from   time.Time
+		to     time.Time
+		bucket string
+		loc    *time.Location
+		want   []string
+	}{
+		{
+			name:   "Hourly",
+			from:   time.Date(2026, 10, 1, 10, 30, 0, 0, time.UTC),
+			to:     time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+			bucket: model.AuditBucketHour,
+			loc:    time.UTC,
+			want:   []string{"2026-10-01T10:00:00Z", "2026-10-01T11:00:00Z", "2026-10-01T12:00:00Z"},
+		},
+		{
+			name:   "Daily in time zone",
+			from:   time.Date(2026, 10, 1, 16, 0, 0, 0, time.UTC), // 2026-10-02 01:00 JST
+			to:     time.Date(2026, 10, 3, 14, 0, 0, 0, time.UTC), // 2026-10-03 23:00 JST
+			bucket: model.AuditBucketDay,
+			loc:    tokyo,
+			want:   []string{"2026-10-02T00:00:00+09:00", "2026-10-03T00:00:00+09:00"},
+		},
+		{
+			name:   "Weekly starts on Monday",
+			from:   time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), // 日曜日
+			to:     time.Date(2026, 10, 20, 0, 0, 0, 0, time.UTC),
+			bucket: model.AuditBucketWeek,
+			loc:    time.UTC,
+			want:   []string{"2026-10-12T00:00:00Z", "2026-10-19T00:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buckets, err := auditStatisticsBuckets(tt.from, tt.to, tt.bucket, tt.loc)
+			require.NoError(t, err)
+
+			got := make([]string, len(buckets))
+			for i, bucket := range buckets {
+				got[i] = bucket.Format(time.RFC3339)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAuditStatisticsBuckets_TooMany(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := auditStatisticsBuckets(from, from.AddDate(1, 0, 0), model.AuditBucketHour, time.UTC)
+	assert.Error(t, err)
+}
+
+func TestBuildAuditStatistics(t *testing.T) {
+	buckets := []time.Time{
+		time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 3, 0, 0, 0, 0, time.UTC),
+	}
+	rows := []*model.AuditLogBucketCount{
+		{Bucket: buckets[0], Action: "login", Status: "success", Count: 5},
+		{Bucket: buckets[0], Action: "login", Status: "failed", Count: 2},
+		{Bucket: buckets[2], Action: "update", Status: "success", Count: 3},
+		{Bucket: buckets[2], Action: "login", Status: "success", Count: 1},
+	}
+
+	stats := buildAuditStatistics(rows, buckets, []string{model.AuditDimensionAction}, time.UTC)
+
+	assert.Equal(t, int64(11), stats.TotalLogs)
+	assert.Equal(t, map[string]int64{"login": 8, "update": 3}, stats.ByAction)
+	assert.Equal(t, map[string]int64{"success": 9, "failed": 2}, stats.ByStatus)
+	assert.InDelta(t, 9.0/11.0, stats.SuccessRate, 1e-9)
+	assert.False(t, stats.Truncated)
+
+	// 件数の多い順に並び、該当のない集計単位は0で埋められる
+	require.Len(t, stats.Series, 2)
+	assert.Equal(t, map[string]string{"action": "login"}, stats.Series[0].Group)
+	assert.Equal(t, int64(8), stats.Series[0].Total)
+	assert.Equal(t, []int64{7, 0, 1}, stats.Series[0].Counts)
+	assert.Equal(t, map[string]string{"action": "update"}, stats.Series[1].Group)
+	assert.Equal(t, []int64{0, 0, 3}, stats.Series[1].Counts)
+}
+
+func TestBuildAuditStatistics_NoGroupBy(t *testing.T) {
+	buckets := []time.Time{
+		time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC),
+	}
+
+	stats := buildAuditStatistics(nil, buckets, nil, time.UTC)
+	assert.Equal(t, int64(0), stats.TotalLogs)
+	assert.Equal(t, 0.0, stats.SuccessRate)
+	assert.NotNil(t, stats.Series)
+	assert.Empty(t, stats.Series)
+
+	rows := []*model.AuditLogBucketCount{
+		{Bucket: buckets[1], Action: "login", Status: "success", UserID: 1, Count: 4},
+		{Bucket: buckets[1], Action: "logout", Status: "success", UserID: 2, Count: 1},
+	}
+	stats = buildAuditStatistics(rows, buckets, nil, time.UTC)
+	require.Len(t, stats.Series, 1)
+	assert.Empty(t, stats.Series[0].Group)
+	assert.Equal(t, []int64{0, 5}, stats.Series[0].Counts)
+}
+
+func TestBuildAuditStatistics_WallClockBucket(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	buckets := []time.Time{time.Date(2026, 10, 2, 0, 0, 0, 0, tokyo)}
+	// データベースはタイムゾーンの壁時計の時刻をタイムゾーンなしで返す
+	rows := []*model.AuditLogBucketCount{
+		{Bucket: time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), Action: "login", Status: "success", UserID: 7, Count: 2},
+	}
+
+	stats := buildAuditStatistics(rows, buckets, []string{model.AuditDimensionActor}, tokyo)
+	require.Len(t, stats.Series, 1)
+	assert.Equal(t, map[string]string{"actor": "7"}, stats.Series[0].Group)
+	assert.Equal(t, []int64{2}, stats.Series[0].Counts)
+}
+
+func TestNormalizeAuditStatisticsQuery(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+	q := &model.AuditStatisticsQuery{}
+	require.NoError(t, normalizeAuditStatisticsQuery(q, now))
+	assert.Equal(t, model.AuditBucketDay, q.Bucket)
+	assert.Equal(t, time.UTC, q.Location)
+	assert.Equal(t, now, *q.Filter.To)
+	assert.Equal(t, now.AddDate(0, 0, -30), *q.Filter.From)
+	assert.Equal(t, defaultAuditStatisticsTopN, q.TopN)
+
+	q = &model.AuditStatisticsQuery{Bucket: model.AuditBucketHour, TopN: 1000}
+	require.NoError(t, normalizeAuditStatisticsQuery(q, now))
+	assert.Equal(t, now.Add(-24*time.Hour), *q.Filter.From)
+	assert.Equal(t, MaxAuditStatisticsTopN, q.TopN)
+}
+
+func TestNormalizeAuditStatisticsQuery_Invalid(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		query *model.AuditStatisticsQuery
+	}{
+		{"Unknown bucket", &model.AuditStatisticsQuery{Bucket: "month"}},
+		{"Unknown dimension", &model.AuditStatisticsQuery{GroupBy: []string{"ip_address"}}},
+		{"Duplicate dimension", &model.AuditStatisticsQuery{GroupBy: []string{"action", "action"}}},
+		{"From after to", &model.AuditStatisticsQuery{Filter: &model.AuditLogFilter{From: &later, To: &now}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, normalizeAuditStatisticsQuery(tt.query, now))
+		})
+	}
+}
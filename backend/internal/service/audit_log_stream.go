@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+var (
+	// ErrTooManyStreams は同時接続数の上限に達していることを表します
+	ErrTooManyStreams = errors.New("too many audit log streams")
+	// ErrStreamsClosed はシャットダウン中のため購読できないことを表します
+	ErrStreamsClosed = errors.New("audit log streams are closed")
+)
+
+// AuditLogBroker は監査ログが追加されたことを購読中のストリームに知らせます
+// 通知は内容を持たず、受け取ったストリームがデータベースから新しい監査ログを取得します
+// 購読者ごとの通知は1件にまとめられるため、受信が遅いクライアントがいても通知側は待たされません
+type AuditLogBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+	maxClients  int
+	closed      bool
+}
+
+// NewAuditLogBroker は新しいAuditLogBrokerを作成します
+// maxClients が0以下の場合は同時接続数を制限しません
+func NewAuditLogBroker(maxClients int) *AuditLogBroker {
+	return &AuditLogBroker{
+		subscribers: make(map[chan struct{}]struct{}),
+		maxClients:  maxClients,
+	}
+}
+
+// Subscribe は通知の購読を開始し、通知を受け取るチャネルと購読を終了する関数を返します
+// Close された場合はチャネルが閉じられます
+func (b *AuditLogBroker) Subscribe() (<-chan struct{}, func(), error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, nil, ErrStreamsClosed
+	}
+	if b.maxClients > 0 && len(b.subscribers) >= b.maxClients {
+		return nil, nil, ErrTooManyStreams
+	}
+
+	ch := make(chan struct{}, 1)
+	b.subscribers[ch] = struct{}{}
+
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, unsubscribe, nil
+}
+
+// Notify は購読中のすべてのストリームに監査ログの追加を知らせます
+// 前回の通知を処理していないストリームには新たに送らず、まとめて処理させます
+func (b *AuditLogBroker) Notify() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Len は購読中のストリームの数を返します
+func (b *AuditLogBroker) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+// Close はすべての購読を終了し、以降の購読を拒否します（シャットダウン時に使用）
+func (b *AuditLogBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
+
+// AuditLogStreamService は監査ログのストリーム配信に関するビジネスロジックを提供します
+type AuditLogStreamService struct {
+	repo      *repository.AuditLogRepository
+	broker    *AuditLogBroker
+	batchSize int
+	logger    *zap.Logger
+}
+
+// NewAuditLogStreamService は新しいAuditLogStreamServiceを作成します
+func NewAuditLogStreamService(repo *repository.AuditLogRepository, broker *AuditLogBroker, batchSize int, logger *zap.Logger) *AuditLogStreamService {
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &AuditLogStreamService{
+		repo:      repo,
+		broker:    broker,
+		batchSize: batchSize,
+		logger:    logger,
+	}
+}
+
+// BatchSize は1回の取得で返す最大件数です
+func (s *AuditLogStreamService) BatchSize() int {
+	return s.batchSize
+}
+
+// Authorize は閲覧者の権限に応じて検索条件を制限します
+// 閲覧できる範囲は一覧取得と同じです（RestrictAuditLogFilter）
+func (s *AuditLogStreamService) Authorize(filter *model.AuditLogFilter, viewerID uint, viewAll bool) error {
+	if len(filter.ChangeMatches)+len(filter.ChangedFields) > model.MaxAuditChangeConditions {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("too many change conditions"))
+	}
+	return RestrictAuditLogFilter(filter, viewerID, viewAll)
+}
+
+// StartID はストリームを開始する位置を返します
+// lastEventID を指定した場合はその続きから、指定しない場合は接続以降に追加された監査ログから送信します
+func (s *AuditLogStreamService) StartID(ctx context.Context, lastEventID *uint) (uint, error) {
+	if lastEventID != nil {
+		return *lastEventID, nil
+	}
+
+	id, err := s.repo.LatestID(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch latest audit log ID", zap.Error(err))
+		return 0, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return id, nil
+}
+
+// Subscribe は監査ログの追加の通知を購読します
+// 同時接続数の上限に達している場合は503エラーを返します
+func (s *AuditLogStreamService) Subscribe() (<-chan struct{}, func(), error) {
+	wake, unsubscribe, err := s.broker.Subscribe()
+	if err != nil {
+		return nil, nil, util.NewServiceUnavailableError(util.ErrCodeServiceUnavailable, err)
+	}
+	return wake, unsubscribe, nil
+}
+
+// Next は afterID より新しく条件に一致する監査ログを古い順に最大 BatchSize 件取得します
+func (s *AuditLogStreamService) Next(ctx context.Context, filter *model.AuditLogFilter, afterID uint) ([]*model.AuditLogResponse, error) {
+	auditLogs, err := s.repo.FindAfter(ctx, filter, afterID, s.batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*model.AuditLogResponse, len(auditLogs))
+	for i, log := range auditLogs {
+		responses[i] = log.ToResponse()
+	}
+	return responses, nil
+}
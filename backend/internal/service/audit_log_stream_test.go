@@ -0,0 +1,128 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+func TestAuditLogBroker_NotifyCoalesces(t *testing.T) {
+	broker := NewAuditLogBroker(0)
+	wake, unsubscribe, err := broker.Subscribe()
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	// 受信していない間の通知は1件にまとめられ、通知側はブロックしない
+	broker.Notify()
+	broker.Notify()
+	broker.Notify()
+
+	assert.Len(t, wake, 1)
+	<-wake
+	assert.Len(t, wake, 0)
+}
+
+func TestAuditLogBroker_MaxClients(t *testing.T) {
+	broker := NewAuditLogBroker(1)
+	_, unsubscribe, err := broker.Subscribe()
+	require.NoError(t, err)
+
+	_, _, err = broker.Subscribe()
+	assert.True(t, errors.Is(err, ErrTooManyStreams))
+
+	// 購読を終了すると再び接続できる
+	unsubscribe()
+	unsubscribe() // 二重に呼んでも問題ない
+	_, _, err = broker.Subscribe()
+	assert.NoError(t, err)
+}
+
+func TestAuditLogBroker_Close(t *testing.T) {
+	broker := NewAuditLogBroker(0)
+	wake, unsubscribe, err := broker.Subscribe()
+	require.NoError(t, err)
+
+	broker.Close()
+
+	_, ok := <-wake
+	assert.False(t, ok)
+	assert.Equal(t, 0, broker.Len())
+	unsubscribe() // Close 後に呼んでも問題ない
+
+	_, _, err = broker.Subscribe()
+	assert.True(t, errors.Is(err, ErrStreamsClosed))
+}
+
+func TestAuditLogStreamService_Authorize(t *testing.T) {
+	service := NewAuditLogStreamService(nil, NewAuditLogBroker(0), 0, zap.NewNop())
+	uintPtr := func(v uint) *uint { return &v }
+
+	tests := []struct {
+		name       string
+		filter     *model.AuditLogFilter
+		viewerID   uint
+		viewAll    bool
+		wantUserID *uint
+		wantCode   string
+	}{
+		{
+			name:     "Permission holder sees all users",
+			filter:   &model.AuditLogFilter{},
+			viewerID: 1,
+			viewAll:  true,
+		},
+		{
+			name:       "Permission holder can filter by other user",
+			filter:     &model.AuditLogFilter{UserID: uintPtr(2)},
+			viewerID:   1,
+			viewAll:    true,
+			wantUserID: uintPtr(2),
+		},
+		{
+			name:       "User is limited to own logs",
+			filter:     &model.AuditLogFilter{},
+			viewerID:   3,
+			wantUserID: uintPtr(3),
+		},
+		{
+			name:       "User can filter by self",
+			filter:     &model.AuditLogFilter{UserID: uintPtr(3)},
+			viewerID:   3,
+			wantUserID: uintPtr(3),
+		},
+		{
+			name:     "User cannot filter by other user",
+			filter:   &model.AuditLogFilter{UserID: uintPtr(2)},
+			viewerID: 3,
+			wantCode: util.ErrCodeInsufficientPermission,
+		},
+		{
+			name:     "Too many change conditions",
+			filter:   &model.AuditLogFilter{ChangedFields: make([]string, model.MaxAuditChangeConditions+1)},
+			viewerID: 1,
+			viewAll:  true,
+			wantCode: util.ErrCodeInvalidParameter,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Authorize(tt.filter, tt.viewerID, tt.viewAll)
+			if tt.wantCode != "" {
+				var appErr *util.AppError
+				require.True(t, errors.As(err, &appErr))
+				assert.Equal(t, tt.wantCode, appErr.Code)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantUserID, tt.filter.UserID)
+		})
+	}
+}
@@ -2,14 +2,11 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"github.com/stretchr/testify/require"
 	"gorm.io/gorm"
 
 	"github.com/varubogu/effisio/backend/internal/model"
@@ -34,64 +31,48 @@ func (m *MockAuditLogRepository) FindByID(ctx context.Context, id uint) (*model.
 	return args.Get(0).(*model.AuditLog), args.Error(1)
 }
 
-func (m *MockAuditLogRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	args := m.Called(ctx, params)
+func (m *MockAuditLogRepository) FindByFilter(ctx context.Context, filter *model.AuditLogFilter, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
+	args := m.Called(ctx, filter, params)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
 	}
 	return args.Get(0).([]*model.AuditLog), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockAuditLogRepository) FindByUserID(ctx context.Context, userID uint, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	args := m.Called(ctx, userID, params)
-	if args.Get(0) == nil {
-		return nil, args.Get(1).(int64), args.Error(2)
-	}
-	return args.Get(0).([]*model.AuditLog), args.Get(1).(int64), args.Error(2)
-}
-
-func (m *MockAuditLogRepository) FindByResourceID(ctx context.Context, resourceType, resourceID string, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	args := m.Called(ctx, resourceType, resourceID, params)
-	if args.Get(0) == nil {
-		return nil, args.Get(1).(int64), args.Error(2)
-	}
-	return args.Get(0).([]*model.AuditLog), args.Get(1).(int64), args.Error(2)
+func (m *MockAuditLogRepository) DeleteOldLogs(ctx context.Context, days int, holds []*model.AuditHoldScope) error {
+	return m.Called(ctx, days, holds).Error(0)
 }
 
-func (m *MockAuditLogRepository) FindByAction(ctx context.Context, action string, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	args := m.Called(ctx, action, params)
+func (m *MockAuditLogRepository) VerifyChain(ctx context.Context) (*model.AuditChainVerification, error) {
+	args := m.Called(ctx)
 	if args.Get(0) == nil {
-		return nil, args.Get(1).(int64), args.Error(2)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.AuditLog), args.Get(1).(int64), args.Error(2)
+	return args.Get(0).(*model.AuditChainVerification), args.Error(1)
 }
 
-func (m *MockAuditLogRepository) FindByDateRange(ctx context.Context, startDate, endDate time.Time, params *util.PaginationParams) ([]*model.AuditLog, int64, error) {
-	args := m.Called(ctx, startDate, endDate, params)
+func (m *MockAuditLogRepository) CountByBucket(ctx context.Context, q *model.AuditStatisticsQuery) ([]*model.AuditLogBucketCount, error) {
+	args := m.Called(ctx, q)
 	if args.Get(0) == nil {
-		return nil, args.Get(1).(int64), args.Error(2)
+		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.AuditLog), args.Get(1).(int64), args.Error(2)
-}
-
-func (m *MockAuditLogRepository) DeleteOldLogs(ctx context.Context, days int) error {
-	return m.Called(ctx, days).Error(0)
+	return args.Get(0).([]*model.AuditLogBucketCount), args.Error(1)
 }
 
-func (m *MockAuditLogRepository) CountByAction(ctx context.Context) (map[string]int64, error) {
-	args := m.Called(ctx)
+func (m *MockAuditLogRepository) TopFailedActors(ctx context.Context, filter *model.AuditLogFilter, limit int) ([]*model.AuditLogActorCount, error) {
+	args := m.Called(ctx, filter, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(map[string]int64), args.Error(1)
+	return args.Get(0).([]*model.AuditLogActorCount), args.Error(1)
 }
 
-func (m *MockAuditLogRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
-	args := m.Called(ctx)
+func (m *MockAuditLogRepository) TopFailedIPs(ctx context.Context, filter *model.AuditLogFilter, limit int) ([]*model.AuditLogIPCount, error) {
+	args := m.Called(ctx, filter, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).(map[string]int64), args.Error(1)
+	return args.Get(0).([]*model.AuditLogIPCount), args.Error(1)
 }
 
 func getAuditLogger() *zap.Logger {
@@ -101,7 +82,7 @@ func getAuditLogger() *zap.Logger {
 
 func TestAuditLogService_LogAction_Success(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
@@ -144,7 +125,7 @@ func TestAuditLogService_LogAction_Success(t *testing.T) {
 
 func TestAuditLogService_LogAction_ValidationError(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
@@ -229,7 +210,7 @@ func TestAuditLogService_LogAction_ValidationError(t *testing.T) {
 
 func TestAuditLogService_GetByID(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
@@ -256,7 +237,7 @@ func TestAuditLogService_GetByID(t *testing.T) {
 
 func TestAuditLogService_GetByID_NotFound(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
@@ -271,7 +252,7 @@ func TestAuditLogService_GetByID_NotFound(t *testing.T) {
 
 func TestAuditLogService_List(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
@@ -299,20 +280,20 @@ func TestAuditLogService_List(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindAll", ctx, params).Return(logs, int64(2), nil)
+	mockRepo.On("FindByFilter", ctx, &model.AuditLogFilter{}, params).Return(logs, int64(2), nil)
 
-	resp, err := service.List(ctx, params)
+	resp, err := service.List(ctx, nil, params)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Len(t, resp.Data, 2)
-	assert.Equal(t, int64(2), resp.Total)
+	assert.Equal(t, int64(2), resp.Pagination.Total)
 	mockRepo.AssertExpectations(t)
 }
 
 func TestAuditLogService_ListByUserID(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
@@ -329,7 +310,8 @@ func TestAuditLogService_ListByUserID(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindByUserID", ctx, uint(1), params).Return(logs, int64(1), nil)
+	userID := uint(1)
+	mockRepo.On("FindByFilter", ctx, &model.AuditLogFilter{UserID: &userID}, params).Return(logs, int64(1), nil)
 
 	resp, err := service.ListByUserID(ctx, 1, params)
 
@@ -341,7 +323,7 @@ func TestAuditLogService_ListByUserID(t *testing.T) {
 
 func TestAuditLogService_ListByResource(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
@@ -358,7 +340,7 @@ func TestAuditLogService_ListByResource(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindByResourceID", ctx, model.ResourceTypeUser, "user-123", params).
+	mockRepo.On("FindByFilter", ctx, &model.AuditLogFilter{ResourceType: model.ResourceTypeUser, ResourceID: "user-123"}, params).
 		Return(logs, int64(1), nil)
 
 	resp, err := service.ListByResource(ctx, model.ResourceTypeUser, "user-123", params)
@@ -371,7 +353,7 @@ func TestAuditLogService_ListByResource(t *testing.T) {
 
 func TestAuditLogService_ListByResource_MissingParams(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{}
@@ -384,10 +366,21 @@ func TestAuditLogService_ListByResource_MissingParams(t *testing.T) {
 
 func TestAuditLogService_GetStatistics(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
+	from := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	query := &model.AuditStatisticsQuery{Filter: &model.AuditLogFilter{From: &from, To: &to}}
+
+	rows := []*model.AuditLogBucketCount{
+		{Bucket: from, Action: model.ActionCreate, Status: model.AuditStatusSuccess, Count: 10},
+		{Bucket: from, Action: model.ActionUpdate, Status: model.AuditStatusSuccess, Count: 5},
+		{Bucket: from, Action: model.ActionDelete, Status: model.AuditStatusSuccess, Count: 1},
+		{Bucket: from, Action: model.ActionDelete, Status: model.AuditStatusFailed, Count: 1},
+	}
+
 	byAction := map[string]int64{
 		model.ActionCreate: 10,
 		model.ActionUpdate: 5,
@@ -399,10 +392,11 @@ func TestAuditLogService_GetStatistics(t *testing.T) {
 		model.AuditStatusFailed:  1,
 	}
 
-	mockRepo.On("CountByAction", ctx).Return(byAction, nil)
-	mockRepo.On("CountByStatus", ctx).Return(byStatus, nil)
+	mockRepo.On("CountByBucket", ctx, query).Return(rows, nil)
+	mockRepo.On("TopFailedActors", ctx, query.Filter, defaultAuditStatisticsTopN).Return([]*model.AuditLogActorCount{}, nil)
+	mockRepo.On("TopFailedIPs", ctx, query.Filter, defaultAuditStatisticsTopN).Return([]*model.AuditLogIPCount{}, nil)
 
-	stats, err := service.GetStatistics(ctx)
+	stats, err := service.GetStatistics(ctx, query)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, stats)
@@ -415,11 +409,11 @@ func TestAuditLogService_GetStatistics(t *testing.T) {
 
 func TestAuditLogService_DeleteOldLogs(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
-	mockRepo.On("DeleteOldLogs", ctx, 90).Return(nil)
+	mockRepo.On("DeleteOldLogs", ctx, 90, []*model.AuditHoldScope(nil)).Return(nil)
 
 	err := service.DeleteOldLogs(ctx, 90)
 
@@ -429,7 +423,7 @@ func TestAuditLogService_DeleteOldLogs(t *testing.T) {
 
 func TestAuditLogService_DeleteOldLogs_InvalidDays(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 
@@ -440,7 +434,7 @@ func TestAuditLogService_DeleteOldLogs_InvalidDays(t *testing.T) {
 
 func TestAuditLogService_ListByAction(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
@@ -457,7 +451,7 @@ func TestAuditLogService_ListByAction(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindByAction", ctx, model.ActionCreate, params).Return(logs, int64(1), nil)
+	mockRepo.On("FindByFilter", ctx, &model.AuditLogFilter{Actions: []string{model.ActionCreate}}, params).Return(logs, int64(1), nil)
 
 	resp, err := service.ListByAction(ctx, model.ActionCreate, params)
 
@@ -469,7 +463,7 @@ func TestAuditLogService_ListByAction(t *testing.T) {
 
 func TestAuditLogService_ListByDateRange(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
@@ -489,7 +483,7 @@ func TestAuditLogService_ListByDateRange(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindByDateRange", ctx, startDate, endDate, params).Return(logs, int64(1), nil)
+	mockRepo.On("FindByFilter", ctx, &model.AuditLogFilter{From: &startDate, To: &endDate}, params).Return(logs, int64(1), nil)
 
 	resp, err := service.ListByDateRange(ctx, startDate, endDate, params)
 
@@ -501,7 +495,7 @@ func TestAuditLogService_ListByDateRange(t *testing.T) {
 
 func TestAuditLogService_ListByDateRange_InvalidRange(t *testing.T) {
 	mockRepo := new(MockAuditLogRepository)
-	service := NewAuditLogService(mockRepo, getAuditLogger())
+	service := NewAuditLogService(mockRepo, nil, nil, nil, nil, getAuditLogger())
 
 	ctx := context.Background()
 	params := &util.PaginationParams{}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/auditsink"
+)
+
+// fakeTxKey はテスト用のトランザクションをコンテキストに保持するためのキーです
+type fakeTxKey struct{}
+
+// fakeUnitOfWork はテスト用のトランザクションです
+// トランザクション内の書き込みはコミットされるまで保留し、ロールバックした場合は破棄します
+type fakeUnitOfWork struct {
+	mu        sync.Mutex
+	committed []string
+	failWrite map[string]error // 書き込み内容ごとに失敗させるエラー
+}
+
+func (u *fakeUnitOfWork) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	pending := &[]string{}
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, pending)); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.committed = append(u.committed, *pending...)
+	return nil
+}
+
+// write はトランザクション内であれば保留し、そうでなければ即座にコミットします
+func (u *fakeUnitOfWork) write(ctx context.Context, entry string) error {
+	if err := u.failWrite[entry]; err != nil {
+		return err
+	}
+	if pending, ok := ctx.Value(fakeTxKey{}).(*[]string); ok {
+		*pending = append(*pending, entry)
+		return nil
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.committed = append(u.committed, entry)
+	return nil
+}
+
+// CreateBatch は監査ログを「audit:アクション:ステータス」として書き込みます
+func (u *fakeUnitOfWork) CreateBatch(ctx context.Context, auditLogs []*model.AuditLog) error {
+	for _, auditLog := range auditLogs {
+		if err := u.write(ctx, "audit:"+auditLog.Action+":"+auditLog.Status); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func newTransactionalAuditLogService(uow *fakeUnitOfWork) *AuditLogService {
+	return &AuditLogService{
+		tx:     uow,
+		writer: NewAuditLogWriter(uow, AuditLogWriterConfig{}, zap.NewNop()),
+		logger: zap.NewNop(),
+	}
+}
+
+// updateUser はユーザーの更新と成功の監査ログを返す変更処理です
+func updateUser(uow *fakeUnitOfWork) func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+	return func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := uow.write(ctx, "user:update"); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       1,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   "taro",
+			Status:       model.AuditStatusSuccess,
+		}, nil
+	}
+}
+
+func failureRequest() *model.CreateAuditLogRequest {
+	return &model.CreateAuditLogRequest{
+		UserID:       1,
+		Action:       model.ActionUpdate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   "taro",
+	}
+}
+
+func TestRecordChangeCommitsChangeAndAuditLogTogether(t *testing.T) {
+	uow := &fakeUnitOfWork{}
+	service := newTransactionalAuditLogService(uow)
+
+	err := service.RecordChange(context.Background(), failureRequest(), updateUser(uow))
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user:update", "audit:update:success"}, uow.committed)
+}
+
+func TestRecordChangeWhenChangeFails(t *testing.T) {
+	changeErr := errors.New("connection reset")
+	uow := &fakeUnitOfWork{failWrite: map[string]error{"user:update": changeErr}}
+	service := newTransactionalAuditLogService(uow)
+
+	err := service.RecordChange(context.Background(), failureRequest(), updateUser(uow))
+
+	// 変更が失敗した場合は成功の監査ログを残さず、失敗を別のトランザクションで記録する
+	assert.ErrorIs(t, err, changeErr)
+	assert.Equal(t, []string{"audit:update:failed"}, uow.committed)
+}
+
+func TestRecordChangeWhenAuditLogFails(t *testing.T) {
+	auditErr := errors.New("audit_logs is not writable")
+	uow := &fakeUnitOfWork{failWrite: map[string]error{"audit:update:success": auditErr}}
+	service := newTransactionalAuditLogService(uow)
+
+	err := service.RecordChange(context.Background(), failureRequest(), updateUser(uow))
+
+	// 監査ログを書き込めない場合は変更もロールバックし、監査されない変更を残さない
+	assert.ErrorContains(t, err, auditErr.Error())
+	assert.Equal(t, []string{"audit:update:failed"}, uow.committed)
+}
+
+func TestRecordChangeWithoutFailureLog(t *testing.T) {
+	changeErr := errors.New("connection reset")
+	uow := &fakeUnitOfWork{failWrite: map[string]error{"user:update": changeErr}}
+	service := newTransactionalAuditLogService(uow)
+
+	err := service.RecordChange(context.Background(), nil, updateUser(uow))
+
+	assert.ErrorIs(t, err, changeErr)
+	assert.Empty(t, uow.committed)
+}
+
+// recordingSink はテスト用の転送先で、受け取ったイベントを記録します
+type recordingSink struct {
+	mu     sync.Mutex
+	events []*auditsink.Event
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Send(ctx context.Context, event *auditsink.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) Close() error { return nil }
+
+func TestRecordChangeForwardsOnlyCommittedAuditLogs(t *testing.T) {
+	changeErr := errors.New("connection reset")
+	uow := &fakeUnitOfWork{}
+	sink := &recordingSink{}
+	sinks := auditsink.NewDispatcher(auditsink.Config{}, zap.NewNop())
+	sinks.Add(sink, auditsink.Filter{})
+	sinks.Start()
+	service := newTransactionalAuditLogService(uow)
+	service.sinks = sinks
+
+	require.NoError(t, service.RecordChange(context.Background(), failureRequest(), updateUser(uow)))
+	uow.failWrite = map[string]error{"user:update": changeErr}
+	require.Error(t, service.RecordChange(context.Background(), failureRequest(), updateUser(uow)))
+	require.NoError(t, sinks.Close(context.Background()))
+
+	// ロールバックされた変更の成功ログは転送せず、失敗ログを転送する
+	require.Len(t, sink.events, 2)
+	assert.Equal(t, model.AuditStatusSuccess, sink.events[0].Status)
+	assert.Equal(t, model.AuditStatusFailed, sink.events[1].Status)
+	assert.Equal(t, changeErr.Error(), sink.events[1].ErrorMessage)
+}
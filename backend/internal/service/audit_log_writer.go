@@ -0,0 +1,337 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// キューが満杯の場合の動作
+const (
+	AuditBackpressureBlock = "block" // 空きが出るまで待ち、待ちきれない場合は破棄する
+	AuditBackpressureDrop  = "drop"  // 待たずに破棄する
+	AuditBackpressureSync  = "sync"  // 呼び出し元でそのまま書き込む
+)
+
+var (
+	// ErrAuditQueueFull はキューが満杯で監査ログを破棄したことを表します
+	ErrAuditQueueFull = errors.New("audit log queue is full")
+	// ErrAuditWriterClosed は停止済みのため監査ログを受け付けられないことを表します
+	ErrAuditWriterClosed = errors.New("audit log writer is closed")
+)
+
+// auditLogBatchStore は監査ログをまとめて保存する手段です
+type auditLogBatchStore interface {
+	CreateBatch(ctx context.Context, auditLogs []*model.AuditLog) error
+}
+
+// AuditLogWriterConfig はAuditLogWriterの設定です
+type AuditLogWriterConfig struct {
+	QueueSize     int           // キューに保持できる件数
+	BatchSize     int           // 1回の書き込みでまとめる最大件数
+	FlushInterval time.Duration // バッチが満たなくても書き込む間隔
+	Backpressure  string        // キューが満杯の場合の動作（block, drop, sync）
+	BlockTimeout  time.Duration // block の場合に空きを待つ最大時間
+	WriteTimeout  time.Duration // 1回の書き込みのタイムアウト
+}
+
+// AuditLogWriterStats はAuditLogWriterの統計情報です
+type AuditLogWriterStats struct {
+	Enabled       bool   `json:"enabled"`
+	QueueLength   int    `json:"queue_length"`
+	QueueCapacity int    `json:"queue_capacity"`
+	Backpressure  string `json:"backpressure"`
+	Enqueued      int64  `json:"enqueued"`
+	Written       int64  `json:"written"`
+	Dropped       int64  `json:"dropped"`
+	Failed        int64  `json:"failed"`
+	Batches       int64  `json:"batches"`
+}
+
+// AuditLogWriter は監査ログをキューに溜めて、バックグラウンドでまとめて書き込みます
+// リクエストの処理中にINSERTを待たずに済むようにし、停止時はキューに残った監査ログを書き込んでから終了します
+type AuditLogWriter struct {
+	store  auditLogBatchStore
+	config AuditLogWriterConfig
+	logger *zap.Logger
+
+	queue   chan *model.AuditLog
+	flushes chan chan struct{} // Flush の要求（書き込み後に閉じる）
+	done    chan struct{}
+	mu      sync.RWMutex // closed と queue のクローズを保護する
+	closed  bool
+
+	onWritten func(auditLogs ...*model.AuditLog) // キュー経由で書き込んだ監査ログを通知する
+
+	enqueued atomic.Int64
+	written  atomic.Int64
+	dropped  atomic.Int64
+	failed   atomic.Int64
+	batches  atomic.Int64
+}
+
+// NewAuditLogWriter は新しいAuditLogWriterを作成します
+// 0以下の設定値は既定値を使用します
+func NewAuditLogWriter(store auditLogBatchStore, config AuditLogWriterConfig, logger *zap.Logger) *AuditLogWriter {
+	if config.QueueSize <= 0 {
+		config.QueueSize = 10000
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.BlockTimeout <= 0 {
+		config.BlockTimeout = 100 * time.Millisecond
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 10 * time.Second
+	}
+	switch config.Backpressure {
+	case AuditBackpressureBlock, AuditBackpressureDrop, AuditBackpressureSync:
+	default:
+		config.Backpressure = AuditBackpressureBlock
+	}
+
+	return &AuditLogWriter{
+		store:   store,
+		config:  config,
+		logger:  logger,
+		queue:   make(chan *model.AuditLog, config.QueueSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start はバックグラウンドでの書き込みを開始します
+func (w *AuditLogWriter) Start() {
+	go w.run()
+}
+
+// OnWritten はキュー経由で監査ログを書き込んだ後に呼び出す関数を設定します（Start の前に呼び出してください）
+func (w *AuditLogWriter) OnWritten(fn func(auditLogs ...*model.AuditLog)) {
+	w.onWritten = fn
+}
+
+// Enqueue は監査ログをキューに追加します
+// キューが満杯の場合は設定した動作に従い、破棄した場合は ErrAuditQueueFull を返します
+func (w *AuditLogWriter) Enqueue(ctx context.Context, auditLog *model.AuditLog) error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.closed {
+		return ErrAuditWriterClosed
+	}
+
+	select {
+	case w.queue <- auditLog:
+		w.enqueued.Add(1)
+		return nil
+	default:
+	}
+
+	switch w.config.Backpressure {
+	case AuditBackpressureSync:
+		if err := w.WriteSync(ctx, auditLog); err != nil {
+			return err
+		}
+		w.notifyWritten(auditLog)
+		return nil
+	case AuditBackpressureBlock:
+		timer := time.NewTimer(w.config.BlockTimeout)
+		defer timer.Stop()
+		select {
+		case w.queue <- auditLog:
+			w.enqueued.Add(1)
+			return nil
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+
+	w.dropped.Add(1)
+	w.logger.Error("Audit log dropped because the queue is full",
+		zap.String("action", auditLog.Action),
+		zap.String("resource_type", auditLog.ResourceType),
+		zap.String("resource_id", auditLog.ResourceID),
+	)
+	return ErrAuditQueueFull
+}
+
+// WriteSync はキューを経由せずに監査ログを書き込み、永続化を待ちます
+// 重要なセキュリティイベント等、確実に記録したい場合に使用します
+func (w *AuditLogWriter) WriteSync(ctx context.Context, auditLog *model.AuditLog) error {
+	// リクエストがキャンセルされても記録は完了させる
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), w.config.WriteTimeout)
+	defer cancel()
+
+	if err := w.store.CreateBatch(ctx, []*model.AuditLog{auditLog}); err != nil {
+		w.failed.Add(1)
+		return err
+	}
+	w.written.Add(1)
+	return nil
+}
+
+// Flush はキューに残っている監査ログを書き込み、完了を待ちます
+// 書き込まれていない監査ログを読み出す処理（匿名化等）の前に使用します
+func (w *AuditLogWriter) Flush(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case w.flushes <- reply:
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case <-reply:
+		return nil
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close は新しい監査ログの受け付けを止め、キューに残った監査ログを書き込んでから終了します
+// ctx の期限までに書き込みが終わらない場合は ctx のエラーを返します
+func (w *AuditLogWriter) Close(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		w.logger.Error("Audit log writer stopped before draining the queue", zap.Int("remaining", len(w.queue)))
+		return ctx.Err()
+	}
+}
+
+// Stats は統計情報を返します
+func (w *AuditLogWriter) Stats() *AuditLogWriterStats {
+	return &AuditLogWriterStats{
+		Enabled:       true,
+		QueueLength:   len(w.queue),
+		QueueCapacity: cap(w.queue),
+		Backpressure:  w.config.Backpressure,
+		Enqueued:      w.enqueued.Load(),
+		Written:       w.written.Load(),
+		Dropped:       w.dropped.Load(),
+		Failed:        w.failed.Load(),
+		Batches:       w.batches.Load(),
+	}
+}
+
+// run はキューから監査ログを取り出し、バッチサイズに達するか一定間隔ごとに書き込みます
+func (w *AuditLogWriter) run() {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*model.AuditLog, 0, w.config.BatchSize)
+	for {
+		select {
+		case auditLog, ok := <-w.queue:
+			if !ok {
+				w.flush(batch)
+				return
+			}
+			batch = append(batch, auditLog)
+			if len(batch) >= w.config.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		case reply := <-w.flushes:
+			batch = w.drain(batch)
+			w.flush(batch)
+			batch = batch[:0]
+			close(reply)
+		}
+	}
+}
+
+// drain はキューに溜まっている監査ログをすべて取り出します（バッチサイズごとに書き込みます）
+func (w *AuditLogWriter) drain(batch []*model.AuditLog) []*model.AuditLog {
+	for {
+		select {
+		case auditLog, ok := <-w.queue:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, auditLog)
+			if len(batch) >= w.config.BatchSize {
+				w.flush(batch)
+				batch = batch[:0]
+			}
+		default:
+			return batch
+		}
+	}
+}
+
+// flush はバッチを書き込みます
+// まとめての書き込みに失敗した場合は、1件の不正な監査ログで他を失わないよう1件ずつ書き込み直します
+func (w *AuditLogWriter) flush(batch []*model.AuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+
+	w.batches.Add(1)
+	err := w.write(batch)
+	if err == nil {
+		w.written.Add(int64(len(batch)))
+		w.notifyWritten(batch...)
+		return
+	}
+	w.logger.Warn("Failed to write audit log batch, retrying one by one", zap.Int("size", len(batch)), zap.Error(err))
+
+	for _, auditLog := range batch {
+		if err := w.write([]*model.AuditLog{auditLog}); err != nil {
+			w.failed.Add(1)
+			w.logger.Error("Failed to write audit log",
+				zap.Uint("user_id", auditLog.UserID),
+				zap.String("action", auditLog.Action),
+				zap.String("resource_type", auditLog.ResourceType),
+				zap.String("resource_id", auditLog.ResourceID),
+				zap.Error(err),
+			)
+			continue
+		}
+		w.written.Add(1)
+		w.notifyWritten(auditLog)
+	}
+}
+
+// notifyWritten は書き込んだ監査ログを OnWritten で設定した関数に通知します
+func (w *AuditLogWriter) notifyWritten(auditLogs ...*model.AuditLog) {
+	if w.onWritten != nil {
+		w.onWritten(auditLogs...)
+	}
+}
+
+// write はタイムアウト付きで監査ログを書き込みます
+func (w *AuditLogWriter) write(auditLogs []*model.AuditLog) error {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.WriteTimeout)
+	defer cancel()
+	return w.store.CreateBatch(ctx, auditLogs)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+// fakeAuditLogStore はテスト用の監査ログの保存先です
+type fakeAuditLogStore struct {
+	mu      sync.Mutex
+	batches [][]*model.AuditLog
+	written []*model.AuditLog
+	block   chan struct{} // 指定した場合、閉じるまで書き込みを止める
+	reject  string        // このアクションを含むバッチの書き込みを失敗させる
+}
+
+func (s *fakeAuditLogStore) CreateBatch(ctx context.Context, auditLogs []*model.AuditLog) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, auditLog := range auditLogs {
+		if auditLog.Action == s.reject {
+			return errors.New("rejected")
+		}
+	}
+	s.batches = append(s.batches, auditLogs)
+	s.written = append(s.written, auditLogs...)
+	return nil
+}
+
+func (s *fakeAuditLogStore) writtenCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.written)
+}
+
+func newTestAuditLog(action string) *model.AuditLog {
+	return &model.AuditLog{UserID: 1, Action: action, ResourceType: model.ResourceTypeUser, ResourceID: "taro", Status: model.AuditStatusSuccess}
+}
+
+func TestAuditLogWriterBatchesBySize(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	writer := NewAuditLogWriter(store, AuditLogWriterConfig{BatchSize: 2, FlushInterval: time.Hour}, zap.NewNop())
+	writer.Start()
+
+	for i := 0; i < 4; i++ {
+		require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+	}
+
+	assert.Eventually(t, func() bool { return store.writtenCount() == 4 }, time.Second, 5*time.Millisecond)
+	require.NoError(t, writer.Close(context.Background()))
+
+	assert.Len(t, store.batches, 2)
+	stats := writer.Stats()
+	assert.Equal(t, int64(4), stats.Enqueued)
+	assert.Equal(t, int64(4), stats.Written)
+	assert.Equal(t, int64(2), stats.Batches)
+}
+
+func TestAuditLogWriterFlushesByInterval(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	writer := NewAuditLogWriter(store, AuditLogWriterConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, zap.NewNop())
+	writer.Start()
+	defer writer.Close(context.Background())
+
+	require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+
+	assert.Eventually(t, func() bool { return store.writtenCount() == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestAuditLogWriterCloseDrainsQueue(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	writer := NewAuditLogWriter(store, AuditLogWriterConfig{BatchSize: 100, FlushInterval: time.Hour}, zap.NewNop())
+	writer.Start()
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+	}
+	require.NoError(t, writer.Close(context.Background()))
+
+	assert.Equal(t, 10, store.writtenCount())
+	assert.ErrorIs(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)), ErrAuditWriterClosed)
+}
+
+func TestAuditLogWriterFlush(t *testing.T) {
+	store := &fakeAuditLogStore{}
+	writer := NewAuditLogWriter(store, AuditLogWriterConfig{BatchSize: 100, FlushInterval: time.Hour}, zap.NewNop())
+	writer.Start()
+	defer writer.Close(context.Background())
+
+	require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+	require.NoError(t, writer.Flush(context.Background()))
+
+	assert.Equal(t, 1, store.writtenCount())
+}
+
+func TestAuditLogWriterBackpressure(t *testing.T) {
+	t.Run("Drop when the queue is full", func(t *testing.T) {
+		store := &fakeAuditLogStore{}
+		// 開始しないことでキューを満杯のままにする
+		writer := NewAuditLogWriter(store, AuditLogWriterConfig{QueueSize: 1, Backpressure: AuditBackpressureDrop}, zap.NewNop())
+
+		require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+		err := writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate))
+
+		assert.ErrorIs(t, err, ErrAuditQueueFull)
+		stats := writer.Stats()
+		assert.Equal(t, int64(1), stats.Dropped)
+		assert.Equal(t, 1, stats.QueueLength)
+		assert.Equal(t, 1, stats.QueueCapacity)
+	})
+
+	t.Run("Block until timeout", func(t *testing.T) {
+		store := &fakeAuditLogStore{}
+		writer := NewAuditLogWriter(store, AuditLogWriterConfig{QueueSize: 1, Backpressure: AuditBackpressureBlock, BlockTimeout: 20 * time.Millisecond}, zap.NewNop())
+
+		require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+		started := time.Now()
+		err := writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate))
+
+		assert.ErrorIs(t, err, ErrAuditQueueFull)
+		assert.GreaterOrEqual(t, time.Since(started), 20*time.Millisecond)
+		assert.Equal(t, int64(1), writer.Stats().Dropped)
+	})
+
+	t.Run("Write synchronously when the queue is full", func(t *testing.T) {
+		store := &fakeAuditLogStore{}
+		writer := NewAuditLogWriter(store, AuditLogWriterConfig{QueueSize: 1, Backpressure: AuditBackpressureSync}, zap.NewNop())
+
+		require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+		require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionLogin)))
+
+		assert.Equal(t, 1, store.writtenCount())
+		assert.Equal(t, model.ActionLogin, store.written[0].Action)
+		assert.Equal(t, int64(0), writer.Stats().Dropped)
+	})
+}
+
+func TestAuditLogWriterRetriesFailedBatchOneByOne(t *testing.T) {
+	store := &fakeAuditLogStore{reject: model.ActionDelete}
+	writer := NewAuditLogWriter(store, AuditLogWriterConfig{BatchSize: 100, FlushInterval: time.Hour}, zap.NewNop())
+	writer.Start()
+
+	require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+	require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionDelete)))
+	require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionCreate)))
+	require.NoError(t, writer.Close(context.Background()))
+
+	assert.Equal(t, 2, store.writtenCount())
+	stats := writer.Stats()
+	assert.Equal(t, int64(2), stats.Written)
+	assert.Equal(t, int64(1), stats.Failed)
+}
+
+func TestAuditLogWriterCloseTimeout(t *testing.T) {
+	store := &fakeAuditLogStore{block: make(chan struct{})}
+	defer close(store.block)
+	writer := NewAuditLogWriter(store, AuditLogWriterConfig{BatchSize: 1, FlushInterval: time.Hour}, zap.NewNop())
+	writer.Start()
+
+	require.NoError(t, writer.Enqueue(context.Background(), newTestAuditLog(model.ActionUpdate)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, writer.Close(ctx), context.DeadlineExceeded)
+}
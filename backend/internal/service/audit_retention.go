@@ -0,0 +1,426 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// AuditHoldProvider は削除・匿名化してはならない監査ログの範囲を提供します（訴訟ホールド）
+type AuditHoldProvider interface {
+	AuditHoldScopes(ctx context.Context) ([]*model.AuditHoldScope, error)
+}
+
+// AuditRetentionConfig は監査ログの保持期間ポリシーの実行に関する設定です
+type AuditRetentionConfig struct {
+	MinRetentionDays int           // ポリシーに指定できる保持期間の下限（誤設定による大量削除を防ぐ）
+	BatchSize        int           // 1回のトランザクションで削除する最大件数
+	BatchPause       time.Duration // 削除の間隔（監査ログの書き込みを待たせ続けないため）
+}
+
+// AuditRetentionService は監査ログの保持期間ポリシーの管理と実行に関するビジネスロジックを提供します
+type AuditRetentionService struct {
+	repo            *repository.AuditRetentionPolicyRepository
+	auditLogRepo    *repository.AuditLogRepository
+	holds           AuditHoldProvider // nil の場合は訴訟ホールドを考慮しない
+	config          AuditRetentionConfig
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+	now             func() time.Time
+}
+
+// NewAuditRetentionService は新しいAuditRetentionServiceを作成します
+func NewAuditRetentionService(
+	repo *repository.AuditRetentionPolicyRepository,
+	auditLogRepo *repository.AuditLogRepository,
+	holds AuditHoldProvider,
+	config AuditRetentionConfig,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *AuditRetentionService {
+	if config.MinRetentionDays <= 0 {
+		config.MinRetentionDays = 1
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 1000
+	}
+	return &AuditRetentionService{
+		repo:            repo,
+		auditLogRepo:    auditLogRepo,
+		holds:           holds,
+		config:          config,
+		logger:          logger,
+		auditLogService: auditLogService,
+		now:             time.Now,
+	}
+}
+
+// List はポリシーをすべて取得します
+func (s *AuditRetentionService) List(ctx context.Context) ([]*model.AuditRetentionPolicy, error) {
+	policies, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch audit retention policies", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return policies, nil
+}
+
+// GetByID はIDでポリシーを取得します
+func (s *AuditRetentionService) GetByID(ctx context.Context, id uint) (*model.AuditRetentionPolicy, error) {
+	return s.findPolicy(ctx, id)
+}
+
+// Create はポリシーを作成します
+func (s *AuditRetentionService) Create(ctx context.Context, actorID uint, req *model.CreateAuditRetentionPolicyRequest) (*model.AuditRetentionPolicy, error) {
+	policy := &model.AuditRetentionPolicy{
+		Name:          req.Name,
+		Action:        req.Action,
+		ResourceType:  req.ResourceType,
+		Status:        req.Status,
+		RetentionDays: req.RetentionDays,
+		Enabled:       req.Enabled == nil || *req.Enabled,
+		Description:   req.Description,
+		CreatedBy:     actorID,
+	}
+	if err := s.validatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Create(ctx, policy); err != nil {
+		s.logger.Error("Failed to create audit retention policy", zap.Error(err))
+		s.logPolicyFailure(ctx, actorID, model.ActionCreate, req.Name, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Audit retention policy created", zap.Uint("id", policy.ID), zap.String("name", policy.Name))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionCreate,
+			ResourceType: model.ResourceTypeAuditRetentionPolicy,
+			ResourceID:   policy.Name,
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  retentionPolicyChanges(policy),
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return policy, nil
+}
+
+// Update はポリシーを更新します
+func (s *AuditRetentionService) Update(ctx context.Context, actorID, id uint, req *model.UpdateAuditRetentionPolicyRequest) (*model.AuditRetentionPolicy, error) {
+	policy, err := s.findPolicy(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := retentionPolicyChanges(policy)
+
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.Action != nil {
+		policy.Action = *req.Action
+	}
+	if req.ResourceType != nil {
+		policy.ResourceType = *req.ResourceType
+	}
+	if req.Status != nil {
+		policy.Status = *req.Status
+	}
+	if req.RetentionDays != nil {
+		policy.RetentionDays = *req.RetentionDays
+	}
+	if req.Enabled != nil {
+		policy.Enabled = *req.Enabled
+	}
+	if req.Description != nil {
+		policy.Description = *req.Description
+	}
+	if err := s.validatePolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Update(ctx, policy); err != nil {
+		s.logger.Error("Failed to update audit retention policy", zap.Uint("id", id), zap.Error(err))
+		s.logPolicyFailure(ctx, actorID, model.ActionUpdate, policy.Name, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Audit retention policy updated", zap.Uint("id", id))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeAuditRetentionPolicy,
+			ResourceID:   policy.Name,
+			Changes: model.AuditLogChanges{
+				Before: before,
+				After:  retentionPolicyChanges(policy),
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return policy, nil
+}
+
+// Delete はポリシーを削除します
+// 削除したポリシーの対象だった監査ログは、他のポリシーに一致しない限り削除されなくなります
+func (s *AuditRetentionService) Delete(ctx context.Context, actorID, id uint) error {
+	policy, err := s.findPolicy(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete audit retention policy", zap.Uint("id", id), zap.Error(err))
+		s.logPolicyFailure(ctx, actorID, model.ActionDelete, policy.Name, err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Audit retention policy deleted", zap.Uint("id", id))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionDelete,
+			ResourceType: model.ResourceTypeAuditRetentionPolicy,
+			ResourceID:   policy.Name,
+			Changes: model.AuditLogChanges{
+				Before: retentionPolicyChanges(policy),
+				After:  map[string]interface{}{},
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return nil
+}
+
+// Run は有効なポリシーに従って保持期間を過ぎた監査ログを削除します
+// dryRun の場合は削除せず、ポリシーごとに削除される件数を返します
+// 削除は BatchSize 件ずつ別のトランザクションで行い、実行結果を監査ログに記録します
+func (s *AuditRetentionService) Run(ctx context.Context, actorID uint, dryRun bool) (*model.AuditRetentionResult, error) {
+	now := s.now()
+	policies, err := s.repo.FindEnabled(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch audit retention policies", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// ホールドを取得できない場合は、保持すべき監査ログを削除しないよう中止する
+	var holds []*model.AuditHoldScope
+	if s.holds != nil {
+		holds, err = s.holds.AuditHoldScopes(ctx)
+		if err != nil {
+			s.logger.Error("Failed to fetch legal holds for audit retention", zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+	}
+
+	result := &model.AuditRetentionResult{
+		DryRun:   dryRun,
+		RanAt:    now,
+		Policies: make([]*model.AuditRetentionPolicyResult, 0, len(policies)),
+	}
+	failed := false
+	for _, policy := range policies {
+		policyResult := &model.AuditRetentionPolicyResult{
+			PolicyID: policy.ID,
+			Name:     policy.Name,
+			Cutoff:   policy.Cutoff(now),
+		}
+		result.Policies = append(result.Policies, policyResult)
+
+		// 下限を引き上げた後も、それより短いポリシーで削除しない
+		if policy.RetentionDays < s.config.MinRetentionDays {
+			policyResult.Error = fmt.Sprintf("retention_days is below the minimum of %d days", s.config.MinRetentionDays)
+			failed = true
+			continue
+		}
+
+		if dryRun {
+			policyResult.Count, err = s.auditLogRepo.CountExpired(ctx, policy, policies, holds, now)
+		} else {
+			policyResult.Count, err = s.deleteExpired(ctx, policy, policies, holds, now)
+		}
+		result.Total += policyResult.Count
+		if err != nil {
+			// 1つのポリシーの失敗で残りのポリシーを止めない
+			s.logger.Error("Failed to apply audit retention policy",
+				zap.Uint("policy_id", policy.ID),
+				zap.Bool("dry_run", dryRun),
+				zap.Error(err),
+			)
+			policyResult.Error = err.Error()
+			failed = true
+		}
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	s.logger.Info("Audit retention policies applied", zap.Int64("deleted", result.Total), zap.Int("policies", len(policies)))
+	s.logRun(ctx, actorID, result, failed)
+	return result, nil
+}
+
+// deleteExpired はポリシーで削除する監査ログがなくなるまで、BatchSize 件ずつ削除します
+func (s *AuditRetentionService) deleteExpired(ctx context.Context, policy *model.AuditRetentionPolicy, policies []*model.AuditRetentionPolicy, holds []*model.AuditHoldScope, now time.Time) (int64, error) {
+	var total int64
+	for {
+		deleted, err := s.auditLogRepo.DeleteExpired(ctx, policy, policies, holds, now, s.config.BatchSize)
+		total += deleted
+		if err != nil {
+			return total, err
+		}
+		if deleted < int64(s.config.BatchSize) {
+			return total, nil
+		}
+
+		if s.config.BatchPause > 0 {
+			select {
+			case <-ctx.Done():
+				return total, ctx.Err()
+			case <-time.After(s.config.BatchPause):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return total, err
+		}
+	}
+}
+
+// logRun は保持期間ポリシーの実行結果を監査ログに記録します
+func (s *AuditRetentionService) logRun(ctx context.Context, actorID uint, result *model.AuditRetentionResult, failed bool) {
+	if s.auditLogService == nil {
+		return
+	}
+
+	policies := make([]interface{}, len(result.Policies))
+	for i, policyResult := range result.Policies {
+		entry := map[string]interface{}{
+			"policy_id": policyResult.PolicyID,
+			"name":      policyResult.Name,
+			"cutoff":    policyResult.Cutoff,
+			"deleted":   policyResult.Count,
+		}
+		if policyResult.Error != "" {
+			entry["error"] = policyResult.Error
+		}
+		policies[i] = entry
+	}
+
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionPurge,
+		ResourceType: model.ResourceTypeAuditLog,
+		ResourceID:   "retention",
+		Changes: model.AuditLogChanges{
+			Before: map[string]interface{}{},
+			After: map[string]interface{}{
+				"deleted":  result.Total,
+				"policies": policies,
+			},
+		},
+		Status:  model.AuditStatusSuccess,
+		Durable: true,
+	}
+	if failed {
+		auditReq.Status = model.AuditStatusFailed
+		auditReq.ErrorMessage = "some retention policies failed"
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// validatePolicy はポリシーの内容を検証します
+func (s *AuditRetentionService) validatePolicy(ctx context.Context, policy *model.AuditRetentionPolicy) error {
+	if err := validateRetentionPolicy(policy, s.config.MinRetentionDays); err != nil {
+		return err
+	}
+
+	exists, err := s.repo.ExistsByName(ctx, policy.Name, policy.ID)
+	if err != nil {
+		s.logger.Error("Failed to check audit retention policy name", zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return util.NewConflictError(util.ErrCodeRetentionPolicyAlreadyExists, errors.New("retention policy name already exists"))
+	}
+	return nil
+}
+
+// validateRetentionPolicy はポリシーの各項目を検証します
+func validateRetentionPolicy(policy *model.AuditRetentionPolicy, minRetentionDays int) error {
+	if policy.Name == "" {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("name is required"))
+	}
+	if policy.Status != "" && policy.Status != model.AuditStatusSuccess && policy.Status != model.AuditStatusFailed {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("status must be success or failed"))
+	}
+	if policy.RetentionDays < minRetentionDays {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter,
+			fmt.Errorf("retention_days must be at least %d", minRetentionDays))
+	}
+	return nil
+}
+
+// findPolicy はIDでポリシーを取得し、存在しない場合は404エラーを返します
+func (s *AuditRetentionService) findPolicy(ctx context.Context, id uint) (*model.AuditRetentionPolicy, error) {
+	policy, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeRetentionPolicyNotFound, err)
+		}
+		s.logger.Error("Failed to fetch audit retention policy", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return policy, nil
+}
+
+// logPolicyFailure はポリシーの操作の失敗を監査ログに記録します
+func (s *AuditRetentionService) logPolicyFailure(ctx context.Context, actorID uint, action, resourceID string, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       action,
+		ResourceType: model.ResourceTypeAuditRetentionPolicy,
+		ResourceID:   resourceID,
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// retentionPolicyChanges は監査ログに記録するポリシーの内容を返します
+func retentionPolicyChanges(policy *model.AuditRetentionPolicy) map[string]interface{} {
+	return map[string]interface{}{
+		"name":           policy.Name,
+		"action":         policy.Action,
+		"resource_type":  policy.ResourceType,
+		"status":         policy.Status,
+		"retention_days": policy.RetentionDays,
+		"enabled":        policy.Enabled,
+	}
+}
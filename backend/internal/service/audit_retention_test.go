@@ -0,0 +1,40 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+func TestValidateRetentionPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  *model.AuditRetentionPolicy
+		wantErr bool
+	}{
+		{"Valid", &model.AuditRetentionPolicy{Name: "logins", Action: model.ActionLogin, RetentionDays: 90}, false},
+		{"Valid at the minimum", &model.AuditRetentionPolicy{Name: "all", RetentionDays: 30}, false},
+		{"Failed status", &model.AuditRetentionPolicy{Name: "failures", Status: model.AuditStatusFailed, RetentionDays: 365}, false},
+		{"Empty name", &model.AuditRetentionPolicy{RetentionDays: 90}, true},
+		{"Unknown status", &model.AuditRetentionPolicy{Name: "pending", Status: "pending", RetentionDays: 90}, true},
+		{"Shorter than the minimum", &model.AuditRetentionPolicy{Name: "short", RetentionDays: 29}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateRetentionPolicy(tt.policy, 30)
+
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var appErr *util.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, util.ErrCodeInvalidParameter, appErr.Code)
+		})
+	}
+}
@@ -12,26 +12,46 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/varubogu/effisio/backend/internal/model"
-	"github.com/varubogu/effisio/backend/internal/repository"
 	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
+// authUserStore はAuthServiceが使用するユーザーの永続化手段です（repository.UserRepository）
+type authUserStore interface {
+	FindByID(ctx context.Context, id uint) (*model.User, error)
+	FindByUsername(ctx context.Context, username string) (*model.User, error)
+	UpdateLastLogin(ctx context.Context, id uint, lastLogin time.Time) error
+}
+
+// refreshTokenStore はリフレッシュトークンの永続化手段です（repository.RefreshTokenRepository）
+type refreshTokenStore interface {
+	Create(ctx context.Context, token *model.RefreshToken) error
+	FindByTokenID(ctx context.Context, tokenID string) (*model.RefreshToken, error)
+	Revoke(ctx context.Context, tokenID string) error
+	RevokeAllByUserID(ctx context.Context, userID uint) error
+}
+
 // AuthService は認証関連のビジネスロジックを提供します
 type AuthService struct {
-	userRepo         *repository.UserRepository
-	refreshTokenRepo *repository.RefreshTokenRepository
+	userRepo         authUserStore
+	refreshTokenRepo refreshTokenStore
 	jwtService       *util.JWTService
 	logger           *zap.Logger
 	auditLogService  *AuditLogService
+	groupService     *GroupService
+	webhookService   *WebhookService
+	loginDetector    *LoginDetector // nil の場合は不審なログインを検知しない
 }
 
 // NewAuthService は新しいAuthServiceを作成します
 func NewAuthService(
-	userRepo *repository.UserRepository,
-	refreshTokenRepo *repository.RefreshTokenRepository,
+	userRepo authUserStore,
+	refreshTokenRepo refreshTokenStore,
 	jwtService *util.JWTService,
 	logger *zap.Logger,
 	auditLogService *AuditLogService,
+	groupService *GroupService,
+	webhookService *WebhookService,
+	loginDetector *LoginDetector,
 ) *AuthService {
 	return &AuthService{
 		userRepo:         userRepo,
@@ -39,13 +59,66 @@ func NewAuthService(
 		jwtService:       jwtService,
 		logger:           logger,
 		auditLogService:  auditLogService,
+		groupService:     groupService,
+		webhookService:   webhookService,
+		loginDetector:    loginDetector,
+	}
+}
+
+// permissionsFor はアクセストークンに含める権限を返します
+// ロールの権限に所属グループの権限を加えた、発行時点の実効権限です
+func (s *AuthService) permissionsFor(ctx context.Context, user *model.User) ([]string, error) {
+	if s.groupService == nil {
+		return util.GetPermissionsForRole(user.Role), nil
+	}
+	return s.groupService.EffectivePermissions(ctx, user)
+}
+
+// ログイン失敗の理由（Webhookの auth.login_failed イベントで通知します）
+const (
+	loginFailureUserNotFound    = "user_not_found"
+	loginFailureInactive        = "inactive"
+	loginFailureInvalidPassword = "invalid_password"
+	loginFailureBlockedSource   = "blocked_source"
+	loginFailureSecurityLockout = "security_lockout"
+)
+
+// emitLoginFailed はログインの失敗をWebhookで通知します
+// ユーザーが存在しない場合、userID は nil になります
+func (s *AuthService) emitLoginFailed(ctx context.Context, username string, userID *uint, reason string) {
+	if s.webhookService == nil {
+		return
 	}
+	s.webhookService.Emit(ctx, model.WebhookEventAuthLoginFailed, map[string]interface{}{
+		"username": username,
+		"user_id":  userID,
+		"reason":   reason,
+	})
+}
+
+// detectLogin はログインの試行を不審なログインの検知に渡し、ユーザーを停止したかを返します
+// 検知は試行の監査ログより前に行われる場合があるため、今回の試行は LoginAttempt として渡します
+func (s *AuthService) detectLogin(ctx context.Context, req *LoginRequest, username string, user *model.User, success bool) bool {
+	if s.loginDetector == nil {
+		return false
+	}
+	return s.loginDetector.Analyze(ctx, &LoginAttempt{
+		Username:  username,
+		User:      user,
+		Success:   success,
+		IPAddress: req.IPAddress,
+		UserAgent: req.UserAgent,
+	})
 }
 
 // LoginRequest はログインリクエストです
 type LoginRequest struct {
 	Username string `json:"username" binding:"required"`
 	Password string `json:"password" binding:"required"`
+
+	// 送信元（ハンドラーが設定し、監査ログの記録と不審なログインの検知に使用する）
+	IPAddress string `json:"-"`
+	UserAgent string `json:"-"`
 }
 
 // LoginResponse はログインレスポンスです
@@ -68,6 +141,28 @@ type RefreshTokenResponse struct {
 
 // Login はユーザー名とパスワードで認証します
 func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginResponse, error) {
+	// パスワードスプレーの送信元として拒否されているIPアドレスからのログインは認証しない
+	if s.loginDetector != nil {
+		if err := s.loginDetector.CheckSource(ctx, req.IPAddress); err != nil {
+			s.logger.Warn("Login attempt from blocked source", zap.String("username", req.Username), zap.String("ip_address", req.IPAddress))
+			if s.auditLogService != nil {
+				auditReq := &model.CreateAuditLogRequest{
+					UserID:       1, // システムユーザー
+					Action:       model.ActionLogin,
+					ResourceType: model.ResourceTypeUser,
+					ResourceID:   req.Username,
+					IPAddress:    req.IPAddress,
+					UserAgent:    req.UserAgent,
+					Status:       model.AuditStatusFailed,
+					ErrorMessage: "Login source is blocked",
+				}
+				s.auditLogService.LogAction(ctx, auditReq)
+			}
+			s.emitLoginFailed(ctx, req.Username, nil, loginFailureBlockedSource)
+			return nil, err
+		}
+	}
+
 	// ユーザー名でユーザーを取得
 	user, err := s.userRepo.FindByUsername(ctx, req.Username)
 	if err != nil {
@@ -80,19 +175,23 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 					Action:       model.ActionLogin,
 					ResourceType: model.ResourceTypeUser,
 					ResourceID:   req.Username,
+					IPAddress:    req.IPAddress,
+					UserAgent:    req.UserAgent,
 					Status:       model.AuditStatusFailed,
 					ErrorMessage: "User not found",
 				}
 				s.auditLogService.LogAction(ctx, auditReq)
 			}
+			s.emitLoginFailed(ctx, req.Username, nil, loginFailureUserNotFound)
+			s.detectLogin(ctx, req, req.Username, nil, false)
 			return nil, util.NewUnauthorizedError(util.ErrCodeInvalidCredentials, errors.New("invalid credentials"))
 		}
 		s.logger.Error("Failed to find user", zap.Error(err))
 		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
-	// ユーザーのステータスをチェック
-	if user.Status != model.UserStatusActive {
+	// ユーザーのステータスと利用期限をチェック
+	if !user.CanLogin(time.Now()) {
 		s.logger.Warn("Login attempt by inactive user", zap.String("username", req.Username), zap.String("status", user.Status))
 		// 監査ログに失敗を記録（ユーザーが非アクティブ）
 		if s.auditLogService != nil {
@@ -101,11 +200,15 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 				Action:       model.ActionLogin,
 				ResourceType: model.ResourceTypeUser,
 				ResourceID:   user.Username,
+				IPAddress:    req.IPAddress,
+				UserAgent:    req.UserAgent,
 				Status:       model.AuditStatusFailed,
 				ErrorMessage: "User account is not active",
 			}
 			s.auditLogService.LogAction(ctx, auditReq)
 		}
+		s.emitLoginFailed(ctx, user.Username, &user.ID, loginFailureInactive)
+		s.detectLogin(ctx, req, user.Username, user, false)
 		return nil, util.NewForbiddenError(util.ErrCodeInsufficientPermission, errors.New("user account is not active"))
 	}
 
@@ -119,16 +222,45 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 				Action:       model.ActionLogin,
 				ResourceType: model.ResourceTypeUser,
 				ResourceID:   user.Username,
+				IPAddress:    req.IPAddress,
+				UserAgent:    req.UserAgent,
 				Status:       model.AuditStatusFailed,
 				ErrorMessage: "Invalid password",
 			}
 			s.auditLogService.LogAction(ctx, auditReq)
 		}
+		s.emitLoginFailed(ctx, user.Username, &user.ID, loginFailureInvalidPassword)
+		s.detectLogin(ctx, req, user.Username, user, false)
 		return nil, util.NewUnauthorizedError(util.ErrCodeInvalidCredentials, errors.New("invalid credentials"))
 	}
 
+	// 不審なログインを検知してユーザーを停止した場合は、トークンを発行しない
+	if s.detectLogin(ctx, req, user.Username, user, true) {
+		s.logger.Warn("Login rejected by security lockout", zap.String("username", user.Username))
+		// 監査ログに失敗を記録（不審なログインによる停止）
+		if s.auditLogService != nil {
+			auditReq := &model.CreateAuditLogRequest{
+				UserID:       user.ID,
+				Action:       model.ActionLogin,
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   user.Username,
+				IPAddress:    req.IPAddress,
+				UserAgent:    req.UserAgent,
+				Status:       model.AuditStatusFailed,
+				ErrorMessage: "User account is locked by security alert",
+			}
+			s.auditLogService.LogAction(ctx, auditReq)
+		}
+		s.emitLoginFailed(ctx, user.Username, &user.ID, loginFailureSecurityLockout)
+		return nil, util.NewForbiddenError(util.ErrCodeAccountLocked, errors.New("user account is locked"))
+	}
+
 	// 権限リストを取得
-	permissions := util.GetPermissionsForRole(user.Role)
+	permissions, err := s.permissionsFor(ctx, user)
+	if err != nil {
+		s.logger.Error("Failed to resolve permissions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
 
 	// アクセストークンを生成
 	accessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Username, user.Role, permissions)
@@ -160,7 +292,7 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 	// 最終ログイン時刻を更新
 	now := time.Now()
 	user.LastLogin = &now
-	if err := s.userRepo.Update(ctx, user); err != nil {
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
 		s.logger.Warn("Failed to update last login time", zap.Error(err))
 		// ログイン時刻の更新失敗は致命的ではないので続行
 	}
@@ -174,6 +306,8 @@ func (s *AuthService) Login(ctx context.Context, req *LoginRequest) (*LoginRespo
 			Action:       model.ActionLogin,
 			ResourceType: model.ResourceTypeUser,
 			ResourceID:   user.Username,
+			IPAddress:    req.IPAddress,
+			UserAgent:    req.UserAgent,
 			Status:       model.AuditStatusSuccess,
 		}
 		s.auditLogService.LogAction(ctx, auditReq)
@@ -222,14 +356,18 @@ func (s *AuthService) RefreshToken(ctx context.Context, req *RefreshTokenRequest
 		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
-	// ユーザーのステータスをチェック
-	if user.Status != model.UserStatusActive {
+	// ユーザーのステータスと利用期限をチェック
+	if !user.CanLogin(time.Now()) {
 		s.logger.Warn("Refresh token used by inactive user", zap.Uint("user_id", user.ID))
 		return nil, util.NewForbiddenError(util.ErrCodeInsufficientPermission, errors.New("user account is not active"))
 	}
 
 	// 権限リストを取得
-	permissions := util.GetPermissionsForRole(user.Role)
+	permissions, err := s.permissionsFor(ctx, user)
+	if err != nil {
+		s.logger.Error("Failed to resolve permissions", zap.Uint("user_id", user.ID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
 
 	// 新しいアクセストークンを生成
 	newAccessToken, err := s.jwtService.GenerateAccessToken(user.ID, user.Username, user.Role, permissions)
@@ -13,7 +13,6 @@ import (
 	"gorm.io/gorm"
 
 	"github.com/varubogu/effisio/backend/internal/model"
-	"github.com/varubogu/effisio/backend/internal/repository"
 	"github.com/varubogu/effisio/backend/pkg/util"
 	"go.uber.org/zap"
 )
@@ -23,8 +22,8 @@ type MockUserRepository struct {
 	mock.Mock
 }
 
-func (m *MockUserRepository) FindAll(ctx context.Context, params *util.PaginationParams) ([]*model.User, int64, error) {
-	args := m.Called(ctx, params)
+func (m *MockUserRepository) FindAll(ctx context.Context, params *util.PaginationParams, attributes map[string]string) ([]*model.User, int64, error) {
+	args := m.Called(ctx, params, attributes)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
 	}
@@ -63,6 +62,18 @@ func (m *MockUserRepository) Update(ctx context.Context, user *model.User) error
 	return m.Called(ctx, user).Error(0)
 }
 
+func (m *MockUserRepository) UpdateWithVersion(ctx context.Context, user *model.User, expectedVersion uint) error {
+	args := m.Called(ctx, user, expectedVersion)
+	if args.Error(0) == nil {
+		user.Version = expectedVersion + 1
+	}
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id uint, lastLogin time.Time) error {
+	return m.Called(ctx, id, lastLogin).Error(0)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id uint) error {
 	return m.Called(ctx, id).Error(0)
 }
@@ -123,6 +134,42 @@ func (m *MockUserRepository) GetLastLoginStats(ctx context.Context, days int) ([
 	}), args.Error(1)
 }
 
+func (m *MockUserRepository) FindDeleted(ctx context.Context, params *util.PaginationParams) ([]*model.User, int64, error) {
+	args := m.Called(ctx, params)
+	if args.Get(0) == nil {
+		return nil, args.Get(1).(int64), args.Error(2)
+	}
+	return args.Get(0).([]*model.User), args.Get(1).(int64), args.Error(2)
+}
+
+func (m *MockUserRepository) FindDeletedByID(ctx context.Context, id uint) (*model.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Restore(ctx context.Context, id uint) error {
+	return m.Called(ctx, id).Error(0)
+}
+
+func (m *MockUserRepository) FindDeletedBefore(ctx context.Context, cutoff time.Time) ([]*model.User, error) {
+	args := m.Called(ctx, cutoff)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.User), args.Error(1)
+}
+
+func (m *MockUserRepository) Suspend(ctx context.Context, user *model.User, changedAt time.Time) error {
+	return m.Called(ctx, user, changedAt).Error(0)
+}
+
+func (m *MockUserRepository) Purge(ctx context.Context, id uint) error {
+	return m.Called(ctx, id).Error(0)
+}
+
 // MockRefreshTokenRepository mocks the RefreshTokenRepository
 type MockRefreshTokenRepository struct {
 	mock.Mock
@@ -175,7 +222,7 @@ func TestAuthServiceLogin_Success(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 	hashedPassword := getHashedPassword(t, "password123")
@@ -191,9 +238,7 @@ func TestAuthServiceLogin_Success(t *testing.T) {
 	}
 
 	mockUserRepo.On("FindByUsername", ctx, "testuser").Return(user, nil)
-	mockUserRepo.On("Update", ctx, mock.MatchedBy(func(u *model.User) bool {
-		return u.ID == 1 && u.LastLogin != nil
-	})).Return(nil)
+	mockUserRepo.On("UpdateLastLogin", ctx, uint(1), mock.AnythingOfType("time.Time")).Return(nil)
 	mockTokenRepo.On("Create", ctx, mock.MatchedBy(func(t *model.RefreshToken) bool {
 		return t.UserID == 1 && !t.Revoked
 	})).Return(nil)
@@ -220,7 +265,7 @@ func TestAuthServiceLogin_InvalidUsername(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -242,7 +287,7 @@ func TestAuthServiceLogin_InvalidPassword(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 	hashedPassword := getHashedPassword(t, "correctpassword")
@@ -274,7 +319,7 @@ func TestAuthServiceLogin_InactiveUser(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 	hashedPassword := getHashedPassword(t, "password123")
@@ -302,11 +347,62 @@ func TestAuthServiceLogin_InactiveUser(t *testing.T) {
 	mockUserRepo.AssertExpectations(t)
 }
 
+func TestAuthServiceLogin_RejectedBySecurityLockout(t *testing.T) {
+	mockUserRepo := new(MockUserRepository)
+	mockTokenRepo := new(MockRefreshTokenRepository)
+	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
+	detector, mocks := newTestLoginDetector(newIPConfig(model.SecurityActionLockout))
+	// 停止とトークンの無効化は認証サービスと同じリポジトリで行う
+	detector.userRepo = mockUserRepo
+	detector.refreshTokenRepo = mockTokenRepo
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, detector)
+
+	ctx := context.Background()
+	user := &model.User{
+		ID:           1,
+		Username:     "testuser",
+		PasswordHash: getHashedPassword(t, "password123"),
+		Role:         "user",
+		Status:       model.UserStatusActive,
+	}
+	since := detectorNow.Add(-30 * 24 * time.Hour)
+
+	mockUserRepo.On("FindByUsername", ctx, "testuser").Return(user, nil)
+	mocks.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+	mocks.history.On("ExistsSuccessfulLogin", ctx, user.ID, "203.0.113.5", "", since).Return(false, nil)
+	mocks.alerts.On("ExistsOpen", ctx, model.SecurityRuleNewIP, &user.ID, "203.0.113.5", detectorNow.Add(-time.Hour)).Return(false, nil)
+	mocks.alerts.On("Create", ctx, mock.AnythingOfType("*model.SecurityAlert")).Return(nil)
+	mockUserRepo.On("Suspend", ctx, user, detectorNow).Return(nil)
+	mockTokenRepo.On("RevokeAllByUserID", ctx, user.ID).Return(nil)
+
+	req := &LoginRequest{
+		Username:  "testuser",
+		Password:  "password123",
+		IPAddress: "203.0.113.5",
+	}
+
+	resp, err := authService.Login(ctx, req)
+
+	require.Error(t, err)
+	assert.Nil(t, resp)
+	var appErr *util.AppError
+	require.True(t, errors.As(err, &appErr))
+	assert.Equal(t, util.ErrCodeAccountLocked, appErr.Code)
+
+	// トークンを発行せず、最終ログイン時刻も更新しない
+	mockUserRepo.AssertExpectations(t)
+	mockTokenRepo.AssertExpectations(t)
+	mocks.alerts.AssertExpectations(t)
+	mocks.history.AssertExpectations(t)
+	mockTokenRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	mockUserRepo.AssertNotCalled(t, "UpdateLastLogin", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestAuthServiceRefreshToken_Success(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -355,7 +451,7 @@ func TestAuthServiceRefreshToken_InvalidToken(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -373,7 +469,7 @@ func TestAuthServiceRefreshToken_RevokedToken(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -407,7 +503,7 @@ func TestAuthServiceLogout_Success(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -427,7 +523,7 @@ func TestAuthServiceLogout_InvalidToken(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -441,7 +537,7 @@ func TestAuthServiceLogoutAll_Success(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -457,7 +553,7 @@ func TestAuthServiceLogoutAll_DatabaseError(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -473,7 +569,7 @@ func TestAuthServiceRefreshToken_UserNotFound(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -506,7 +602,7 @@ func TestAuthServiceRefreshToken_InactiveUser(t *testing.T) {
 	mockUserRepo := new(MockUserRepository)
 	mockTokenRepo := new(MockRefreshTokenRepository)
 	jwtService := util.NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
-	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger())
+	authService := NewAuthService(mockUserRepo, mockTokenRepo, jwtService, getLogger(), nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -2,10 +2,8 @@ package service
 
 import (
 	"context"
-	"time"
 
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 
 	"github.com/varubogu/effisio/backend/internal/model"
 	"github.com/varubogu/effisio/backend/internal/repository"
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/mailer"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// EmailVerificationService はメールアドレスの確認に関するビジネスロジックを提供します
+// メールアドレスの変更は確認が完了するまで pending_email に保持し、現在のアドレスは変更しません
+type EmailVerificationService struct {
+	userRepo        *repository.UserRepository
+	jwtService      *util.JWTService
+	mailer          mailer.Mailer
+	verificationTTL time.Duration
+	verificationURL string
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+}
+
+// NewEmailVerificationService は新しいEmailVerificationServiceを作成します
+func NewEmailVerificationService(
+	userRepo *repository.UserRepository,
+	jwtService *util.JWTService,
+	mailer mailer.Mailer,
+	verificationTTL time.Duration,
+	verificationURL string,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *EmailVerificationService {
+	return &EmailVerificationService{
+		userRepo:        userRepo,
+		jwtService:      jwtService,
+		mailer:          mailer,
+		verificationTTL: verificationTTL,
+		verificationURL: verificationURL,
+		logger:          logger,
+		auditLogService: auditLogService,
+	}
+}
+
+// requestEmailChange はメールアドレスの変更を確認待ちにします
+// 呼び出し側でユーザーを保存した後に Send で確認メールを送信してください
+func requestEmailChange(user *model.User, email string) {
+	if email == user.Email {
+		// 元のアドレスに戻した場合は変更依頼を取り消す
+		user.PendingEmail = ""
+		if user.EmailVerified {
+			user.EmailTokenID = ""
+		}
+		return
+	}
+	user.PendingEmail = email
+	user.EmailTokenID = uuid.New().String()
+}
+
+// Send は確認メールを送信します
+// 送信先は確認待ちのアドレス、なければ未確認の現在のアドレスです
+func (s *EmailVerificationService) Send(ctx context.Context, user *model.User) error {
+	target := user.EmailVerificationTarget()
+	if target == "" {
+		return errors.New("email address is already verified")
+	}
+	if user.EmailTokenID == "" {
+		return errors.New("email verification token is not issued")
+	}
+
+	token, err := s.jwtService.GenerateEmailVerificationToken(user.ID, target, user.EmailTokenID, time.Now().Add(s.verificationTTL))
+	if err != nil {
+		return err
+	}
+	link, err := linkWithToken(s.verificationURL, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, buildEmailVerificationMessage(user, target, link, user.PendingEmail != "")); err != nil {
+		return err
+	}
+
+	s.logger.Info("Email verification sent", zap.Uint("user_id", user.ID))
+	return nil
+}
+
+// Resend は確認メールを再送します
+// トークンを再発行するため、以前に送信したリンクは使用できなくなります
+func (s *EmailVerificationService) Resend(ctx context.Context, actorID uint, userID uint) (*model.UserResponse, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch user", zap.Uint("id", userID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	target := user.EmailVerificationTarget()
+	if target == "" {
+		return nil, util.NewConflictError(util.ErrCodeEmailAlreadyVerified, errors.New("email address is already verified"))
+	}
+
+	tokenID := uuid.New().String()
+	if err := s.userRepo.UpdateEmailToken(ctx, user.ID, tokenID); err != nil {
+		s.logger.Error("Failed to issue email verification token", zap.Uint("id", userID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	user.EmailTokenID = tokenID
+
+	if err := s.Send(ctx, user); err != nil {
+		s.logger.Error("Failed to resend email verification", zap.Uint("id", userID), zap.Error(err))
+		if s.auditLogService != nil {
+			auditReq := &model.CreateAuditLogRequest{
+				UserID:       actorID,
+				Action:       model.ActionResend,
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   user.Username,
+				Status:       model.AuditStatusFailed,
+				ErrorMessage: err.Error(),
+			}
+			s.auditLogService.LogAction(ctx, auditReq)
+		}
+		return nil, util.NewInternalError(util.ErrCodeMailDeliveryError, err)
+	}
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionResend,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  map[string]interface{}{"email_verification_sent_to": target},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return user.ToResponse(), nil
+}
+
+// Confirm は確認トークンを検証してメールアドレスを確認済みにします
+// 変更中の場合はこの時点で新しいアドレスに切り替わります
+func (s *EmailVerificationService) Confirm(ctx context.Context, token string) (*model.UserResponse, error) {
+	invalid := util.NewBadRequestError(util.ErrCodeInvalidEmailVerification, errors.New("verification link is invalid or expired"))
+
+	claims, err := s.jwtService.ValidateEmailVerificationToken(token)
+	if err != nil {
+		s.logger.Info("Invalid email verification token", zap.Error(err))
+		return nil, invalid
+	}
+
+	user, err := s.userRepo.FindByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		s.logger.Error("Failed to fetch user", zap.Uint("id", claims.UserID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// 再送・再変更により再発行された場合、古いトークンは使用できない
+	if user.EmailTokenID == "" || user.EmailTokenID != claims.TokenID || user.EmailVerificationTarget() != claims.Email {
+		return nil, invalid
+	}
+
+	// 確認待ちの間に他のユーザーが同じアドレスを使用した場合は切り替えられない
+	if claims.Email != user.Email {
+		existingUser, err := s.userRepo.FindByEmail(ctx, claims.Email)
+		if err == nil && existingUser.ID != user.ID {
+			return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("email already exists"))
+		}
+	}
+
+	before := map[string]interface{}{
+		"email":          user.Email,
+		"email_verified": user.EmailVerified,
+	}
+
+	if err := s.userRepo.ConfirmEmail(ctx, user.ID, claims.TokenID, claims.Email); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		s.logger.Error("Failed to confirm email", zap.Uint("id", user.ID), zap.Error(err))
+		if s.auditLogService != nil {
+			auditReq := &model.CreateAuditLogRequest{
+				UserID:       user.ID,
+				Action:       model.ActionVerify,
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   user.Username,
+				Status:       model.AuditStatusFailed,
+				ErrorMessage: err.Error(),
+			}
+			s.auditLogService.LogAction(ctx, auditReq)
+		}
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	user.Email = claims.Email
+	user.PendingEmail = ""
+	user.EmailTokenID = ""
+	user.EmailVerified = true
+	user.Version++
+
+	s.logger.Info("Email verified", zap.Uint("id", user.ID))
+
+	// 監査ログに成功を記録（実行者は本人）
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       user.ID,
+			Action:       model.ActionVerify,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: before,
+				After: map[string]interface{}{
+					"email":          user.Email,
+					"email_verified": user.EmailVerified,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return user.ToResponse(), nil
+}
+
+// buildEmailVerificationMessage は確認メールを作成します
+func buildEmailVerificationMessage(user *model.User, to, link string, changing bool) *mailer.Message {
+	name := user.FullName
+	if name == "" {
+		name = user.Username
+	}
+
+	intro := "Effisio に登録されたメールアドレスの確認をお願いします。"
+	if changing {
+		intro = "Effisio のメールアドレスをこのアドレスに変更する依頼を受け付けました。\n確認が完了するまで、現在のメールアドレスは変更されません。"
+	}
+
+	body := fmt.Sprintf(`%s 様
+
+%s
+以下のリンクを開いて確認を完了してください。
+
+%s
+
+心当たりがない場合は、このメールを破棄してください。
+`, name, intro, link)
+
+	return &mailer.Message{
+		To:      []string{to},
+		Subject: "Effisio メールアドレスの確認",
+		Body:    body,
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestRequestEmailChange(t *testing.T) {
+	t.Run("New address is kept pending", func(t *testing.T) {
+		user := &model.User{Email: "old@example.com", EmailVerified: true}
+
+		requestEmailChange(user, "new@example.com")
+
+		assert.Equal(t, "old@example.com", user.Email)
+		assert.Equal(t, "new@example.com", user.PendingEmail)
+		assert.NotEmpty(t, user.EmailTokenID)
+		assert.True(t, user.EmailVerified)
+		assert.Equal(t, "new@example.com", user.EmailVerificationTarget())
+	})
+
+	t.Run("Changing again rotates the token", func(t *testing.T) {
+		user := &model.User{Email: "old@example.com", PendingEmail: "typo@example.com", EmailTokenID: "first", EmailVerified: true}
+
+		requestEmailChange(user, "new@example.com")
+
+		assert.Equal(t, "new@example.com", user.PendingEmail)
+		assert.NotEqual(t, "first", user.EmailTokenID)
+	})
+
+	t.Run("Reverting to the current address cancels the change", func(t *testing.T) {
+		user := &model.User{Email: "old@example.com", PendingEmail: "new@example.com", EmailTokenID: "token", EmailVerified: true}
+
+		requestEmailChange(user, "old@example.com")
+
+		assert.Empty(t, user.PendingEmail)
+		assert.Empty(t, user.EmailTokenID)
+		assert.Empty(t, user.EmailVerificationTarget())
+	})
+
+	t.Run("Unverified address keeps its token when reverting", func(t *testing.T) {
+		user := &model.User{Email: "old@example.com", PendingEmail: "new@example.com", EmailTokenID: "token"}
+
+		requestEmailChange(user, "old@example.com")
+
+		assert.Empty(t, user.PendingEmail)
+		assert.Equal(t, "token", user.EmailTokenID)
+		assert.Equal(t, "old@example.com", user.EmailVerificationTarget())
+	})
+}
+
+func TestBuildEmailVerificationMessage(t *testing.T) {
+	user := &model.User{Username: "taro", Email: "old@example.com"}
+	link := "http://localhost:3000/email/verify?token=abc.def"
+
+	msg := buildEmailVerificationMessage(user, "new@example.com", link, true)
+
+	assert.Equal(t, []string{"new@example.com"}, msg.To)
+	assert.Contains(t, msg.Body, "taro 様")
+	assert.Contains(t, msg.Body, link)
+	assert.Contains(t, msg.Body, "現在のメールアドレスは変更されません")
+
+	msg = buildEmailVerificationMessage(user, "old@example.com", link, false)
+	assert.NotContains(t, msg.Body, "現在のメールアドレスは変更されません")
+}
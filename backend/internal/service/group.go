@@ -0,0 +1,466 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// GroupService はユーザーグループとグループに付与した権限に関するビジネスロジックを提供します
+type GroupService struct {
+	groupRepo       *repository.GroupRepository
+	userRepo        *repository.UserRepository
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+}
+
+// NewGroupService は新しいGroupServiceを作成します
+func NewGroupService(
+	groupRepo *repository.GroupRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *GroupService {
+	return &GroupService{
+		groupRepo:       groupRepo,
+		userRepo:        userRepo,
+		logger:          logger,
+		auditLogService: auditLogService,
+	}
+}
+
+// List はグループ一覧を取得します
+func (s *GroupService) List(ctx context.Context, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	groups, total, err := s.groupRepo.FindAll(ctx, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch groups", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	return util.NewPaginatedResponse(groups, total, params), nil
+}
+
+// GetByID はIDでグループを取得します
+func (s *GroupService) GetByID(ctx context.Context, id uint) (*model.Group, error) {
+	return s.findGroup(ctx, id)
+}
+
+// Create はグループを作成します
+func (s *GroupService) Create(ctx context.Context, actorID uint, req *model.CreateGroupRequest) (*model.Group, error) {
+	if err := validateGroupPermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	exists, err := s.groupRepo.ExistsByName(ctx, req.Name)
+	if err != nil {
+		s.logger.Error("Failed to check group name existence", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return nil, util.NewConflictError(util.ErrCodeGroupAlreadyExists, errors.New("group name already exists"))
+	}
+
+	group := &model.Group{
+		Name:        req.Name,
+		Description: req.Description,
+		Permissions: normalizePermissions(req.Permissions),
+	}
+	if req.ParentID != nil && *req.ParentID != 0 {
+		if _, err := s.findGroup(ctx, *req.ParentID); err != nil {
+			return nil, err
+		}
+		group.ParentID = req.ParentID
+	}
+
+	if err := s.groupRepo.Create(ctx, group); err != nil {
+		s.logger.Error("Failed to create group", zap.Error(err))
+		s.logGroupFailure(ctx, actorID, model.ActionCreate, req.Name, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Group created", zap.Uint("id", group.ID), zap.String("name", group.Name))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionCreate,
+			ResourceType: model.ResourceTypeGroup,
+			ResourceID:   groupResourceID(group.ID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  groupChanges(group),
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return group, nil
+}
+
+// Update はグループを更新します
+// 親グループを変更する場合、自身や子孫を親に指定することはできません
+func (s *GroupService) Update(ctx context.Context, actorID uint, id uint, req *model.UpdateGroupRequest) (*model.Group, error) {
+	group, err := s.findGroup(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := groupChanges(group)
+
+	if req.Name != nil && *req.Name != group.Name {
+		exists, err := s.groupRepo.ExistsByName(ctx, *req.Name)
+		if err != nil {
+			s.logger.Error("Failed to check group name existence", zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+		if exists {
+			return nil, util.NewConflictError(util.ErrCodeGroupAlreadyExists, errors.New("group name already exists"))
+		}
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		if err := validateGroupPermissions(*req.Permissions); err != nil {
+			return nil, err
+		}
+		group.Permissions = normalizePermissions(*req.Permissions)
+	}
+	if req.ParentID != nil {
+		if *req.ParentID == 0 {
+			group.ParentID = nil
+		} else {
+			if err := s.checkParent(ctx, id, *req.ParentID); err != nil {
+				return nil, err
+			}
+			group.ParentID = req.ParentID
+		}
+	}
+
+	if err := s.groupRepo.Update(ctx, group); err != nil {
+		s.logger.Error("Failed to update group", zap.Uint("id", id), zap.Error(err))
+		s.logGroupFailure(ctx, actorID, model.ActionUpdate, groupResourceID(id), err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Group updated", zap.Uint("id", id))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeGroup,
+			ResourceID:   groupResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: before,
+				After:  groupChanges(group),
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return group, nil
+}
+
+// Delete はグループを削除します
+// 子グループがある場合は、子グループの親を変更するか削除してからでないと削除できません
+func (s *GroupService) Delete(ctx context.Context, actorID uint, id uint) error {
+	group, err := s.findGroup(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	children, err := s.groupRepo.CountChildren(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to count child groups", zap.Uint("id", id), zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if children > 0 {
+		return util.NewConflictError(util.ErrCodeGroupHasChildren, errors.New("group has child groups"))
+	}
+
+	if err := s.groupRepo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete group", zap.Uint("id", id), zap.Error(err))
+		s.logGroupFailure(ctx, actorID, model.ActionDelete, groupResourceID(id), err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Group deleted", zap.Uint("id", id))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionDelete,
+			ResourceType: model.ResourceTypeGroup,
+			ResourceID:   groupResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: groupChanges(group),
+				After:  map[string]interface{}{},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return nil
+}
+
+// ListMembers はグループに直接所属するユーザーの一覧を取得します
+func (s *GroupService) ListMembers(ctx context.Context, id uint, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	if _, err := s.findGroup(ctx, id); err != nil {
+		return nil, err
+	}
+
+	users, total, err := s.groupRepo.FindMembers(ctx, id, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch group members", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	responses := make([]*model.UserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToResponse()
+	}
+
+	return util.NewPaginatedResponse(responses, total, params), nil
+}
+
+// AddMembers はグループにユーザーを追加します
+// 既に所属しているユーザーは無視し、新たに追加したユーザーのみ監査ログに記録します
+func (s *GroupService) AddMembers(ctx context.Context, actorID uint, id uint, req *model.GroupMembersRequest) error {
+	if _, err := s.findGroup(ctx, id); err != nil {
+		return err
+	}
+
+	userIDs := uniqueIDs(req.UserIDs)
+	for _, userID := range userIDs {
+		if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return util.NewNotFoundError(util.ErrCodeUserNotFound, fmt.Errorf("user %d not found", userID))
+			}
+			s.logger.Error("Failed to fetch user", zap.Uint("id", userID), zap.Error(err))
+			return util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+	}
+
+	existing, err := s.groupRepo.FindMemberIDs(ctx, id, userIDs)
+	if err != nil {
+		s.logger.Error("Failed to fetch group members", zap.Uint("id", id), zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	isMember := make(map[uint]bool, len(existing))
+	for _, userID := range existing {
+		isMember[userID] = true
+	}
+
+	added := make([]uint, 0, len(userIDs))
+	members := make([]*model.GroupMember, 0, len(userIDs))
+	for _, userID := range userIDs {
+		if isMember[userID] {
+			continue
+		}
+		added = append(added, userID)
+		members = append(members, &model.GroupMember{GroupID: id, UserID: userID, AddedBy: actorID})
+	}
+	if len(members) == 0 {
+		return nil
+	}
+
+	if err := s.groupRepo.AddMembers(ctx, members); err != nil {
+		s.logger.Error("Failed to add group members", zap.Uint("id", id), zap.Error(err))
+		s.logGroupFailure(ctx, actorID, model.ActionAddMember, groupResourceID(id), err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Group members added", zap.Uint("id", id), zap.Int("count", len(added)))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionAddMember,
+			ResourceType: model.ResourceTypeGroup,
+			ResourceID:   groupResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  map[string]interface{}{"user_ids": added},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return nil
+}
+
+// RemoveMember はグループからユーザーを削除します
+func (s *GroupService) RemoveMember(ctx context.Context, actorID uint, id uint, userID uint) error {
+	if _, err := s.findGroup(ctx, id); err != nil {
+		return err
+	}
+
+	if err := s.groupRepo.RemoveMember(ctx, id, userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return util.NewNotFoundError(util.ErrCodeGroupMemberNotFound, err)
+		}
+		s.logger.Error("Failed to remove group member", zap.Uint("id", id), zap.Uint("user_id", userID), zap.Error(err))
+		s.logGroupFailure(ctx, actorID, model.ActionRemoveMember, groupResourceID(id), err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Group member removed", zap.Uint("id", id), zap.Uint("user_id", userID))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionRemoveMember,
+			ResourceType: model.ResourceTypeGroup,
+			ResourceID:   groupResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"user_ids": []uint{userID}},
+				After:  map[string]interface{}{},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return nil
+}
+
+// EffectivePermissions はユーザーの実効権限を返します
+// ロールの権限と、所属グループ（親グループを含む）に付与された権限の和集合です
+func (s *GroupService) EffectivePermissions(ctx context.Context, user *model.User) ([]string, error) {
+	groupPermissions, err := s.groupRepo.FindPermissionsByUserID(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return unionPermissions(util.GetPermissionsForRole(user.Role), groupPermissions), nil
+}
+
+// findGroup はIDでグループを取得します
+func (s *GroupService) findGroup(ctx context.Context, id uint) (*model.Group, error) {
+	group, err := s.groupRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeGroupNotFound, err)
+		}
+		s.logger.Error("Failed to fetch group", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return group, nil
+}
+
+// checkParent は親グループに指定できるかチェックします
+// 親グループの祖先に自身が含まれる場合は循環になるため指定できません
+func (s *GroupService) checkParent(ctx context.Context, id, parentID uint) error {
+	if parentID == id {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("group cannot be its own parent"))
+	}
+	if _, err := s.findGroup(ctx, parentID); err != nil {
+		return err
+	}
+
+	ancestors, err := s.groupRepo.FindAncestorIDs(ctx, parentID)
+	if err != nil {
+		s.logger.Error("Failed to fetch ancestor groups", zap.Uint("id", parentID), zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	for _, ancestorID := range ancestors {
+		if ancestorID == id {
+			return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("group cannot be nested under its own descendant"))
+		}
+	}
+	return nil
+}
+
+// logGroupFailure はグループの操作失敗を監査ログに記録します
+func (s *GroupService) logGroupFailure(ctx context.Context, actorID uint, action, resourceID string, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       action,
+		ResourceType: model.ResourceTypeGroup,
+		ResourceID:   resourceID,
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// groupResourceID は監査ログ上のグループのリソースIDを返します
+func groupResourceID(id uint) string {
+	return fmt.Sprintf("group-%d", id)
+}
+
+// groupChanges は監査ログに記録するグループの内容を返します
+func groupChanges(group *model.Group) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        group.Name,
+		"parent_id":   group.ParentID,
+		"permissions": []string(group.Permissions),
+	}
+}
+
+// validateGroupPermissions はグループに付与する権限が定義済みかチェックします
+func validateGroupPermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !util.IsValidPermission(permission) {
+			return util.NewValidationError(
+				map[string]string{"permissions": fmt.Sprintf("unknown permission %q", permission)},
+				errors.New("unknown permission"),
+			)
+		}
+	}
+	return nil
+}
+
+// normalizePermissions は権限の重複を除いて並べ替えます
+func normalizePermissions(permissions []string) model.StringList {
+	return model.StringList(unionPermissions(permissions))
+}
+
+// unionPermissions は複数の権限リストの和集合を並べ替えて返します
+func unionPermissions(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, list := range lists {
+		for _, permission := range list {
+			if !seen[permission] {
+				seen[permission] = true
+				result = append(result, permission)
+			}
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// uniqueIDs は重複を除いたIDを指定された順序のまま返します
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	result := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+	return result
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+func TestUnionPermissions(t *testing.T) {
+	tests := []struct {
+		name     string
+		lists    [][]string
+		expected []string
+	}{
+		{
+			name:     "Role only",
+			lists:    [][]string{{"tasks:write", "tasks:read"}, nil},
+			expected: []string{"tasks:read", "tasks:write"},
+		},
+		{
+			name:     "Role and group grants",
+			lists:    [][]string{{"tasks:read"}, {"users:read", "tasks:read"}},
+			expected: []string{"tasks:read", "users:read"},
+		},
+		{
+			name:     "No permissions",
+			lists:    [][]string{},
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, unionPermissions(tt.lists...))
+		})
+	}
+}
+
+func TestValidateGroupPermissions(t *testing.T) {
+	assert.NoError(t, validateGroupPermissions([]string{"users:read", "settings:write"}))
+	assert.NoError(t, validateGroupPermissions(nil))
+
+	err := validateGroupPermissions([]string{"users:read", "users:impersonate"})
+	var appErr *util.AppError
+	if assert.True(t, errors.As(err, &appErr)) {
+		assert.Equal(t, util.ErrCodeValidationError, appErr.Code)
+	}
+}
+
+func TestNormalizePermissions(t *testing.T) {
+	assert.Equal(t, model.StringList{"tasks:read", "users:read"}, normalizePermissions([]string{"users:read", "tasks:read", "users:read"}))
+	assert.Equal(t, model.StringList{}, normalizePermissions(nil))
+}
+
+func TestUniqueIDs(t *testing.T) {
+	assert.Equal(t, []uint{3, 1, 2}, uniqueIDs([]uint{3, 1, 3, 2, 1}))
+}
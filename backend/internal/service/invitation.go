@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/mailer"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// InvitationService はユーザー招待に関するビジネスロジックを提供します
+type InvitationService struct {
+	userRepo        *repository.UserRepository
+	invitationRepo  *repository.InvitationRepository
+	jwtService      *util.JWTService
+	mailer          mailer.Mailer
+	invitationTTL   time.Duration
+	invitationURL   string
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+}
+
+// NewInvitationService は新しいInvitationServiceを作成します
+func NewInvitationService(
+	userRepo *repository.UserRepository,
+	invitationRepo *repository.InvitationRepository,
+	jwtService *util.JWTService,
+	mailer mailer.Mailer,
+	invitationTTL time.Duration,
+	invitationURL string,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *InvitationService {
+	return &InvitationService{
+		userRepo:        userRepo,
+		invitationRepo:  invitationRepo,
+		jwtService:      jwtService,
+		mailer:          mailer,
+		invitationTTL:   invitationTTL,
+		invitationURL:   invitationURL,
+		logger:          logger,
+		auditLogService: auditLogService,
+	}
+}
+
+// Invite はパスワード未設定の招待中ユーザーを作成し、招待メールを送信します
+// メール送信に失敗しても招待は残るため、再送で送り直せます
+func (s *InvitationService) Invite(ctx context.Context, actorID uint, req *model.InviteUserRequest) (*model.InviteUserResponse, error) {
+	// ユーザー名の重複チェック
+	exists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
+	if err != nil {
+		s.logger.Error("Failed to check username existence", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("username already exists"))
+	}
+
+	// メールアドレスの重複チェック
+	exists, err = s.userRepo.ExistsByEmail(ctx, req.Email)
+	if err != nil {
+		s.logger.Error("Failed to check email existence", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("email already exists"))
+	}
+
+	// パスワードは受諾時に本人が設定するため空のまま作成（空のハッシュではログインできない）
+	user := &model.User{
+		Username:   req.Username,
+		Email:      req.Email,
+		FullName:   req.FullName,
+		Department: req.Department,
+		Role:       req.Role,
+		Status:     model.UserStatusPending,
+	}
+
+	// ユーザーと招待は1つのトランザクションで作成し、招待なしで pending のユーザーが残らないようにする
+	var invitation *model.Invitation
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionInvite,
+		ResourceType: model.ResourceTypeInvitation,
+		ResourceID:   req.Username,
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.userRepo.Create(ctx, user); err != nil {
+			return nil, fmt.Errorf("failed to create invited user: %w", err)
+		}
+
+		invitation = &model.Invitation{
+			UserID:    user.ID,
+			Email:     user.Email,
+			TokenID:   uuid.New().String(),
+			Status:    model.InvitationStatusPending,
+			InvitedBy: actorID,
+			ExpiresAt: time.Now().Add(s.invitationTTL),
+		}
+		if err := s.invitationRepo.Create(ctx, invitation); err != nil {
+			return nil, fmt.Errorf("failed to create invitation: %w", err)
+		}
+
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionInvite,
+			ResourceType: model.ResourceTypeInvitation,
+			ResourceID:   invitationResourceID(invitation.ID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After: map[string]interface{}{
+					"user_id":    user.ID,
+					"username":   user.Username,
+					"email":      user.Email,
+					"role":       user.Role,
+					"status":     invitation.Status,
+					"expires_at": invitation.ExpiresAt,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		s.logger.Error("Failed to invite user", zap.String("username", req.Username), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("User invited", zap.Uint("invitation_id", invitation.ID), zap.Uint("user_id", user.ID))
+
+	mailSent := true
+	if err := s.send(ctx, invitation, user, false); err != nil {
+		s.logger.Warn("Failed to send invitation mail", zap.Uint("invitation_id", invitation.ID), zap.Error(err))
+		mailSent = false
+	}
+
+	return &model.InviteUserResponse{
+		User:       user.ToResponse(),
+		Invitation: invitation.ToResponse(),
+		MailSent:   mailSent,
+	}, nil
+}
+
+// List は招待一覧を取得します
+func (s *InvitationService) List(ctx context.Context, params *util.PaginationParams, status string) (*util.PaginatedResponse, error) {
+	invitations, total, err := s.invitationRepo.FindAll(ctx, params, status)
+	if err != nil {
+		s.logger.Error("Failed to fetch invitations", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	responses := make([]*model.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = invitation.ToResponse()
+	}
+
+	return util.NewPaginatedResponse(responses, total, params), nil
+}
+
+// Resend は招待メールを再送します
+// トークンを再発行して有効期限を延長するため、以前に送信したリンクは使用できなくなります
+func (s *InvitationService) Resend(ctx context.Context, actorID uint, id uint) (*model.InvitationResponse, error) {
+	invitation, err := s.findInvitation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !invitation.CanResend(time.Now()) {
+		return nil, util.NewConflictError(util.ErrCodeInvitationNotAvailable,
+			fmt.Errorf("invitation is %s", invitation.CurrentStatus(time.Now())))
+	}
+
+	user, err := s.userRepo.FindByID(ctx, invitation.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewConflictError(util.ErrCodeInvitationNotAvailable, errors.New("invited user no longer exists"))
+		}
+		s.logger.Error("Failed to fetch invited user", zap.Uint("user_id", invitation.UserID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	if err := s.send(ctx, invitation, user, true); err != nil {
+		s.logger.Error("Failed to resend invitation", zap.Uint("invitation_id", id), zap.Error(err))
+		s.logInvitationFailure(ctx, actorID, model.ActionResend, invitationResourceID(id), err)
+		return nil, util.NewInternalError(util.ErrCodeMailDeliveryError, err)
+	}
+
+	s.logger.Info("Invitation resent", zap.Uint("invitation_id", id))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionResend,
+			ResourceType: model.ResourceTypeInvitation,
+			ResourceID:   invitationResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After: map[string]interface{}{
+					"expires_at": invitation.ExpiresAt,
+					"sent_count": invitation.SentCount,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return invitation.ToResponse(), nil
+}
+
+// Revoke は招待を取り消します
+// 招待中のユーザーは削除され、ユーザー名とメールアドレスは再度招待できるようになります
+func (s *InvitationService) Revoke(ctx context.Context, actorID uint, id uint) (*model.InvitationResponse, error) {
+	invitation, err := s.findInvitation(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := invitation.CurrentStatus(time.Now())
+
+	now := time.Now()
+	if err := s.invitationRepo.TransitionStatus(ctx, id, model.InvitationStatusPending, model.InvitationStatusRevoked, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewConflictError(util.ErrCodeInvitationNotAvailable, fmt.Errorf("invitation is %s", invitation.Status))
+		}
+		s.logger.Error("Failed to revoke invitation", zap.Uint("invitation_id", id), zap.Error(err))
+		s.logInvitationFailure(ctx, actorID, model.ActionRevoke, invitationResourceID(id), err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	invitation.Status = model.InvitationStatusRevoked
+	invitation.RevokedAt = &now
+
+	// 受諾されていない招待中ユーザーのみ削除する
+	user, err := s.userRepo.FindByID(ctx, invitation.UserID)
+	if err == nil && user.Status == model.UserStatusPending {
+		if err := s.userRepo.Delete(ctx, user.ID); err != nil {
+			s.logger.Error("Failed to delete invited user", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+	}
+
+	s.logger.Info("Invitation revoked", zap.Uint("invitation_id", id))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionRevoke,
+			ResourceType: model.ResourceTypeInvitation,
+			ResourceID:   invitationResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"status": before},
+				After:  map[string]interface{}{"status": invitation.Status},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return invitation.ToResponse(), nil
+}
+
+// Accept は招待を受諾し、パスワードを設定してユーザーを有効化します
+// トークンの不正・期限切れ・使用済み等の理由は区別せずに同じエラーを返します
+func (s *InvitationService) Accept(ctx context.Context, req *model.AcceptInvitationRequest) (*model.UserResponse, error) {
+	invalid := util.NewBadRequestError(util.ErrCodeInvalidInvitation, errors.New("invitation is invalid or expired"))
+
+	claims, err := s.jwtService.ValidateInvitationToken(req.Token)
+	if err != nil {
+		s.logger.Info("Invalid invitation token", zap.Error(err))
+		return nil, invalid
+	}
+
+	invitation, err := s.invitationRepo.FindByID(ctx, claims.InvitationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		s.logger.Error("Failed to fetch invitation", zap.Uint("invitation_id", claims.InvitationID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// 再送により再発行された場合、古いトークンは使用できない
+	if invitation.TokenID != claims.TokenID || invitation.CurrentStatus(time.Now()) != model.InvitationStatusPending {
+		return nil, invalid
+	}
+
+	// パスワードをハッシュ化
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.Error("Failed to hash password", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodePasswordHashError, err)
+	}
+
+	// 招待の遷移とユーザーの有効化は1つのトランザクションで行い、どちらかが失敗した場合は招待を受諾前に戻す
+	// 同じトークンで同時に受諾されても一度しか成功しないよう、先に招待を遷移させる
+	now := time.Now()
+	var user *model.User
+	failure := &model.CreateAuditLogRequest{
+		UserID:       invitation.UserID,
+		Action:       model.ActionAccept,
+		ResourceType: model.ResourceTypeInvitation,
+		ResourceID:   invitationResourceID(invitation.ID),
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.invitationRepo.TransitionStatus(ctx, invitation.ID, model.InvitationStatusPending, model.InvitationStatusAccepted, now); err != nil {
+			return nil, err
+		}
+		if err := s.userRepo.Activate(ctx, invitation.UserID, string(hashedPassword)); err != nil {
+			return nil, err
+		}
+
+		var err error
+		user, err = s.userRepo.FindByID(ctx, invitation.UserID)
+		if err != nil {
+			return nil, err
+		}
+
+		// 実行者は招待されたユーザー本人
+		return &model.CreateAuditLogRequest{
+			UserID:       user.ID,
+			Action:       model.ActionAccept,
+			ResourceType: model.ResourceTypeInvitation,
+			ResourceID:   invitationResourceID(invitation.ID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"status": model.InvitationStatusPending, "user_status": model.UserStatusPending},
+				After:  map[string]interface{}{"status": model.InvitationStatusAccepted, "user_status": user.Status},
+			},
+			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		// 既に受諾済み、または招待されたユーザーが削除済み
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalid
+		}
+		s.logger.Error("Failed to accept invitation", zap.Uint("invitation_id", invitation.ID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Invitation accepted", zap.Uint("invitation_id", invitation.ID), zap.Uint("user_id", user.ID))
+
+	return user.ToResponse(), nil
+}
+
+// send は招待トークンを発行して招待メールを送信します
+// renew が true の場合はトークンを再発行して有効期限を延長します
+func (s *InvitationService) send(ctx context.Context, invitation *model.Invitation, user *model.User, renew bool) error {
+	if renew {
+		tokenID := uuid.New().String()
+		expiresAt := time.Now().Add(s.invitationTTL)
+		if err := s.invitationRepo.UpdateToken(ctx, invitation.ID, tokenID, expiresAt); err != nil {
+			return err
+		}
+		invitation.TokenID = tokenID
+		invitation.ExpiresAt = expiresAt
+		invitation.Status = model.InvitationStatusPending
+	}
+
+	token, err := s.jwtService.GenerateInvitationToken(invitation.ID, invitation.TokenID, invitation.ExpiresAt)
+	if err != nil {
+		return err
+	}
+	link, err := linkWithToken(s.invitationURL, token)
+	if err != nil {
+		return err
+	}
+
+	if err := s.mailer.Send(ctx, buildInvitationMessage(user, link, invitation.ExpiresAt)); err != nil {
+		return err
+	}
+
+	sentAt := time.Now()
+	if err := s.invitationRepo.RecordSent(ctx, invitation.ID, sentAt); err != nil {
+		// 送信自体は成功しているため、記録の失敗はログのみ
+		s.logger.Error("Failed to record invitation delivery", zap.Uint("invitation_id", invitation.ID), zap.Error(err))
+	}
+	invitation.SentCount++
+	invitation.LastSentAt = &sentAt
+	return nil
+}
+
+// findInvitation はIDで招待を取得します
+func (s *InvitationService) findInvitation(ctx context.Context, id uint) (*model.Invitation, error) {
+	invitation, err := s.invitationRepo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeInvitationNotFound, err)
+		}
+		s.logger.Error("Failed to fetch invitation", zap.Uint("invitation_id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return invitation, nil
+}
+
+// recordChange は変更と成功の監査ログを1つのトランザクションで記録します（監査ログが無効の場合は変更のみ行う）
+func (s *InvitationService) recordChange(ctx context.Context, failure *model.CreateAuditLogRequest, change func(ctx context.Context) (*model.CreateAuditLogRequest, error)) error {
+	if s.auditLogService == nil {
+		_, err := change(ctx)
+		return err
+	}
+	return s.auditLogService.RecordChange(ctx, failure, change)
+}
+
+// logInvitationFailure は招待操作の失敗を監査ログに記録します
+func (s *InvitationService) logInvitationFailure(ctx context.Context, actorID uint, action, resourceID string, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       action,
+		ResourceType: model.ResourceTypeInvitation,
+		ResourceID:   resourceID,
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// invitationResourceID は監査ログ上の招待のリソースIDを返します
+func invitationResourceID(id uint) string {
+	return fmt.Sprintf("invitation-%d", id)
+}
+
+// linkWithToken は画面のURLにトークンをクエリとして付与します
+func linkWithToken(baseURL, token string) (string, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// buildInvitationMessage は招待メールを作成します
+func buildInvitationMessage(user *model.User, link string, expiresAt time.Time) *mailer.Message {
+	name := user.FullName
+	if name == "" {
+		name = user.Username
+	}
+	body := fmt.Sprintf(`%s 様
+
+Effisio に招待されました。
+以下のリンクからパスワードを設定して、アカウントを有効化してください。
+
+%s
+
+このリンクの有効期限は %s です。
+心当たりがない場合は、このメールを破棄してください。
+`, name, link, expiresAt.Format("2006-01-02 15:04 MST"))
+
+	return &mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Effisio へのご招待",
+		Body:    body,
+	}
+}
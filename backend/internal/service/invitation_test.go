@@ -0,0 +1,78 @@
+package service
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestLinkWithToken(t *testing.T) {
+	tests := []struct {
+		name     string
+		baseURL  string
+		expected string
+	}{
+		{
+			name:     "Without query",
+			baseURL:  "http://localhost:3000/invitations/accept",
+			expected: "http://localhost:3000/invitations/accept?token=abc.def",
+		},
+		{
+			name:     "With existing query",
+			baseURL:  "https://app.example.com/accept?lang=ja",
+			expected: "https://app.example.com/accept?lang=ja&token=abc.def",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := linkWithToken(tt.baseURL, "abc.def")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, link)
+
+			parsed, err := url.Parse(link)
+			require.NoError(t, err)
+			assert.Equal(t, "abc.def", parsed.Query().Get("token"))
+		})
+	}
+}
+
+func TestBuildInvitationMessage(t *testing.T) {
+	expiresAt := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	user := &model.User{Username: "newuser", Email: "new@example.com"}
+
+	msg := buildInvitationMessage(user, "http://localhost:3000/invitations/accept?token=x", expiresAt)
+
+	assert.Equal(t, []string{"new@example.com"}, msg.To)
+	assert.Contains(t, msg.Body, "newuser 様")
+	assert.Contains(t, msg.Body, "http://localhost:3000/invitations/accept?token=x")
+	assert.Contains(t, msg.Body, "2024-01-15 10:30 UTC")
+
+	user.FullName = "新規 太郎"
+	msg = buildInvitationMessage(user, "http://example.com", expiresAt)
+	assert.Contains(t, msg.Body, "新規 太郎 様")
+}
+
+func TestInvitation_CurrentStatus(t *testing.T) {
+	now := time.Now()
+
+	pending := &model.Invitation{Status: model.InvitationStatusPending, ExpiresAt: now.Add(time.Hour)}
+	assert.Equal(t, model.InvitationStatusPending, pending.CurrentStatus(now))
+	assert.True(t, pending.CanResend(now))
+
+	expired := &model.Invitation{Status: model.InvitationStatusPending, ExpiresAt: now.Add(-time.Hour)}
+	assert.Equal(t, model.InvitationStatusExpired, expired.CurrentStatus(now))
+	assert.True(t, expired.CanResend(now))
+
+	accepted := &model.Invitation{Status: model.InvitationStatusAccepted, ExpiresAt: now.Add(-time.Hour)}
+	assert.Equal(t, model.InvitationStatusAccepted, accepted.CurrentStatus(now))
+	assert.False(t, accepted.CanResend(now))
+
+	revoked := &model.Invitation{Status: model.InvitationStatusRevoked, ExpiresAt: now.Add(time.Hour)}
+	assert.False(t, revoked.CanResend(now))
+}
@@ -0,0 +1,347 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// UserHoldChecker はユーザーが訴訟ホールドの対象かを判定します
+type UserHoldChecker interface {
+	UserHeld(ctx context.Context, userID uint) (bool, error)
+}
+
+// LegalHoldProvider は訴訟ホールドの対象となる監査ログの範囲とユーザーを提供します
+type LegalHoldProvider interface {
+	AuditHoldProvider
+	UserHoldChecker
+}
+
+// LegalHoldChecker は有効な訴訟ホールドから、削除・匿名化してはならない監査ログとユーザーを判定します
+// 監査ログを書き込むサービスにも渡すため、監査ログの記録を行う LegalHoldService とは分けています
+type LegalHoldChecker struct {
+	repo     *repository.LegalHoldRepository
+	userRepo *repository.UserRepository
+}
+
+// NewLegalHoldChecker は新しいLegalHoldCheckerを作成します
+func NewLegalHoldChecker(repo *repository.LegalHoldRepository, userRepo *repository.UserRepository) *LegalHoldChecker {
+	return &LegalHoldChecker{
+		repo:     repo,
+		userRepo: userRepo,
+	}
+}
+
+// AuditHoldScopes は有効なホールドの対象となる監査ログの範囲を返します
+// ユーザーを指定したホールドは、ユーザーが実行した監査ログに加えて、ユーザーを対象とする監査ログも含みます
+func (c *LegalHoldChecker) AuditHoldScopes(ctx context.Context) ([]*model.AuditHoldScope, error) {
+	holds, err := c.repo.FindActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	users := make(map[uint]*model.User)
+	for _, hold := range holds {
+		if hold.UserID == nil {
+			continue
+		}
+		if _, ok := users[*hold.UserID]; ok {
+			continue
+		}
+		user, err := c.userRepo.FindByIDUnscoped(ctx, *hold.UserID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 物理削除済みのユーザーは実行者としてのみ照合する
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		users[user.ID] = user
+	}
+	return legalHoldScopes(holds, users), nil
+}
+
+// UserHeld はユーザーを対象とする有効なホールドが存在するかを返します
+func (c *LegalHoldChecker) UserHeld(ctx context.Context, userID uint) (bool, error) {
+	return c.repo.ExistsActiveForUser(ctx, userID)
+}
+
+// LegalHoldService は訴訟ホールドの設定と解除に関するビジネスロジックを提供します
+type LegalHoldService struct {
+	repo            *repository.LegalHoldRepository
+	userRepo        *repository.UserRepository
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+	now             func() time.Time
+}
+
+// NewLegalHoldService は新しいLegalHoldServiceを作成します
+func NewLegalHoldService(
+	repo *repository.LegalHoldRepository,
+	userRepo *repository.UserRepository,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *LegalHoldService {
+	return &LegalHoldService{
+		repo:            repo,
+		userRepo:        userRepo,
+		logger:          logger,
+		auditLogService: auditLogService,
+		now:             time.Now,
+	}
+}
+
+// List はホールドを新しい順に取得します（includeReleased の場合は解除済みを含める）
+func (s *LegalHoldService) List(ctx context.Context, includeReleased bool) ([]*model.LegalHold, error) {
+	holds, err := s.repo.FindAll(ctx, includeReleased)
+	if err != nil {
+		s.logger.Error("Failed to fetch legal holds", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return holds, nil
+}
+
+// GetByID はIDでホールドを取得します
+func (s *LegalHoldService) GetByID(ctx context.Context, id uint) (*model.LegalHold, error) {
+	return s.findHold(ctx, id)
+}
+
+// Place はホールドを設定します
+// 責任者を省略した場合は設定したユーザーを責任者とします
+func (s *LegalHoldService) Place(ctx context.Context, actorID uint, req *model.PlaceLegalHoldRequest) (*model.LegalHold, error) {
+	hold := &model.LegalHold{
+		UserID:       req.UserID,
+		ResourceType: req.ResourceType,
+		ResourceID:   req.ResourceID,
+		From:         req.From,
+		To:           req.To,
+		Reason:       strings.TrimSpace(req.Reason),
+		OwnerID:      actorID,
+		PlacedBy:     actorID,
+	}
+	if req.OwnerID != nil {
+		hold.OwnerID = *req.OwnerID
+	}
+	if err := validateLegalHold(hold); err != nil {
+		return nil, err
+	}
+
+	// 責任者は在籍しているユーザー、対象は削除済みを含むユーザー
+	if _, err := s.findUser(ctx, hold.OwnerID, false); err != nil {
+		return nil, err
+	}
+	if hold.UserID != nil {
+		if _, err := s.findUser(ctx, *hold.UserID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Create(ctx, hold); err != nil {
+		s.logger.Error("Failed to create legal hold", zap.Error(err))
+		s.logHoldFailure(ctx, actorID, model.ActionHold, "new", err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Legal hold placed", zap.Uint("id", hold.ID), zap.Uint("owner_id", hold.OwnerID))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionHold,
+			ResourceType: model.ResourceTypeLegalHold,
+			ResourceID:   fmt.Sprintf("%d", hold.ID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  legalHoldChanges(hold),
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return hold, nil
+}
+
+// Release はホールドを解除します
+// 解除したホールドは記録として残り、対象だったユーザーと監査ログは他のホールドの対象でない限り削除・匿名化できるようになります
+func (s *LegalHoldService) Release(ctx context.Context, actorID, id uint, req *model.ReleaseLegalHoldRequest) (*model.LegalHold, error) {
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("reason is required"))
+	}
+
+	hold, err := s.findHold(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !hold.Active() {
+		return nil, util.NewConflictError(util.ErrCodeLegalHoldReleased, errors.New("legal hold is already released"))
+	}
+	before := legalHoldChanges(hold)
+
+	releasedAt := s.now()
+	hold.ReleasedAt = &releasedAt
+	hold.ReleasedBy = &actorID
+	hold.ReleaseReason = reason
+	released, err := s.repo.Release(ctx, hold)
+	if err != nil {
+		s.logger.Error("Failed to release legal hold", zap.Uint("id", id), zap.Error(err))
+		s.logHoldFailure(ctx, actorID, model.ActionRelease, fmt.Sprintf("%d", id), err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if !released {
+		// 同時に解除された
+		return nil, util.NewConflictError(util.ErrCodeLegalHoldReleased, errors.New("legal hold is already released"))
+	}
+
+	s.logger.Info("Legal hold released", zap.Uint("id", id))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionRelease,
+			ResourceType: model.ResourceTypeLegalHold,
+			ResourceID:   fmt.Sprintf("%d", hold.ID),
+			Changes: model.AuditLogChanges{
+				Before: before,
+				After:  legalHoldChanges(hold),
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return hold, nil
+}
+
+// findHold はIDでホールドを取得し、存在しない場合は404エラーを返します
+func (s *LegalHoldService) findHold(ctx context.Context, id uint) (*model.LegalHold, error) {
+	hold, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeLegalHoldNotFound, err)
+		}
+		s.logger.Error("Failed to fetch legal hold", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return hold, nil
+}
+
+// findUser はユーザーを取得し、存在しない場合は404エラーを返します（unscoped の場合は削除済みを含める）
+func (s *LegalHoldService) findUser(ctx context.Context, id uint, unscoped bool) (*model.User, error) {
+	var user *model.User
+	var err error
+	if unscoped {
+		user, err = s.userRepo.FindByIDUnscoped(ctx, id)
+	} else {
+		user, err = s.userRepo.FindByID(ctx, id)
+	}
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch user for legal hold", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return user, nil
+}
+
+// logHoldFailure はホールドの操作の失敗を監査ログに記録します
+func (s *LegalHoldService) logHoldFailure(ctx context.Context, actorID uint, action, resourceID string, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       action,
+		ResourceType: model.ResourceTypeLegalHold,
+		ResourceID:   resourceID,
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+		Durable:      true,
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// validateLegalHold はホールドの各項目を検証します
+func validateLegalHold(hold *model.LegalHold) error {
+	if hold.Reason == "" {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("reason is required"))
+	}
+	// 範囲を限定しないホールドはすべての削除を止めるため、いずれかの条件を必須とする
+	if hold.UserID == nil && hold.ResourceType == "" && hold.From == nil && hold.To == nil {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter,
+			errors.New("at least one of user_id, resource_type, from or to is required"))
+	}
+	if hold.ResourceID != "" && hold.ResourceType == "" {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("resource_type is required with resource_id"))
+	}
+	if hold.From != nil && hold.To != nil && hold.From.After(*hold.To) {
+		return util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("from must be before to"))
+	}
+	return nil
+}
+
+// legalHoldScopes はホールドを監査ログの範囲に変換します
+// users に対象のユーザーがある場合は、ユーザーを指すリソースIDの監査ログも範囲に加えます
+func legalHoldScopes(holds []*model.LegalHold, users map[uint]*model.User) []*model.AuditHoldScope {
+	scopes := make([]*model.AuditHoldScope, 0, len(holds))
+	for _, hold := range holds {
+		scopes = append(scopes, hold.AuditHoldScope())
+
+		// リソースタイプを限定したホールドは、ユーザーを対象とする監査ログに広げない
+		if hold.UserID == nil || hold.ResourceType != "" {
+			continue
+		}
+		user, ok := users[*hold.UserID]
+		if !ok {
+			continue
+		}
+		for _, resourceID := range subjectResourceIDs(user) {
+			scopes = append(scopes, &model.AuditHoldScope{
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   resourceID,
+				From:         hold.From,
+				To:           hold.To,
+			})
+		}
+	}
+	return scopes
+}
+
+// auditLogHeld は監査ログがいずれかのホールドの範囲に含まれるかを返します
+func auditLogHeld(scopes []*model.AuditHoldScope, log *model.AuditLog) bool {
+	for _, scope := range scopes {
+		if scope.Matches(log) {
+			return true
+		}
+	}
+	return false
+}
+
+// legalHoldChanges は監査ログに記録するホールドの内容を返します
+func legalHoldChanges(hold *model.LegalHold) map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":        hold.UserID,
+		"resource_type":  hold.ResourceType,
+		"resource_id":    hold.ResourceID,
+		"from":           hold.From,
+		"to":             hold.To,
+		"reason":         hold.Reason,
+		"owner_id":       hold.OwnerID,
+		"released_at":    hold.ReleasedAt,
+		"released_by":    hold.ReleasedBy,
+		"release_reason": hold.ReleaseReason,
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+func TestValidateLegalHold(t *testing.T) {
+	userID := uint(5)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		hold    *model.LegalHold
+		wantErr bool
+	}{
+		{"ユーザー", &model.LegalHold{UserID: &userID, Reason: "調査"}, false},
+		{"リソース", &model.LegalHold{ResourceType: "group", ResourceID: "3", Reason: "調査"}, false},
+		{"期間", &model.LegalHold{From: &from, To: &to, Reason: "調査"}, false},
+		{"理由がない", &model.LegalHold{UserID: &userID}, true},
+		{"条件がない", &model.LegalHold{Reason: "調査"}, true},
+		{"リソースタイプがないリソースID", &model.LegalHold{ResourceID: "3", Reason: "調査"}, true},
+		{"開始が終了より後", &model.LegalHold{From: &to, To: &from, Reason: "調査"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateLegalHold(tt.hold)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			var appErr *util.AppError
+			require.True(t, errors.As(err, &appErr))
+			assert.Equal(t, util.ErrCodeInvalidParameter, appErr.Code)
+		})
+	}
+}
+
+func TestLegalHoldScopes(t *testing.T) {
+	userID := uint(5)
+	deletedUserID := uint(9)
+	user := &model.User{ID: userID, Username: "alice", Email: "alice@example.com"}
+
+	holds := []*model.LegalHold{
+		{ID: 1, UserID: &userID},
+		{ID: 2, UserID: &userID, ResourceType: "group"},
+		{ID: 3, UserID: &deletedUserID},
+	}
+	scopes := legalHoldScopes(holds, map[uint]*model.User{userID: user})
+
+	// ホールド1: 実行者 + ユーザーを指すリソースID（ユーザー名・メールアドレス・user-ID）
+	// ホールド2: リソースタイプを限定しているため実行者のみ
+	// ホールド3: 物理削除済みのユーザーは実行者のみ
+	require.Len(t, scopes, 6)
+	assert.Equal(t, &userID, scopes[0].UserID)
+	assert.Equal(t, model.ResourceTypeUser, scopes[1].ResourceType)
+	assert.Equal(t, "alice", scopes[1].ResourceID)
+	assert.Nil(t, scopes[1].UserID)
+	assert.Equal(t, "alice@example.com", scopes[2].ResourceID)
+	assert.Equal(t, "user-5", scopes[3].ResourceID)
+	assert.Equal(t, "group", scopes[4].ResourceType)
+	assert.Equal(t, &deletedUserID, scopes[5].UserID)
+}
+
+func TestAuditLogHeld(t *testing.T) {
+	userID := uint(5)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)
+	scopes := []*model.AuditHoldScope{
+		{UserID: &userID, From: &from, To: &to},
+		{ResourceType: model.ResourceTypeGroup, ResourceID: "3"},
+	}
+
+	tests := []struct {
+		name string
+		log  *model.AuditLog
+		want bool
+	}{
+		{"期間内のユーザーの監査ログ", &model.AuditLog{UserID: 5, CreatedAt: from.AddDate(0, 1, 0)}, true},
+		{"期間の終了と同時", &model.AuditLog{UserID: 5, CreatedAt: to}, true},
+		{"期間外のユーザーの監査ログ", &model.AuditLog{UserID: 5, CreatedAt: to.AddDate(0, 1, 0)}, false},
+		{"他のユーザーの監査ログ", &model.AuditLog{UserID: 6, CreatedAt: from}, false},
+		{"リソースが一致", &model.AuditLog{UserID: 6, ResourceType: model.ResourceTypeGroup, ResourceID: "3"}, true},
+		{"リソースIDが異なる", &model.AuditLog{UserID: 6, ResourceType: model.ResourceTypeGroup, ResourceID: "4"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, auditLogHeld(scopes, tt.log))
+		})
+	}
+
+	assert.False(t, auditLogHeld(nil, &model.AuditLog{UserID: 5}))
+}
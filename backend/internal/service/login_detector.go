@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/geoip"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// securityRuleSeverities は検知ルールごとのアラートの重要度です
+var securityRuleSeverities = map[string]string{
+	model.SecurityRulePasswordSpraying: model.SecuritySeverityHigh,
+	model.SecurityRuleNewIP:            model.SecuritySeverityMedium,
+	model.SecurityRuleNewUserAgent:     model.SecuritySeverityLow,
+	model.SecurityRuleImpossibleTravel: model.SecuritySeverityHigh,
+}
+
+// LoginDetectionConfig は不審なログインの検知の設定です
+type LoginDetectionConfig struct {
+	HistoryWindow       time.Duration       // 新しいIPアドレス・User-Agentと移動の判定に使う過去のログインの期間
+	AlertDedupWindow    time.Duration       // 同じ未対応のアラートがある場合に、新たに作成しない期間
+	SprayingWindow      time.Duration       // パスワードスプレーの判定に使うログイン失敗の期間
+	SprayingThreshold   int                 // 1つのIPアドレスからログインに失敗したアカウント数の閾値（0以下の場合は判定しない）
+	IPLockoutDuration   time.Duration       // パスワードスプレーの送信元からのログインを拒否する期間
+	NewIPEnabled        bool                // 新しいIPアドレスからのログインを判定するか
+	NewUserAgentEnabled bool                // 新しいUser-Agentからのログインを判定するか
+	TravelMaxSpeedKmh   float64             // 移動できるとみなす最大の速度（km/h）
+	TravelMinDistanceKm float64             // 移動を判定する最小の距離（km。GeoIPの誤差による誤検知を避ける）
+	Actions             map[string][]string // ルールごとの検知時の対応（lockout, notify）
+}
+
+// LoginAttempt は検知の対象となるログインの試行です
+type LoginAttempt struct {
+	Username  string
+	User      *model.User // ユーザーが存在しない場合は nil
+	Success   bool        // 認証に成功したか
+	IPAddress string
+	UserAgent string
+}
+
+// securityAlertStore はLoginDetectorが使うセキュリティアラートの操作です（repository.SecurityAlertRepository）
+type securityAlertStore interface {
+	ExistsOpen(ctx context.Context, rule string, userID *uint, ipAddress string, since time.Time) (bool, error)
+	ExistsOpenIPLockout(ctx context.Context, ipAddress string, since time.Time) (bool, error)
+	Create(ctx context.Context, alert *model.SecurityAlert) error
+}
+
+// loginHistoryStore はLoginDetectorが使うログインの監査ログの検索です（repository.AuditLogRepository）
+type loginHistoryStore interface {
+	FindFailedLoginAccounts(ctx context.Context, ipAddress string, since time.Time, limit int) ([]string, error)
+	ExistsSuccessfulLogin(ctx context.Context, userID uint, ipAddress, userAgent string, since time.Time) (bool, error)
+	FindLastSuccessfulLogin(ctx context.Context, userID uint, since time.Time) (*model.AuditLog, error)
+}
+
+// userSuspender はLoginDetectorが使うユーザーの停止です（repository.UserRepository）
+type userSuspender interface {
+	Suspend(ctx context.Context, user *model.User, changedAt time.Time) error
+}
+
+// sessionRevoker はLoginDetectorが使うリフレッシュトークンの無効化です（repository.RefreshTokenRepository）
+type sessionRevoker interface {
+	RevokeAllByUserID(ctx context.Context, userID uint) error
+}
+
+// LoginDetector はログインの監査ログから不審なログインを検知し、セキュリティアラートを作成します
+// 検知したルールに設定された対応（ユーザーの停止、Webhookでの通知）も行います
+type LoginDetector struct {
+	alertRepo        securityAlertStore
+	auditLogRepo     loginHistoryStore
+	userRepo         userSuspender
+	refreshTokenRepo sessionRevoker
+	geo              *geoip.DB // nil の場合は移動の判定を行わない
+	config           LoginDetectionConfig
+	actions          map[string][]string
+	logger           *zap.Logger
+	auditLogService  *AuditLogService
+	webhookService   *WebhookService
+	now              func() time.Time
+}
+
+// NewLoginDetector は新しいLoginDetectorを作成します
+func NewLoginDetector(
+	alertRepo securityAlertStore,
+	auditLogRepo loginHistoryStore,
+	userRepo userSuspender,
+	refreshTokenRepo sessionRevoker,
+	geo *geoip.DB,
+	config LoginDetectionConfig,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+	webhookService *WebhookService,
+) *LoginDetector {
+	actions, unknown := normalizeSecurityActions(config.Actions)
+	for _, action := range unknown {
+		logger.Warn("Unknown security alert action is ignored", zap.String("action", action))
+	}
+	return &LoginDetector{
+		alertRepo:        alertRepo,
+		auditLogRepo:     auditLogRepo,
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		geo:              geo,
+		config:           config,
+		actions:          actions,
+		logger:           logger,
+		auditLogService:  auditLogService,
+		webhookService:   webhookService,
+		now:              time.Now,
+	}
+}
+
+// CheckSource は送信元のIPアドレスからのログインが拒否されていないかを確認します
+// パスワードスプレーを検知して lockout を行ったIPアドレスは、アラートが確認されるか期間を過ぎるまで拒否します
+func (d *LoginDetector) CheckSource(ctx context.Context, ipAddress string) error {
+	if ipAddress == "" || d.config.IPLockoutDuration <= 0 || !d.hasAction(model.SecurityRulePasswordSpraying, model.SecurityActionLockout) {
+		return nil
+	}
+
+	blocked, err := d.alertRepo.ExistsOpenIPLockout(ctx, ipAddress, d.now().Add(-d.config.IPLockoutDuration))
+	if err != nil {
+		// 検知の障害でログインを止めない
+		d.logger.Error("Failed to check blocked login source", zap.String("ip_address", ipAddress), zap.Error(err))
+		return nil
+	}
+	if blocked {
+		return util.NewForbiddenError(util.ErrCodeLoginBlocked, errors.New("login from this IP address is temporarily blocked"))
+	}
+	return nil
+}
+
+// Analyze はログインの試行を検知ルールで判定し、検知した場合はアラートの作成と設定された対応を行います
+// 判定に失敗した場合はログに出力し、ログインは止めません
+// 検知によりユーザーを停止した場合（今回のログインを拒否すべき場合）は true を返します
+func (d *LoginDetector) Analyze(ctx context.Context, attempt *LoginAttempt) bool {
+	if !attempt.Success || attempt.User == nil {
+		// パスワードスプレーは送信元に対するアラートのため、失敗したログインのユーザーは停止しない
+		// （停止すると攻撃者が任意のアカウントを停止できてしまう）
+		if alert := d.detectSpraying(ctx, attempt); alert != nil {
+			return d.raise(ctx, alert, nil)
+		}
+		return false
+	}
+
+	locked := false
+	for _, alert := range d.detectSuccess(ctx, attempt) {
+		if d.raise(ctx, alert, attempt.User) {
+			locked = true
+		}
+	}
+	return locked
+}
+
+// detectSpraying は1つのIPアドレスから複数のアカウントへのログイン失敗を判定します
+func (d *LoginDetector) detectSpraying(ctx context.Context, attempt *LoginAttempt) *model.SecurityAlert {
+	threshold := d.config.SprayingThreshold
+	if attempt.IPAddress == "" || threshold <= 0 {
+		return nil
+	}
+
+	accounts, err := d.auditLogRepo.FindFailedLoginAccounts(ctx, attempt.IPAddress, d.now().Add(-d.config.SprayingWindow), threshold)
+	if err != nil {
+		d.logger.Error("Failed to fetch failed logins", zap.String("ip_address", attempt.IPAddress), zap.Error(err))
+		return nil
+	}
+	// 今回の失敗は監査ログにまだ書き込まれていない場合がある
+	accounts = appendAccount(accounts, attempt.Username)
+	if len(accounts) < threshold {
+		return nil
+	}
+
+	return d.newAlert(model.SecurityRulePasswordSpraying, nil, attempt, map[string]interface{}{
+		"window":    d.config.SprayingWindow.String(),
+		"threshold": threshold,
+		"accounts":  accounts,
+	})
+}
+
+// detectSuccess は成功したログインを、ユーザーの過去のログインと比較して判定します
+func (d *LoginDetector) detectSuccess(ctx context.Context, attempt *LoginAttempt) []*model.SecurityAlert {
+	user := attempt.User
+	since := d.now().Add(-d.config.HistoryWindow)
+
+	// 期間内に過去のログインがない場合（初回のログイン等）は比較できないため判定しない
+	known, err := d.auditLogRepo.ExistsSuccessfulLogin(ctx, user.ID, "", "", since)
+	if err != nil {
+		d.logger.Error("Failed to fetch login history", zap.Uint("user_id", user.ID), zap.Error(err))
+		return nil
+	}
+	if !known {
+		return nil
+	}
+
+	var alerts []*model.SecurityAlert
+	if d.config.NewIPEnabled && attempt.IPAddress != "" {
+		seen, err := d.auditLogRepo.ExistsSuccessfulLogin(ctx, user.ID, attempt.IPAddress, "", since)
+		if err != nil {
+			d.logger.Error("Failed to fetch login history", zap.Uint("user_id", user.ID), zap.Error(err))
+		} else if !seen {
+			alerts = append(alerts, d.newAlert(model.SecurityRuleNewIP, user, attempt, map[string]interface{}{
+				"history_window": d.config.HistoryWindow.String(),
+			}))
+		}
+	}
+	if d.config.NewUserAgentEnabled && attempt.UserAgent != "" {
+		seen, err := d.auditLogRepo.ExistsSuccessfulLogin(ctx, user.ID, "", attempt.UserAgent, since)
+		if err != nil {
+			d.logger.Error("Failed to fetch login history", zap.Uint("user_id", user.ID), zap.Error(err))
+		} else if !seen {
+			alerts = append(alerts, d.newAlert(model.SecurityRuleNewUserAgent, user, attempt, map[string]interface{}{
+				"history_window": d.config.HistoryWindow.String(),
+			}))
+		}
+	}
+	if alert := d.detectTravel(ctx, attempt, since); alert != nil {
+		alerts = append(alerts, alert)
+	}
+	return alerts
+}
+
+// detectTravel は直前のログインとの距離と経過時間から、移動できない2つのIPアドレスからのログインを判定します
+func (d *LoginDetector) detectTravel(ctx context.Context, attempt *LoginAttempt, since time.Time) *model.SecurityAlert {
+	if d.geo == nil || attempt.IPAddress == "" {
+		return nil
+	}
+	user := attempt.User
+
+	last, err := d.auditLogRepo.FindLastSuccessfulLogin(ctx, user.ID, since)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			d.logger.Error("Failed to fetch last login", zap.Uint("user_id", user.ID), zap.Error(err))
+		}
+		return nil
+	}
+	if last.IPAddress == attempt.IPAddress {
+		return nil
+	}
+
+	// 位置が分からないIPアドレス（プライベートアドレス等）は判定しない
+	from, ok := d.geo.Lookup(last.IPAddress)
+	if !ok {
+		return nil
+	}
+	to, ok := d.geo.Lookup(attempt.IPAddress)
+	if !ok {
+		return nil
+	}
+
+	elapsed := d.now().Sub(last.CreatedAt)
+	distance, speed, impossible := impossibleTravel(from, to, elapsed, d.config.TravelMaxSpeedKmh, d.config.TravelMinDistanceKm)
+	if !impossible {
+		return nil
+	}
+
+	return d.newAlert(model.SecurityRuleImpossibleTravel, user, attempt, map[string]interface{}{
+		"previous_ip_address": last.IPAddress,
+		"previous_login_at":   last.CreatedAt,
+		"previous_location":   from,
+		"location":            to,
+		"distance_km":         math.Round(distance),
+		"elapsed_minutes":     math.Round(elapsed.Minutes()),
+		"speed_kmh":           math.Round(speed),
+	})
+}
+
+// newAlert はルールの重要度でアラートを作成します（保存はしません）
+func (d *LoginDetector) newAlert(rule string, user *model.User, attempt *LoginAttempt, details map[string]interface{}) *model.SecurityAlert {
+	alert := &model.SecurityAlert{
+		Rule:      rule,
+		Severity:  securityRuleSeverities[rule],
+		Username:  attempt.Username,
+		IPAddress: attempt.IPAddress,
+		UserAgent: attempt.UserAgent,
+		Details:   alertDetails(details),
+	}
+	if user != nil {
+		alert.UserID = &user.ID
+		alert.Username = user.Username
+	}
+	return alert
+}
+
+// raise はアラートを保存し、ルールに設定された対応を行います
+// 同じ未対応のアラートが期間内にある場合は、作成も対応も行いません
+// ユーザーを停止した場合は true を返します
+func (d *LoginDetector) raise(ctx context.Context, alert *model.SecurityAlert, user *model.User) bool {
+	exists, err := d.alertRepo.ExistsOpen(ctx, alert.Rule, alert.UserID, alert.IPAddress, d.now().Add(-d.config.AlertDedupWindow))
+	if err != nil {
+		d.logger.Error("Failed to check existing security alerts", zap.String("rule", alert.Rule), zap.Error(err))
+		return false
+	}
+	if exists {
+		return false
+	}
+
+	alert.Actions = model.StringList(d.actions[alert.Rule])
+	if alert.Actions == nil {
+		alert.Actions = model.StringList{}
+	}
+	if err := d.alertRepo.Create(ctx, alert); err != nil {
+		d.logger.Error("Failed to create security alert", zap.String("rule", alert.Rule), zap.Error(err))
+		return false
+	}
+
+	d.logger.Warn("Suspicious login detected",
+		zap.Uint("alert_id", alert.ID),
+		zap.String("rule", alert.Rule),
+		zap.String("severity", alert.Severity),
+		zap.String("username", alert.Username),
+		zap.String("ip_address", alert.IPAddress),
+		zap.Strings("actions", alert.Actions),
+	)
+
+	locked := false
+	for _, action := range alert.Actions {
+		switch action {
+		case model.SecurityActionLockout:
+			// パスワードスプレーは対象のユーザーがいないため、CheckSource で送信元からのログインを拒否する
+			if user != nil {
+				locked = d.lockout(ctx, alert, user)
+			}
+		case model.SecurityActionNotify:
+			d.notify(ctx, alert)
+		}
+	}
+	return locked
+}
+
+// lockout はユーザーを停止し、リフレッシュトークンをすべて無効化します
+// 停止に失敗した場合も、不審なログインとして今回のログインは拒否します
+func (d *LoginDetector) lockout(ctx context.Context, alert *model.SecurityAlert, user *model.User) bool {
+	previousStatus := user.Status
+	if err := d.userRepo.Suspend(ctx, user, d.now()); err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			d.logger.Error("Failed to suspend user by security alert", zap.Uint("user_id", user.ID), zap.Error(err))
+			d.logLockout(ctx, alert, user, previousStatus, err)
+		}
+		return true
+	}
+	if err := d.refreshTokenRepo.RevokeAllByUserID(ctx, user.ID); err != nil {
+		d.logger.Error("Failed to revoke refresh tokens of locked user", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+
+	d.logger.Warn("User locked out by security alert", zap.Uint("user_id", user.ID), zap.Uint("alert_id", alert.ID))
+	d.logLockout(ctx, alert, user, previousStatus, nil)
+	if d.webhookService != nil {
+		d.webhookService.Emit(ctx, model.WebhookEventUserSuspended, user.ToResponse())
+	}
+	return true
+}
+
+// logLockout はユーザーの停止を監査ログに記録します（err が nil でない場合は失敗として記録）
+func (d *LoginDetector) logLockout(ctx context.Context, alert *model.SecurityAlert, user *model.User, previousStatus string, err error) {
+	if d.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       model.SystemUserID,
+		Action:       model.ActionLockout,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   user.Username,
+		Changes: model.AuditLogChanges{
+			Before: map[string]interface{}{"status": previousStatus},
+			After: map[string]interface{}{
+				"status":            user.Status,
+				"security_alert_id": alert.ID,
+				"rule":              alert.Rule,
+			},
+		},
+		IPAddress: alert.IPAddress,
+		UserAgent: alert.UserAgent,
+		Status:    model.AuditStatusSuccess,
+		Durable:   true,
+	}
+	if err != nil {
+		auditReq.Status = model.AuditStatusFailed
+		auditReq.ErrorMessage = err.Error()
+	}
+	d.auditLogService.LogAction(ctx, auditReq)
+}
+
+// notify はアラートをWebhookの security.alert イベントで通知します
+func (d *LoginDetector) notify(ctx context.Context, alert *model.SecurityAlert) {
+	if d.webhookService == nil {
+		return
+	}
+	d.webhookService.Emit(ctx, model.WebhookEventSecurityAlert, alert)
+}
+
+// hasAction はルールに対応が設定されているかを返します
+func (d *LoginDetector) hasAction(rule, action string) bool {
+	for _, a := range d.actions[rule] {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSecurityActions はルールごとの対応から重複と不明な対応を取り除きます
+// 取り除いた不明な対応を unknown として返します
+func normalizeSecurityActions(actions map[string][]string) (normalized map[string][]string, unknown []string) {
+	normalized = make(map[string][]string, len(actions))
+	for rule, list := range actions {
+		seen := make(map[string]bool, len(list))
+		for _, action := range list {
+			if action != model.SecurityActionLockout && action != model.SecurityActionNotify {
+				unknown = append(unknown, fmt.Sprintf("%s:%s", rule, action))
+				continue
+			}
+			if !seen[action] {
+				seen[action] = true
+				normalized[rule] = append(normalized[rule], action)
+			}
+		}
+	}
+	return normalized, unknown
+}
+
+// impossibleTravel は2つの位置の間を elapsed で移動するのに必要な速度が maxSpeedKmh を超えるかを判定します
+// 距離が minDistanceKm 未満の場合は、GeoIPの誤差の範囲として判定しません
+// 経過時間が1分未満の場合は1分として速度を計算します
+func impossibleTravel(from, to *geoip.Location, elapsed time.Duration, maxSpeedKmh, minDistanceKm float64) (distanceKm, speedKmh float64, impossible bool) {
+	distanceKm = geoip.Distance(from, to)
+	hours := math.Max(elapsed.Hours(), 1.0/60)
+	speedKmh = distanceKm / hours
+	return distanceKm, speedKmh, distanceKm >= minDistanceKm && speedKmh > maxSpeedKmh
+}
+
+// appendAccount は accounts に username が含まれていない場合に追加します
+func appendAccount(accounts []string, username string) []string {
+	if username == "" {
+		return accounts
+	}
+	for _, account := range accounts {
+		if account == username {
+			return accounts
+		}
+	}
+	return append(accounts, username)
+}
+
+// alertDetails はアラートの根拠をJSONに変換します
+func alertDetails(details map[string]interface{}) datatypes.JSON {
+	data, err := json.Marshal(details)
+	if err != nil {
+		return datatypes.JSON("{}")
+	}
+	return datatypes.JSON(data)
+}
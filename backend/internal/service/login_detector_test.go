@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/geoip"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// MockSecurityAlertRepository mocks the SecurityAlertRepository
+type MockSecurityAlertRepository struct {
+	mock.Mock
+}
+
+func (m *MockSecurityAlertRepository) ExistsOpen(ctx context.Context, rule string, userID *uint, ipAddress string, since time.Time) (bool, error) {
+	args := m.Called(ctx, rule, userID, ipAddress, since)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSecurityAlertRepository) ExistsOpenIPLockout(ctx context.Context, ipAddress string, since time.Time) (bool, error) {
+	args := m.Called(ctx, ipAddress, since)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockSecurityAlertRepository) Create(ctx context.Context, alert *model.SecurityAlert) error {
+	return m.Called(ctx, alert).Error(0)
+}
+
+// MockLoginHistoryRepository mocks the login queries of the AuditLogRepository
+type MockLoginHistoryRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginHistoryRepository) FindFailedLoginAccounts(ctx context.Context, ipAddress string, since time.Time, limit int) ([]string, error) {
+	args := m.Called(ctx, ipAddress, since, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockLoginHistoryRepository) ExistsSuccessfulLogin(ctx context.Context, userID uint, ipAddress, userAgent string, since time.Time) (bool, error) {
+	args := m.Called(ctx, userID, ipAddress, userAgent, since)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLoginHistoryRepository) FindLastSuccessfulLogin(ctx context.Context, userID uint, since time.Time) (*model.AuditLog, error) {
+	args := m.Called(ctx, userID, since)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AuditLog), args.Error(1)
+}
+
+// detectorNow はテストで固定する現在時刻です
+var detectorNow = time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+// loginDetectorMocks はLoginDetectorの依存のモックです
+type loginDetectorMocks struct {
+	alerts  *MockSecurityAlertRepository
+	history *MockLoginHistoryRepository
+	users   *MockUserRepository
+	tokens  *MockRefreshTokenRepository
+}
+
+func (m *loginDetectorMocks) assertExpectations(t *testing.T) {
+	m.alerts.AssertExpectations(t)
+	m.history.AssertExpectations(t)
+	m.users.AssertExpectations(t)
+	m.tokens.AssertExpectations(t)
+}
+
+// newTestLoginDetector はモックを使ったLoginDetectorを作成します
+func newTestLoginDetector(config LoginDetectionConfig) (*LoginDetector, *loginDetectorMocks) {
+	mocks := &loginDetectorMocks{
+		alerts:  new(MockSecurityAlertRepository),
+		history: new(MockLoginHistoryRepository),
+		users:   new(MockUserRepository),
+		tokens:  new(MockRefreshTokenRepository),
+	}
+	detector := NewLoginDetector(mocks.alerts, mocks.history, mocks.users, mocks.tokens, nil, config, zap.NewNop(), nil, nil)
+	detector.now = func() time.Time { return detectorNow }
+	return detector, mocks
+}
+
+// newIPConfig は新しいIPアドレスからのログインのみを判定する設定です
+func newIPConfig(actions ...string) LoginDetectionConfig {
+	return LoginDetectionConfig{
+		HistoryWindow:    30 * 24 * time.Hour,
+		AlertDedupWindow: time.Hour,
+		NewIPEnabled:     true,
+		Actions:          map[string][]string{model.SecurityRuleNewIP: actions},
+	}
+}
+
+func TestLoginDetector_Analyze_NewIP(t *testing.T) {
+	ctx := context.Background()
+	since := detectorNow.Add(-30 * 24 * time.Hour)
+	dedupSince := detectorNow.Add(-time.Hour)
+
+	tests := []struct {
+		name           string
+		config         LoginDetectionConfig
+		setupMocks     func(m *loginDetectorMocks, user *model.User)
+		expectedLocked bool
+	}{
+		{
+			name:   "lockout suspends the user and revokes sessions",
+			config: newIPConfig(model.SecurityActionLockout),
+			setupMocks: func(m *loginDetectorMocks, user *model.User) {
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "203.0.113.5", "", since).Return(false, nil)
+				m.alerts.On("ExistsOpen", ctx, model.SecurityRuleNewIP, &user.ID, "203.0.113.5", dedupSince).Return(false, nil)
+				m.alerts.On("Create", ctx, mock.MatchedBy(func(alert *model.SecurityAlert) bool {
+					return alert.Rule == model.SecurityRuleNewIP &&
+						alert.Severity == model.SecuritySeverityMedium &&
+						*alert.UserID == user.ID &&
+						assert.ObjectsAreEqual(model.StringList{model.SecurityActionLockout}, alert.Actions)
+				})).Return(nil)
+				m.users.On("Suspend", ctx, user, detectorNow).Return(nil)
+				m.tokens.On("RevokeAllByUserID", ctx, user.ID).Return(nil)
+			},
+			expectedLocked: true,
+		},
+		{
+			name:   "notify only does not lock out",
+			config: newIPConfig(model.SecurityActionNotify),
+			setupMocks: func(m *loginDetectorMocks, user *model.User) {
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "203.0.113.5", "", since).Return(false, nil)
+				m.alerts.On("ExistsOpen", ctx, model.SecurityRuleNewIP, &user.ID, "203.0.113.5", dedupSince).Return(false, nil)
+				m.alerts.On("Create", ctx, mock.AnythingOfType("*model.SecurityAlert")).Return(nil)
+			},
+			expectedLocked: false,
+		},
+		{
+			name: "disabled rule raises nothing",
+			config: func() LoginDetectionConfig {
+				config := newIPConfig(model.SecurityActionLockout)
+				config.NewIPEnabled = false
+				return config
+			}(),
+			setupMocks: func(m *loginDetectorMocks, user *model.User) {
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+			},
+			expectedLocked: false,
+		},
+		{
+			name:   "known IP address",
+			config: newIPConfig(model.SecurityActionLockout),
+			setupMocks: func(m *loginDetectorMocks, user *model.User) {
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "203.0.113.5", "", since).Return(true, nil)
+			},
+			expectedLocked: false,
+		},
+		{
+			name:   "first login has no history to compare",
+			config: newIPConfig(model.SecurityActionLockout),
+			setupMocks: func(m *loginDetectorMocks, user *model.User) {
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(false, nil)
+			},
+			expectedLocked: false,
+		},
+		{
+			name:   "open alert is not raised again",
+			config: newIPConfig(model.SecurityActionLockout),
+			setupMocks: func(m *loginDetectorMocks, user *model.User) {
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+				m.history.On("ExistsSuccessfulLogin", ctx, user.ID, "203.0.113.5", "", since).Return(false, nil)
+				m.alerts.On("ExistsOpen", ctx, model.SecurityRuleNewIP, &user.ID, "203.0.113.5", dedupSince).Return(true, nil)
+			},
+			expectedLocked: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector, mocks := newTestLoginDetector(tt.config)
+			user := &model.User{ID: 7, Username: "alice", Status: model.UserStatusActive}
+			tt.setupMocks(mocks, user)
+
+			locked := detector.Analyze(ctx, &LoginAttempt{
+				Username:  "alice",
+				User:      user,
+				Success:   true,
+				IPAddress: "203.0.113.5",
+				UserAgent: "Mozilla/5.0",
+			})
+
+			assert.Equal(t, tt.expectedLocked, locked)
+			mocks.assertExpectations(t)
+		})
+	}
+}
+
+func TestLoginDetector_Analyze_LockoutOfSuspendedUser(t *testing.T) {
+	ctx := context.Background()
+	detector, mocks := newTestLoginDetector(newIPConfig(model.SecurityActionLockout))
+	user := &model.User{ID: 7, Username: "alice", Status: model.UserStatusActive}
+
+	since := detectorNow.Add(-30 * 24 * time.Hour)
+
+	mocks.history.On("ExistsSuccessfulLogin", ctx, user.ID, "", "", since).Return(true, nil)
+	mocks.history.On("ExistsSuccessfulLogin", ctx, user.ID, "203.0.113.5", "", since).Return(false, nil)
+	mocks.alerts.On("ExistsOpen", ctx, model.SecurityRuleNewIP, &user.ID, "203.0.113.5", detectorNow.Add(-time.Hour)).Return(false, nil)
+	mocks.alerts.On("Create", ctx, mock.AnythingOfType("*model.SecurityAlert")).Return(nil)
+	// 既に停止されている場合もログインは拒否し、トークンの無効化は行わない
+	mocks.users.On("Suspend", ctx, user, detectorNow).Return(gorm.ErrRecordNotFound)
+
+	locked := detector.Analyze(ctx, &LoginAttempt{Username: "alice", User: user, Success: true, IPAddress: "203.0.113.5"})
+
+	assert.True(t, locked)
+	mocks.assertExpectations(t)
+	mocks.tokens.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything)
+}
+
+func TestLoginDetector_Analyze_PasswordSpraying(t *testing.T) {
+	ctx := context.Background()
+	config := LoginDetectionConfig{
+		AlertDedupWindow:  time.Hour,
+		SprayingWindow:    10 * time.Minute,
+		SprayingThreshold: 3,
+		Actions:           map[string][]string{model.SecurityRulePasswordSpraying: {model.SecurityActionLockout}},
+	}
+	since := detectorNow.Add(-10 * time.Minute)
+
+	t.Run("threshold reached", func(t *testing.T) {
+		detector, mocks := newTestLoginDetector(config)
+		mocks.history.On("FindFailedLoginAccounts", ctx, "198.51.100.9", since, 3).Return([]string{"alice", "bob"}, nil)
+		mocks.alerts.On("ExistsOpen", ctx, model.SecurityRulePasswordSpraying, (*uint)(nil), "198.51.100.9", detectorNow.Add(-time.Hour)).Return(false, nil)
+		mocks.alerts.On("Create", ctx, mock.MatchedBy(func(alert *model.SecurityAlert) bool {
+			return alert.Rule == model.SecurityRulePasswordSpraying && alert.UserID == nil
+		})).Return(nil)
+
+		// 今回の失敗（carol）を含めて閾値に達する。対象のユーザーがいないためユーザーは停止しない
+		locked := detector.Analyze(ctx, &LoginAttempt{Username: "carol", IPAddress: "198.51.100.9"})
+
+		assert.False(t, locked)
+		mocks.assertExpectations(t)
+		mocks.users.AssertNotCalled(t, "Suspend", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("existing user is not locked out", func(t *testing.T) {
+		detector, mocks := newTestLoginDetector(config)
+		mocks.history.On("FindFailedLoginAccounts", ctx, "198.51.100.9", since, 3).Return([]string{"alice", "bob"}, nil)
+		mocks.alerts.On("ExistsOpen", ctx, model.SecurityRulePasswordSpraying, (*uint)(nil), "198.51.100.9", detectorNow.Add(-time.Hour)).Return(false, nil)
+		mocks.alerts.On("Create", ctx, mock.MatchedBy(func(alert *model.SecurityAlert) bool {
+			return alert.Rule == model.SecurityRulePasswordSpraying && alert.UserID == nil
+		})).Return(nil)
+		user := &model.User{ID: 7, Username: "carol", Status: model.UserStatusActive}
+
+		// 閾値を超えたのが実在するユーザーでも、停止するのは送信元のみ
+		locked := detector.Analyze(ctx, &LoginAttempt{User: user, Username: "carol", IPAddress: "198.51.100.9"})
+
+		assert.False(t, locked)
+		assert.Equal(t, model.UserStatusActive, user.Status)
+		mocks.assertExpectations(t)
+		mocks.users.AssertNotCalled(t, "Suspend", mock.Anything, mock.Anything, mock.Anything)
+		mocks.tokens.AssertNotCalled(t, "RevokeAllByUserID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("below threshold", func(t *testing.T) {
+		detector, mocks := newTestLoginDetector(config)
+		mocks.history.On("FindFailedLoginAccounts", ctx, "198.51.100.9", since, 3).Return([]string{"alice"}, nil)
+
+		locked := detector.Analyze(ctx, &LoginAttempt{Username: "alice", IPAddress: "198.51.100.9"})
+
+		assert.False(t, locked)
+		mocks.assertExpectations(t)
+		mocks.alerts.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestLoginDetector_CheckSource(t *testing.T) {
+	ctx := context.Background()
+	lockoutConfig := LoginDetectionConfig{
+		IPLockoutDuration: time.Hour,
+		Actions:           map[string][]string{model.SecurityRulePasswordSpraying: {model.SecurityActionLockout}},
+	}
+	since := detectorNow.Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		config      LoginDetectionConfig
+		setupMocks  func(m *loginDetectorMocks)
+		expectBlock bool
+	}{
+		{
+			name:   "blocked source",
+			config: lockoutConfig,
+			setupMocks: func(m *loginDetectorMocks) {
+				m.alerts.On("ExistsOpenIPLockout", ctx, "198.51.100.9", since).Return(true, nil)
+			},
+			expectBlock: true,
+		},
+		{
+			name:   "no open lockout",
+			config: lockoutConfig,
+			setupMocks: func(m *loginDetectorMocks) {
+				m.alerts.On("ExistsOpenIPLockout", ctx, "198.51.100.9", since).Return(false, nil)
+			},
+		},
+		{
+			name:   "lookup failure does not block",
+			config: lockoutConfig,
+			setupMocks: func(m *loginDetectorMocks) {
+				m.alerts.On("ExistsOpenIPLockout", ctx, "198.51.100.9", since).Return(false, errors.New("database error"))
+			},
+		},
+		{
+			name: "lockout action disabled",
+			config: LoginDetectionConfig{
+				IPLockoutDuration: time.Hour,
+				Actions:           map[string][]string{model.SecurityRulePasswordSpraying: {model.SecurityActionNotify}},
+			},
+			setupMocks: func(m *loginDetectorMocks) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detector, mocks := newTestLoginDetector(tt.config)
+			tt.setupMocks(mocks)
+
+			err := detector.CheckSource(ctx, "198.51.100.9")
+
+			if tt.expectBlock {
+				require.Error(t, err)
+				var appErr *util.AppError
+				require.True(t, errors.As(err, &appErr))
+				assert.Equal(t, util.ErrCodeLoginBlocked, appErr.Code)
+			} else {
+				assert.NoError(t, err)
+			}
+			mocks.assertExpectations(t)
+		})
+	}
+}
+
+func TestImpossibleTravel(t *testing.T) {
+	tokyo := &geoip.Location{Latitude: 35.6895, Longitude: 139.6917}
+	osaka := &geoip.Location{Latitude: 34.6937, Longitude: 135.5023}
+	newYork := &geoip.Location{Latitude: 40.7128, Longitude: -74.0060}
+
+	tests := []struct {
+		name    string
+		from    *geoip.Location
+		to      *geoip.Location
+		elapsed time.Duration
+		want    bool
+	}{
+		{"東京からニューヨークへ1時間", tokyo, newYork, time.Hour, true},
+		{"東京からニューヨークへ1日", tokyo, newYork, 24 * time.Hour, false},
+		{"同時のログイン", tokyo, newYork, 0, true},
+		{"最小の距離未満", tokyo, osaka, time.Minute, false},
+		{"同じ位置", tokyo, tokyo, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, impossible := impossibleTravel(tt.from, tt.to, tt.elapsed, 900, 500)
+			assert.Equal(t, tt.want, impossible)
+		})
+	}
+
+	// 1分未満は1分として速度を計算する
+	distance, speed, _ := impossibleTravel(tokyo, newYork, 0, 900, 500)
+	assert.InDelta(t, distance*60, speed, 1e-6)
+}
+
+func TestAppendAccount(t *testing.T) {
+	assert.Equal(t, []string{"alice", "bob"}, appendAccount([]string{"alice"}, "bob"))
+	assert.Equal(t, []string{"alice"}, appendAccount([]string{"alice"}, "alice"))
+	assert.Equal(t, []string{"alice"}, appendAccount([]string{"alice"}, ""))
+	assert.Equal(t, []string{"bob"}, appendAccount(nil, "bob"))
+}
+
+func TestNormalizeSecurityActions(t *testing.T) {
+	actions, unknown := normalizeSecurityActions(map[string][]string{
+		model.SecurityRulePasswordSpraying: {model.SecurityActionNotify, model.SecurityActionLockout, model.SecurityActionNotify},
+		model.SecurityRuleNewIP:            {"mail"},
+		model.SecurityRuleNewUserAgent:     nil,
+	})
+
+	assert.Equal(t, []string{model.SecurityActionNotify, model.SecurityActionLockout}, actions[model.SecurityRulePasswordSpraying])
+	assert.Empty(t, actions[model.SecurityRuleNewIP])
+	assert.Empty(t, actions[model.SecurityRuleNewUserAgent])
+	assert.Equal(t, []string{"new_ip:mail"}, unknown)
+}
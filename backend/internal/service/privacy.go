@@ -0,0 +1,399 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// redactedValue は匿名化された値の代わりに記録する文字列です
+const redactedValue = "[REDACTED]"
+
+// personalDataKeys は監査ログの変更内容のうち個人情報として扱うキーです
+var personalDataKeys = map[string]bool{
+	"username":      true,
+	"email":         true,
+	"pending_email": true,
+	"full_name":     true,
+	"department":    true,
+	"attributes":    true, // 社員番号等の個人を特定できる値を含み得るため属性全体を対象とする
+}
+
+// PrivacyService は個人データの開示請求・消去請求に関するビジネスロジックを提供します
+type PrivacyService struct {
+	userRepo         *repository.UserRepository
+	refreshTokenRepo *repository.RefreshTokenRepository
+	auditLogRepo     *repository.AuditLogRepository
+	holds            LegalHoldProvider // nil の場合は訴訟ホールドを考慮しない
+	logger           *zap.Logger
+	auditLogService  *AuditLogService
+}
+
+// NewPrivacyService は新しいPrivacyServiceを作成します
+func NewPrivacyService(
+	userRepo *repository.UserRepository,
+	refreshTokenRepo *repository.RefreshTokenRepository,
+	auditLogRepo *repository.AuditLogRepository,
+	holds LegalHoldProvider,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *PrivacyService {
+	return &PrivacyService{
+		userRepo:         userRepo,
+		refreshTokenRepo: refreshTokenRepo,
+		auditLogRepo:     auditLogRepo,
+		holds:            holds,
+		logger:           logger,
+		auditLogService:  auditLogService,
+	}
+}
+
+// PersonalDataExport は開示請求に対して出力する個人データです
+type PersonalDataExport struct {
+	ExportedAt time.Time                 `json:"exported_at"`
+	User       *model.UserResponse       `json:"user"`
+	DeletedAt  *time.Time                `json:"deleted_at"`
+	Sessions   []*model.RefreshToken     `json:"sessions"`
+	AuditLogs  []*model.AuditLogResponse `json:"audit_logs"`
+}
+
+// Export はユーザーについて保持している全ての個人データを出力します
+// actorID は監査ログに記録する実行者です
+func (s *PrivacyService) Export(ctx context.Context, actorID uint, id uint) (*PersonalDataExport, error) {
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// セッション（リフレッシュトークン）を取得
+	sessions, err := s.refreshTokenRepo.FindAllByUserID(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch sessions for export", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// 実行者または対象となっている監査ログを取得
+	auditLogs, err := s.auditLogRepo.FindByActorOrSubject(ctx, id, subjectResourceIDs(user))
+	if err != nil {
+		s.logger.Error("Failed to fetch audit logs for export", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	logResponses := make([]*model.AuditLogResponse, len(auditLogs))
+	for i, log := range auditLogs {
+		logResponses[i] = log.ToResponse()
+	}
+
+	export := &PersonalDataExport{
+		ExportedAt: time.Now(),
+		User:       user.ToResponse(),
+		Sessions:   sessions,
+		AuditLogs:  logResponses,
+	}
+	if user.DeletedAt.Valid {
+		deletedAt := user.DeletedAt.Time
+		export.DeletedAt = &deletedAt
+	}
+
+	s.logger.Info("Personal data exported", zap.Uint("id", id))
+
+	// 監査ログに記録（個人情報を含めないようIDのみで記録）
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionExport,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   fmt.Sprintf("user-%d", id),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After: map[string]interface{}{
+					"sessions":   len(sessions),
+					"audit_logs": len(auditLogs),
+				},
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return export, nil
+}
+
+// AnonymizeResult は匿名化処理の結果です
+type AnonymizeResult struct {
+	UserID              uint `json:"user_id"`
+	AnonymizedAuditLogs int  `json:"anonymized_audit_logs"`
+	HeldAuditLogs       int  `json:"held_audit_logs"` // 訴訟ホールドの対象のため匿名化しなかった件数
+	RevokedSessions     bool `json:"revoked_sessions"`
+}
+
+// Anonymize はユーザーの個人情報を匿名化します
+// ユーザーレコードと監査ログの行は残したまま、個人を特定できる値のみを置き換えます
+// ユーザーが訴訟ホールドの対象の場合は匿名化せず、他のホールドの対象の監査ログはそのまま残します
+// 監査ログ・セッション・ユーザーの更新と成功の監査ログは1つのトランザクションで行い、途中で失敗した場合はすべてロールバックします
+// actorID は監査ログに記録する実行者です
+func (s *PrivacyService) Anonymize(ctx context.Context, actorID uint, id uint) (*AnonymizeResult, error) {
+	user, err := s.findUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var holds []*model.AuditHoldScope
+	if s.holds != nil {
+		held, err := s.holds.UserHeld(ctx, id)
+		if err != nil {
+			s.logger.Error("Failed to check legal hold for anonymization", zap.Uint("id", id), zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+		if held {
+			err := errors.New("user is under legal hold")
+			s.logAnonymizeFailure(ctx, actorID, id, err)
+			return nil, util.NewConflictError(util.ErrCodeUnderLegalHold, err)
+		}
+
+		holds, err = s.holds.AuditHoldScopes(ctx)
+		if err != nil {
+			s.logger.Error("Failed to fetch legal holds for anonymization", zap.Uint("id", id), zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+	}
+
+	resourceIDs := subjectResourceIDs(user)
+	pseudonym := fmt.Sprintf("anonymized-%d", id)
+
+	// キューに残っている監査ログも匿名化の対象にするため、先に書き込ませる
+	if s.auditLogService != nil {
+		if err := s.auditLogService.Flush(ctx); err != nil {
+			s.logger.Error("Failed to flush audit logs before anonymization", zap.Uint("id", id), zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+		}
+	}
+
+	literals := []string{user.Username, user.Email}
+	if user.FullName != "" {
+		literals = append(literals, user.FullName)
+	}
+	if user.PendingEmail != "" {
+		literals = append(literals, user.PendingEmail)
+	}
+
+	// ユーザーの個人情報を匿名化（ログイン不可にするためパスワードハッシュも消去）
+	fields := map[string]interface{}{
+		"username":       pseudonym,
+		"email":          pseudonym + "@anonymized.invalid",
+		"pending_email":  "",
+		"email_token_id": "",
+		"email_verified": false,
+		"full_name":      "",
+		"department":     "",
+		"attributes":     model.UserAttributes{},
+		"password_hash":  "",
+		"status":         model.UserStatusInactive,
+		"last_login":     nil,
+	}
+
+	result := &AnonymizeResult{UserID: id}
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionAnonymize,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   fmt.Sprintf("user-%d", id),
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		// 監査ログの個人情報を匿名化
+		auditLogs, err := s.auditLogRepo.FindByActorOrSubject(ctx, id, resourceIDs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch audit logs: %w", err)
+		}
+
+		// ホールドの対象の監査ログは書き換えない
+		anonymized := make([]*model.AuditLog, 0, len(auditLogs))
+		for _, log := range auditLogs {
+			if !auditLogHeld(holds, log) {
+				anonymized = append(anonymized, log)
+			}
+		}
+		for _, log := range anonymized {
+			if err := anonymizeAuditLog(log, user, pseudonym, literals); err != nil {
+				return nil, fmt.Errorf("failed to anonymize audit log %d: %w", log.ID, err)
+			}
+		}
+		if err := s.auditLogRepo.UpdatePersonalData(ctx, anonymized); err != nil {
+			return nil, fmt.Errorf("failed to update anonymized audit logs: %w", err)
+		}
+
+		// セッションを全て無効化
+		if err := s.refreshTokenRepo.RevokeAllByUserID(ctx, id); err != nil {
+			return nil, fmt.Errorf("failed to revoke sessions: %w", err)
+		}
+
+		if err := s.userRepo.Anonymize(ctx, id, fields); err != nil {
+			return nil, fmt.Errorf("failed to anonymize user: %w", err)
+		}
+
+		result.AnonymizedAuditLogs = len(anonymized)
+		result.HeldAuditLogs = len(auditLogs) - len(anonymized)
+		result.RevokedSessions = true
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionAnonymize,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   fmt.Sprintf("user-%d", id),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After: map[string]interface{}{
+					"username":        pseudonym,
+					"audit_logs":      result.AnonymizedAuditLogs,
+					"held_audit_logs": result.HeldAuditLogs,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		s.logger.Error("Failed to anonymize user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("User anonymized", zap.Uint("id", id), zap.Int("audit_logs", result.AnonymizedAuditLogs), zap.Int("held_audit_logs", result.HeldAuditLogs))
+
+	return result, nil
+}
+
+// findUser は削除済みを含めてユーザーを取得します
+func (s *PrivacyService) findUser(ctx context.Context, id uint) (*model.User, error) {
+	user, err := s.userRepo.FindByIDUnscoped(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return user, nil
+}
+
+// recordChange は変更と成功の監査ログを1つのトランザクションで記録します（監査ログが無効の場合は変更のみ行う）
+func (s *PrivacyService) recordChange(ctx context.Context, failure *model.CreateAuditLogRequest, change func(ctx context.Context) (*model.CreateAuditLogRequest, error)) error {
+	if s.auditLogService == nil {
+		_, err := change(ctx)
+		return err
+	}
+	return s.auditLogService.RecordChange(ctx, failure, change)
+}
+
+// logAnonymizeFailure は匿名化の失敗を監査ログに記録します
+func (s *PrivacyService) logAnonymizeFailure(ctx context.Context, actorID uint, id uint, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionAnonymize,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   fmt.Sprintf("user-%d", id),
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+		Durable:      true,
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// subjectResourceIDs は監査ログ上でユーザーを指すリソースIDの一覧を返します
+func subjectResourceIDs(user *model.User) []string {
+	return []string{
+		user.Username,
+		user.Email,
+		fmt.Sprintf("user-%d", user.ID),
+	}
+}
+
+// anonymizeAuditLog は監査ログ1件の個人情報を匿名化します
+func anonymizeAuditLog(log *model.AuditLog, user *model.User, pseudonym string, literals []string) error {
+	// 実行者の場合は接続元情報を消去
+	if log.UserID == user.ID {
+		log.IPAddress = ""
+		log.UserAgent = ""
+	}
+
+	// 本人を対象とする監査ログかどうか（実行者としての監査ログは他のユーザーを対象とし得る）
+	subject := isSubjectAuditLog(log, user)
+
+	// リソースIDがユーザー名・メールアドレスの場合は仮名に置き換え
+	if log.ResourceType == model.ResourceTypeUser &&
+		(log.ResourceID == user.Username || log.ResourceID == user.Email) {
+		log.ResourceID = pseudonym
+	}
+
+	if len(log.Changes) == 0 {
+		return nil
+	}
+
+	var changes map[string]interface{}
+	if err := json.Unmarshal(log.Changes, &changes); err != nil {
+		return err
+	}
+
+	redacted, err := json.Marshal(redactPersonalData(changes, literals, subject))
+	if err != nil {
+		return err
+	}
+	log.Changes = redacted
+	return nil
+}
+
+// isSubjectAuditLog は監査ログが本人のユーザー情報を対象とするかどうかを返します
+func isSubjectAuditLog(log *model.AuditLog, user *model.User) bool {
+	if log.ResourceType != model.ResourceTypeUser {
+		return false
+	}
+	for _, resourceID := range subjectResourceIDs(user) {
+		if log.ResourceID == resourceID {
+			return true
+		}
+	}
+	return false
+}
+
+// redactPersonalData は値を再帰的に走査し、既知の個人情報の値を置き換えます
+// redactKeys が true の場合（本人を対象とする監査ログ）は個人情報のキーの値も置き換えます
+// 他のユーザーを対象とする監査ログでは、そのユーザーの個人情報を残すためキーによる置き換えは行いません
+// キーや配列の要素数は変更しないため、変更内容の構造は維持されます
+func redactPersonalData(value interface{}, literals []string, redactKeys bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if redactKeys && personalDataKeys[key] {
+				if child != nil && child != "" {
+					v[key] = redactedValue
+				}
+				continue
+			}
+			v[key] = redactPersonalData(child, literals, redactKeys)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = redactPersonalData(child, literals, redactKeys)
+		}
+		return v
+	case string:
+		for _, literal := range literals {
+			if literal != "" && v == literal {
+				return redactedValue
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func TestAnonymizeAuditLog_ActorEntry(t *testing.T) {
+	user := &model.User{ID: 7, Username: "alice", Email: "alice@example.com", FullName: "Alice Smith"}
+
+	changes, _ := json.Marshal(map[string]interface{}{
+		"before": map[string]interface{}{
+			"email":     "alice@example.com",
+			"full_name": "Alice Smith",
+			"role":      "user",
+		},
+		"after": map[string]interface{}{
+			"email":     "alice@new.example.com",
+			"full_name": "Alice Smith",
+			"role":      "manager",
+			"note":      "alice",
+		},
+	})
+
+	log := &model.AuditLog{
+		ID:           1,
+		UserID:       7,
+		Action:       model.ActionUpdate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   "alice",
+		Changes:      changes,
+		IPAddress:    "192.168.1.1",
+		UserAgent:    "Mozilla/5.0",
+		Status:       model.AuditStatusSuccess,
+	}
+
+	err := anonymizeAuditLog(log, user, "anonymized-7", []string{user.Username, user.Email, user.FullName})
+	require.NoError(t, err)
+
+	assert.Equal(t, "anonymized-7", log.ResourceID)
+	assert.Empty(t, log.IPAddress)
+	assert.Empty(t, log.UserAgent)
+	// 個人情報以外のカラムは維持される
+	assert.Equal(t, model.ActionUpdate, log.Action)
+	assert.Equal(t, model.AuditStatusSuccess, log.Status)
+
+	var result model.AuditLogChanges
+	require.NoError(t, json.Unmarshal(log.Changes, &result))
+	assert.Equal(t, redactedValue, result.Before["email"])
+	assert.Equal(t, redactedValue, result.Before["full_name"])
+	assert.Equal(t, "user", result.Before["role"])
+	assert.Equal(t, redactedValue, result.After["email"])
+	assert.Equal(t, "manager", result.After["role"])
+	assert.Equal(t, redactedValue, result.After["note"])
+	// キーは削除されない
+	assert.Len(t, result.After, 4)
+}
+
+func TestAnonymizeAuditLog_SubjectEntryKeepsActorConnectionInfo(t *testing.T) {
+	user := &model.User{ID: 7, Username: "alice", Email: "alice@example.com"}
+
+	log := &model.AuditLog{
+		ID:           2,
+		UserID:       1,
+		Action:       model.ActionDelete,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   "alice",
+		IPAddress:    "10.0.0.1",
+		UserAgent:    "admin-browser",
+	}
+
+	err := anonymizeAuditLog(log, user, "anonymized-7", []string{user.Username, user.Email})
+	require.NoError(t, err)
+
+	assert.Equal(t, "anonymized-7", log.ResourceID)
+	// 実行者は別ユーザーのため接続元情報は残す
+	assert.Equal(t, "10.0.0.1", log.IPAddress)
+	assert.Equal(t, "admin-browser", log.UserAgent)
+}
+
+func TestAnonymizeAuditLog_ActorEntryAboutOtherUser(t *testing.T) {
+	user := &model.User{ID: 7, Username: "alice", Email: "alice@example.com", Role: model.RoleAdmin}
+
+	changes, _ := json.Marshal(map[string]interface{}{
+		"before": map[string]interface{}{
+			"email":     "bob@example.com",
+			"full_name": "Bob Jones",
+		},
+		"after": map[string]interface{}{
+			"email":      "bob@new.example.com",
+			"full_name":  "Bob Jones",
+			"updated_by": "alice",
+		},
+	})
+
+	log := &model.AuditLog{
+		ID:           3,
+		UserID:       7,
+		Action:       model.ActionUpdate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   "bob",
+		Changes:      changes,
+		IPAddress:    "192.168.1.1",
+		UserAgent:    "Mozilla/5.0",
+	}
+
+	err := anonymizeAuditLog(log, user, "anonymized-7", []string{user.Username, user.Email})
+	require.NoError(t, err)
+
+	// 実行者としての接続元情報は消去する
+	assert.Equal(t, "bob", log.ResourceID)
+	assert.Empty(t, log.IPAddress)
+	assert.Empty(t, log.UserAgent)
+
+	// 対象の別ユーザーの個人情報は残し、本人の値のみ置き換える
+	var result model.AuditLogChanges
+	require.NoError(t, json.Unmarshal(log.Changes, &result))
+	assert.Equal(t, "bob@example.com", result.Before["email"])
+	assert.Equal(t, "Bob Jones", result.Before["full_name"])
+	assert.Equal(t, "bob@new.example.com", result.After["email"])
+	assert.Equal(t, redactedValue, result.After["updated_by"])
+}
+
+func TestRedactPersonalData_NestedValues(t *testing.T) {
+	value := map[string]interface{}{
+		"items": []interface{}{"bob@example.com", "other"},
+		"nested": map[string]interface{}{
+			"username":   "bob",
+			"department": "",
+		},
+	}
+
+	result := redactPersonalData(value, []string{"bob", "bob@example.com"}, true).(map[string]interface{})
+
+	assert.Equal(t, []interface{}{redactedValue, "other"}, result["items"])
+	nested := result["nested"].(map[string]interface{})
+	assert.Equal(t, redactedValue, nested["username"])
+	// 空の値はそのまま
+	assert.Equal(t, "", nested["department"])
+}
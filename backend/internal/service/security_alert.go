@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// SecurityAlertService はセキュリティアラートの参照と確認のビジネスロジックを提供します
+type SecurityAlertService struct {
+	repo            *repository.SecurityAlertRepository
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+	now             func() time.Time
+}
+
+// NewSecurityAlertService は新しいSecurityAlertServiceを作成します
+func NewSecurityAlertService(repo *repository.SecurityAlertRepository, logger *zap.Logger, auditLogService *AuditLogService) *SecurityAlertService {
+	return &SecurityAlertService{
+		repo:            repo,
+		logger:          logger,
+		auditLogService: auditLogService,
+		now:             time.Now,
+	}
+}
+
+// List は条件に一致するアラートを新しい順に取得します
+func (s *SecurityAlertService) List(ctx context.Context, filter *model.SecurityAlertFilter, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	alerts, total, err := s.repo.FindByFilter(ctx, filter, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch security alerts", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return util.NewPaginatedResponse(alerts, total, params), nil
+}
+
+// GetByID はIDでアラートを取得します
+func (s *SecurityAlertService) GetByID(ctx context.Context, id uint) (*model.SecurityAlert, error) {
+	return s.findAlert(ctx, id)
+}
+
+// Acknowledge はアラートを確認済みにします
+// パスワードスプレーのアラートを確認すると、送信元のIPアドレスからのログインの拒否も解除されます
+// 停止されたユーザーは確認では再開されないため、ユーザーの更新で有効に戻します
+func (s *SecurityAlertService) Acknowledge(ctx context.Context, actorID, id uint, req *model.AcknowledgeSecurityAlertRequest) (*model.SecurityAlert, error) {
+	alert, err := s.findAlert(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert.Acknowledged() {
+		return nil, util.NewConflictError(util.ErrCodeSecurityAlertAcknowledged, errors.New("security alert is already acknowledged"))
+	}
+
+	acknowledgedAt := s.now()
+	alert.AcknowledgedAt = &acknowledgedAt
+	alert.AcknowledgedBy = &actorID
+	alert.AcknowledgeNote = strings.TrimSpace(req.Note)
+	acknowledged, err := s.repo.Acknowledge(ctx, alert)
+	if err != nil {
+		s.logger.Error("Failed to acknowledge security alert", zap.Uint("id", id), zap.Error(err))
+		if s.auditLogService != nil {
+			s.auditLogService.LogAction(ctx, &model.CreateAuditLogRequest{
+				UserID:       actorID,
+				Action:       model.ActionAcknowledge,
+				ResourceType: model.ResourceTypeSecurityAlert,
+				ResourceID:   fmt.Sprintf("%d", id),
+				Status:       model.AuditStatusFailed,
+				ErrorMessage: err.Error(),
+				Durable:      true,
+			})
+		}
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if !acknowledged {
+		// 同時に確認された
+		return nil, util.NewConflictError(util.ErrCodeSecurityAlertAcknowledged, errors.New("security alert is already acknowledged"))
+	}
+
+	s.logger.Info("Security alert acknowledged", zap.Uint("id", id), zap.String("rule", alert.Rule))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionAcknowledge,
+			ResourceType: model.ResourceTypeSecurityAlert,
+			ResourceID:   fmt.Sprintf("%d", alert.ID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"acknowledged": false},
+				After: map[string]interface{}{
+					"acknowledged": true,
+					"rule":         alert.Rule,
+					"severity":     alert.Severity,
+					"note":         alert.AcknowledgeNote,
+				},
+			},
+			Status:  model.AuditStatusSuccess,
+			Durable: true,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return alert, nil
+}
+
+// findAlert はIDでアラートを取得し、存在しない場合は404エラーを返します
+func (s *SecurityAlertService) findAlert(ctx context.Context, id uint) (*model.SecurityAlert, error) {
+	alert, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeSecurityAlertNotFound, err)
+		}
+		s.logger.Error("Failed to fetch security alert", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return alert, nil
+}
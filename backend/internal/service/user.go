@@ -1,9 +1,15 @@
 package service
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
+	"time"
 
+	"github.com/go-playground/validator/v10"
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -13,25 +19,68 @@ import (
 	"github.com/varubogu/effisio/backend/pkg/util"
 )
 
+// userStore はUserServiceが使用するユーザーの永続化手段です（repository.UserRepository）
+type userStore interface {
+	FindAll(ctx context.Context, params *util.PaginationParams, attributes map[string]string) ([]*model.User, int64, error)
+	FindByID(ctx context.Context, id uint) (*model.User, error)
+	FindByEmail(ctx context.Context, email string) (*model.User, error)
+	Create(ctx context.Context, user *model.User) error
+	UpdateWithVersion(ctx context.Context, user *model.User, expectedVersion uint) error
+	Delete(ctx context.Context, id uint) error
+	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	FindDeleted(ctx context.Context, params *util.PaginationParams) ([]*model.User, int64, error)
+	FindDeletedByID(ctx context.Context, id uint) (*model.User, error)
+	Restore(ctx context.Context, id uint) error
+	FindDeletedBefore(ctx context.Context, cutoff time.Time) ([]*model.User, error)
+	Purge(ctx context.Context, id uint) error
+}
+
 // UserService はユーザー関連のビジネスロジックを提供します
 type UserService struct {
-	repo              *repository.UserRepository
-	logger            *zap.Logger
-	auditLogService   *AuditLogService
+	repo                     userStore
+	logger                   *zap.Logger
+	auditLogService          *AuditLogService
+	emailVerificationService *EmailVerificationService
+	attributeService         *UserAttributeService
+	groupService             *GroupService
+	webhookService           *WebhookService
+	holds                    UserHoldChecker // nil の場合は訴訟ホールドを考慮しない
 }
 
 // NewUserService は新しいUserServiceを作成します
-func NewUserService(repo *repository.UserRepository, logger *zap.Logger, auditLogService *AuditLogService) *UserService {
+func NewUserService(
+	repo userStore,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+	emailVerificationService *EmailVerificationService,
+	attributeService *UserAttributeService,
+	groupService *GroupService,
+	webhookService *WebhookService,
+	holds UserHoldChecker,
+) *UserService {
 	return &UserService{
-		repo:              repo,
-		logger:            logger,
-		auditLogService:   auditLogService,
+		repo:                     repo,
+		logger:                   logger,
+		auditLogService:          auditLogService,
+		emailVerificationService: emailVerificationService,
+		attributeService:         attributeService,
+		groupService:             groupService,
+		webhookService:           webhookService,
+		holds:                    holds,
 	}
 }
 
 // List はユーザー一覧を取得します
-func (s *UserService) List(ctx context.Context, params *util.PaginationParams) (*util.PaginatedResponse, error) {
-	users, total, err := s.repo.FindAll(ctx, params)
+// attributes を指定した場合は追加属性の値が一致するユーザーのみを返します
+func (s *UserService) List(ctx context.Context, params *util.PaginationParams, attributes map[string]string) (*util.PaginatedResponse, error) {
+	if s.attributeService != nil {
+		if err := s.attributeService.ValidateFilter(ctx, attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	users, total, err := s.repo.FindAll(ctx, params, attributes)
 	if err != nil {
 		s.logger.Error("Failed to fetch users", zap.Error(err))
 		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
@@ -60,7 +109,7 @@ func (s *UserService) GetByID(ctx context.Context, id uint) (*model.UserResponse
 }
 
 // Create は新しいユーザーを作成します
-func (s *UserService) Create(ctx context.Context, req *model.CreateUserRequest) (*model.UserResponse, error) {
+func (s *UserService) Create(ctx context.Context, actorID uint, req *model.CreateUserRequest) (*model.UserResponse, error) {
 	// ユーザー名の重複チェック
 	exists, err := s.repo.ExistsByUsername(ctx, req.Username)
 	if err != nil {
@@ -81,6 +130,14 @@ func (s *UserService) Create(ctx context.Context, req *model.CreateUserRequest)
 		return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("email already exists"))
 	}
 
+	// 追加属性の検証
+	attributes := mergeAttributes(nil, req.Attributes)
+	if s.attributeService != nil {
+		if err := s.attributeService.Validate(ctx, nil, attributes); err != nil {
+			return nil, err
+		}
+	}
+
 	// パスワードをハッシュ化
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -90,64 +147,67 @@ func (s *UserService) Create(ctx context.Context, req *model.CreateUserRequest)
 
 	// ユーザーモデルを作成
 	user := &model.User{
-		Username:     req.Username,
-		Email:        req.Email,
-		FullName:     req.FullName,
-		Department:   req.Department,
-		PasswordHash: string(hashedPassword),
-		Role:         req.Role,
-		Status:       model.UserStatusActive,
+		Username:         req.Username,
+		Email:            req.Email,
+		FullName:         req.FullName,
+		Department:       req.Department,
+		PasswordHash:     string(hashedPassword),
+		Role:             req.Role,
+		Status:           model.UserStatusActive,
+		Attributes:       attributes,
+		EmailTokenID:     uuid.New().String(), // メールアドレスは確認メールで確認するまで未確認
+		AccountExpiresAt: req.AccountExpiresAt,
 	}
 
-	// データベースに保存
-	if err := s.repo.Create(ctx, user); err != nil {
-		s.logger.Error("Failed to create user", zap.Error(err))
-		// 監査ログに失敗を記録
-		if s.auditLogService != nil {
-			auditReq := &model.CreateAuditLogRequest{
-				UserID:       1, // システムユーザー（実装時に認証ユーザーから取得）
-				Action:       model.ActionCreate,
-				ResourceType: model.ResourceTypeUser,
-				ResourceID:   req.Username,
-				Status:       model.AuditStatusFailed,
-				ErrorMessage: err.Error(),
-			}
-			s.auditLogService.LogAction(ctx, auditReq)
-		}
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	// データベースに保存し、同じトランザクションで監査ログを記録
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionCreate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   req.Username,
 	}
-
-	s.logger.Info("User created", zap.Uint("id", user.ID), zap.String("username", user.Username))
-
-	// 監査ログに成功を記録
-	if s.auditLogService != nil {
-		auditReq := &model.CreateAuditLogRequest{
-			UserID:       1, // システムユーザー（実装時に認証ユーザーから取得）
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.repo.Create(ctx, user); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
 			Action:       model.ActionCreate,
 			ResourceType: model.ResourceTypeUser,
 			ResourceID:   user.Username,
 			Changes: model.AuditLogChanges{
 				Before: map[string]interface{}{},
 				After: map[string]interface{}{
-					"id":         user.ID,
-					"username":   user.Username,
-					"email":      user.Email,
-					"full_name":  user.FullName,
-					"department": user.Department,
-					"role":       user.Role,
-					"status":     user.Status,
+					"id":                 user.ID,
+					"username":           user.Username,
+					"email":              user.Email,
+					"full_name":          user.FullName,
+					"department":         user.Department,
+					"role":               user.Role,
+					"status":             user.Status,
+					"attributes":         user.Attributes.Clone(),
+					"account_expires_at": user.AccountExpiresAt,
 				},
 			},
 			Status: model.AuditStatusSuccess,
-		}
-		s.auditLogService.LogAction(ctx, auditReq)
+		}, nil
+	}); err != nil {
+		s.logger.Error("Failed to create user", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
+	s.logger.Info("User created", zap.Uint("id", user.ID), zap.String("username", user.Username))
+
+	s.sendEmailVerification(ctx, user)
+	s.emitUserEvent(ctx, model.WebhookEventUserCreated, user)
+
 	return user.ToResponse(), nil
 }
 
 // Update はユーザー情報を更新します
-func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUserRequest) (*model.UserResponse, error) {
+// expectedVersion はクライアントが取得したバージョン（If-Match）で、一致しない場合は412を返します
+// model.AnyVersion（If-Match: *）の場合は現在のバージョンに対して更新します
+func (s *UserService) Update(ctx context.Context, actorID uint, id uint, req *model.UpdateUserRequest, expectedVersion uint) (*model.UserResponse, error) {
 	// 既存ユーザーを取得
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
@@ -158,23 +218,41 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
+	// If-Match: * の場合は取得時点のバージョンを条件にする
+	if expectedVersion == model.AnyVersion {
+		expectedVersion = user.Version
+	}
+
+	// 明らかに古いバージョンの場合は早期に失敗させる（最終的な判定は条件付きUPDATEで行う）
+	if user.Version != expectedVersion {
+		return nil, util.NewPreconditionFailedError(util.ErrCodeVersionConflict, errors.New("user has been modified by another request"))
+	}
+
+	previousStatus := user.Status
+
 	// 監査ログ用に更新前の値を保存
 	beforeChanges := map[string]interface{}{
-		"email":      user.Email,
-		"full_name":  user.FullName,
-		"department": user.Department,
-		"role":       user.Role,
-		"status":     user.Status,
+		"email":              user.Email,
+		"pending_email":      user.PendingEmail,
+		"full_name":          user.FullName,
+		"department":         user.Department,
+		"role":               user.Role,
+		"status":             user.Status,
+		"attributes":         user.Attributes.Clone(),
+		"account_expires_at": user.AccountExpiresAt,
 	}
 
 	// 更新データを適用
-	if req.Email != nil {
+	emailChanged := false
+	if req.Email != nil && *req.Email != user.PendingEmail {
 		// メールアドレスの重複チェック（自分以外）
 		existingUser, err := s.repo.FindByEmail(ctx, *req.Email)
 		if err == nil && existingUser.ID != id {
 			return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("email already exists"))
 		}
-		user.Email = *req.Email
+		// 新しいアドレスは確認が完了するまで反映しない
+		emailChanged = *req.Email != user.Email
+		requestEmailChange(user, *req.Email)
 	}
 	if req.FullName != nil {
 		user.FullName = *req.FullName
@@ -186,42 +264,47 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 		user.Role = *req.Role
 	}
 	if req.Status != nil {
-		user.Status = *req.Status
+		user.SetStatus(*req.Status, time.Now())
 	}
-
-	// 監査ログ用に更新後の値を保存
-	afterChanges := map[string]interface{}{
-		"email":      user.Email,
-		"full_name":  user.FullName,
-		"department": user.Department,
-		"role":       user.Role,
-		"status":     user.Status,
+	if req.AccountExpiresAt != nil {
+		user.AccountExpiresAt = req.AccountExpiresAt
 	}
-
-	// データベースを更新
-	if err := s.repo.Update(ctx, user); err != nil {
-		s.logger.Error("Failed to update user", zap.Uint("id", id), zap.Error(err))
-		// 監査ログに失敗を記録
-		if s.auditLogService != nil {
-			auditReq := &model.CreateAuditLogRequest{
-				UserID:       1, // システムユーザー（実装時に認証ユーザーから取得）
-				Action:       model.ActionUpdate,
-				ResourceType: model.ResourceTypeUser,
-				ResourceID:   user.Username,
-				Status:       model.AuditStatusFailed,
-				ErrorMessage: err.Error(),
+	if req.Attributes != nil {
+		attributes := mergeAttributes(user.Attributes, req.Attributes)
+		if s.attributeService != nil && !reflect.DeepEqual(attributes, user.Attributes.Clone()) {
+			if err := s.attributeService.Validate(ctx, user.Attributes, attributes); err != nil {
+				return nil, err
 			}
-			s.auditLogService.LogAction(ctx, auditReq)
 		}
-		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		user.Attributes = attributes
 	}
 
-	s.logger.Info("User updated", zap.Uint("id", user.ID))
+	// 監査ログ用に更新後の値を保存（変更された項目のみ）
+	afterChanges := map[string]interface{}{
+		"email":              user.Email,
+		"pending_email":      user.PendingEmail,
+		"full_name":          user.FullName,
+		"department":         user.Department,
+		"role":               user.Role,
+		"status":             user.Status,
+		"attributes":         user.Attributes.Clone(),
+		"account_expires_at": user.AccountExpiresAt,
+	}
+	beforeChanges, afterChanges = diffPatchDocuments(beforeChanges, afterChanges)
 
-	// 監査ログに成功を記録
-	if s.auditLogService != nil {
-		auditReq := &model.CreateAuditLogRequest{
-			UserID:       1, // システムユーザー（実装時に認証ユーザーから取得）
+	// データベースを更新（バージョンが一致する場合のみ）し、同じトランザクションで監査ログを記録
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionUpdate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   user.Username,
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.repo.UpdateWithVersion(ctx, user, expectedVersion); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
 			Action:       model.ActionUpdate,
 			ResourceType: model.ResourceTypeUser,
 			ResourceID:   user.Username,
@@ -230,15 +313,28 @@ func (s *UserService) Update(ctx context.Context, id uint, req *model.UpdateUser
 				After:  afterChanges,
 			},
 			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.logger.Info("User update rejected by version conflict", zap.Uint("id", id), zap.Uint("expected_version", expectedVersion))
+			return nil, util.NewPreconditionFailedError(util.ErrCodeVersionConflict, errors.New("user has been modified by another request"))
 		}
-		s.auditLogService.LogAction(ctx, auditReq)
+		s.logger.Error("Failed to update user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
+	s.logger.Info("User updated", zap.Uint("id", user.ID))
+
+	if emailChanged {
+		s.sendEmailVerification(ctx, user)
+	}
+	s.emitUserUpdated(ctx, user, previousStatus)
+
 	return user.ToResponse(), nil
 }
 
 // Delete はユーザーを削除します（ソフトデリート）
-func (s *UserService) Delete(ctx context.Context, id uint) error {
+func (s *UserService) Delete(ctx context.Context, actorID uint, id uint) error {
 	// 存在確認
 	user, err := s.repo.FindByID(ctx, id)
 	if err != nil {
@@ -249,30 +345,19 @@ func (s *UserService) Delete(ctx context.Context, id uint) error {
 		return util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
-	// 削除実行
-	if err := s.repo.Delete(ctx, id); err != nil {
-		s.logger.Error("Failed to delete user", zap.Uint("id", id), zap.Error(err))
-		// 監査ログに失敗を記録
-		if s.auditLogService != nil {
-			auditReq := &model.CreateAuditLogRequest{
-				UserID:       1, // システムユーザー（実装時に認証ユーザーから取得）
-				Action:       model.ActionDelete,
-				ResourceType: model.ResourceTypeUser,
-				ResourceID:   user.Username,
-				Status:       model.AuditStatusFailed,
-				ErrorMessage: err.Error(),
-			}
-			s.auditLogService.LogAction(ctx, auditReq)
-		}
-		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	// 削除を実行し、同じトランザクションで監査ログを記録
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionDelete,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   user.Username,
 	}
-
-	s.logger.Info("User deleted", zap.Uint("id", id))
-
-	// 監査ログに成功を記録
-	if s.auditLogService != nil {
-		auditReq := &model.CreateAuditLogRequest{
-			UserID:       1, // システムユーザー（実装時に認証ユーザーから取得）
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
 			Action:       model.ActionDelete,
 			ResourceType: model.ResourceTypeUser,
 			ResourceID:   user.Username,
@@ -289,9 +374,492 @@ func (s *UserService) Delete(ctx context.Context, id uint) error {
 				After: map[string]interface{}{},
 			},
 			Status: model.AuditStatusSuccess,
-		}
-		s.auditLogService.LogAction(ctx, auditReq)
+		}, nil
+	}); err != nil {
+		s.logger.Error("Failed to delete user", zap.Uint("id", id), zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
 	}
 
+	s.logger.Info("User deleted", zap.Uint("id", id))
+
+	s.emitUserEvent(ctx, model.WebhookEventUserDeleted, user)
+
 	return nil
 }
+
+// ListDeleted は削除済みユーザー一覧を取得します
+func (s *UserService) ListDeleted(ctx context.Context, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	users, total, err := s.repo.FindDeleted(ctx, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch deleted users", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	responses := make([]*model.DeletedUserResponse, len(users))
+	for i, user := range users {
+		responses[i] = user.ToDeletedResponse()
+	}
+
+	return util.NewPaginatedResponse(responses, total, params), nil
+}
+
+// Restore は削除済みユーザーを復元します
+func (s *UserService) Restore(ctx context.Context, actorID uint, id uint) (*model.UserResponse, error) {
+	// 削除済みユーザーを取得
+	user, err := s.repo.FindDeletedByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch deleted user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// 削除後に同じユーザー名・メールアドレスが再利用されていないかチェック
+	exists, err := s.repo.ExistsByUsername(ctx, user.Username)
+	if err != nil {
+		s.logger.Error("Failed to check username existence", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("username is already used by another user"))
+	}
+
+	exists, err = s.repo.ExistsByEmail(ctx, user.Email)
+	if err != nil {
+		s.logger.Error("Failed to check email existence", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("email is already used by another user"))
+	}
+
+	// 復元を実行し、同じトランザクションで監査ログを記録
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionRestore,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   user.Username,
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.repo.Restore(ctx, id); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionRestore,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{
+					"deleted_at": user.DeletedAt.Time,
+				},
+				After: map[string]interface{}{
+					"deleted_at": nil,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		s.logger.Error("Failed to restore user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("User restored", zap.Uint("id", id))
+
+	user.DeletedAt = gorm.DeletedAt{}
+	return user.ToResponse(), nil
+}
+
+// PurgeDeleted は保持期間を過ぎた削除済みユーザーを物理削除します
+// actorID は監査ログに記録する実行者です（定期実行の場合はシステムユーザー）
+// 訴訟ホールドの対象のユーザーは削除しません。削除したユーザー数を返します
+func (s *UserService) PurgeDeleted(ctx context.Context, actorID uint, retention time.Duration) (int, error) {
+	if retention <= 0 {
+		return 0, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("retention must be positive"))
+	}
+
+	cutoff := time.Now().Add(-retention)
+	users, err := s.repo.FindDeletedBefore(ctx, cutoff)
+	if err != nil {
+		s.logger.Error("Failed to fetch users to purge", zap.Time("cutoff", cutoff), zap.Error(err))
+		return 0, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	purged := 0
+	for _, user := range users {
+		// 訴訟ホールドの対象のユーザーは解除されるまで残す
+		if s.holds != nil {
+			held, err := s.holds.UserHeld(ctx, user.ID)
+			if err != nil {
+				s.logger.Error("Failed to check legal hold for user", zap.Uint("id", user.ID), zap.Error(err))
+				continue
+			}
+			if held {
+				s.logger.Info("Deleted user is under legal hold, skipping purge", zap.Uint("id", user.ID))
+				continue
+			}
+		}
+
+		if err := s.repo.Purge(ctx, user.ID); err != nil {
+			// 1件の失敗で残りの削除を止めない
+			s.logger.Error("Failed to purge user", zap.Uint("id", user.ID), zap.Error(err))
+			if s.auditLogService != nil {
+				auditReq := &model.CreateAuditLogRequest{
+					UserID:       actorID,
+					Action:       model.ActionPurge,
+					ResourceType: model.ResourceTypeUser,
+					ResourceID:   user.Username,
+					Status:       model.AuditStatusFailed,
+					ErrorMessage: err.Error(),
+				}
+				s.auditLogService.LogAction(ctx, auditReq)
+			}
+			continue
+		}
+		purged++
+
+		// 監査ログに成功を記録
+		if s.auditLogService != nil {
+			auditReq := &model.CreateAuditLogRequest{
+				UserID:       actorID,
+				Action:       model.ActionPurge,
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   user.Username,
+				Changes: model.AuditLogChanges{
+					Before: map[string]interface{}{
+						"id":         user.ID,
+						"username":   user.Username,
+						"deleted_at": user.DeletedAt.Time,
+					},
+					After: map[string]interface{}{},
+				},
+				Status: model.AuditStatusSuccess,
+			}
+			s.auditLogService.LogAction(ctx, auditReq)
+		}
+	}
+
+	if purged > 0 {
+		s.logger.Info("Deleted users purged", zap.Int("count", purged), zap.Time("cutoff", cutoff))
+	}
+	return purged, nil
+}
+
+// GetMe はログイン中のユーザー自身の情報を取得します
+// 権限はトークンではなく現在のロールと所属グループから算出します
+func (s *UserService) GetMe(ctx context.Context, id uint) (*model.MeResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch current user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	return s.toMeResponse(ctx, user)
+}
+
+// UpdateMe はログイン中のユーザー自身の情報を更新します
+func (s *UserService) UpdateMe(ctx context.Context, id uint, req *model.UpdateMeRequest) (*model.MeResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch current user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// 監査ログ用に更新前の値を保存
+	beforeChanges := map[string]interface{}{
+		"full_name": user.FullName,
+	}
+
+	if req.FullName != nil {
+		user.FullName = *req.FullName
+	}
+
+	afterChanges := map[string]interface{}{
+		"full_name": user.FullName,
+	}
+
+	// データベースを更新し、同じトランザクションで監査ログを記録（実行者は本人）
+	failure := &model.CreateAuditLogRequest{
+		UserID:       id,
+		Action:       model.ActionUpdate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   user.Username,
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.repo.UpdateWithVersion(ctx, user, user.Version); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       id,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: beforeChanges,
+				After:  afterChanges,
+			},
+			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.logger.Info("Current user update rejected by version conflict", zap.Uint("id", id), zap.Uint("expected_version", user.Version))
+			return nil, util.NewPreconditionFailedError(util.ErrCodeVersionConflict, errors.New("user has been modified by another request"))
+		}
+		s.logger.Error("Failed to update current user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Current user updated", zap.Uint("id", id))
+
+	return s.toMeResponse(ctx, user)
+}
+
+// toMeResponse はログイン中のユーザー自身の情報を実効権限付きで返します
+func (s *UserService) toMeResponse(ctx context.Context, user *model.User) (*model.MeResponse, error) {
+	permissions := util.GetPermissionsForRole(user.Role)
+	if s.groupService != nil {
+		var err error
+		permissions, err = s.groupService.EffectivePermissions(ctx, user)
+		if err != nil {
+			s.logger.Error("Failed to resolve permissions", zap.Uint("id", user.ID), zap.Error(err))
+			return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+	}
+
+	return &model.MeResponse{
+		UserResponse: *user.ToResponse(),
+		Permissions:  permissions,
+	}, nil
+}
+
+// patchValidator はパッチ適用後のドキュメントを検証します
+// リクエストのバインドと同じ binding タグで検証します
+var patchValidator = newPatchValidator()
+
+func newPatchValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	return v
+}
+
+// Patch はJSON Merge Patch（RFC 7396）またはJSON Patch（RFC 6902）でユーザー情報を部分更新します
+// パッチは更新可能な項目のみを持つドキュメントに適用され、適用後の内容を検証してから保存します
+func (s *UserService) Patch(ctx context.Context, actorID uint, id uint, contentType string, patch []byte, expectedVersion uint) (*model.UserResponse, error) {
+	user, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeUserNotFound, err)
+		}
+		s.logger.Error("Failed to fetch user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	// If-Match: * の場合は取得時点のバージョンを条件にする
+	if expectedVersion == model.AnyVersion {
+		expectedVersion = user.Version
+	}
+
+	// 明らかに古いバージョンの場合は早期に失敗させる（最終的な判定は条件付きUPDATEで行う）
+	if user.Version != expectedVersion {
+		return nil, util.NewPreconditionFailedError(util.ErrCodeVersionConflict, errors.New("user has been modified by another request"))
+	}
+
+	before, err := patchDocumentToMap(user.PatchDocument())
+	if err != nil {
+		return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+	}
+
+	var patched map[string]interface{}
+	switch contentType {
+	case util.ContentTypeMergePatch:
+		patched, err = util.ApplyMergePatch(before, patch)
+	case util.ContentTypeJSONPatch:
+		patched, err = util.ApplyJSONPatch(before, patch)
+	default:
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("unsupported patch type"))
+	}
+	if err != nil {
+		if errors.Is(err, util.ErrPatchTestFailed) {
+			return nil, util.NewConflictError(util.ErrCodePatchTestFailed, err)
+		}
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidPatch, err)
+	}
+
+	// 更新できない項目（ユーザー名やパスワード等）が追加された場合はエラーにする
+	doc, err := decodePatchDocument(patched)
+	if err != nil {
+		return nil, util.NewBadRequestError(util.ErrCodeInvalidPatch, err)
+	}
+	if err := patchValidator.Struct(doc); err != nil {
+		return nil, util.NewValidationError(util.ParseValidationErrors(err), err)
+	}
+
+	// パッチでメールアドレスが変更された場合は確認待ちとして扱い、確認完了まで反映しない
+	emailChanged := doc.Email != user.Email && doc.Email != user.PendingEmail
+	previousPendingEmail := user.PendingEmail
+	previousStatus := user.Status
+	if emailChanged {
+		// メールアドレスの重複チェック（自分以外）
+		existingUser, err := s.repo.FindByEmail(ctx, doc.Email)
+		if err == nil && existingUser.ID != id {
+			return nil, util.NewConflictError(util.ErrCodeUserAlreadyExists, errors.New("email already exists"))
+		}
+		requestEmailChange(user, doc.Email)
+	}
+	doc.Email = user.Email
+
+	// 追加属性の検証（変更がある場合のみ）
+	if doc.Attributes == nil {
+		doc.Attributes = model.UserAttributes{}
+	}
+	if s.attributeService != nil && !reflect.DeepEqual(doc.Attributes, user.Attributes.Clone()) {
+		if err := s.attributeService.Validate(ctx, user.Attributes, doc.Attributes); err != nil {
+			return nil, err
+		}
+	}
+
+	user.FullName = doc.FullName
+	user.Department = doc.Department
+	user.Role = doc.Role
+	user.SetStatus(doc.Status, time.Now())
+	user.Attributes = doc.Attributes
+	user.AccountExpiresAt = doc.AccountExpiresAt
+
+	after, err := patchDocumentToMap(doc)
+	if err != nil {
+		return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+	}
+	beforeChanges, afterChanges := diffPatchDocuments(before, after)
+	if user.PendingEmail != previousPendingEmail {
+		beforeChanges["pending_email"] = previousPendingEmail
+		afterChanges["pending_email"] = user.PendingEmail
+	}
+
+	// データベースを更新（バージョンが一致する場合のみ）し、同じトランザクションで監査ログを記録（変更された項目のみ）
+	failure := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       model.ActionUpdate,
+		ResourceType: model.ResourceTypeUser,
+		ResourceID:   user.Username,
+	}
+	if err := s.recordChange(ctx, failure, func(ctx context.Context) (*model.CreateAuditLogRequest, error) {
+		if err := s.repo.UpdateWithVersion(ctx, user, expectedVersion); err != nil {
+			return nil, err
+		}
+		return &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: beforeChanges,
+				After:  afterChanges,
+			},
+			Status: model.AuditStatusSuccess,
+		}, nil
+	}); err != nil {
+		if errors.Is(err, repository.ErrVersionConflict) {
+			s.logger.Info("User patch rejected by version conflict", zap.Uint("id", id), zap.Uint("expected_version", expectedVersion))
+			return nil, util.NewPreconditionFailedError(util.ErrCodeVersionConflict, errors.New("user has been modified by another request"))
+		}
+		s.logger.Error("Failed to patch user", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("User patched", zap.Uint("id", user.ID), zap.String("patch_type", contentType))
+
+	if emailChanged {
+		s.sendEmailVerification(ctx, user)
+	}
+	s.emitUserUpdated(ctx, user, previousStatus)
+
+	return user.ToResponse(), nil
+}
+
+// recordChange は change による変更と監査ログを1つのトランザクションで書き込みます
+// 監査ログが無効な場合は変更のみを行います
+func (s *UserService) recordChange(ctx context.Context, failure *model.CreateAuditLogRequest, change func(ctx context.Context) (*model.CreateAuditLogRequest, error)) error {
+	if s.auditLogService == nil {
+		_, err := change(ctx)
+		return err
+	}
+	return s.auditLogService.RecordChange(ctx, failure, change)
+}
+
+// emitUserEvent はユーザーのイベントをWebhookで通知します
+func (s *UserService) emitUserEvent(ctx context.Context, eventType string, user *model.User) {
+	if s.webhookService == nil {
+		return
+	}
+	s.webhookService.Emit(ctx, eventType, user.ToResponse())
+}
+
+// emitUserUpdated はユーザーの更新をWebhookで通知します
+// 停止状態に変更された場合は、更新とは別に user.suspended でも通知します
+func (s *UserService) emitUserUpdated(ctx context.Context, user *model.User, previousStatus string) {
+	s.emitUserEvent(ctx, model.WebhookEventUserUpdated, user)
+	if user.Status == model.UserStatusSuspended && previousStatus != model.UserStatusSuspended {
+		s.emitUserEvent(ctx, model.WebhookEventUserSuspended, user)
+	}
+}
+
+// sendEmailVerification は確認メールを送信します
+// 送信に失敗してもユーザーの作成・更新は取り消さず、再送で対応できるようにします
+func (s *UserService) sendEmailVerification(ctx context.Context, user *model.User) {
+	if s.emailVerificationService == nil {
+		return
+	}
+	if err := s.emailVerificationService.Send(ctx, user); err != nil {
+		s.logger.Warn("Failed to send email verification", zap.Uint("id", user.ID), zap.Error(err))
+	}
+}
+
+// patchDocumentToMap はパッチ対象のドキュメントをJSONオブジェクトとして返します
+func patchDocumentToMap(doc *model.UserPatchDocument) (map[string]interface{}, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// decodePatchDocument はパッチ適用後のJSONオブジェクトをドキュメントに変換します
+// 未知のフィールドや型の合わない値はエラーになります
+func decodePatchDocument(patched map[string]interface{}) (*model.UserPatchDocument, error) {
+	data, err := json.Marshal(patched)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	var doc model.UserPatchDocument
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+// diffPatchDocuments は変更された項目のみの更新前後の値を返します
+func diffPatchDocuments(before, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	beforeChanges := make(map[string]interface{})
+	afterChanges := make(map[string]interface{})
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			beforeChanges[key] = before[key]
+			afterChanges[key] = value
+		}
+	}
+	return beforeChanges, afterChanges
+}
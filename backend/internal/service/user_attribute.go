@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// attributeKeyPattern は属性キーとして使用できる形式です（JSONBのキーやクエリパラメータで扱いやすい形式に限定）
+var attributeKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// maxAttributeStringLength は文字列型の属性値の最大文字数です
+const maxAttributeStringLength = 255
+
+// UserAttributeService はユーザー追加属性の定義と値の検証に関するビジネスロジックを提供します
+type UserAttributeService struct {
+	repo            *repository.UserAttributeDefinitionRepository
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+}
+
+// NewUserAttributeService は新しいUserAttributeServiceを作成します
+func NewUserAttributeService(repo *repository.UserAttributeDefinitionRepository, logger *zap.Logger, auditLogService *AuditLogService) *UserAttributeService {
+	return &UserAttributeService{
+		repo:            repo,
+		logger:          logger,
+		auditLogService: auditLogService,
+	}
+}
+
+// List は全ての属性定義を取得します
+func (s *UserAttributeService) List(ctx context.Context) ([]*model.UserAttributeDefinition, error) {
+	definitions, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch attribute definitions", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return definitions, nil
+}
+
+// Create は属性定義を作成します
+func (s *UserAttributeService) Create(ctx context.Context, actorID uint, req *model.CreateUserAttributeDefinitionRequest) (*model.UserAttributeDefinition, error) {
+	definition := &model.UserAttributeDefinition{
+		Key:         req.Key,
+		Label:       req.Label,
+		Type:        req.Type,
+		Required:    req.Required,
+		EnumValues:  model.StringList(req.EnumValues),
+		Pattern:     req.Pattern,
+		Description: req.Description,
+	}
+	if definition.EnumValues == nil {
+		definition.EnumValues = model.StringList{}
+	}
+
+	if details := validateAttributeDefinition(definition); len(details) > 0 {
+		return nil, util.NewValidationError(details, errors.New("invalid attribute definition"))
+	}
+
+	exists, err := s.repo.ExistsByKey(ctx, definition.Key)
+	if err != nil {
+		s.logger.Error("Failed to check attribute key existence", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	if exists {
+		return nil, util.NewConflictError(util.ErrCodeAttributeAlreadyExists, errors.New("attribute key already exists"))
+	}
+
+	if err := s.repo.Create(ctx, definition); err != nil {
+		s.logger.Error("Failed to create attribute definition", zap.Error(err))
+		s.logDefinitionFailure(ctx, actorID, model.ActionCreate, definition.Key, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Attribute definition created", zap.Uint("id", definition.ID), zap.String("key", definition.Key))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionCreate,
+			ResourceType: model.ResourceTypeUserAttribute,
+			ResourceID:   definition.Key,
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  attributeDefinitionChanges(definition),
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return definition, nil
+}
+
+// Update は属性定義を更新します
+// 既に保存されている値は再検証しないため、変更後の定義に合わない値は次回の更新時に修正が必要になります
+func (s *UserAttributeService) Update(ctx context.Context, actorID uint, id uint, req *model.UpdateUserAttributeDefinitionRequest) (*model.UserAttributeDefinition, error) {
+	definition, err := s.findDefinition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := attributeDefinitionChanges(definition)
+
+	if req.Label != nil {
+		definition.Label = *req.Label
+	}
+	if req.Required != nil {
+		definition.Required = *req.Required
+	}
+	if req.EnumValues != nil {
+		definition.EnumValues = model.StringList(*req.EnumValues)
+	}
+	if req.Pattern != nil {
+		definition.Pattern = *req.Pattern
+	}
+	if req.Description != nil {
+		definition.Description = *req.Description
+	}
+
+	if details := validateAttributeDefinition(definition); len(details) > 0 {
+		return nil, util.NewValidationError(details, errors.New("invalid attribute definition"))
+	}
+
+	if err := s.repo.Update(ctx, definition); err != nil {
+		s.logger.Error("Failed to update attribute definition", zap.Uint("id", id), zap.Error(err))
+		s.logDefinitionFailure(ctx, actorID, model.ActionUpdate, definition.Key, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Attribute definition updated", zap.Uint("id", id))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUserAttribute,
+			ResourceID:   definition.Key,
+			Changes: model.AuditLogChanges{
+				Before: before,
+				After:  attributeDefinitionChanges(definition),
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return definition, nil
+}
+
+// Delete は属性定義を削除します
+func (s *UserAttributeService) Delete(ctx context.Context, actorID uint, id uint) error {
+	definition, err := s.findDefinition(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete attribute definition", zap.Uint("id", id), zap.Error(err))
+		s.logDefinitionFailure(ctx, actorID, model.ActionDelete, definition.Key, err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Attribute definition deleted", zap.Uint("id", id))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionDelete,
+			ResourceType: model.ResourceTypeUserAttribute,
+			ResourceID:   definition.Key,
+			Changes: model.AuditLogChanges{
+				Before: attributeDefinitionChanges(definition),
+				After:  map[string]interface{}{},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return nil
+}
+
+// Validate はユーザーの追加属性を定義に従って検証します
+// before から変更・追加された値のみ型や形式を検証し、必須項目は after 全体で確認します
+// 定義が削除された属性の既存の値はそのまま残せますが、新しく設定することはできません
+func (s *UserAttributeService) Validate(ctx context.Context, before, after model.UserAttributes) error {
+	definitions, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch attribute definitions", zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	if details := validateAttributes(definitions, before, after); len(details) > 0 {
+		return util.NewValidationError(details, errors.New("invalid user attributes"))
+	}
+	return nil
+}
+
+// ValidateFilter は一覧の絞り込みに指定された属性キーが定義済みかを検証します
+func (s *UserAttributeService) ValidateFilter(ctx context.Context, filter map[string]string) error {
+	if len(filter) == 0 {
+		return nil
+	}
+
+	definitions, err := s.repo.FindAll(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch attribute definitions", zap.Error(err))
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	defined := make(map[string]bool, len(definitions))
+	for _, definition := range definitions {
+		defined[definition.Key] = true
+	}
+	for key := range filter {
+		if !defined[key] {
+			return util.NewBadRequestError(util.ErrCodeInvalidParameter, fmt.Errorf("unknown attribute %q", key))
+		}
+	}
+	return nil
+}
+
+// findDefinition はIDで属性定義を取得します
+func (s *UserAttributeService) findDefinition(ctx context.Context, id uint) (*model.UserAttributeDefinition, error) {
+	definition, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeAttributeNotFound, err)
+		}
+		s.logger.Error("Failed to fetch attribute definition", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return definition, nil
+}
+
+// logDefinitionFailure は属性定義の操作失敗を監査ログに記録します
+func (s *UserAttributeService) logDefinitionFailure(ctx context.Context, actorID uint, action, key string, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       action,
+		ResourceType: model.ResourceTypeUserAttribute,
+		ResourceID:   key,
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// attributeDefinitionChanges は監査ログに記録する属性定義の内容を返します
+func attributeDefinitionChanges(definition *model.UserAttributeDefinition) map[string]interface{} {
+	return map[string]interface{}{
+		"label":       definition.Label,
+		"type":        definition.Type,
+		"required":    definition.Required,
+		"enum_values": []string(definition.EnumValues),
+		"pattern":     definition.Pattern,
+	}
+}
+
+// validateAttributeDefinition は属性定義の内容を検証します
+// 問題がある項目ごとのエラー内容を返します
+func validateAttributeDefinition(definition *model.UserAttributeDefinition) map[string]string {
+	details := make(map[string]string)
+
+	if !attributeKeyPattern.MatchString(definition.Key) {
+		details["key"] = "must start with a lowercase letter and contain only lowercase letters, digits and underscores"
+	}
+
+	switch definition.Type {
+	case model.AttributeTypeEnum:
+		if len(definition.EnumValues) == 0 {
+			details["enum_values"] = "is required for enum type"
+		}
+		seen := make(map[string]bool, len(definition.EnumValues))
+		for _, value := range definition.EnumValues {
+			if seen[value] {
+				details["enum_values"] = fmt.Sprintf("contains duplicate value %q", value)
+			}
+			seen[value] = true
+		}
+	case model.AttributeTypeString, model.AttributeTypeNumber, model.AttributeTypeBoolean, model.AttributeTypeDate:
+		if len(definition.EnumValues) > 0 {
+			details["enum_values"] = "is allowed only for enum type"
+		}
+	default:
+		details["type"] = "must be one of string number boolean date enum"
+	}
+
+	if definition.Pattern != "" {
+		if definition.Type != model.AttributeTypeString {
+			details["pattern"] = "is allowed only for string type"
+		} else if _, err := compileAttributePattern(definition.Pattern); err != nil {
+			details["pattern"] = "is not a valid regular expression"
+		}
+	}
+
+	return details
+}
+
+// compileAttributePattern は値全体に一致させるよう属性の正規表現をコンパイルします
+func compileAttributePattern(pattern string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + pattern + `)$`)
+}
+
+// mergeAttributes は現在の属性に変更を適用した結果を返します
+// 値が null（nil）のキーは削除します
+func mergeAttributes(current, changes model.UserAttributes) model.UserAttributes {
+	merged := current.Clone()
+	for key, value := range changes {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// validateAttributes はユーザーの追加属性を定義に従って検証します
+// 問題がある属性ごとのエラー内容を "attributes.<キー>" をキーとして返します
+func validateAttributes(definitions []*model.UserAttributeDefinition, before, after model.UserAttributes) map[string]string {
+	details := make(map[string]string)
+
+	byKey := make(map[string]*model.UserAttributeDefinition, len(definitions))
+	for _, definition := range definitions {
+		byKey[definition.Key] = definition
+	}
+
+	keys := make([]string, 0, len(after))
+	for key := range after {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := after[key]
+		if previous, ok := before[key]; ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+
+		definition, ok := byKey[key]
+		if !ok {
+			details["attributes."+key] = "is not a defined attribute"
+			continue
+		}
+		if message := validateAttributeValue(definition, value); message != "" {
+			details["attributes."+key] = message
+		}
+	}
+
+	for _, definition := range definitions {
+		if !definition.Required {
+			continue
+		}
+		if value, ok := after[definition.Key]; !ok || value == nil || value == "" {
+			details["attributes."+definition.Key] = "is required"
+		}
+	}
+
+	return details
+}
+
+// validateAttributeValue は属性の値1件を検証します
+// 問題がない場合は空文字を返します
+func validateAttributeValue(definition *model.UserAttributeDefinition, value interface{}) string {
+	switch definition.Type {
+	case model.AttributeTypeString:
+		text, ok := value.(string)
+		if !ok {
+			return "must be a string"
+		}
+		if utf8.RuneCountInString(text) > maxAttributeStringLength {
+			return fmt.Sprintf("must be at most %d characters", maxAttributeStringLength)
+		}
+		if definition.Pattern != "" {
+			pattern, err := compileAttributePattern(definition.Pattern)
+			if err != nil || !pattern.MatchString(text) {
+				return "does not match the required format"
+			}
+		}
+	case model.AttributeTypeNumber:
+		switch value.(type) {
+		case float64, float32, int, int64, int32, uint, uint64, uint32:
+		default:
+			return "must be a number"
+		}
+	case model.AttributeTypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return "must be a boolean"
+		}
+	case model.AttributeTypeDate:
+		text, ok := value.(string)
+		if !ok {
+			return "must be a date (YYYY-MM-DD)"
+		}
+		if _, err := time.Parse("2006-01-02", text); err != nil {
+			return "must be a date (YYYY-MM-DD)"
+		}
+	case model.AttributeTypeEnum:
+		text, ok := value.(string)
+		if !ok {
+			return "must be one of the allowed values"
+		}
+		for _, allowed := range definition.EnumValues {
+			if text == allowed {
+				return ""
+			}
+		}
+		return "must be one of the allowed values"
+	default:
+		return "has an unsupported type"
+	}
+	return ""
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+)
+
+func newAttributeTestDefinitions() []*model.UserAttributeDefinition {
+	return []*model.UserAttributeDefinition{
+		{Key: "employee_number", Type: model.AttributeTypeString, Required: true, Pattern: `E[0-9]{5}`},
+		{Key: "cost_center", Type: model.AttributeTypeEnum, EnumValues: model.StringList{"CC-100", "CC-200"}},
+		{Key: "grade", Type: model.AttributeTypeNumber},
+		{Key: "remote", Type: model.AttributeTypeBoolean},
+		{Key: "joined_on", Type: model.AttributeTypeDate},
+	}
+}
+
+func TestValidateAttributes(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   model.UserAttributes
+		after    model.UserAttributes
+		expected map[string]string
+	}{
+		{
+			name: "Valid values",
+			after: model.UserAttributes{
+				"employee_number": "E00001",
+				"cost_center":     "CC-100",
+				"grade":           float64(3),
+				"remote":          true,
+				"joined_on":       "2024-04-01",
+			},
+			expected: map[string]string{},
+		},
+		{
+			name:     "Missing required attribute",
+			after:    model.UserAttributes{"grade": float64(3)},
+			expected: map[string]string{"attributes.employee_number": "is required"},
+		},
+		{
+			name: "Invalid values",
+			after: model.UserAttributes{
+				"employee_number": "E1",
+				"cost_center":     "CC-999",
+				"grade":           "3",
+				"remote":          "yes",
+				"joined_on":       "2024/04/01",
+			},
+			expected: map[string]string{
+				"attributes.employee_number": "does not match the required format",
+				"attributes.cost_center":     "must be one of the allowed values",
+				"attributes.grade":           "must be a number",
+				"attributes.remote":          "must be a boolean",
+				"attributes.joined_on":       "must be a date (YYYY-MM-DD)",
+			},
+		},
+		{
+			name:     "Pattern must match the whole value",
+			after:    model.UserAttributes{"employee_number": "xE00001x"},
+			expected: map[string]string{"attributes.employee_number": "does not match the required format"},
+		},
+		{
+			name:     "Unknown attribute",
+			after:    model.UserAttributes{"employee_number": "E00001", "nickname": "taro"},
+			expected: map[string]string{"attributes.nickname": "is not a defined attribute"},
+		},
+		{
+			name:     "Unchanged value of a removed definition is kept",
+			before:   model.UserAttributes{"employee_number": "E00001", "nickname": "taro"},
+			after:    model.UserAttributes{"employee_number": "E00002", "nickname": "taro"},
+			expected: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := validateAttributes(newAttributeTestDefinitions(), tt.before, tt.after)
+			assert.Equal(t, tt.expected, details)
+		})
+	}
+}
+
+func TestValidateAttributeDefinition(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition *model.UserAttributeDefinition
+		invalid    []string
+	}{
+		{
+			name:       "Valid string definition",
+			definition: &model.UserAttributeDefinition{Key: "employee_number", Type: model.AttributeTypeString, Pattern: `E[0-9]+`},
+		},
+		{
+			name:       "Valid enum definition",
+			definition: &model.UserAttributeDefinition{Key: "location", Type: model.AttributeTypeEnum, EnumValues: model.StringList{"tokyo", "osaka"}},
+		},
+		{
+			name:       "Invalid key",
+			definition: &model.UserAttributeDefinition{Key: "Cost-Center", Type: model.AttributeTypeString},
+			invalid:    []string{"key"},
+		},
+		{
+			name:       "Enum without values",
+			definition: &model.UserAttributeDefinition{Key: "location", Type: model.AttributeTypeEnum},
+			invalid:    []string{"enum_values"},
+		},
+		{
+			name:       "Duplicate enum values",
+			definition: &model.UserAttributeDefinition{Key: "location", Type: model.AttributeTypeEnum, EnumValues: model.StringList{"tokyo", "tokyo"}},
+			invalid:    []string{"enum_values"},
+		},
+		{
+			name:       "Pattern on non-string type",
+			definition: &model.UserAttributeDefinition{Key: "grade", Type: model.AttributeTypeNumber, Pattern: `[0-9]+`},
+			invalid:    []string{"pattern"},
+		},
+		{
+			name:       "Invalid pattern",
+			definition: &model.UserAttributeDefinition{Key: "code", Type: model.AttributeTypeString, Pattern: `(`},
+			invalid:    []string{"pattern"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			details := validateAttributeDefinition(tt.definition)
+			keys := make([]string, 0, len(details))
+			for key := range details {
+				keys = append(keys, key)
+			}
+			assert.ElementsMatch(t, tt.invalid, keys)
+		})
+	}
+}
+
+func TestMergeAttributes(t *testing.T) {
+	current := model.UserAttributes{"cost_center": "CC-100", "grade": float64(3)}
+
+	merged := mergeAttributes(current, model.UserAttributes{"grade": nil, "remote": true})
+
+	assert.Equal(t, model.UserAttributes{"cost_center": "CC-100", "remote": true}, merged)
+	// 元の属性は変更されない
+	assert.Equal(t, model.UserAttributes{"cost_center": "CC-100", "grade": float64(3)}, current)
+}
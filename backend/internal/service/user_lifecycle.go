@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/mailer"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+// 自動で無効化した理由（監査ログに記録します）
+const (
+	deactivationReasonDormant = "dormant"
+	deactivationReasonExpired = "account_expired"
+)
+
+// UserLifecycleService はアカウントの休眠・利用期限切れによる自動無効化を提供します
+// スケジューラから定期的に実行され、すべての変更を監査ログに記録します
+type UserLifecycleService struct {
+	userRepo        *repository.UserRepository
+	mailer          mailer.Mailer
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+}
+
+// NewUserLifecycleService は新しいUserLifecycleServiceを作成します
+func NewUserLifecycleService(
+	userRepo *repository.UserRepository,
+	mailer mailer.Mailer,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *UserLifecycleService {
+	return &UserLifecycleService{
+		userRepo:        userRepo,
+		mailer:          mailer,
+		logger:          logger,
+		auditLogService: auditLogService,
+	}
+}
+
+// dormantCutoffs は休眠の予告・無効化の対象となる最終利用日時の境界を返します
+// 最終利用が warnSince より前のユーザーに予告し、deactivateSince より前のユーザーを無効化します
+func dormantCutoffs(now time.Time, dormantAfter, warnBefore time.Duration) (warnSince, deactivateSince time.Time) {
+	deactivateSince = now.Add(-dormantAfter)
+	warnSince = now.Add(-(dormantAfter - warnBefore))
+	return warnSince, deactivateSince
+}
+
+// ProcessDormant は一定期間利用のないユーザーに予告メールを送り、予告から warnBefore 経過後に無効化します
+// warnBefore が0以下の場合は予告せずに無効化します
+// 予告したユーザー数と無効化したユーザー数を返します
+func (s *UserLifecycleService) ProcessDormant(ctx context.Context, dormantAfter, warnBefore time.Duration) (int, int, error) {
+	if dormantAfter <= 0 {
+		return 0, 0, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("dormant period must be positive"))
+	}
+	if warnBefore >= dormantAfter {
+		return 0, 0, util.NewBadRequestError(util.ErrCodeInvalidParameter, errors.New("warning period must be shorter than dormant period"))
+	}
+
+	now := time.Now()
+	warnSince, deactivateSince := dormantCutoffs(now, dormantAfter, warnBefore)
+
+	warned := 0
+	if warnBefore > 0 {
+		users, err := s.userRepo.FindDormantToWarn(ctx, warnSince)
+		if err != nil {
+			s.logger.Error("Failed to fetch dormant users to warn", zap.Time("inactive_since", warnSince), zap.Error(err))
+			return 0, 0, util.NewInternalError(util.ErrCodeDatabaseError, err)
+		}
+		for _, user := range users {
+			if s.warnDormant(ctx, user, now, now.Add(warnBefore)) {
+				warned++
+			}
+		}
+	}
+
+	// 予告する場合は、予告から warnBefore 経過したユーザーのみを無効化する
+	var warnedBefore *time.Time
+	if warnBefore > 0 {
+		t := now.Add(-warnBefore)
+		warnedBefore = &t
+	}
+	users, err := s.userRepo.FindDormant(ctx, deactivateSince, warnedBefore)
+	if err != nil {
+		s.logger.Error("Failed to fetch dormant users", zap.Time("inactive_since", deactivateSince), zap.Error(err))
+		return warned, 0, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	deactivated := 0
+	for _, user := range users {
+		if s.deactivate(ctx, user, now, deactivationReasonDormant) {
+			deactivated++
+		}
+	}
+
+	if warned > 0 || deactivated > 0 {
+		s.logger.Info("Dormant users processed", zap.Int("warned", warned), zap.Int("deactivated", deactivated))
+	}
+	return warned, deactivated, nil
+}
+
+// ExpireAccounts は利用期限を過ぎたユーザーを無効化します
+// 無効化したユーザー数を返します
+func (s *UserLifecycleService) ExpireAccounts(ctx context.Context) (int, error) {
+	now := time.Now()
+	users, err := s.userRepo.FindExpired(ctx, now)
+	if err != nil {
+		s.logger.Error("Failed to fetch expired users", zap.Error(err))
+		return 0, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	expired := 0
+	for _, user := range users {
+		if s.deactivate(ctx, user, now, deactivationReasonExpired) {
+			expired++
+		}
+	}
+
+	if expired > 0 {
+		s.logger.Info("Expired users deactivated", zap.Int("count", expired))
+	}
+	return expired, nil
+}
+
+// warnDormant は休眠による無効化の予告メールを送り、予告日時を記録します
+// メールの送信に失敗しても予告日時は記録し、無効化の予定を遅らせないようにします
+func (s *UserLifecycleService) warnDormant(ctx context.Context, user *model.User, now, deactivateAt time.Time) bool {
+	if err := s.mailer.Send(ctx, buildDormantWarningMessage(user, deactivateAt)); err != nil {
+		s.logger.Warn("Failed to send dormant warning", zap.Uint("id", user.ID), zap.Error(err))
+	}
+
+	if err := s.userRepo.MarkDormantWarned(ctx, user.ID, now); err != nil {
+		// 1件の失敗で残りの処理を止めない
+		s.logger.Error("Failed to mark dormant warning", zap.Uint("id", user.ID), zap.Error(err))
+		if s.auditLogService != nil {
+			auditReq := &model.CreateAuditLogRequest{
+				UserID:       1, // システムユーザー
+				Action:       model.ActionUpdate,
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   user.Username,
+				Status:       model.AuditStatusFailed,
+				ErrorMessage: err.Error(),
+			}
+			s.auditLogService.LogAction(ctx, auditReq)
+		}
+		return false
+	}
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       1, // システムユーザー
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{
+					"dormant_warned_at": nil,
+				},
+				After: map[string]interface{}{
+					"dormant_warned_at":  now,
+					"deactivation_after": deactivateAt,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+	return true
+}
+
+// deactivate はユーザーを無効化して監査ログに記録します
+// 読み込み後にログイン等で状態が変わっていた場合は何もしません
+func (s *UserLifecycleService) deactivate(ctx context.Context, user *model.User, now time.Time, reason string) bool {
+	if err := s.userRepo.Deactivate(ctx, user, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			s.logger.Info("User changed before deactivation", zap.Uint("id", user.ID), zap.String("reason", reason))
+			return false
+		}
+		// 1件の失敗で残りの処理を止めない
+		s.logger.Error("Failed to deactivate user", zap.Uint("id", user.ID), zap.String("reason", reason), zap.Error(err))
+		if s.auditLogService != nil {
+			auditReq := &model.CreateAuditLogRequest{
+				UserID:       1, // システムユーザー
+				Action:       model.ActionUpdate,
+				ResourceType: model.ResourceTypeUser,
+				ResourceID:   user.Username,
+				Status:       model.AuditStatusFailed,
+				ErrorMessage: err.Error(),
+			}
+			s.auditLogService.LogAction(ctx, auditReq)
+		}
+		return false
+	}
+
+	s.logger.Info("User deactivated", zap.Uint("id", user.ID), zap.String("reason", reason))
+
+	// 監査ログに成功を記録
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       1, // システムユーザー
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeUser,
+			ResourceID:   user.Username,
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{
+					"status": model.UserStatusActive,
+				},
+				After: map[string]interface{}{
+					"status": user.Status,
+					"reason": reason,
+				},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+	return true
+}
+
+// buildDormantWarningMessage は休眠による無効化の予告メールを作成します
+func buildDormantWarningMessage(user *model.User, deactivateAt time.Time) *mailer.Message {
+	name := user.FullName
+	if name == "" {
+		name = user.Username
+	}
+
+	body := fmt.Sprintf(`%s 様
+
+Effisio のアカウント（%s）は長期間利用されていないため、
+%s 以降に自動で無効化されます。
+
+引き続き利用する場合は、それまでにログインしてください。
+無効化された後に利用を再開する場合は、管理者に連絡してください。
+`, name, user.Username, deactivateAt.Format("2006-01-02 15:04 MST"))
+
+	return &mailer.Message{
+		To:      []string{user.Email},
+		Subject: "Effisio アカウント無効化のお知らせ",
+		Body:    body,
+	}
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+)
+
+func TestDormantCutoffs(t *testing.T) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	warnSince, deactivateSince := dormantCutoffs(now, 90*24*time.Hour, 7*24*time.Hour)
+
+	// 83日利用がなければ予告し、90日利用がなければ無効化する
+	assert.Equal(t, now.Add(-83*24*time.Hour), warnSince)
+	assert.Equal(t, now.Add(-90*24*time.Hour), deactivateSince)
+}
+
+func TestProcessDormantRejectsInvalidPeriods(t *testing.T) {
+	service := NewUserLifecycleService(nil, nil, zap.NewNop(), nil)
+
+	tests := []struct {
+		name         string
+		dormantAfter time.Duration
+		warnBefore   time.Duration
+	}{
+		{"Zero dormant period", 0, 0},
+		{"Warning longer than dormant period", 24 * time.Hour, 48 * time.Hour},
+		{"Warning equal to dormant period", 24 * time.Hour, 24 * time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := service.ProcessDormant(context.Background(), tt.dormantAfter, tt.warnBefore)
+
+			require.Error(t, err)
+			var appErr *util.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, util.ErrCodeInvalidParameter, appErr.Code)
+		})
+	}
+}
+
+func TestUserLoginEligibility(t *testing.T) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	tests := []struct {
+		name      string
+		user      *model.User
+		canLogin  bool
+		isExpired bool
+	}{
+		{"Active without end date", &model.User{Status: model.UserStatusActive}, true, false},
+		{"Active before end date", &model.User{Status: model.UserStatusActive, AccountExpiresAt: &future}, true, false},
+		{"Active past end date", &model.User{Status: model.UserStatusActive, AccountExpiresAt: &past}, false, true},
+		{"Active at end date", &model.User{Status: model.UserStatusActive, AccountExpiresAt: &now}, false, true},
+		{"Inactive", &model.User{Status: model.UserStatusInactive}, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.canLogin, tt.user.CanLogin(now))
+			assert.Equal(t, tt.isExpired, tt.user.IsExpired(now))
+		})
+	}
+}
+
+func TestUserSetStatus(t *testing.T) {
+	now := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	warnedAt := now.Add(-24 * time.Hour)
+
+	t.Run("Status change resets dormancy", func(t *testing.T) {
+		user := &model.User{Status: model.UserStatusInactive, DormantWarnedAt: &warnedAt}
+
+		user.SetStatus(model.UserStatusActive, now)
+
+		assert.Equal(t, model.UserStatusActive, user.Status)
+		require.NotNil(t, user.StatusChangedAt)
+		assert.Equal(t, now, *user.StatusChangedAt)
+		assert.Nil(t, user.DormantWarnedAt)
+	})
+
+	t.Run("Same status keeps timestamps", func(t *testing.T) {
+		user := &model.User{Status: model.UserStatusActive, DormantWarnedAt: &warnedAt}
+
+		user.SetStatus(model.UserStatusActive, now)
+
+		assert.Nil(t, user.StatusChangedAt)
+		assert.Equal(t, &warnedAt, user.DormantWarnedAt)
+	})
+}
+
+func TestBuildDormantWarningMessage(t *testing.T) {
+	user := &model.User{Username: "taro", Email: "taro@example.com", FullName: "山田 太郎"}
+	deactivateAt := time.Date(2024, 4, 8, 9, 30, 0, 0, time.UTC)
+
+	msg := buildDormantWarningMessage(user, deactivateAt)
+
+	assert.Equal(t, []string{"taro@example.com"}, msg.To)
+	assert.Contains(t, msg.Body, "山田 太郎 様")
+	assert.Contains(t, msg.Body, "taro")
+	assert.Contains(t, msg.Body, "2024-04-08 09:30 UTC")
+}
@@ -3,23 +3,25 @@ package service
 import (
 	"context"
 	"errors"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 	"gorm.io/gorm"
 
 	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/auditsink"
 	"github.com/varubogu/effisio/backend/pkg/util"
-	"go.uber.org/zap"
 )
 
 func TestUserService_List_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
@@ -45,47 +47,47 @@ func TestUserService_List_Success(t *testing.T) {
 		},
 	}
 
-	mockRepo.On("FindAll", ctx, params).Return(users, int64(2), nil)
+	mockRepo.On("FindAll", ctx, params, map[string]string(nil)).Return(users, int64(2), nil)
 
-	resp, err := userService.List(ctx, params)
+	resp, err := userService.List(ctx, params, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Len(t, resp.Data, 2)
-	assert.Equal(t, int64(2), resp.Total)
+	assert.Equal(t, int64(2), resp.Pagination.Total)
 
 	mockRepo.AssertExpectations(t)
 }
 
 func TestUserService_List_EmptyResult(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
 
-	mockRepo.On("FindAll", ctx, params).Return([]*model.User{}, int64(0), nil)
+	mockRepo.On("FindAll", ctx, params, map[string]string(nil)).Return([]*model.User{}, int64(0), nil)
 
-	resp, err := userService.List(ctx, params)
+	resp, err := userService.List(ctx, params, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Len(t, resp.Data, 0)
-	assert.Equal(t, int64(0), resp.Total)
+	assert.Equal(t, int64(0), resp.Pagination.Total)
 
 	mockRepo.AssertExpectations(t)
 }
 
 func TestUserService_List_DatabaseError(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	params := &util.PaginationParams{Page: 1, PerPage: 10, Offset: 0}
 
-	mockRepo.On("FindAll", ctx, params).Return(nil, int64(0), errors.New("database error"))
+	mockRepo.On("FindAll", ctx, params, map[string]string(nil)).Return(nil, int64(0), errors.New("database error"))
 
-	resp, err := userService.List(ctx, params)
+	resp, err := userService.List(ctx, params, nil)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
@@ -95,7 +97,7 @@ func TestUserService_List_DatabaseError(t *testing.T) {
 
 func TestUserService_GetByID_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	user := &model.User{
@@ -122,7 +124,7 @@ func TestUserService_GetByID_Success(t *testing.T) {
 
 func TestUserService_GetByID_NotFound(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -138,7 +140,7 @@ func TestUserService_GetByID_NotFound(t *testing.T) {
 
 func TestUserService_Create_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -166,7 +168,7 @@ func TestUserService_Create_Success(t *testing.T) {
 		u.ID = 1
 	})
 
-	resp, err := userService.Create(ctx, req)
+	resp, err := userService.Create(ctx, 1, req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -178,7 +180,7 @@ func TestUserService_Create_Success(t *testing.T) {
 
 func TestUserService_Create_UsernameDuplicate(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -193,7 +195,7 @@ func TestUserService_Create_UsernameDuplicate(t *testing.T) {
 
 	mockRepo.On("ExistsByUsername", ctx, "existinguser").Return(true, nil)
 
-	resp, err := userService.Create(ctx, req)
+	resp, err := userService.Create(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
@@ -203,7 +205,7 @@ func TestUserService_Create_UsernameDuplicate(t *testing.T) {
 
 func TestUserService_Create_EmailDuplicate(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -219,7 +221,7 @@ func TestUserService_Create_EmailDuplicate(t *testing.T) {
 	mockRepo.On("ExistsByUsername", ctx, "newuser").Return(false, nil)
 	mockRepo.On("ExistsByEmail", ctx, "existing@example.com").Return(true, nil)
 
-	resp, err := userService.Create(ctx, req)
+	resp, err := userService.Create(ctx, 1, req)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
@@ -229,7 +231,7 @@ func TestUserService_Create_EmailDuplicate(t *testing.T) {
 
 func TestUserService_Update_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	newEmail := "updated@example.com"
@@ -244,6 +246,7 @@ func TestUserService_Update_Success(t *testing.T) {
 		Role:         "user",
 		Status:       model.UserStatusActive,
 		FullName:     "Old Name",
+		Version:      1,
 	}
 
 	req := &model.UpdateUserRequest{
@@ -254,18 +257,21 @@ func TestUserService_Update_Success(t *testing.T) {
 
 	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
 	mockRepo.On("FindByEmail", ctx, newEmail).Return(nil, gorm.ErrRecordNotFound)
-	mockRepo.On("Update", ctx, mock.MatchedBy(func(u *model.User) bool {
+	mockRepo.On("UpdateWithVersion", ctx, mock.MatchedBy(func(u *model.User) bool {
 		return u.ID == 1 &&
-			u.Email == newEmail &&
+			u.Email == "old@example.com" &&
+			u.PendingEmail == newEmail &&
 			u.FullName == newFullName &&
 			u.Role == newRole
-	})).Return(nil)
+	}), uint(1)).Return(nil)
 
-	resp, err := userService.Update(ctx, 1, req)
+	resp, err := userService.Update(ctx, 1, 1, req, 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
-	assert.Equal(t, newEmail, resp.Email)
+	// 新しいメールアドレスは確認されるまで保留される
+	assert.Equal(t, "old@example.com", resp.Email)
+	assert.Equal(t, newEmail, resp.PendingEmail)
 	assert.Equal(t, newFullName, resp.FullName)
 	assert.Equal(t, newRole, resp.Role)
 
@@ -274,7 +280,7 @@ func TestUserService_Update_Success(t *testing.T) {
 
 func TestUserService_Update_NotFound(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	newEmail := "updated@example.com"
@@ -285,7 +291,7 @@ func TestUserService_Update_NotFound(t *testing.T) {
 
 	mockRepo.On("FindByID", ctx, uint(999)).Return(nil, gorm.ErrRecordNotFound)
 
-	resp, err := userService.Update(ctx, 999, req)
+	resp, err := userService.Update(ctx, 1, 999, req, 1)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
@@ -295,7 +301,7 @@ func TestUserService_Update_NotFound(t *testing.T) {
 
 func TestUserService_Update_EmailConflict(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	newEmail := "taken@example.com"
@@ -307,6 +313,7 @@ func TestUserService_Update_EmailConflict(t *testing.T) {
 		PasswordHash: "hash",
 		Role:         "user",
 		Status:       model.UserStatusActive,
+		Version:      1,
 	}
 
 	otherUser := &model.User{
@@ -321,7 +328,7 @@ func TestUserService_Update_EmailConflict(t *testing.T) {
 	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
 	mockRepo.On("FindByEmail", ctx, newEmail).Return(otherUser, nil)
 
-	resp, err := userService.Update(ctx, 1, req)
+	resp, err := userService.Update(ctx, 1, 1, req, 1)
 
 	assert.Error(t, err)
 	assert.Nil(t, resp)
@@ -331,7 +338,7 @@ func TestUserService_Update_EmailConflict(t *testing.T) {
 
 func TestUserService_Delete_Success(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	user := &model.User{
@@ -342,7 +349,7 @@ func TestUserService_Delete_Success(t *testing.T) {
 	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
 	mockRepo.On("Delete", ctx, uint(1)).Return(nil)
 
-	err := userService.Delete(ctx, 1)
+	err := userService.Delete(ctx, 1, 1)
 
 	assert.NoError(t, err)
 
@@ -351,13 +358,13 @@ func TestUserService_Delete_Success(t *testing.T) {
 
 func TestUserService_Delete_NotFound(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 
 	mockRepo.On("FindByID", ctx, uint(999)).Return(nil, gorm.ErrRecordNotFound)
 
-	err := userService.Delete(ctx, 999)
+	err := userService.Delete(ctx, 1, 999)
 
 	assert.Error(t, err)
 
@@ -366,7 +373,7 @@ func TestUserService_Delete_NotFound(t *testing.T) {
 
 func TestUserService_Delete_DatabaseError(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 	user := &model.User{
@@ -377,7 +384,7 @@ func TestUserService_Delete_DatabaseError(t *testing.T) {
 	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
 	mockRepo.On("Delete", ctx, uint(1)).Return(errors.New("database error"))
 
-	err := userService.Delete(ctx, 1)
+	err := userService.Delete(ctx, 1, 1)
 
 	assert.Error(t, err)
 
@@ -386,7 +393,7 @@ func TestUserService_Delete_DatabaseError(t *testing.T) {
 
 func TestUserService_Create_PartialUpdate(t *testing.T) {
 	mockRepo := new(MockUserRepository)
-	userService := NewUserService(mockRepo, getLogger())
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
 
 	ctx := context.Background()
 
@@ -399,6 +406,7 @@ func TestUserService_Create_PartialUpdate(t *testing.T) {
 		Status:       model.UserStatusActive,
 		FullName:     "Old Name",
 		Department:   "Old Dept",
+		Version:      1,
 	}
 
 	// Only update status
@@ -408,14 +416,14 @@ func TestUserService_Create_PartialUpdate(t *testing.T) {
 	}
 
 	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
-	mockRepo.On("Update", ctx, mock.MatchedBy(func(u *model.User) bool {
+	mockRepo.On("UpdateWithVersion", ctx, mock.MatchedBy(func(u *model.User) bool {
 		return u.ID == 1 &&
 			u.Status == model.UserStatusInactive &&
 			u.Email == "old@example.com" && // Unchanged
 			u.FullName == "Old Name"        // Unchanged
-	})).Return(nil)
+	}), uint(1)).Return(nil)
 
-	resp, err := userService.Update(ctx, 1, req)
+	resp, err := userService.Update(ctx, 1, 1, req, 1)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
@@ -423,3 +431,436 @@ func TestUserService_Create_PartialUpdate(t *testing.T) {
 
 	mockRepo.AssertExpectations(t)
 }
+
+func TestUserService_Restore_Success(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	user := &model.User{
+		ID:        1,
+		Username:  "testuser",
+		Email:     "test@example.com",
+		Status:    model.UserStatusActive,
+		DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+
+	mockRepo.On("FindDeletedByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("ExistsByUsername", ctx, "testuser").Return(false, nil)
+	mockRepo.On("ExistsByEmail", ctx, "test@example.com").Return(false, nil)
+	mockRepo.On("Restore", ctx, uint(1)).Return(nil)
+
+	resp, err := userService.Restore(ctx, 1, 1)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, resp)
+	assert.Equal(t, "testuser", resp.Username)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Restore_RecordsActor(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	sink := &recordingSink{}
+	sinks := auditsink.NewDispatcher(auditsink.Config{}, zap.NewNop())
+	sinks.Add(sink, auditsink.Filter{})
+	sinks.Start()
+	auditLogService := newTransactionalAuditLogService(&fakeUnitOfWork{})
+	auditLogService.sinks = sinks
+	userService := NewUserService(mockRepo, getLogger(), auditLogService, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	user := &model.User{
+		ID:        1,
+		Username:  "testuser",
+		Email:     "test@example.com",
+		DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+
+	mockRepo.On("FindDeletedByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("ExistsByUsername", ctx, "testuser").Return(false, nil)
+	mockRepo.On("ExistsByEmail", ctx, "test@example.com").Return(false, nil)
+	mockRepo.On("Restore", mock.Anything, uint(1)).Return(nil)
+
+	_, err := userService.Restore(ctx, 42, 1)
+	require.NoError(t, err)
+	require.NoError(t, sinks.Close(ctx))
+
+	// 監査ログには復元を実行した管理者を記録する
+	require.Len(t, sink.events, 1)
+	assert.Equal(t, uint(42), sink.events[0].UserID)
+	assert.Equal(t, model.ActionRestore, sink.events[0].Action)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Restore_UsernameReused(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	user := &model.User{
+		ID:        1,
+		Username:  "testuser",
+		Email:     "test@example.com",
+		DeletedAt: gorm.DeletedAt{Time: time.Now().Add(-time.Hour), Valid: true},
+	}
+
+	mockRepo.On("FindDeletedByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("ExistsByUsername", ctx, "testuser").Return(true, nil)
+
+	resp, err := userService.Restore(ctx, 1, 1)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+	mockRepo.AssertNotCalled(t, "Restore", ctx, uint(1))
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Restore_NotFound(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+
+	mockRepo.On("FindDeletedByID", ctx, uint(999)).Return(nil, gorm.ErrRecordNotFound)
+
+	resp, err := userService.Restore(ctx, 1, 999)
+
+	assert.Error(t, err)
+	assert.Nil(t, resp)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_PurgeDeleted_ContinuesOnError(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	users := []*model.User{
+		{ID: 1, Username: "user1"},
+		{ID: 2, Username: "user2"},
+		{ID: 3, Username: "user3"},
+	}
+
+	mockRepo.On("FindDeletedBefore", ctx, mock.AnythingOfType("time.Time")).Return(users, nil)
+	mockRepo.On("Purge", ctx, uint(1)).Return(nil)
+	mockRepo.On("Purge", ctx, uint(2)).Return(errors.New("database error"))
+	mockRepo.On("Purge", ctx, uint(3)).Return(nil)
+
+	purged, err := userService.PurgeDeleted(ctx, 1, 30*24*time.Hour)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_PurgeDeleted_InvalidRetention(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	purged, err := userService.PurgeDeleted(context.Background(), 1, 0)
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, purged)
+}
+
+func TestUserService_GetMe_IncludesPermissions(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	lastLogin := time.Now().Add(-time.Hour)
+	user := &model.User{
+		ID:        1,
+		Username:  "testuser",
+		Role:      model.RoleManager,
+		Status:    model.UserStatusActive,
+		LastLogin: &lastLogin,
+	}
+
+	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
+
+	resp, err := userService.GetMe(ctx, 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "testuser", resp.Username)
+	assert.Equal(t, &lastLogin, resp.LastLogin)
+	assert.ElementsMatch(t, util.GetPermissionsForRole(model.RoleManager), resp.Permissions)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_UpdateMe_OnlyFullName(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	newFullName := "New Name"
+	user := &model.User{
+		ID:       1,
+		Username: "testuser",
+		Email:    "test@example.com",
+		FullName: "Old Name",
+		Role:     model.RoleUser,
+		Status:   model.UserStatusActive,
+		Version:  1,
+	}
+
+	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("UpdateWithVersion", ctx, mock.MatchedBy(func(u *model.User) bool {
+		return u.FullName == newFullName &&
+			u.Role == model.RoleUser &&
+			u.Email == "test@example.com"
+	}), uint(1)).Return(nil)
+
+	resp, err := userService.UpdateMe(ctx, 1, &model.UpdateMeRequest{FullName: &newFullName})
+
+	require.NoError(t, err)
+	assert.Equal(t, newFullName, resp.FullName)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_UpdateMe_VersionConflict(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	newFullName := "New Name"
+	user := &model.User{
+		ID:       1,
+		Username: "testuser",
+		Email:    "test@example.com",
+		FullName: "Old Name",
+		Version:  1,
+	}
+
+	// 取得してから更新するまでに他のリクエストで更新された場合
+	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("UpdateWithVersion", ctx, mock.Anything, uint(1)).Return(repository.ErrVersionConflict)
+
+	resp, err := userService.UpdateMe(ctx, 1, &model.UpdateMeRequest{FullName: &newFullName})
+
+	assert.Nil(t, resp)
+	var appErr *util.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusPreconditionFailed, appErr.StatusCode)
+	assert.Equal(t, util.ErrCodeVersionConflict, appErr.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Update_StaleVersion(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	newFullName := "Updated Name"
+
+	user := &model.User{
+		ID:       1,
+		Username: "testuser",
+		Email:    "test@example.com",
+		Version:  3,
+	}
+
+	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
+
+	resp, err := userService.Update(ctx, 1, 1, &model.UpdateUserRequest{FullName: &newFullName}, 2)
+
+	assert.Nil(t, resp)
+	var appErr *util.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusPreconditionFailed, appErr.StatusCode)
+	mockRepo.AssertNotCalled(t, "UpdateWithVersion", mock.Anything, mock.Anything, mock.Anything)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Update_AnyVersion(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	newFullName := "Updated Name"
+
+	user := &model.User{
+		ID:       1,
+		Username: "testuser",
+		Email:    "test@example.com",
+		Version:  3,
+	}
+
+	// If-Match: * の場合は取得時点のバージョンを条件に更新する
+	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("UpdateWithVersion", ctx, mock.Anything, uint(3)).Return(nil)
+
+	resp, err := userService.Update(ctx, 1, 1, &model.UpdateUserRequest{FullName: &newFullName}, model.AnyVersion)
+
+	require.NoError(t, err)
+	assert.Equal(t, newFullName, resp.FullName)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Update_ConcurrentModification(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	newFullName := "Updated Name"
+
+	user := &model.User{
+		ID:       1,
+		Username: "testuser",
+		Email:    "test@example.com",
+		Version:  2,
+	}
+
+	// 読み込み後に別のリクエストが更新したため条件付きUPDATEが0件になる
+	mockRepo.On("FindByID", ctx, uint(1)).Return(user, nil)
+	mockRepo.On("UpdateWithVersion", ctx, mock.Anything, uint(2)).Return(repository.ErrVersionConflict)
+
+	resp, err := userService.Update(ctx, 1, 1, &model.UpdateUserRequest{FullName: &newFullName}, 2)
+
+	assert.Nil(t, resp)
+	var appErr *util.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, http.StatusPreconditionFailed, appErr.StatusCode)
+	assert.Equal(t, util.ErrCodeVersionConflict, appErr.Code)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func newPatchTestUser() *model.User {
+	return &model.User{
+		ID:         1,
+		Username:   "testuser",
+		Email:      "test@example.com",
+		FullName:   "Test User",
+		Department: "Engineering",
+		Role:       "user",
+		Status:     "active",
+		Version:    1,
+	}
+}
+
+func TestUserService_Patch_MergePatch(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+
+	mockRepo.On("FindByID", ctx, uint(1)).Return(newPatchTestUser(), nil)
+	mockRepo.On("UpdateWithVersion", ctx, mock.MatchedBy(func(u *model.User) bool {
+		return u.Role == "manager" && u.Department == "" && u.Email == "test@example.com"
+	}), uint(1)).Return(nil)
+
+	resp, err := userService.Patch(ctx, 1, 1, util.ContentTypeMergePatch, []byte(`{"role":"manager","department":null}`), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "manager", resp.Role)
+	assert.Equal(t, "", resp.Department)
+	assert.Equal(t, "Test User", resp.FullName)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Patch_JSONPatch(t *testing.T) {
+	mockRepo := new(MockUserRepository)
+	userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+	ctx := context.Background()
+	patch := `[{"op":"test","path":"/status","value":"active"},{"op":"replace","path":"/status","value":"suspended"}]`
+
+	mockRepo.On("FindByID", ctx, uint(1)).Return(newPatchTestUser(), nil)
+	mockRepo.On("UpdateWithVersion", ctx, mock.MatchedBy(func(u *model.User) bool {
+		return u.Status == "suspended"
+	}), uint(1)).Return(nil)
+
+	resp, err := userService.Patch(ctx, 1, 1, util.ContentTypeJSONPatch, []byte(patch), 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, "suspended", resp.Status)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUserService_Patch_Rejected(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		patch       string
+		statusCode  int
+		code        string
+	}{
+		{
+			name:        "Test operation failed",
+			contentType: util.ContentTypeJSONPatch,
+			patch:       `[{"op":"test","path":"/role","value":"admin"},{"op":"replace","path":"/role","value":"viewer"}]`,
+			statusCode:  http.StatusConflict,
+			code:        util.ErrCodePatchTestFailed,
+		},
+		{
+			name:        "Field not allowed",
+			contentType: util.ContentTypeMergePatch,
+			patch:       `{"username":"renamed"}`,
+			statusCode:  http.StatusBadRequest,
+			code:        util.ErrCodeInvalidPatch,
+		},
+		{
+			name:        "Invalid value after patch",
+			contentType: util.ContentTypeMergePatch,
+			patch:       `{"role":"superuser"}`,
+			statusCode:  http.StatusBadRequest,
+			code:        util.ErrCodeValidationError,
+		},
+		{
+			name:        "Required field removed",
+			contentType: util.ContentTypeJSONPatch,
+			patch:       `[{"op":"remove","path":"/email"}]`,
+			statusCode:  http.StatusBadRequest,
+			code:        util.ErrCodeValidationError,
+		},
+		{
+			name:        "Malformed patch",
+			contentType: util.ContentTypeJSONPatch,
+			patch:       `{"op":"remove"}`,
+			statusCode:  http.StatusBadRequest,
+			code:        util.ErrCodeInvalidPatch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			userService := NewUserService(mockRepo, getLogger(), nil, nil, nil, nil, nil, nil)
+
+			ctx := context.Background()
+			mockRepo.On("FindByID", ctx, uint(1)).Return(newPatchTestUser(), nil)
+
+			resp, err := userService.Patch(ctx, 1, 1, tt.contentType, []byte(tt.patch), 1)
+
+			assert.Nil(t, resp)
+			var appErr *util.AppError
+			require.ErrorAs(t, err, &appErr)
+			assert.Equal(t, tt.statusCode, appErr.StatusCode)
+			assert.Equal(t, tt.code, appErr.Code)
+			mockRepo.AssertNotCalled(t, "UpdateWithVersion", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestDiffPatchDocuments(t *testing.T) {
+	before := map[string]interface{}{"email": "a@example.com", "role": "user", "status": "active"}
+	after := map[string]interface{}{"email": "a@example.com", "role": "manager", "status": "active"}
+
+	beforeChanges, afterChanges := diffPatchDocuments(before, after)
+
+	assert.Equal(t, map[string]interface{}{"role": "user"}, beforeChanges)
+	assert.Equal(t, map[string]interface{}{"role": "manager"}, afterChanges)
+}
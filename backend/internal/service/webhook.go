@@ -0,0 +1,620 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/internal/repository"
+	"github.com/varubogu/effisio/backend/pkg/util"
+	"github.com/varubogu/effisio/backend/pkg/webhook"
+)
+
+// WebhookDeliveryConfig はWebhookの配信に関する設定です
+type WebhookDeliveryConfig struct {
+	BatchSize     int           // 1回の処理で送信する最大件数
+	Timeout       time.Duration // 1回の送信のタイムアウト
+	MaxAttempts   int           // デッドレターにするまでの最大送信回数
+	RetryInterval time.Duration // 再試行の初回の待ち時間
+	MaxRetryDelay time.Duration // 再試行の待ち時間の上限
+}
+
+// WebhookService はWebhookの送信先の管理と配信に関するビジネスロジックを提供します
+// イベントは送信先ごとの配信としてデータベースに保存し、スケジューラのジョブが送信します
+type WebhookService struct {
+	repo            *repository.WebhookRepository
+	sender          *webhook.Sender
+	config          WebhookDeliveryConfig
+	logger          *zap.Logger
+	auditLogService *AuditLogService
+	now             func() time.Time
+}
+
+// NewWebhookService は新しいWebhookServiceを作成します
+func NewWebhookService(
+	repo *repository.WebhookRepository,
+	sender *webhook.Sender,
+	config WebhookDeliveryConfig,
+	logger *zap.Logger,
+	auditLogService *AuditLogService,
+) *WebhookService {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 30 * time.Second
+	}
+	if config.MaxRetryDelay < config.RetryInterval {
+		config.MaxRetryDelay = config.RetryInterval
+	}
+	return &WebhookService{
+		repo:            repo,
+		sender:          sender,
+		config:          config,
+		logger:          logger,
+		auditLogService: auditLogService,
+		now:             time.Now,
+	}
+}
+
+// List は送信先の一覧を取得します
+func (s *WebhookService) List(ctx context.Context, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	subscriptions, total, err := s.repo.FindAll(ctx, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch webhooks", zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	return util.NewPaginatedResponse(subscriptions, total, params), nil
+}
+
+// GetByID はIDで送信先を取得します
+func (s *WebhookService) GetByID(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	return s.findSubscription(ctx, id)
+}
+
+// Create は送信先を作成します
+// シークレットを指定しなかった場合は生成し、レスポンスでのみ返します
+func (s *WebhookService) Create(ctx context.Context, actorID uint, req *model.CreateWebhookRequest) (*model.CreatedWebhookResponse, error) {
+	if err := validateWebhookEvents(req.Events); err != nil {
+		return nil, err
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		generated, err := webhook.GenerateSecret()
+		if err != nil {
+			return nil, util.NewInternalError(util.ErrCodeInternalError, err)
+		}
+		secret = generated
+	}
+
+	subscription := &model.WebhookSubscription{
+		Name:        req.Name,
+		URL:         req.URL,
+		Events:      model.StringList(uniqueStrings(req.Events)),
+		Secret:      secret,
+		Active:      req.Active == nil || *req.Active,
+		Description: req.Description,
+		CreatedBy:   actorID,
+	}
+	if err := s.repo.Create(ctx, subscription); err != nil {
+		s.logger.Error("Failed to create webhook", zap.Error(err))
+		s.logWebhookFailure(ctx, actorID, model.ActionCreate, req.Name, err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Webhook created", zap.Uint("id", subscription.ID), zap.String("url", subscription.URL))
+
+	// 監査ログに成功を記録（シークレットは記録しない）
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionCreate,
+			ResourceType: model.ResourceTypeWebhook,
+			ResourceID:   webhookResourceID(subscription.ID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{},
+				After:  webhookChanges(subscription),
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return &model.CreatedWebhookResponse{WebhookSubscription: subscription, Secret: secret}, nil
+}
+
+// Update は送信先を更新します
+func (s *WebhookService) Update(ctx context.Context, actorID, id uint, req *model.UpdateWebhookRequest) (*model.WebhookSubscription, error) {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	before := webhookChanges(subscription)
+
+	if req.Name != nil {
+		subscription.Name = *req.Name
+	}
+	if req.URL != nil {
+		subscription.URL = *req.URL
+	}
+	if req.Events != nil {
+		if err := validateWebhookEvents(*req.Events); err != nil {
+			return nil, err
+		}
+		subscription.Events = model.StringList(uniqueStrings(*req.Events))
+	}
+	secretRotated := false
+	if req.Secret != nil && *req.Secret != subscription.Secret {
+		subscription.Secret = *req.Secret
+		secretRotated = true
+	}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+	if req.Description != nil {
+		subscription.Description = *req.Description
+	}
+
+	if err := s.repo.Update(ctx, subscription); err != nil {
+		s.logger.Error("Failed to update webhook", zap.Uint("id", id), zap.Error(err))
+		s.logWebhookFailure(ctx, actorID, model.ActionUpdate, webhookResourceID(id), err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Webhook updated", zap.Uint("id", id))
+
+	if s.auditLogService != nil {
+		after := webhookChanges(subscription)
+		if secretRotated {
+			after["secret_rotated"] = true
+		}
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionUpdate,
+			ResourceType: model.ResourceTypeWebhook,
+			ResourceID:   webhookResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: before,
+				After:  after,
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return subscription, nil
+}
+
+// Delete は送信先を削除します（未送信の配信と送信履歴も削除されます）
+func (s *WebhookService) Delete(ctx context.Context, actorID, id uint) error {
+	subscription, err := s.findSubscription(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		s.logger.Error("Failed to delete webhook", zap.Uint("id", id), zap.Error(err))
+		s.logWebhookFailure(ctx, actorID, model.ActionDelete, webhookResourceID(id), err)
+		return util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Webhook deleted", zap.Uint("id", id))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionDelete,
+			ResourceType: model.ResourceTypeWebhook,
+			ResourceID:   webhookResourceID(id),
+			Changes: model.AuditLogChanges{
+				Before: webhookChanges(subscription),
+				After:  map[string]interface{}{},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return nil
+}
+
+// Emit はイベントを購読している送信先ごとに配信を登録します
+// 送信はスケジューラのジョブが行うため、呼び出し元の処理を待たせません
+// 登録に失敗しても呼び出し元の処理は取り消さず、ログに記録するのみとします
+func (s *WebhookService) Emit(ctx context.Context, eventType string, data interface{}) {
+	subscriptions, err := s.repo.FindActive(ctx)
+	if err != nil {
+		s.logger.Error("Failed to fetch webhooks for event", zap.String("event", eventType), zap.Error(err))
+		return
+	}
+
+	event := &model.WebhookEvent{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		CreatedAt: s.now().UTC(),
+		Data:      data,
+	}
+	deliveries, err := buildDeliveries(subscriptions, event, s.now())
+	if err != nil {
+		s.logger.Error("Failed to encode webhook event", zap.String("event", eventType), zap.Error(err))
+		return
+	}
+	if len(deliveries) == 0 {
+		return
+	}
+
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		s.logger.Error("Failed to enqueue webhook deliveries",
+			zap.String("event", eventType),
+			zap.String("event_id", event.ID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	s.logger.Debug("Webhook deliveries enqueued",
+		zap.String("event", eventType),
+		zap.String("event_id", event.ID),
+		zap.Int("count", len(deliveries)),
+	)
+}
+
+// ProcessDeliveries は送信時刻を過ぎた配信を送信し、送信した件数を返します
+// 複数のインスタンスで実行しても同じ配信を同時に送信しないよう、配信をリースしてから送信します
+func (s *WebhookService) ProcessDeliveries(ctx context.Context) (int, error) {
+	// 送信中に停止してもリース切れで再送されるよう、全件のタイムアウトより長くリースする
+	lease := s.config.Timeout*time.Duration(s.config.BatchSize) + time.Minute
+	deliveries, err := s.repo.ClaimDue(ctx, s.now(), lease, s.config.BatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	if len(deliveries) == 0 {
+		return 0, nil
+	}
+
+	// 同じ送信先への配信のためにシークレットとURLをまとめて取得する
+	subscriptions := make(map[uint]*model.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if ctx.Err() != nil {
+			break
+		}
+
+		subscription, ok := subscriptions[delivery.SubscriptionID]
+		if !ok {
+			subscription, err = s.repo.FindByID(ctx, delivery.SubscriptionID)
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				s.logger.Error("Failed to fetch webhook", zap.Uint("id", delivery.SubscriptionID), zap.Error(err))
+				continue
+			}
+			subscriptions[delivery.SubscriptionID] = subscription
+		}
+
+		s.deliver(ctx, subscription, delivery)
+	}
+
+	return len(deliveries), nil
+}
+
+// deliver は配信を1回送信し、結果を記録します
+func (s *WebhookService) deliver(ctx context.Context, subscription *model.WebhookSubscription, delivery *model.WebhookDelivery) {
+	var result *webhook.Result
+	if subscription == nil || !subscription.Active {
+		// 無効化された送信先への配信は送信せずに失敗として扱う
+		result = &webhook.Result{Err: errors.New("webhook is disabled")}
+	} else {
+		sendCtx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+		result = s.sender.Send(sendCtx, &webhook.Request{
+			URL:        subscription.URL,
+			Secret:     subscription.Secret,
+			EventType:  delivery.EventType,
+			DeliveryID: strconv.FormatUint(uint64(delivery.ID), 10),
+			Body:       delivery.Payload,
+		})
+		cancel()
+	}
+
+	now := s.now()
+	attempt := applyDeliveryResult(delivery, result, now, s.config)
+
+	// シャットダウン中でも送信結果は記録する
+	if err := s.repo.SaveAttempt(context.WithoutCancel(ctx), delivery, attempt); err != nil {
+		s.logger.Error("Failed to record webhook delivery attempt", zap.Uint("delivery_id", delivery.ID), zap.Error(err))
+		return
+	}
+
+	switch delivery.Status {
+	case model.WebhookDeliverySucceeded:
+		s.logger.Info("Webhook delivered",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.String("event", delivery.EventType),
+			zap.Int("status_code", result.StatusCode),
+		)
+	case model.WebhookDeliveryDead:
+		s.logger.Warn("Webhook delivery moved to dead letter",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.String("event", delivery.EventType),
+			zap.Int("attempts", delivery.Attempts),
+			zap.String("error", delivery.LastError),
+		)
+	default:
+		s.logger.Info("Webhook delivery failed, will retry",
+			zap.Uint("delivery_id", delivery.ID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Time("next_attempt_at", delivery.NextAttemptAt),
+			zap.String("error", delivery.LastError),
+		)
+	}
+}
+
+// ListDeliveries は送信先の配信一覧を取得します
+func (s *WebhookService) ListDeliveries(ctx context.Context, subscriptionID uint, status string, params *util.PaginationParams) (*util.PaginatedResponse, error) {
+	if _, err := s.findSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	deliveries, total, err := s.repo.FindDeliveries(ctx, subscriptionID, status, params)
+	if err != nil {
+		s.logger.Error("Failed to fetch webhook deliveries", zap.Uint("id", subscriptionID), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	return util.NewPaginatedResponse(deliveries, total, params), nil
+}
+
+// GetDelivery は配信と送信履歴を取得します
+func (s *WebhookService) GetDelivery(ctx context.Context, id uint) (*model.WebhookDeliveryDetailResponse, error) {
+	delivery, err := s.findDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts, err := s.repo.FindAttempts(ctx, id)
+	if err != nil {
+		s.logger.Error("Failed to fetch webhook delivery attempts", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	return &model.WebhookDeliveryDetailResponse{WebhookDelivery: delivery, AttemptLog: attempts}, nil
+}
+
+// ReplayDelivery は配信を送信待ちに戻し、次の処理で再送されるようにします
+// 送信済みの配信も再送でき、受信側は配信IDで重複を判定できます
+func (s *WebhookService) ReplayDelivery(ctx context.Context, actorID, id uint) (*model.WebhookDelivery, error) {
+	delivery, err := s.findDelivery(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := delivery.Status
+
+	if err := s.repo.ResetDelivery(ctx, id, s.now()); err != nil {
+		s.logger.Error("Failed to replay webhook delivery", zap.Uint("id", id), zap.Error(err))
+		s.logWebhookFailure(ctx, actorID, model.ActionResend, webhookResourceID(delivery.SubscriptionID), err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Webhook delivery replayed", zap.Uint("id", id), zap.String("previous_status", previousStatus))
+
+	if s.auditLogService != nil {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionResend,
+			ResourceType: model.ResourceTypeWebhook,
+			ResourceID:   webhookResourceID(delivery.SubscriptionID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"delivery_id": id, "status": previousStatus},
+				After:  map[string]interface{}{"delivery_id": id, "status": model.WebhookDeliveryPending},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return s.findDelivery(ctx, id)
+}
+
+// ReplayDeadDeliveries は送信先のデッドレターをすべて送信待ちに戻します
+func (s *WebhookService) ReplayDeadDeliveries(ctx context.Context, actorID, subscriptionID uint) (*model.ReplayWebhookDeliveriesResponse, error) {
+	if _, err := s.findSubscription(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	replayed, err := s.repo.ResetDeadDeliveries(ctx, subscriptionID, s.now())
+	if err != nil {
+		s.logger.Error("Failed to replay dead webhook deliveries", zap.Uint("id", subscriptionID), zap.Error(err))
+		s.logWebhookFailure(ctx, actorID, model.ActionResend, webhookResourceID(subscriptionID), err)
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+
+	s.logger.Info("Dead webhook deliveries replayed", zap.Uint("id", subscriptionID), zap.Int64("count", replayed))
+
+	if s.auditLogService != nil && replayed > 0 {
+		auditReq := &model.CreateAuditLogRequest{
+			UserID:       actorID,
+			Action:       model.ActionResend,
+			ResourceType: model.ResourceTypeWebhook,
+			ResourceID:   webhookResourceID(subscriptionID),
+			Changes: model.AuditLogChanges{
+				Before: map[string]interface{}{"status": model.WebhookDeliveryDead},
+				After:  map[string]interface{}{"status": model.WebhookDeliveryPending, "replayed": replayed},
+			},
+			Status: model.AuditStatusSuccess,
+		}
+		s.auditLogService.LogAction(ctx, auditReq)
+	}
+
+	return &model.ReplayWebhookDeliveriesResponse{Replayed: replayed}, nil
+}
+
+// findSubscription はIDで送信先を取得し、存在しない場合は404エラーを返します
+func (s *WebhookService) findSubscription(ctx context.Context, id uint) (*model.WebhookSubscription, error) {
+	subscription, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeWebhookNotFound, err)
+		}
+		s.logger.Error("Failed to fetch webhook", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return subscription, nil
+}
+
+// findDelivery はIDで配信を取得し、存在しない場合は404エラーを返します
+func (s *WebhookService) findDelivery(ctx context.Context, id uint) (*model.WebhookDelivery, error) {
+	delivery, err := s.repo.FindDeliveryByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, util.NewNotFoundError(util.ErrCodeWebhookDeliveryNotFound, err)
+		}
+		s.logger.Error("Failed to fetch webhook delivery", zap.Uint("id", id), zap.Error(err))
+		return nil, util.NewInternalError(util.ErrCodeDatabaseError, err)
+	}
+	return delivery, nil
+}
+
+// logWebhookFailure は送信先の操作失敗を監査ログに記録します
+func (s *WebhookService) logWebhookFailure(ctx context.Context, actorID uint, action, resourceID string, err error) {
+	if s.auditLogService == nil {
+		return
+	}
+	auditReq := &model.CreateAuditLogRequest{
+		UserID:       actorID,
+		Action:       action,
+		ResourceType: model.ResourceTypeWebhook,
+		ResourceID:   resourceID,
+		Status:       model.AuditStatusFailed,
+		ErrorMessage: err.Error(),
+	}
+	s.auditLogService.LogAction(ctx, auditReq)
+}
+
+// buildDeliveries はイベントを購読している送信先ごとの配信を作成します
+// 本文は送信先によらず同じで、再試行や再送でも同じ内容を送ります
+func buildDeliveries(subscriptions []*model.WebhookSubscription, event *model.WebhookEvent, now time.Time) ([]*model.WebhookDelivery, error) {
+	var deliveries []*model.WebhookDelivery
+	var payload []byte
+	for _, subscription := range subscriptions {
+		if !subscription.Active || !subscription.Subscribes(event.Type) {
+			continue
+		}
+		if payload == nil {
+			encoded, err := json.Marshal(event)
+			if err != nil {
+				return nil, err
+			}
+			payload = encoded
+		}
+		deliveries = append(deliveries, &model.WebhookDelivery{
+			SubscriptionID: subscription.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+			Status:         model.WebhookDeliveryPending,
+			NextAttemptAt:  now,
+		})
+	}
+	return deliveries, nil
+}
+
+// applyDeliveryResult は送信結果を配信に反映し、送信履歴を返します
+// 失敗した場合は待ち時間を延ばしながら再試行し、最大送信回数に達したらデッドレターにします
+func applyDeliveryResult(delivery *model.WebhookDelivery, result *webhook.Result, now time.Time, config WebhookDeliveryConfig) *model.WebhookDeliveryAttempt {
+	delivery.Attempts++
+	delivery.LastStatusCode = result.StatusCode
+	delivery.LockedUntil = nil
+
+	attempt := &model.WebhookDeliveryAttempt{
+		DeliveryID:   delivery.ID,
+		Attempt:      delivery.Attempts,
+		StatusCode:   result.StatusCode,
+		ResponseBody: result.ResponseBody,
+		DurationMs:   result.Duration.Milliseconds(),
+	}
+
+	if result.Succeeded() {
+		delivery.Status = model.WebhookDeliverySucceeded
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return attempt
+	}
+
+	delivery.LastError = result.Err.Error()
+	attempt.Error = delivery.LastError
+	if delivery.Attempts >= config.MaxAttempts {
+		delivery.Status = model.WebhookDeliveryDead
+		return attempt
+	}
+	delivery.Status = model.WebhookDeliveryPending
+	delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts, config.RetryInterval, config.MaxRetryDelay))
+	return attempt
+}
+
+// webhookBackoff は attempts 回失敗した後の再試行までの待ち時間を返します
+// 待ち時間は失敗するたびに倍になり、max を上限とします
+func webhookBackoff(attempts int, base, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// validateWebhookEvents はイベント種別が有効かチェックします
+func validateWebhookEvents(events []string) error {
+	for _, event := range events {
+		if !model.IsValidWebhookEvent(event) {
+			return util.NewBadRequestError(util.ErrCodeInvalidWebhookEvent, fmt.Errorf("unknown webhook event: %s", event))
+		}
+	}
+	return nil
+}
+
+// uniqueStrings は重複を除いた文字列を元の順序で返します
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if seen[value] {
+			continue
+		}
+		seen[value] = true
+		result = append(result, value)
+	}
+	return result
+}
+
+// webhookResourceID は監査ログ上の送信先のリソースIDを返します
+func webhookResourceID(id uint) string {
+	return fmt.Sprintf("webhook-%d", id)
+}
+
+// webhookChanges は監査ログに記録する送信先の内容を返します（シークレットは含めません）
+func webhookChanges(subscription *model.WebhookSubscription) map[string]interface{} {
+	return map[string]interface{}{
+		"name":   subscription.Name,
+		"url":    subscription.URL,
+		"events": []string(subscription.Events),
+		"active": subscription.Active,
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/varubogu/effisio/backend/internal/model"
+	"github.com/varubogu/effisio/backend/pkg/util"
+	"github.com/varubogu/effisio/backend/pkg/webhook"
+)
+
+func TestWebhookBackoff(t *testing.T) {
+	base := 30 * time.Second
+	max := 10 * time.Minute
+
+	tests := []struct {
+		attempts int
+		expected time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{30, 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, webhookBackoff(tt.attempts, base, max), "attempts=%d", tt.attempts)
+	}
+}
+
+func TestBuildDeliveries(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	subscriptions := []*model.WebhookSubscription{
+		{ID: 1, Active: true, Events: model.StringList{model.WebhookEventUserCreated}},
+		{ID: 2, Active: true, Events: model.StringList{model.WebhookEventAll}},
+		{ID: 3, Active: true, Events: model.StringList{model.WebhookEventUserDeleted}},
+		{ID: 4, Active: false, Events: model.StringList{model.WebhookEventAll}},
+	}
+	event := &model.WebhookEvent{
+		ID:        "evt-1",
+		Type:      model.WebhookEventUserCreated,
+		CreatedAt: now,
+		Data:      map[string]interface{}{"id": 10},
+	}
+
+	deliveries, err := buildDeliveries(subscriptions, event, now)
+
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, uint(1), deliveries[0].SubscriptionID)
+	assert.Equal(t, uint(2), deliveries[1].SubscriptionID)
+	for _, delivery := range deliveries {
+		assert.Equal(t, "evt-1", delivery.EventID)
+		assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, now, delivery.NextAttemptAt)
+		assert.JSONEq(t, `{"id":"evt-1","type":"user.created","created_at":"2024-04-01T09:00:00Z","data":{"id":10}}`, string(delivery.Payload))
+	}
+}
+
+func TestApplyDeliveryResult(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	config := WebhookDeliveryConfig{MaxAttempts: 3, RetryInterval: time.Minute, MaxRetryDelay: time.Hour}
+
+	t.Run("Success", func(t *testing.T) {
+		delivery := &model.WebhookDelivery{ID: 1, Status: model.WebhookDeliveryPending, LastError: "previous"}
+
+		attempt := applyDeliveryResult(delivery, &webhook.Result{StatusCode: 204, Duration: 15 * time.Millisecond}, now, config)
+
+		assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Empty(t, delivery.LastError)
+		require.NotNil(t, delivery.DeliveredAt)
+		assert.Equal(t, now, *delivery.DeliveredAt)
+		assert.Equal(t, 1, attempt.Attempt)
+		assert.Equal(t, 204, attempt.StatusCode)
+		assert.Equal(t, int64(15), attempt.DurationMs)
+	})
+
+	t.Run("Failure schedules retry with backoff", func(t *testing.T) {
+		delivery := &model.WebhookDelivery{ID: 1, Status: model.WebhookDeliveryPending, Attempts: 1}
+
+		attempt := applyDeliveryResult(delivery, &webhook.Result{StatusCode: 500, Err: assert.AnError}, now, config)
+
+		assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, now.Add(2*time.Minute), delivery.NextAttemptAt)
+		assert.Equal(t, 500, delivery.LastStatusCode)
+		assert.Equal(t, assert.AnError.Error(), attempt.Error)
+		assert.Nil(t, delivery.DeliveredAt)
+	})
+
+	t.Run("Failure at max attempts moves to dead letter", func(t *testing.T) {
+		delivery := &model.WebhookDelivery{ID: 1, Status: model.WebhookDeliveryPending, Attempts: 2}
+
+		applyDeliveryResult(delivery, &webhook.Result{Err: assert.AnError}, now, config)
+
+		assert.Equal(t, model.WebhookDeliveryDead, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+	})
+}
+
+func TestWebhookDeliveryToReceiver(t *testing.T) {
+	now := time.Now()
+	secret := "whsec_test_secret_value"
+	config := WebhookDeliveryConfig{MaxAttempts: 3, RetryInterval: time.Minute, MaxRetryDelay: time.Hour}
+
+	var received model.WebhookEvent
+	responses := []int{http.StatusServiceUnavailable, http.StatusOK}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := webhook.Verify(secret, r.Header.Get(webhook.HeaderSignature), body, 5*time.Minute, time.Now()); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, model.WebhookEventUserSuspended, r.Header.Get(webhook.HeaderEvent))
+		assert.Equal(t, "7", r.Header.Get(webhook.HeaderDelivery))
+		assert.NoError(t, json.Unmarshal(body, &received))
+		w.WriteHeader(responses[calls])
+		calls++
+	}))
+	defer server.Close()
+
+	subscription := &model.WebhookSubscription{ID: 1, URL: server.URL, Secret: secret, Active: true, Events: model.StringList{model.WebhookEventAll}}
+	event := &model.WebhookEvent{ID: "evt-1", Type: model.WebhookEventUserSuspended, CreatedAt: now.UTC(), Data: map[string]interface{}{"username": "alice"}}
+	deliveries, err := buildDeliveries([]*model.WebhookSubscription{subscription}, event, now)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	delivery := deliveries[0]
+	delivery.ID = 7
+
+	sender := webhook.NewSender(time.Second)
+	send := func() *webhook.Result {
+		return sender.Send(context.Background(), &webhook.Request{
+			URL:        subscription.URL,
+			Secret:     subscription.Secret,
+			EventType:  delivery.EventType,
+			DeliveryID: "7",
+			Body:       delivery.Payload,
+		})
+	}
+
+	// 1回目は受信側の障害で失敗し、再試行待ちになる
+	applyDeliveryResult(delivery, send(), now, config)
+	assert.Equal(t, model.WebhookDeliveryPending, delivery.Status)
+	assert.Equal(t, http.StatusServiceUnavailable, delivery.LastStatusCode)
+
+	// 2回目は同じ本文で成功する
+	applyDeliveryResult(delivery, send(), now.Add(time.Minute), config)
+	assert.Equal(t, model.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, "evt-1", received.ID)
+	assert.Equal(t, model.WebhookEventUserSuspended, received.Type)
+}
+
+func TestValidateWebhookEvents(t *testing.T) {
+	assert.NoError(t, validateWebhookEvents([]string{model.WebhookEventAll, model.WebhookEventAuthLoginFailed}))
+
+	err := validateWebhookEvents([]string{model.WebhookEventUserCreated, "user.renamed"})
+	require.Error(t, err)
+	var appErr *util.AppError
+	require.ErrorAs(t, err, &appErr)
+	assert.Equal(t, util.ErrCodeInvalidWebhookEvent, appErr.Code)
+}
+
+func TestWebhookSubscriptionSubscribes(t *testing.T) {
+	subscription := &model.WebhookSubscription{Events: model.StringList{model.WebhookEventUserCreated, model.WebhookEventUserDeleted}}
+
+	assert.True(t, subscription.Subscribes(model.WebhookEventUserCreated))
+	assert.True(t, subscription.Subscribes(model.WebhookEventUserDeleted))
+	assert.False(t, subscription.Subscribes(model.WebhookEventAuthLoginFailed))
+}
@@ -0,0 +1,20 @@
+-- ロールバック用（逆の操作）
+-- 注意: 削除済みユーザーとユーザー名・メールアドレスが重複している場合は失敗します
+BEGIN;
+
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE SET NULL;
+
+DROP INDEX IF EXISTS idx_users_username;
+DROP INDEX IF EXISTS idx_users_email;
+
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+CREATE INDEX idx_users_username ON users(username);
+CREATE INDEX idx_users_email ON users(email);
+
+COMMENT ON COLUMN users.username IS 'ユーザー名（一意）';
+COMMENT ON COLUMN users.email IS 'メールアドレス（一意）';
+
+COMMIT;
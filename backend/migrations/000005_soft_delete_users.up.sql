@@ -0,0 +1,21 @@
+-- 削除済みユーザーの復元・物理削除に対応
+BEGIN;
+
+-- ユーザー名・メールアドレスの一意制約を有効なレコードのみに限定する
+-- （削除済みユーザーのユーザー名・メールアドレスを再利用できるようにする）
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_username;
+DROP INDEX IF EXISTS idx_users_email;
+
+CREATE UNIQUE INDEX idx_users_username ON users(username) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE deleted_at IS NULL;
+
+-- 監査ログはユーザーの物理削除後も保持するため外部キーを外す
+-- （user_id は NOT NULL のため ON DELETE SET NULL では物理削除できない）
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_user_id_fkey;
+
+COMMENT ON COLUMN users.username IS 'ユーザー名（有効なレコード内で一意）';
+COMMENT ON COLUMN users.email IS 'メールアドレス（有効なレコード内で一意）';
+
+COMMIT;
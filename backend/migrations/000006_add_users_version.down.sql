@@ -0,0 +1,2 @@
+-- ロールバック用（逆の操作）
+ALTER TABLE users DROP COLUMN IF EXISTS version;
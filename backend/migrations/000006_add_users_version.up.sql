@@ -0,0 +1,4 @@
+-- 楽観的排他制御用のバージョンカラムを追加
+ALTER TABLE users ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN users.version IS 'バージョン（楽観的排他制御用、更新ごとに加算）';
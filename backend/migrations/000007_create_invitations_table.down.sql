@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP TABLE IF EXISTS invitations;
+
+-- 招待中のユーザーは元のステータスに存在しないため無効化してから制約を戻す
+UPDATE users SET status = 'inactive' WHERE status = 'pending';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('active', 'inactive', 'suspended'));
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- 招待中（パスワード未設定）のユーザーステータスを追加
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check
+    CHECK (status IN ('active', 'inactive', 'suspended', 'pending'));
+
+-- 招待テーブルを作成
+CREATE TABLE IF NOT EXISTS invitations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    email VARCHAR(255) NOT NULL,
+    token_id VARCHAR(255) UNIQUE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    invited_by INTEGER NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    sent_count INTEGER NOT NULL DEFAULT 0,
+    last_sent_at TIMESTAMP,
+    accepted_at TIMESTAMP,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT invitations_status_check
+        CHECK (status IN ('pending', 'accepted', 'revoked', 'expired'))
+);
+
+-- インデックスを作成
+CREATE INDEX idx_invitations_user_id ON invitations(user_id);
+CREATE INDEX idx_invitations_status ON invitations(status);
+CREATE INDEX idx_invitations_created_at ON invitations(created_at DESC);
+
+COMMENT ON TABLE invitations IS 'ユーザー招待を管理するテーブル';
+COMMENT ON COLUMN invitations.token_id IS '招待トークンID（再送時に再発行し、古いリンクを無効化）';
+COMMENT ON COLUMN invitations.status IS 'ステータス（pending, accepted, revoked, expired）';
+COMMENT ON COLUMN invitations.invited_by IS '招待したユーザーID';
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS email_token_id;
+ALTER TABLE users DROP COLUMN IF EXISTS pending_email;
+ALTER TABLE users DROP COLUMN IF EXISTS email_verified;
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+-- メールアドレスの確認状態と、確認待ちの新しいメールアドレスを追加
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS pending_email VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_token_id VARCHAR(255);
+
+COMMIT;
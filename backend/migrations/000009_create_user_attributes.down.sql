@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE users DROP COLUMN IF EXISTS attributes;
+DROP TABLE IF EXISTS user_attribute_definitions;
+
+COMMIT;
@@ -0,0 +1,22 @@
+BEGIN;
+
+-- ユーザー追加属性の定義テーブルを作成
+CREATE TABLE IF NOT EXISTS user_attribute_definitions (
+    id SERIAL PRIMARY KEY,
+    key VARCHAR(50) UNIQUE NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT false,
+    enum_values JSONB NOT NULL DEFAULT '[]',
+    pattern VARCHAR(255),
+    description TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT user_attribute_definitions_type_check
+        CHECK (type IN ('string', 'number', 'boolean', 'date', 'enum'))
+);
+
+-- ユーザーに追加属性の値を保存するカラムを追加
+ALTER TABLE users ADD COLUMN IF NOT EXISTS attributes JSONB NOT NULL DEFAULT '{}';
+
+COMMIT;
@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_users_account_expires_at;
+ALTER TABLE users DROP COLUMN IF EXISTS dormant_warned_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS account_expires_at;
+
+COMMIT;
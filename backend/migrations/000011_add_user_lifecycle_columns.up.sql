@@ -0,0 +1,12 @@
+BEGIN;
+
+-- 休眠・利用期限切れによる自動無効化のためのカラムを追加
+ALTER TABLE users ADD COLUMN IF NOT EXISTS account_expires_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS dormant_warned_at TIMESTAMP;
+
+-- 利用期限切れの判定ジョブ用（期限が設定された有効なユーザーのみ）
+CREATE INDEX IF NOT EXISTS idx_users_account_expires_at ON users(account_expires_at)
+    WHERE account_expires_at IS NOT NULL AND status = 'active' AND deleted_at IS NULL;
+
+COMMIT;
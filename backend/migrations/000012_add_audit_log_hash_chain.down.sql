@@ -0,0 +1,7 @@
+BEGIN;
+
+DROP TABLE IF EXISTS audit_log_checkpoints;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+-- 改ざん検知用のハッシュチェーン（導入前の記録は空のまま）
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS hash VARCHAR(64) NOT NULL DEFAULT '';
+
+-- 古い監査ログを削除した時点のチェーンの状態（署名付き）
+CREATE TABLE IF NOT EXISTS audit_log_checkpoints (
+    id SERIAL PRIMARY KEY,
+    last_deleted_id INTEGER NOT NULL,
+    last_hash VARCHAR(64) NOT NULL,
+    deleted_count BIGINT NOT NULL,
+    deleted_before TIMESTAMP NOT NULL,
+    signature VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE audit_log_checkpoints IS '監査ログ削除時のハッシュチェーンのチェックポイント';
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_audit_logs_user_id_action_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_ip_address_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_resource_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_status_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_action_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_error_message_trgm;
+DROP INDEX IF EXISTS idx_audit_logs_changes;
+
+-- pg_trgm は他で使われている可能性があるため削除しない
+
+COMMIT;
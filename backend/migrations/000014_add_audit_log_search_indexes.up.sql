@@ -0,0 +1,19 @@
+BEGIN;
+
+-- エラーメッセージの部分一致検索（ILIKE）用
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- 変更内容の包含検索（changes @> '{"after":{"role":"admin"}}'）用
+CREATE INDEX IF NOT EXISTS idx_audit_logs_changes ON audit_logs USING GIN (changes jsonb_path_ops);
+
+-- エラーメッセージの部分一致検索用
+CREATE INDEX IF NOT EXISTS idx_audit_logs_error_message_trgm ON audit_logs USING GIN (error_message gin_trgm_ops);
+
+-- 条件と期間を組み合わせた検索を新しい順に返すための複合インデックス
+CREATE INDEX IF NOT EXISTS idx_audit_logs_action_created_at ON audit_logs(action, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_status_created_at ON audit_logs(status, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource_created_at ON audit_logs(resource_type, resource_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_ip_address_created_at ON audit_logs(ip_address, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_user_id_action_created_at ON audit_logs(user_id, action, created_at DESC);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS audit_logs_notify_created ON audit_logs;
+DROP FUNCTION IF EXISTS notify_audit_log_created();
+
+COMMIT;
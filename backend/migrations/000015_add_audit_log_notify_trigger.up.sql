@@ -0,0 +1,16 @@
+BEGIN;
+
+-- 監査ログの追加をストリーム（SSE）に知らせるための通知
+-- 通知はコミット時に送られるため、ロールバックされた監査ログは通知されない
+CREATE OR REPLACE FUNCTION notify_audit_log_created() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('audit_log_created', NEW.id::text);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_logs_notify_created
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION notify_audit_log_created();
+
+COMMIT;
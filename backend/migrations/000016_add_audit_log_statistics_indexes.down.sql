@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_audit_logs_failed_created_at;
+DROP INDEX IF EXISTS idx_audit_logs_created_at_stats;
+
+COMMIT;
@@ -0,0 +1,12 @@
+BEGIN;
+
+-- 期間を指定した統計の集計（date_trunc による集計）をテーブルを読まずにインデックスのみで行うための索引
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created_at_stats
+    ON audit_logs(created_at) INCLUDE (action, status, resource_type, user_id);
+
+-- 失敗した操作の多いユーザー・IPアドレスの集計用
+CREATE INDEX IF NOT EXISTS idx_audit_logs_failed_created_at
+    ON audit_logs(created_at) INCLUDE (user_id, ip_address)
+    WHERE status = 'failed';
+
+COMMIT;
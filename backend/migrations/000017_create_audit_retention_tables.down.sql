@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS audit_log_tombstones;
+DROP TABLE IF EXISTS audit_retention_policies;
+
+COMMIT;
@@ -0,0 +1,38 @@
+BEGIN;
+
+-- 監査ログの保持期間ポリシー
+CREATE TABLE IF NOT EXISTS audit_retention_policies (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    action VARCHAR(50) NOT NULL DEFAULT '',
+    resource_type VARCHAR(50) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT '',
+    retention_days INTEGER NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    description TEXT,
+    created_by INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT audit_retention_policies_name_unique UNIQUE (name),
+    CONSTRAINT audit_retention_policies_status_check CHECK (status IN ('', 'success', 'failed')),
+    CONSTRAINT audit_retention_policies_days_check CHECK (retention_days > 0)
+);
+
+COMMENT ON TABLE audit_retention_policies IS '監査ログの保持期間ポリシー';
+COMMENT ON COLUMN audit_retention_policies.action IS '対象のアクション（空の場合はすべて）';
+COMMENT ON COLUMN audit_retention_policies.resource_type IS '対象のリソースタイプ（空の場合はすべて）';
+COMMENT ON COLUMN audit_retention_policies.status IS '対象のステータス（空の場合はすべて）';
+
+-- 保持期間ポリシーで削除した監査ログのハッシュ（残った監査ログのハッシュチェーンをつなぐ）
+CREATE TABLE IF NOT EXISTS audit_log_tombstones (
+    id INTEGER PRIMARY KEY,
+    prev_hash VARCHAR(64) NOT NULL,
+    hash VARCHAR(64) NOT NULL,
+    signature VARCHAR(64) NOT NULL,
+    purged_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON TABLE audit_log_tombstones IS '保持期間ポリシーで削除した監査ログの墓標';
+COMMENT ON COLUMN audit_log_tombstones.id IS '削除した監査ログのID';
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS archived_audit_logs;
+DROP TABLE IF EXISTS audit_log_archives;
+
+COMMIT;
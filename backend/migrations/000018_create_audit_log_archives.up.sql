@@ -0,0 +1,55 @@
+BEGIN;
+
+-- 保存先に書き出した監査ログのセグメントのマニフェスト
+CREATE TABLE IF NOT EXISTS audit_log_archives (
+    id SERIAL PRIMARY KEY,
+    storage VARCHAR(20) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    first_id INTEGER NOT NULL,
+    last_id INTEGER NOT NULL,
+    first_created_at TIMESTAMP NOT NULL,
+    last_created_at TIMESTAMP NOT NULL,
+    row_count INTEGER NOT NULL,
+    size BIGINT NOT NULL,
+    checksum VARCHAR(64) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    purged_count BIGINT NOT NULL DEFAULT 0,
+    archived_by INTEGER NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    purged_at TIMESTAMP,
+    imported_at TIMESTAMP,
+    CONSTRAINT audit_log_archives_key_unique UNIQUE (key),
+    CONSTRAINT audit_log_archives_status_check CHECK (status IN ('verified', 'purged'))
+);
+
+CREATE INDEX idx_audit_log_archives_status ON audit_log_archives(status);
+
+COMMENT ON TABLE audit_log_archives IS '監査ログのアーカイブ（セグメント）のマニフェスト';
+COMMENT ON COLUMN audit_log_archives.storage IS '保存先の種類（local, s3）';
+COMMENT ON COLUMN audit_log_archives.key IS '保存先でのキー';
+COMMENT ON COLUMN audit_log_archives.checksum IS '圧縮後のファイルのSHA-256';
+COMMENT ON COLUMN audit_log_archives.status IS '状態（verified: 検証済み・未削除, purged: 削除済み）';
+
+-- 調査のためにアーカイブから取り込んだ監査ログ
+CREATE TABLE IF NOT EXISTS archived_audit_logs (
+    archive_id INTEGER NOT NULL REFERENCES audit_log_archives(id) ON DELETE CASCADE,
+    id INTEGER NOT NULL,
+    user_id INTEGER NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(50) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    status VARCHAR(20) NOT NULL,
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL,
+    prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL DEFAULT '',
+    PRIMARY KEY (archive_id, id)
+);
+
+COMMENT ON TABLE archived_audit_logs IS 'アーカイブから取り込んだ監査ログ（調査用）';
+COMMENT ON COLUMN archived_audit_logs.id IS '元の監査ログID';
+
+COMMIT;
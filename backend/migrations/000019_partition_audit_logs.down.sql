@@ -0,0 +1,61 @@
+-- パーティション分割前のテーブルに戻す（切り離したパーティションの監査ログは戻りません）
+BEGIN;
+
+ALTER TABLE audit_logs RENAME TO audit_logs_partitioned;
+ALTER SEQUENCE audit_logs_id_seq OWNED BY NONE;
+
+CREATE TABLE audit_logs (
+    id INTEGER NOT NULL DEFAULT nextval('audit_logs_id_seq') PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(50) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'success',
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL DEFAULT ''
+);
+
+ALTER SEQUENCE audit_logs_id_seq OWNED BY audit_logs.id;
+
+INSERT INTO audit_logs (
+    id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent,
+    status, error_message, created_at, prev_hash, hash
+)
+SELECT
+    id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent,
+    status, error_message, created_at, prev_hash, hash
+FROM audit_logs_partitioned;
+
+-- パーティションもまとめて削除される
+DROP TABLE audit_logs_partitioned;
+
+CREATE INDEX idx_audit_logs_user_id ON audit_logs(user_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+CREATE INDEX idx_audit_logs_resource_type ON audit_logs(resource_type);
+CREATE INDEX idx_audit_logs_resource_id ON audit_logs(resource_id);
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at DESC);
+CREATE INDEX idx_audit_logs_status ON audit_logs(status);
+CREATE INDEX idx_audit_logs_user_id_created_at ON audit_logs(user_id, created_at DESC);
+CREATE INDEX idx_audit_logs_action_created_at ON audit_logs(action, created_at DESC);
+CREATE INDEX idx_audit_logs_status_created_at ON audit_logs(status, created_at DESC);
+CREATE INDEX idx_audit_logs_resource_created_at ON audit_logs(resource_type, resource_id, created_at DESC);
+CREATE INDEX idx_audit_logs_ip_address_created_at ON audit_logs(ip_address, created_at DESC);
+CREATE INDEX idx_audit_logs_user_id_action_created_at ON audit_logs(user_id, action, created_at DESC);
+CREATE INDEX idx_audit_logs_changes ON audit_logs USING GIN (changes jsonb_path_ops);
+CREATE INDEX idx_audit_logs_error_message_trgm ON audit_logs USING GIN (error_message gin_trgm_ops);
+CREATE INDEX idx_audit_logs_created_at_stats
+    ON audit_logs(created_at) INCLUDE (action, status, resource_type, user_id);
+CREATE INDEX idx_audit_logs_failed_created_at
+    ON audit_logs(created_at) INCLUDE (user_id, ip_address)
+    WHERE status = 'failed';
+
+CREATE TRIGGER audit_logs_notify_created
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION notify_audit_log_created();
+
+COMMIT;
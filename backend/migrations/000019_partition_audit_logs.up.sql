@@ -0,0 +1,91 @@
+-- 監査ログを作成日時で月ごとに分割（宣言的パーティショニング）
+-- 既存の監査ログは新しいテーブルにコピーするため、件数に応じて時間がかかります
+BEGIN;
+
+-- 既存のテーブルを退避し、IDの採番を新しいテーブルに引き継ぐ
+ALTER TABLE audit_logs RENAME TO audit_logs_unpartitioned;
+ALTER SEQUENCE audit_logs_id_seq OWNED BY NONE;
+DROP TRIGGER IF EXISTS audit_logs_notify_created ON audit_logs_unpartitioned;
+
+CREATE TABLE audit_logs (
+    id INTEGER NOT NULL DEFAULT nextval('audit_logs_id_seq'),
+    user_id INTEGER NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id VARCHAR(50) NOT NULL,
+    changes JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'success',
+    error_message TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    prev_hash VARCHAR(64) NOT NULL DEFAULT '',
+    hash VARCHAR(64) NOT NULL DEFAULT ''
+) PARTITION BY RANGE (created_at);
+
+ALTER SEQUENCE audit_logs_id_seq OWNED BY audit_logs.id;
+
+-- 既存の監査ログの最初の月から3か月先までのパーティション
+-- 以降のパーティションはアプリケーションのパーティション管理ジョブが事前に作成する
+DO $$
+DECLARE
+    month DATE;
+    last_month DATE := (date_trunc('month', CURRENT_TIMESTAMP) + INTERVAL '3 months')::date;
+BEGIN
+    SELECT date_trunc('month', COALESCE(MIN(created_at), CURRENT_TIMESTAMP))::date
+      INTO month
+      FROM audit_logs_unpartitioned;
+
+    WHILE month <= last_month LOOP
+        EXECUTE format(
+            'CREATE TABLE %I PARTITION OF audit_logs FOR VALUES FROM (%L) TO (%L)',
+            'audit_logs_p' || to_char(month, 'YYYYMM'),
+            month,
+            (month + INTERVAL '1 month')::date
+        );
+        month := (month + INTERVAL '1 month')::date;
+    END LOOP;
+END $$;
+
+-- パーティションが作成されていない期間の監査ログを受け止める（通常は空）
+CREATE TABLE audit_logs_default PARTITION OF audit_logs DEFAULT;
+
+INSERT INTO audit_logs (
+    id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent,
+    status, error_message, created_at, prev_hash, hash
+)
+SELECT
+    id, user_id, action, resource_type, resource_id, changes, ip_address, user_agent,
+    status, error_message, created_at, prev_hash, hash
+FROM audit_logs_unpartitioned;
+
+DROP TABLE audit_logs_unpartitioned;
+
+-- 主キーにはパーティションキーを含める必要がある（IDは引き続きシーケンスで一意に採番する）
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_pkey PRIMARY KEY (id, created_at);
+
+-- インデックス（各パーティションに作成される）
+-- 単独のカラムのインデックスは、同じカラムで始まる作成日時との複合インデックスで代替する
+CREATE INDEX idx_audit_logs_resource_id ON audit_logs(resource_id);
+CREATE INDEX idx_audit_logs_user_id_created_at ON audit_logs(user_id, created_at DESC);
+CREATE INDEX idx_audit_logs_action_created_at ON audit_logs(action, created_at DESC);
+CREATE INDEX idx_audit_logs_status_created_at ON audit_logs(status, created_at DESC);
+CREATE INDEX idx_audit_logs_resource_created_at ON audit_logs(resource_type, resource_id, created_at DESC);
+CREATE INDEX idx_audit_logs_ip_address_created_at ON audit_logs(ip_address, created_at DESC);
+CREATE INDEX idx_audit_logs_user_id_action_created_at ON audit_logs(user_id, action, created_at DESC);
+CREATE INDEX idx_audit_logs_changes ON audit_logs USING GIN (changes jsonb_path_ops);
+CREATE INDEX idx_audit_logs_error_message_trgm ON audit_logs USING GIN (error_message gin_trgm_ops);
+CREATE INDEX idx_audit_logs_created_at_stats
+    ON audit_logs(created_at) INCLUDE (action, status, resource_type, user_id);
+CREATE INDEX idx_audit_logs_failed_created_at
+    ON audit_logs(created_at) INCLUDE (user_id, ip_address)
+    WHERE status = 'failed';
+
+CREATE TRIGGER audit_logs_notify_created
+    AFTER INSERT ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION notify_audit_log_created();
+
+COMMENT ON TABLE audit_logs IS '監査ログを記録するテーブル（作成日時で月ごとにパーティション分割）';
+COMMENT ON TABLE audit_logs_default IS 'パーティションが作成されていない期間の監査ログ';
+
+COMMIT;
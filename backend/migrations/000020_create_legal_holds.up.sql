@@ -0,0 +1,38 @@
+BEGIN;
+
+-- 訴訟ホールド（対象のユーザーと監査ログの削除・匿名化を禁止する）
+CREATE TABLE IF NOT EXISTS legal_holds (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER,
+    resource_type VARCHAR(50) NOT NULL DEFAULT '',
+    resource_id VARCHAR(50) NOT NULL DEFAULT '',
+    range_from TIMESTAMP,
+    range_to TIMESTAMP,
+    reason TEXT NOT NULL,
+    owner_id INTEGER NOT NULL,
+    placed_by INTEGER NOT NULL,
+    released_at TIMESTAMP,
+    released_by INTEGER,
+    release_reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT legal_holds_scope_check CHECK (
+        user_id IS NOT NULL OR resource_type <> '' OR range_from IS NOT NULL OR range_to IS NOT NULL
+    ),
+    CONSTRAINT legal_holds_resource_check CHECK (resource_id = '' OR resource_type <> ''),
+    CONSTRAINT legal_holds_range_check CHECK (range_from IS NULL OR range_to IS NULL OR range_from <= range_to)
+);
+
+-- 有効なホールドの取得と、ユーザーがホールドの対象かの判定用
+CREATE INDEX IF NOT EXISTS idx_legal_holds_active ON legal_holds(user_id) WHERE released_at IS NULL;
+
+COMMENT ON TABLE legal_holds IS '訴訟ホールド';
+COMMENT ON COLUMN legal_holds.user_id IS '対象のユーザー（監査ログの実行者・対象、およびユーザー自身。空の場合はすべて）';
+COMMENT ON COLUMN legal_holds.resource_type IS '対象の監査ログのリソースタイプ（空の場合はすべて）';
+COMMENT ON COLUMN legal_holds.resource_id IS '対象の監査ログのリソースID（空の場合はすべて）';
+COMMENT ON COLUMN legal_holds.range_from IS '対象の監査ログの作成日時の開始（空の場合は制限なし）';
+COMMENT ON COLUMN legal_holds.range_to IS '対象の監査ログの作成日時の終了（空の場合は制限なし）';
+COMMENT ON COLUMN legal_holds.owner_id IS 'ホールドの責任者';
+COMMENT ON COLUMN legal_holds.released_at IS '解除した日時（空の場合は有効）';
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+-- セキュリティアラート（ログインの監査ログから検知した不審なログイン）
+CREATE TABLE IF NOT EXISTS security_alerts (
+    id SERIAL PRIMARY KEY,
+    rule VARCHAR(50) NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    user_id INTEGER,
+    username VARCHAR(50) NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent TEXT NOT NULL DEFAULT '',
+    details JSONB NOT NULL DEFAULT '{}',
+    actions JSONB NOT NULL DEFAULT '[]',
+    acknowledged_at TIMESTAMP,
+    acknowledged_by INTEGER,
+    acknowledge_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT security_alerts_severity_check CHECK (severity IN ('low', 'medium', 'high'))
+);
+
+-- 一覧の取得用
+CREATE INDEX IF NOT EXISTS idx_security_alerts_created_at ON security_alerts(created_at DESC);
+-- 未対応の同じアラートの重複の判定と、IPアドレスのログイン拒否の判定用
+CREATE INDEX IF NOT EXISTS idx_security_alerts_open ON security_alerts(rule, ip_address, created_at DESC) WHERE acknowledged_at IS NULL;
+
+COMMENT ON TABLE security_alerts IS 'セキュリティアラート';
+COMMENT ON COLUMN security_alerts.rule IS '検知ルール（password_spraying, new_ip, new_user_agent, impossible_travel）';
+COMMENT ON COLUMN security_alerts.severity IS '重要度（low, medium, high）';
+COMMENT ON COLUMN security_alerts.user_id IS '対象のユーザー（パスワードスプレーの場合は空）';
+COMMENT ON COLUMN security_alerts.details IS 'ルールごとの検知の根拠';
+COMMENT ON COLUMN security_alerts.actions IS '実行した対応（lockout, notify）';
+COMMENT ON COLUMN security_alerts.acknowledged_at IS '管理者が確認した日時（空の場合は未対応）';
+
+COMMIT;
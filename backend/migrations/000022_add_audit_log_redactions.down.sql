@@ -0,0 +1,8 @@
+BEGIN;
+
+ALTER TABLE archived_audit_logs DROP COLUMN IF EXISTS redaction_signature;
+ALTER TABLE archived_audit_logs DROP COLUMN IF EXISTS redacted_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS redaction_signature;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS redacted_hash;
+
+COMMIT;
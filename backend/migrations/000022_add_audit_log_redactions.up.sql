@@ -0,0 +1,15 @@
+BEGIN;
+
+-- 個人情報の匿名化の記録（署名付き）
+-- 匿名化してもハッシュチェーンは再計算せず、元のハッシュと匿名化後の内容のハッシュを署名で結び付けて検証する
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS redacted_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS redaction_signature VARCHAR(64) NOT NULL DEFAULT '';
+
+-- アーカイブから取り込んだ監査ログも同じ方法で検証する
+ALTER TABLE archived_audit_logs ADD COLUMN IF NOT EXISTS redacted_hash VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE archived_audit_logs ADD COLUMN IF NOT EXISTS redaction_signature VARCHAR(64) NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN audit_logs.redacted_hash IS '匿名化後の内容のハッシュ（匿名化していない場合は空）';
+COMMENT ON COLUMN audit_logs.redaction_signature IS '匿名化の記録（ID・元のハッシュ・匿名化後のハッシュ）の署名';
+
+COMMIT;
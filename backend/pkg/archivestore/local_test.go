@@ -0,0 +1,63 @@
+package archivestore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewLocalStore(dir)
+	require.NoError(t, err)
+
+	key := "audit-logs/2026/10/segment-1.ndjson.gz"
+	require.NoError(t, store.Put(ctx, key, []byte("segment")))
+
+	data, err := store.Get(ctx, key)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("segment"), data)
+
+	// 一時ファイルが残らない
+	entries, err := os.ReadDir(filepath.Join(dir, "audit-logs", "2026", "10"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	assert.ErrorIs(t, store.Put(ctx, key, []byte("other")), ErrExists)
+
+	require.NoError(t, store.Delete(ctx, key))
+	_, err = store.Get(ctx, key)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, store.Delete(ctx, key))
+}
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		wantErr bool
+	}{
+		{"audit-logs/2026/10/segment.ndjson.gz", false},
+		{"segment.ndjson.gz", false},
+		{"", true},
+		{"/etc/passwd", true},
+		{"audit-logs/../../etc/passwd", true},
+		{"audit-logs//segment", true},
+		{"audit-logs\\segment", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := ValidateKey(tt.key)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
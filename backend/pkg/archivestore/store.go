@@ -0,0 +1,41 @@
+package archivestore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotFound は指定したキーのオブジェクトが存在しないことを表します
+	ErrNotFound = errors.New("archive object not found")
+	// ErrExists は指定したキーのオブジェクトが既に存在することを表します（アーカイブは上書きしない）
+	ErrExists = errors.New("archive object already exists")
+)
+
+// Store はアーカイブの保存先の抽象化です
+// 保存先（ローカルファイルシステム、S3互換ストレージ等）は実装を差し替えて切り替えます
+type Store interface {
+	Name() string
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidateKey はオブジェクトのキーを検証します
+// キーは「/」区切りの相対パスで、保存先の外を指す「..」や空の要素を含められません
+func ValidateKey(key string) error {
+	if key == "" {
+		return errors.New("archive key is required")
+	}
+	if strings.ContainsAny(key, "\\\x00") {
+		return fmt.Errorf("invalid archive key %q", key)
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return fmt.Errorf("invalid archive key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package auditsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readLines はJSON Lines形式のファイルを読み込みます
+func readLines(t *testing.T, path string) []*Event {
+	t.Helper()
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var events []*Event
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event Event
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		events = append(events, &event)
+	}
+	require.NoError(t, scanner.Err())
+	return events
+}
+
+func TestFileSinkWritesJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	sink, err := NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+
+	event := testEvent()
+	event.Changes = json.RawMessage(`{"before":{"role":"user"},"after":{"role":"admin"}}`)
+	require.NoError(t, sink.Send(context.Background(), event))
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+	require.NoError(t, sink.Close())
+
+	events := readLines(t, path)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint(42), events[0].ID)
+	assert.True(t, event.Time.Equal(events[0].Time))
+	assert.JSONEq(t, string(event.Changes), string(events[0].Changes))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestFileSinkRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	line, err := json.Marshal(testEvent())
+	require.NoError(t, err)
+
+	// 2行ごとにローテーションし、古いファイルは2世代まで保持する
+	sink, err := NewFileSink(path, int64(len(line)+1)*2, 2)
+	require.NoError(t, err)
+
+	for i := 1; i <= 7; i++ {
+		event := testEvent()
+		event.ID = uint(i)
+		require.NoError(t, sink.Send(context.Background(), event))
+	}
+	require.NoError(t, sink.Close())
+
+	ids := func(path string) []uint {
+		var result []uint
+		for _, event := range readLines(t, path) {
+			result = append(result, event.ID)
+		}
+		return result
+	}
+	assert.Equal(t, []uint{7}, ids(path))
+	assert.Equal(t, []uint{5, 6}, ids(path+".1"))
+	assert.Equal(t, []uint{3, 4}, ids(path+".2"))
+	assert.NoFileExists(t, path+".3")
+}
+
+func TestFileSinkAppendsToExistingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"id":1}`+"\n"), 0o600))
+
+	sink, err := NewFileSink(path, 0, 0)
+	require.NoError(t, err)
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+	require.NoError(t, sink.Close())
+
+	events := readLines(t, path)
+	require.Len(t, events, 2)
+	assert.Equal(t, uint(1), events[0].ID)
+	assert.Equal(t, uint(42), events[1].ID)
+}
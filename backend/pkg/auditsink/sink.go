@@ -0,0 +1,296 @@
+package auditsink
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Event は外部に転送する監査イベントです
+type Event struct {
+	ID           uint            `json:"id"`
+	Time         time.Time       `json:"time"`
+	UserID       uint            `json:"user_id"`
+	Action       string          `json:"action"`
+	ResourceType string          `json:"resource_type"`
+	ResourceID   string          `json:"resource_id"`
+	Changes      json.RawMessage `json:"changes,omitempty"`
+	IPAddress    string          `json:"ip_address,omitempty"`
+	UserAgent    string          `json:"user_agent,omitempty"`
+	Status       string          `json:"status"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	Hash         string          `json:"hash,omitempty"`
+}
+
+// Failed はイベントが失敗した操作を表すかを返します
+func (e *Event) Failed() bool {
+	return e.Status == "failed"
+}
+
+// Sink は監査イベントの転送先の抽象化です
+// 転送手段（syslog、ファイル等）は実装を差し替えて切り替えます
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *Event) error
+	Close() error
+}
+
+// Filter は転送先ごとに転送するイベントを選択します
+// 各項目は空の場合すべてに一致し、指定した場合はいずれかの値に一致するイベントのみを転送します
+type Filter struct {
+	Actions       []string
+	ResourceTypes []string
+	Statuses      []string
+}
+
+// Match はイベントがフィルタに一致するかを返します
+func (f Filter) Match(event *Event) bool {
+	return matchAny(f.Actions, event.Action) &&
+		matchAny(f.ResourceTypes, event.ResourceType) &&
+		matchAny(f.Statuses, event.Status)
+}
+
+func matchAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Config は Dispatcher の配送に関する設定です
+type Config struct {
+	BufferSize    int           // 転送先ごとに保持できる未送信イベントの件数
+	MaxRetries    int           // 送信に失敗した場合の再試行回数
+	RetryInterval time.Duration // 最初の再試行までの間隔（再試行ごとに倍にする）
+	MaxRetryDelay time.Duration // 再試行の間隔の上限
+	SendTimeout   time.Duration // 1回の送信のタイムアウト
+}
+
+// Stats は転送先ごとの統計情報です
+type Stats struct {
+	Name        string `json:"name"`
+	QueueLength int    `json:"queue_length"`
+	Sent        int64  `json:"sent"`
+	Dropped     int64  `json:"dropped"` // バッファが満杯で破棄した件数
+	Failed      int64  `json:"failed"`  // 再試行しても送信できなかった件数
+	Retries     int64  `json:"retries"`
+}
+
+// Dispatcher は監査イベントを複数の転送先に配送します
+// 転送先ごとにバッファと送信処理を持つため、1つの転送先の障害が他の転送先や呼び出し元を止めることはありません
+type Dispatcher struct {
+	config     Config
+	logger     *zap.Logger
+	forwarders []*forwarder
+	abort      chan struct{} // 閉じると再試行の待機を打ち切る
+	mu         sync.RWMutex  // closed とバッファのクローズを保護する
+	closed     bool
+}
+
+// NewDispatcher は新しいDispatcherを作成します
+// 0以下の設定値は既定値を使用します（MaxRetries は負の場合のみ既定値）
+func NewDispatcher(config Config, logger *zap.Logger) *Dispatcher {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1000
+	}
+	if config.MaxRetries < 0 {
+		config.MaxRetries = 3
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = time.Second
+	}
+	if config.MaxRetryDelay <= 0 {
+		config.MaxRetryDelay = 30 * time.Second
+	}
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 5 * time.Second
+	}
+
+	return &Dispatcher{
+		config: config,
+		logger: logger,
+		abort:  make(chan struct{}),
+	}
+}
+
+// Add は転送先を追加します（Start の前に呼び出してください）
+func (d *Dispatcher) Add(sink Sink, filter Filter) {
+	d.forwarders = append(d.forwarders, &forwarder{
+		sink:   sink,
+		filter: filter,
+		queue:  make(chan *Event, d.config.BufferSize),
+		done:   make(chan struct{}),
+	})
+}
+
+// Len は転送先の数を返します
+func (d *Dispatcher) Len() int {
+	return len(d.forwarders)
+}
+
+// Start は転送先ごとの送信処理を開始します
+func (d *Dispatcher) Start() {
+	for _, f := range d.forwarders {
+		go d.run(f)
+	}
+}
+
+// Publish はイベントをフィルタに一致する転送先のバッファに追加します
+// 呼び出し元を待たせないよう、バッファが満杯の転送先には渡さずに破棄します
+func (d *Dispatcher) Publish(event *Event) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.closed {
+		return
+	}
+
+	for _, f := range d.forwarders {
+		if !f.filter.Match(event) {
+			continue
+		}
+		select {
+		case f.queue <- event:
+		default:
+			f.dropped.Add(1)
+			d.logger.Warn("Audit event dropped because the sink buffer is full",
+				zap.String("sink", f.sink.Name()),
+				zap.Uint("id", event.ID),
+				zap.String("action", event.Action),
+			)
+		}
+	}
+}
+
+// Close は新しいイベントの受け付けを止め、バッファに残ったイベントを送信してから転送先を閉じます
+// ctx の期限までに送信が終わらない場合は再試行を打ち切り、ctx のエラーを返します
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, f := range d.forwarders {
+			close(f.queue)
+		}
+	}
+	d.mu.Unlock()
+
+	var err error
+	for _, f := range d.forwarders {
+		select {
+		case <-f.done:
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		if err != nil {
+			break
+		}
+	}
+	if err != nil {
+		close(d.abort)
+		d.logger.Error("Audit sinks stopped before delivering all events", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Stats は転送先ごとの統計情報を返します
+func (d *Dispatcher) Stats() []*Stats {
+	stats := make([]*Stats, 0, len(d.forwarders))
+	for _, f := range d.forwarders {
+		stats = append(stats, &Stats{
+			Name:        f.sink.Name(),
+			QueueLength: len(f.queue),
+			Sent:        f.sent.Load(),
+			Dropped:     f.dropped.Load(),
+			Failed:      f.failed.Load(),
+			Retries:     f.retries.Load(),
+		})
+	}
+	return stats
+}
+
+// forwarder は1つの転送先へのバッファと送信処理です
+type forwarder struct {
+	sink   Sink
+	filter Filter
+	queue  chan *Event
+	done   chan struct{}
+
+	sent    atomic.Int64
+	dropped atomic.Int64
+	failed  atomic.Int64
+	retries atomic.Int64
+}
+
+// run はバッファからイベントを取り出して順に送信し、バッファが閉じられたら転送先を閉じます
+func (d *Dispatcher) run(f *forwarder) {
+	defer close(f.done)
+	defer func() {
+		if err := f.sink.Close(); err != nil {
+			d.logger.Warn("Failed to close audit sink", zap.String("sink", f.sink.Name()), zap.Error(err))
+		}
+	}()
+
+	for event := range f.queue {
+		d.deliver(f, event)
+	}
+}
+
+// deliver はイベントを送信し、失敗した場合は間隔を倍にしながら再試行します
+func (d *Dispatcher) deliver(f *forwarder, event *Event) {
+	delay := d.config.RetryInterval
+	for attempt := 0; ; attempt++ {
+		err := d.send(f, event)
+		if err == nil {
+			f.sent.Add(1)
+			return
+		}
+
+		if attempt >= d.config.MaxRetries {
+			f.failed.Add(1)
+			d.logger.Error("Failed to forward audit event",
+				zap.String("sink", f.sink.Name()),
+				zap.Uint("id", event.ID),
+				zap.String("action", event.Action),
+				zap.Int("attempts", attempt+1),
+				zap.Error(err),
+			)
+			return
+		}
+
+		f.retries.Add(1)
+		d.logger.Warn("Failed to forward audit event, retrying",
+			zap.String("sink", f.sink.Name()),
+			zap.Duration("delay", delay),
+			zap.Error(err),
+		)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-d.abort:
+			timer.Stop()
+			f.failed.Add(1)
+			return
+		}
+		delay *= 2
+		if delay > d.config.MaxRetryDelay {
+			delay = d.config.MaxRetryDelay
+		}
+	}
+}
+
+// send はタイムアウト付きでイベントを1回送信します
+func (d *Dispatcher) send(f *forwarder, event *Event) error {
+	ctx, cancel := context.WithTimeout(context.Background(), d.config.SendTimeout)
+	defer cancel()
+	return f.sink.Send(ctx, event)
+}
@@ -0,0 +1,180 @@
+package auditsink
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeSink はテスト用の転送先です
+type fakeSink struct {
+	mu       sync.Mutex
+	events   []*Event
+	failures int           // 指定した回数だけ送信を失敗させる
+	block    chan struct{} // 指定した場合、閉じるまで送信を止める
+	closed   bool
+}
+
+func (s *fakeSink) Name() string { return "fake" }
+
+func (s *fakeSink) Send(ctx context.Context, event *Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("connection refused")
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *fakeSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *fakeSink) received() []uint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]uint, 0, len(s.events))
+	for _, event := range s.events {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestFilterMatch(t *testing.T) {
+	event := &Event{Action: "login", ResourceType: "auth", Status: "failed"}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		match  bool
+	}{
+		{"Empty filter", Filter{}, true},
+		{"Matching action", Filter{Actions: []string{"logout", "login"}}, true},
+		{"Other action", Filter{Actions: []string{"update"}}, false},
+		{"Failures only", Filter{Statuses: []string{"failed"}}, true},
+		{"All conditions", Filter{Actions: []string{"login"}, ResourceTypes: []string{"auth"}, Statuses: []string{"failed"}}, true},
+		{"One condition mismatched", Filter{Actions: []string{"login"}, ResourceTypes: []string{"user"}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.match, tt.filter.Match(event))
+		})
+	}
+}
+
+func TestDispatcherRoutesByFilter(t *testing.T) {
+	all := &fakeSink{}
+	failures := &fakeSink{}
+	dispatcher := NewDispatcher(Config{}, zap.NewNop())
+	dispatcher.Add(all, Filter{})
+	dispatcher.Add(failures, Filter{Statuses: []string{"failed"}})
+	dispatcher.Start()
+
+	dispatcher.Publish(&Event{ID: 1, Status: "success"})
+	dispatcher.Publish(&Event{ID: 2, Status: "failed"})
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	assert.Equal(t, []uint{1, 2}, all.received())
+	assert.Equal(t, []uint{2}, failures.received())
+	assert.True(t, all.closed)
+	assert.True(t, failures.closed)
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	sink := &fakeSink{failures: 2}
+	dispatcher := NewDispatcher(Config{MaxRetries: 3, RetryInterval: time.Millisecond}, zap.NewNop())
+	dispatcher.Add(sink, Filter{})
+	dispatcher.Start()
+
+	dispatcher.Publish(&Event{ID: 1})
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	assert.Equal(t, []uint{1}, sink.received())
+	stats := dispatcher.Stats()[0]
+	assert.Equal(t, int64(1), stats.Sent)
+	assert.Equal(t, int64(2), stats.Retries)
+	assert.Equal(t, int64(0), stats.Failed)
+}
+
+func TestDispatcherGivesUpAfterMaxRetries(t *testing.T) {
+	sink := &fakeSink{failures: 3}
+	dispatcher := NewDispatcher(Config{MaxRetries: 2, RetryInterval: time.Millisecond}, zap.NewNop())
+	dispatcher.Add(sink, Filter{})
+	dispatcher.Start()
+
+	dispatcher.Publish(&Event{ID: 1})
+	dispatcher.Publish(&Event{ID: 2})
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	// 1件目は再試行しても送信できずに破棄し、2件目は送信する
+	assert.Equal(t, []uint{2}, sink.received())
+	stats := dispatcher.Stats()[0]
+	assert.Equal(t, int64(1), stats.Failed)
+	assert.Equal(t, int64(1), stats.Sent)
+}
+
+func TestDispatcherDropsWhenBufferIsFull(t *testing.T) {
+	slow := &fakeSink{block: make(chan struct{})}
+	fast := &fakeSink{}
+	dispatcher := NewDispatcher(Config{BufferSize: 1}, zap.NewNop())
+	dispatcher.Add(slow, Filter{})
+	dispatcher.Add(fast, Filter{})
+	// 開始しないことでバッファを満杯のままにする
+
+	dispatcher.Publish(&Event{ID: 1})
+	dispatcher.Publish(&Event{ID: 2})
+
+	stats := dispatcher.Stats()
+	assert.Equal(t, int64(1), stats[0].Dropped)
+	assert.Equal(t, 1, stats[0].QueueLength)
+	assert.Equal(t, int64(1), stats[1].Dropped)
+
+	close(slow.block)
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(context.Background()))
+	assert.Equal(t, []uint{1}, slow.received())
+	assert.Equal(t, []uint{1}, fast.received())
+}
+
+func TestDispatcherCloseAbortsRetries(t *testing.T) {
+	sink := &fakeSink{failures: 100}
+	dispatcher := NewDispatcher(Config{MaxRetries: 100, RetryInterval: time.Hour}, zap.NewNop())
+	dispatcher.Add(sink, Filter{})
+	dispatcher.Start()
+
+	dispatcher.Publish(&Event{ID: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, dispatcher.Close(ctx), context.DeadlineExceeded)
+
+	assert.Eventually(t, func() bool { return dispatcher.Stats()[0].Failed == 1 }, time.Second, 5*time.Millisecond)
+}
+
+func TestDispatcherIgnoresEventsAfterClose(t *testing.T) {
+	sink := &fakeSink{}
+	dispatcher := NewDispatcher(Config{}, zap.NewNop())
+	dispatcher.Add(sink, Filter{})
+	dispatcher.Start()
+	require.NoError(t, dispatcher.Close(context.Background()))
+
+	dispatcher.Publish(&Event{ID: 1})
+
+	assert.Empty(t, sink.received())
+}
@@ -0,0 +1,321 @@
+package auditsink
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// syslogのメッセージ形式
+const (
+	FormatRFC5424 = "rfc5424" // 監査ログの項目を構造化データ（SD-ELEMENT）として送る
+	FormatCEF     = "cef"     // メッセージ本文をCEF（Common Event Format）で送る
+)
+
+// syslogの転送方式
+const (
+	NetworkUDP = "udp"
+	NetworkTCP = "tcp"
+	NetworkTLS = "tls"
+)
+
+const (
+	// facilityLogAudit はRFC 5424のファシリティ「log audit」です
+	facilityLogAudit = 13
+	// severityWarning と severityNotice はRFC 5424の重大度です
+	severityWarning = 4
+	severityNotice  = 5
+
+	// sdID は構造化データのID（32473はドキュメント用に予約されたPrivate Enterprise Number）です
+	sdID = "audit@32473"
+
+	// cefVendor・cefProduct・cefVersion はCEFヘッダーに設定する製品情報です
+	cefVendor  = "Effisio"
+	cefProduct = "Effisio"
+	cefVersion = "1.0"
+
+	// nilValue はRFC 5424で値がないことを表します
+	nilValue = "-"
+	// utf8BOM はメッセージ本文がUTF-8であることを表します（RFC 5424 6.4）
+	utf8BOM = "\xEF\xBB\xBF"
+)
+
+// SyslogConfig はSyslogSinkの設定です
+type SyslogConfig struct {
+	Network   string      // udp, tcp, tls
+	Address   string      // host:port
+	Format    string      // rfc5424 または cef
+	AppName   string      // APP-NAME（未指定の場合は effisio）
+	Hostname  string      // HOSTNAME（未指定の場合は os.Hostname）
+	TLSConfig *tls.Config // tls の場合の設定（未指定の場合はシステムのルート証明書で検証）
+}
+
+// SyslogSink は監査イベントをsyslog（RFC 5424）で送信するSinkです
+// TCP・TLSではRFC 6587・RFC 5425のオクテットカウント方式で区切り、接続が切れた場合は次の送信時に再接続します
+type SyslogSink struct {
+	config SyslogConfig
+	procID string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogSink は新しいSyslogSinkを作成します
+func NewSyslogSink(config SyslogConfig) (*SyslogSink, error) {
+	switch config.Network {
+	case NetworkUDP, NetworkTCP, NetworkTLS:
+	default:
+		return nil, fmt.Errorf("unsupported syslog network: %q", config.Network)
+	}
+	switch config.Format {
+	case FormatRFC5424, FormatCEF:
+	case "":
+		config.Format = FormatRFC5424
+	default:
+		return nil, fmt.Errorf("unsupported syslog format: %q", config.Format)
+	}
+	if config.Address == "" {
+		return nil, errors.New("syslog address is required")
+	}
+	if config.AppName == "" {
+		config.AppName = "effisio"
+	}
+	if config.Hostname == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			hostname = nilValue
+		}
+		config.Hostname = hostname
+	}
+
+	return &SyslogSink{
+		config: config,
+		procID: strconv.Itoa(os.Getpid()),
+	}, nil
+}
+
+// Name は転送先の名前を返します
+func (s *SyslogSink) Name() string {
+	return "syslog-" + s.config.Format
+}
+
+// Send はイベントをsyslogメッセージとして送信します
+// 送信に失敗した場合は接続を閉じ、次の送信で再接続します
+func (s *SyslogSink) Send(ctx context.Context, event *Event) error {
+	msg := s.format(event)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		conn, err := s.dial(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog server: %w", err)
+		}
+		s.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		s.conn.SetWriteDeadline(deadline)
+	}
+
+	frame := msg
+	if s.config.Network != NetworkUDP {
+		frame = strconv.Itoa(len(msg)) + " " + msg
+	}
+	if _, err := s.conn.Write([]byte(frame)); err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return fmt.Errorf("failed to write syslog message: %w", err)
+	}
+	return nil
+}
+
+// Close は接続を閉じます
+func (s *SyslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// dial はsyslogサーバーに接続します
+func (s *SyslogSink) dial(ctx context.Context) (net.Conn, error) {
+	if s.config.Network == NetworkTLS {
+		dialer := &tls.Dialer{Config: s.config.TLSConfig}
+		return dialer.DialContext(ctx, "tcp", s.config.Address)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, s.config.Network, s.config.Address)
+}
+
+// format はイベントを設定された形式のsyslogメッセージにします
+func (s *SyslogSink) format(event *Event) string {
+	if s.config.Format == FormatCEF {
+		return formatSyslog(event, s.config.Hostname, s.config.AppName, s.procID, nilValue, FormatCEFMessage(event))
+	}
+	return formatSyslog(event, s.config.Hostname, s.config.AppName, s.procID, structuredData(event), utf8BOM+summary(event))
+}
+
+// formatSyslog はRFC 5424形式のメッセージを組み立てます
+// <PRI>VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP PROCID SP MSGID SP STRUCTURED-DATA SP MSG
+func formatSyslog(event *Event, hostname, appName, procID, sd, msg string) string {
+	severity := severityNotice
+	if event.Failed() {
+		severity = severityWarning
+	}
+
+	timestamp := nilValue
+	if !event.Time.IsZero() {
+		timestamp = event.Time.UTC().Format("2006-01-02T15:04:05.000000Z07:00")
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>1 %s %s %s %s %s %s",
+		facilityLogAudit*8+severity,
+		timestamp,
+		headerField(hostname, 255),
+		headerField(appName, 48),
+		headerField(procID, 128),
+		headerField(event.Action, 32),
+		sd,
+	)
+	if msg != "" {
+		b.WriteString(" ")
+		b.WriteString(msg)
+	}
+	return b.String()
+}
+
+// headerField はヘッダーの項目を印字可能なASCII文字と最大長に制限します（空の場合は NILVALUE）
+func headerField(value string, maxLen int) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r > 32 && r < 127 {
+			b.WriteRune(r)
+		}
+		if b.Len() == maxLen {
+			break
+		}
+	}
+	if b.Len() == 0 {
+		return nilValue
+	}
+	return b.String()
+}
+
+// structuredData は監査ログの項目を構造化データにします（値が空の項目は省略します）
+func structuredData(event *Event) string {
+	params := []struct {
+		name  string
+		value string
+	}{
+		{"id", strconv.FormatUint(uint64(event.ID), 10)},
+		{"user_id", strconv.FormatUint(uint64(event.UserID), 10)},
+		{"action", event.Action},
+		{"resource_type", event.ResourceType},
+		{"resource_id", event.ResourceID},
+		{"status", event.Status},
+		{"ip", event.IPAddress},
+		{"user_agent", event.UserAgent},
+		{"error", event.ErrorMessage},
+		{"hash", event.Hash},
+	}
+
+	var b strings.Builder
+	b.WriteString("[" + sdID)
+	for _, p := range params {
+		if p.value == "" {
+			continue
+		}
+		b.WriteString(" " + p.name + `="` + escapeParamValue(p.value) + `"`)
+	}
+	b.WriteString("]")
+	return b.String()
+}
+
+// escapeParamValue は構造化データの値をエスケープします（RFC 5424 6.3.3）
+func escapeParamValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`).Replace(value)
+}
+
+// summary はメッセージ本文に設定する概要です
+func summary(event *Event) string {
+	msg := fmt.Sprintf("user %d %s %s/%s %s", event.UserID, event.Action, event.ResourceType, event.ResourceID, event.Status)
+	if event.ErrorMessage != "" {
+		msg += ": " + event.ErrorMessage
+	}
+	return msg
+}
+
+// FormatCEFMessage はイベントをCEF形式にします
+// CEF:Version|Device Vendor|Device Product|Device Version|Signature ID|Name|Severity|Extension
+func FormatCEFMessage(event *Event) string {
+	severity := "3"
+	outcome := "success"
+	if event.Failed() {
+		severity = "7"
+		outcome = "failure"
+	}
+
+	header := []string{
+		"CEF:0",
+		escapeCEFHeader(cefVendor),
+		escapeCEFHeader(cefProduct),
+		escapeCEFHeader(cefVersion),
+		escapeCEFHeader(event.ResourceType + ":" + event.Action),
+		escapeCEFHeader(event.ResourceType + " " + event.Action),
+		severity,
+	}
+
+	var parts []string
+	add := func(key, value string) {
+		if value != "" {
+			parts = append(parts, key+"="+escapeCEFExtension(value))
+		}
+	}
+	// カスタム項目はラベルと値の組で送る（値がない場合はラベルも送らない）
+	addCustom := func(key, label, value string) {
+		if value != "" {
+			add(key+"Label", label)
+			add(key, value)
+		}
+	}
+
+	if !event.Time.IsZero() {
+		add("rt", strconv.FormatInt(event.Time.UnixMilli(), 10))
+	}
+	add("externalId", strconv.FormatUint(uint64(event.ID), 10))
+	add("suid", strconv.FormatUint(uint64(event.UserID), 10))
+	add("act", event.Action)
+	addCustom("cs1", "resourceType", event.ResourceType)
+	addCustom("cs2", "resourceId", event.ResourceID)
+	add("outcome", outcome)
+	add("src", event.IPAddress)
+	add("requestClientApplication", event.UserAgent)
+	add("msg", event.ErrorMessage)
+	addCustom("cs3", "hash", event.Hash)
+
+	return strings.Join(header, "|") + "|" + strings.Join(parts, " ")
+}
+
+// escapeCEFHeader はCEFヘッダーの値をエスケープします
+func escapeCEFHeader(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ").Replace(value)
+}
+
+// escapeCEFExtension はCEF拡張の値をエスケープします
+func escapeCEFExtension(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r\n", `\n`, "\n", `\n`, "\r", `\r`).Replace(value)
+}
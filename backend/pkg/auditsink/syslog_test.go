@@ -0,0 +1,275 @@
+package auditsink
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() *Event {
+	return &Event{
+		ID:           42,
+		Time:         time.Date(2024, 4, 1, 9, 30, 0, 123456000, time.UTC),
+		UserID:       7,
+		Action:       "update",
+		ResourceType: "user",
+		ResourceID:   "taro",
+		IPAddress:    "192.0.2.10",
+		UserAgent:    "curl/8.0",
+		Status:       "success",
+		Hash:         "abc123",
+	}
+}
+
+// acceptMessages はTCPの接続を受け付け、受信したメッセージをチャネルに送ります
+func acceptMessages(t *testing.T, listener net.Listener) <-chan string {
+	t.Helper()
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				for {
+					length, err := r.ReadString(' ')
+					if err != nil {
+						return
+					}
+					n, err := strconv.Atoi(strings.TrimSuffix(length, " "))
+					if err != nil {
+						return
+					}
+					buf := make([]byte, n)
+					if _, err := io.ReadFull(r, buf); err != nil {
+						return
+					}
+					messages <- string(buf)
+				}
+			}(conn)
+		}
+	}()
+	return messages
+}
+
+func receive(t *testing.T, messages <-chan string) string {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("syslog message was not received")
+		return ""
+	}
+}
+
+func TestSyslogSinkUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: NetworkUDP, Address: conn.LocalAddr().String(), Hostname: "app01"})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:n])
+
+	// facility 13 (log audit) * 8 + severity 5 (notice) = 109
+	assert.True(t, strings.HasPrefix(msg, "<109>1 2024-04-01T09:30:00.123456Z app01 effisio "), msg)
+	assert.Contains(t, msg, ` update [audit@32473 id="42" user_id="7" action="update" resource_type="user" resource_id="taro" status="success" ip="192.0.2.10" user_agent="curl/8.0" hash="abc123"] `)
+	assert.True(t, strings.HasSuffix(msg, utf8BOM+"user 7 update user/taro success"), msg)
+}
+
+func TestSyslogSinkTCPWithCEF(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	messages := acceptMessages(t, listener)
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: NetworkTCP, Address: listener.Addr().String(), Format: FormatCEF, Hostname: "app01"})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	event := testEvent()
+	event.Status = "failed"
+	event.ErrorMessage = "permission denied"
+	require.NoError(t, sink.Send(context.Background(), event))
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+
+	first := receive(t, messages)
+	// facility 13 * 8 + severity 4 (warning) = 108
+	assert.True(t, strings.HasPrefix(first, "<108>1 2024-04-01T09:30:00.123456Z app01 effisio "), first)
+	assert.Contains(t, first, " update - CEF:0|Effisio|Effisio|1.0|user:update|user update|7|")
+	assert.Contains(t, first, "outcome=failure")
+	assert.Contains(t, first, "msg=permission denied")
+
+	// 2件目も同じ接続でフレームを区切って受信できる
+	second := receive(t, messages)
+	assert.Contains(t, second, "|3|")
+	assert.Contains(t, second, "outcome=success")
+}
+
+func TestSyslogSinkTLS(t *testing.T) {
+	cert, pool := selfSignedCertificate(t)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer listener.Close()
+	messages := acceptMessages(t, listener)
+
+	sink, err := NewSyslogSink(SyslogConfig{
+		Network:   NetworkTLS,
+		Address:   listener.Addr().String(),
+		Hostname:  "app01",
+		TLSConfig: &tls.Config{RootCAs: pool},
+	})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+
+	assert.Contains(t, receive(t, messages), `[audit@32473 id="42"`)
+}
+
+func TestSyslogSinkTLSRejectsUntrustedServer(t *testing.T) {
+	cert, _ := selfSignedCertificate(t)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{Certificates: []tls.Certificate{cert}})
+	require.NoError(t, err)
+	defer listener.Close()
+	acceptMessages(t, listener)
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: NetworkTLS, Address: listener.Addr().String(), TLSConfig: &tls.Config{RootCAs: x509.NewCertPool()}})
+	require.NoError(t, err)
+
+	assert.Error(t, sink.Send(context.Background(), testEvent()))
+}
+
+func TestSyslogSinkReconnects(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+
+	// 最初の接続はサーバー側ですぐに閉じる
+	accepted := make(chan struct{})
+	go func() {
+		conn, err := listener.Accept()
+		if err == nil {
+			conn.Close()
+		}
+		close(accepted)
+	}()
+
+	sink, err := NewSyslogSink(SyslogConfig{Network: NetworkTCP, Address: addr})
+	require.NoError(t, err)
+	defer sink.Close()
+
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+	<-accepted
+	listener.Close()
+
+	// 切断を検知するまで送信を繰り返し、失敗した後は接続を作り直す
+	require.Eventually(t, func() bool {
+		return sink.Send(context.Background(), testEvent()) != nil
+	}, 2*time.Second, 10*time.Millisecond)
+
+	listener, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer listener.Close()
+	messages := acceptMessages(t, listener)
+
+	require.NoError(t, sink.Send(context.Background(), testEvent()))
+	assert.Contains(t, receive(t, messages), `[audit@32473 id="42"`)
+}
+
+func TestNewSyslogSinkValidation(t *testing.T) {
+	_, err := NewSyslogSink(SyslogConfig{Network: "unix", Address: "/dev/log"})
+	assert.Error(t, err)
+
+	_, err = NewSyslogSink(SyslogConfig{Network: NetworkUDP, Address: "localhost:514", Format: "json"})
+	assert.Error(t, err)
+
+	_, err = NewSyslogSink(SyslogConfig{Network: NetworkUDP})
+	assert.Error(t, err)
+}
+
+func TestStructuredDataEscaping(t *testing.T) {
+	event := testEvent()
+	event.ResourceID = `a"b\c]d`
+
+	assert.Contains(t, structuredData(event), `resource_id="a\"b\\c\]d"`)
+}
+
+func TestFormatCEFMessage(t *testing.T) {
+	event := testEvent()
+	event.ResourceType = "user|admin"
+	event.ErrorMessage = "key=value\nnext"
+
+	msg := FormatCEFMessage(event)
+
+	assert.True(t, strings.HasPrefix(msg, `CEF:0|Effisio|Effisio|1.0|user\|admin:update|user\|admin update|3|`), msg)
+	assert.Contains(t, msg, "rt=1711963800123")
+	assert.Contains(t, msg, "externalId=42 suid=7 act=update")
+	assert.Contains(t, msg, `cs1Label=resourceType cs1=user|admin`)
+	assert.Contains(t, msg, `msg=key\=value\nnext`)
+	assert.Contains(t, msg, "cs3Label=hash cs3=abc123")
+
+	event.Hash = ""
+	assert.NotContains(t, FormatCEFMessage(event), "cs3Label")
+}
+
+func TestHeaderField(t *testing.T) {
+	assert.Equal(t, "-", headerField("", 32))
+	assert.Equal(t, "app01", headerField("app 01", 32))
+	assert.Equal(t, "abc", headerField("abcdef", 3))
+}
+
+// selfSignedCertificate はテスト用の自己署名証明書と、それを信頼するプールを作成します
+func selfSignedCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "syslog.test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	parsed, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(parsed)
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}, pool
+}
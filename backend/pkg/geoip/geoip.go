@@ -0,0 +1,192 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// earthRadiusKm は地球の平均半径（km）です
+const earthRadiusKm = 6371.0
+
+// Location はIPアドレスの位置です
+type Location struct {
+	Country   string  `json:"country,omitempty"`
+	City      string  `json:"city,omitempty"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// DB はネットワーク（CIDR）ごとの位置を保持するGeoIPデータベースです
+// ネットワークは重複しない前提で、重複する場合はどちらの位置を返すかは保証しません
+type DB struct {
+	networks []network // 開始アドレスの昇順
+}
+
+type network struct {
+	start    [16]byte
+	end      [16]byte
+	location Location
+}
+
+// Open はCSV形式のGeoIPデータベースのファイルを読み込みます
+func Open(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	db, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Parse はCSV形式のGeoIPデータベースを読み込みます
+// 1行目はヘッダーで、network（CIDR）・latitude・longitude の列が必須です
+// country（または country_iso_code）・city（または city_name）の列があれば位置の名前として読み込みます
+// MaxMind GeoLite2 の City Blocks のCSVはそのまま読み込めます（緯度・経度が空の行は読み飛ばします）
+func Parse(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("geoip: missing header")
+		}
+		return nil, fmt.Errorf("geoip: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	networkCol, ok1 := columns["network"]
+	latitudeCol, ok2 := columns["latitude"]
+	longitudeCol, ok3 := columns["longitude"]
+	if !ok1 || !ok2 || !ok3 {
+		return nil, errors.New("geoip: network, latitude and longitude columns are required")
+	}
+	countryCol := firstColumn(columns, "country", "country_iso_code")
+	cityCol := firstColumn(columns, "city", "city_name")
+
+	db := &DB{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("geoip: %w", err)
+		}
+
+		latitude, longitude := field(record, latitudeCol), field(record, longitudeCol)
+		if latitude == "" || longitude == "" {
+			continue
+		}
+		n, err := parseNetwork(field(record, networkCol))
+		if err != nil {
+			return nil, fmt.Errorf("geoip: line %d: %w", line, err)
+		}
+		if n.location.Latitude, err = strconv.ParseFloat(latitude, 64); err != nil {
+			return nil, fmt.Errorf("geoip: line %d: invalid latitude %q", line, latitude)
+		}
+		if n.location.Longitude, err = strconv.ParseFloat(longitude, 64); err != nil {
+			return nil, fmt.Errorf("geoip: line %d: invalid longitude %q", line, longitude)
+		}
+		n.location.Country = field(record, countryCol)
+		n.location.City = field(record, cityCol)
+		db.networks = append(db.networks, n)
+	}
+
+	sort.Slice(db.networks, func(i, j int) bool {
+		return bytes.Compare(db.networks[i].start[:], db.networks[j].start[:]) < 0
+	})
+	return db, nil
+}
+
+// Len は読み込んだネットワークの数を返します
+func (db *DB) Len() int {
+	return len(db.networks)
+}
+
+// Lookup はIPアドレスの位置を返します（データベースにない場合は false）
+func (db *DB) Lookup(ipAddress string) (*Location, bool) {
+	ip := net.ParseIP(strings.TrimSpace(ipAddress))
+	if ip == nil {
+		return nil, false
+	}
+	var key [16]byte
+	copy(key[:], ip.To16())
+
+	// 開始アドレスが key より大きい最初のネットワークの1つ前が、key を含みうる唯一のネットワーク
+	i := sort.Search(len(db.networks), func(i int) bool {
+		return bytes.Compare(db.networks[i].start[:], key[:]) > 0
+	}) - 1
+	if i < 0 || bytes.Compare(key[:], db.networks[i].end[:]) > 0 {
+		return nil, false
+	}
+	location := db.networks[i].location
+	return &location, true
+}
+
+// Distance は2つの位置の大圏距離（km）を返します
+func Distance(a, b *Location) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(degrees float64) float64 {
+	return degrees * math.Pi / 180
+}
+
+// parseNetwork はCIDRをアドレスの範囲に変換します（IPv4は IPv4-mapped IPv6 として保持します）
+func parseNetwork(cidr string) (network, error) {
+	_, ipNet, err := net.ParseCIDR(strings.TrimSpace(cidr))
+	if err != nil {
+		return network{}, fmt.Errorf("invalid network %q", cidr)
+	}
+
+	end := make(net.IP, len(ipNet.IP))
+	for i := range ipNet.IP {
+		end[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+	}
+
+	var n network
+	copy(n.start[:], ipNet.IP.To16())
+	copy(n.end[:], end.To16())
+	return n, nil
+}
+
+// firstColumn は names のうち最初に存在する列の位置を返します（存在しない場合は -1）
+func firstColumn(columns map[string]int, names ...string) int {
+	for _, name := range names {
+		if i, ok := columns[name]; ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// field は列の値を返します（列がない場合は空文字）
+func field(record []string, col int) string {
+	if col < 0 || col >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[col])
+}
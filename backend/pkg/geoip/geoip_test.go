@@ -0,0 +1,106 @@
+package geoip
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDatabase = `network,country,city,latitude,longitude
+203.0.113.0/24,JP,Tokyo,35.6895,139.6917
+198.51.100.0/25,US,New York,40.7128,-74.0060
+198.51.100.128/25,US,,,
+2001:db8::/32,JP,Osaka,34.6937,135.5023
+`
+
+func TestParse_Lookup(t *testing.T) {
+	db, err := Parse(strings.NewReader(testDatabase))
+	require.NoError(t, err)
+	assert.Equal(t, 3, db.Len(), "緯度・経度が空の行は読み飛ばす")
+
+	tests := []struct {
+		name    string
+		ip      string
+		want    string
+		wantHit bool
+	}{
+		{"ネットワークの先頭", "203.0.113.0", "Tokyo", true},
+		{"ネットワークの末尾", "203.0.113.255", "Tokyo", true},
+		{"別のネットワーク", "198.51.100.10", "New York", true},
+		{"位置のないネットワーク", "198.51.100.200", "", false},
+		{"IPv6", "2001:db8::1", "Osaka", true},
+		{"データベースにない", "192.0.2.1", "", false},
+		{"すべてのネットワークより前", "1.1.1.1", "", false},
+		{"不正なIPアドレス", "unknown", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			location, ok := db.Lookup(tt.ip)
+			assert.Equal(t, tt.wantHit, ok)
+			if tt.wantHit {
+				assert.Equal(t, tt.want, location.City)
+			}
+		})
+	}
+}
+
+func TestParse_GeoLite2Columns(t *testing.T) {
+	data := "network,geoname_id,registered_country_geoname_id,represented_country_geoname_id,is_anonymous_proxy,is_satellite_provider,postal_code,latitude,longitude,accuracy_radius\n" +
+		"203.0.113.0/24,1850147,1861060,,0,0,100-0001,35.6895,139.6917,50\n"
+
+	db, err := Parse(strings.NewReader(data))
+	require.NoError(t, err)
+
+	location, ok := db.Lookup("203.0.113.5")
+	require.True(t, ok)
+	assert.InDelta(t, 35.6895, location.Latitude, 1e-9)
+	assert.InDelta(t, 139.6917, location.Longitude, 1e-9)
+	assert.Empty(t, location.Country)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"空のファイル", ""},
+		{"必須の列がない", "network,latitude\n203.0.113.0/24,35.0\n"},
+		{"不正なネットワーク", "network,latitude,longitude\n203.0.113.0,35.0,139.0\n"},
+		{"不正な緯度", "network,latitude,longitude\n203.0.113.0/24,north,139.0\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	require.NoError(t, os.WriteFile(path, []byte(testDatabase), 0o600))
+
+	db, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, 3, db.Len())
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
+
+func TestDistance(t *testing.T) {
+	tokyo := &Location{Latitude: 35.6895, Longitude: 139.6917}
+	osaka := &Location{Latitude: 34.6937, Longitude: 135.5023}
+	newYork := &Location{Latitude: 40.7128, Longitude: -74.0060}
+
+	assert.InDelta(t, 397, Distance(tokyo, osaka), 5)
+	assert.InDelta(t, 10850, Distance(tokyo, newYork), 50)
+	assert.Equal(t, 0.0, Distance(tokyo, tokyo))
+	assert.InDelta(t, Distance(tokyo, newYork), Distance(newYork, tokyo), 1e-9)
+}
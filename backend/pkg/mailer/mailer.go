@@ -0,0 +1,131 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Message は送信するメールです
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer はメール送信の抽象化です
+// 送信手段（SMTP、ログ出力等）は実装を差し替えて切り替えます
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// LogMailer はメールを送信せずログに出力するMailerです（開発環境用）
+type LogMailer struct {
+	logger *zap.Logger
+}
+
+// NewLogMailer は新しいLogMailerを作成します
+func NewLogMailer(logger *zap.Logger) *LogMailer {
+	return &LogMailer{
+		logger: logger,
+	}
+}
+
+// Send はメールの内容をログに出力します
+func (m *LogMailer) Send(ctx context.Context, msg *Message) error {
+	if err := validateMessage(msg); err != nil {
+		return err
+	}
+	m.logger.Info("Mail sent (log mailer)",
+		zap.Strings("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Body),
+	)
+	return nil
+}
+
+// SMTPMailer はSMTPサーバー経由でメールを送信するMailerです
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer は新しいSMTPMailerを作成します
+// username が空の場合は認証なしで送信します
+func NewSMTPMailer(host, port, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+	return &SMTPMailer{
+		addr: net.JoinHostPort(host, port),
+		from: from,
+		auth: auth,
+	}
+}
+
+// Send はSMTPサーバーにメールを送信します
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	if err := validateMessage(msg); err != nil {
+		return err
+	}
+
+	data := buildMessage(m.from, msg, time.Now())
+
+	// net/smtp はコンテキストに対応していないため、キャンセル時は結果を待たずに戻る
+	done := make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(m.addr, m.auth, m.from, msg.To, data)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("failed to send mail: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// validateMessage はメールの宛先と件名を検証します
+// ヘッダーインジェクションを防ぐため改行を含む値は受け付けません
+func validateMessage(msg *Message) error {
+	if msg == nil || len(msg.To) == 0 {
+		return errors.New("mail recipient is required")
+	}
+	for _, to := range msg.To {
+		if strings.ContainsAny(to, "\r\n") {
+			return errors.New("invalid mail recipient")
+		}
+	}
+	if strings.ContainsAny(msg.Subject, "\r\n") {
+		return errors.New("invalid mail subject")
+	}
+	return nil
+}
+
+// buildMessage はRFC 5322形式のメール本文を組み立てます
+func buildMessage(from string, msg *Message, now time.Time) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(msg.To, ", ") + "\r\n")
+	// 件名は非ASCII文字を含むためエンコードする
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	// 本文の改行はCRLFに統一する
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestBuildMessage(t *testing.T) {
+	now := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	msg := &Message{
+		To:      []string{"user@example.com"},
+		Subject: "招待のお知らせ",
+		Body:    "line1\nline2",
+	}
+
+	data := string(buildMessage("noreply@example.com", msg, now))
+
+	assert.Contains(t, data, "From: noreply@example.com\r\n")
+	assert.Contains(t, data, "To: user@example.com\r\n")
+	assert.Contains(t, data, "Subject: =?UTF-8?b?")
+	assert.Contains(t, data, "Content-Type: text/plain; charset=UTF-8\r\n")
+	assert.True(t, strings.HasSuffix(data, "\r\n\r\nline1\r\nline2"))
+}
+
+func TestValidateMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		msg     *Message
+		wantErr bool
+	}{
+		{name: "Valid", msg: &Message{To: []string{"user@example.com"}, Subject: "subject"}},
+		{name: "Nil message", msg: nil, wantErr: true},
+		{name: "No recipient", msg: &Message{Subject: "subject"}, wantErr: true},
+		{name: "Header injection in recipient", msg: &Message{To: []string{"user@example.com\r\nBcc: x@example.com"}}, wantErr: true},
+		{name: "Header injection in subject", msg: &Message{To: []string{"user@example.com"}, Subject: "a\nBcc: x@example.com"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateMessage(tt.msg)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLogMailer_Send(t *testing.T) {
+	m := NewLogMailer(zap.NewNop())
+
+	err := m.Send(context.Background(), &Message{To: []string{"user@example.com"}, Subject: "subject", Body: "body"})
+	require.NoError(t, err)
+
+	err = m.Send(context.Background(), &Message{Subject: "subject"})
+	assert.Error(t, err)
+}
@@ -12,31 +12,75 @@ const (
 	ErrCodeInvalidToken           = "AUTH_002"
 	ErrCodeTokenExpired           = "AUTH_003"
 	ErrCodeInsufficientPermission = "AUTH_004"
+	ErrCodeAccountLocked          = "AUTH_005"
+	ErrCodeLoginBlocked           = "AUTH_006"
 
 	// ユーザーエラー (USER_xxx)
 	ErrCodeUserNotFound      = "USER_001"
 	ErrCodeUserAlreadyExists = "USER_002"
 	ErrCodeInvalidCredentials = "USER_003"
+	ErrCodeInvalidEmailVerification = "USER_004"
+	ErrCodeEmailAlreadyVerified     = "USER_005"
+
+	// 招待エラー (INVITE_xxx)
+	ErrCodeInvitationNotFound     = "INVITE_001"
+	ErrCodeInvalidInvitation      = "INVITE_002"
+	ErrCodeInvitationNotAvailable = "INVITE_003"
+
+	// 属性エラー (ATTR_xxx)
+	ErrCodeAttributeNotFound      = "ATTR_001"
+	ErrCodeAttributeAlreadyExists = "ATTR_002"
+
+	// グループエラー (GROUP_xxx)
+	ErrCodeGroupNotFound       = "GROUP_001"
+	ErrCodeGroupAlreadyExists  = "GROUP_002"
+	ErrCodeGroupHasChildren    = "GROUP_003"
+	ErrCodeGroupMemberNotFound = "GROUP_004"
+
+	// Webhookエラー (WEBHOOK_xxx)
+	ErrCodeWebhookNotFound         = "WEBHOOK_001"
+	ErrCodeWebhookDeliveryNotFound = "WEBHOOK_002"
+	ErrCodeInvalidWebhookEvent     = "WEBHOOK_003"
+
+	// 監査ログエラー (AUDIT_xxx)
+	ErrCodeRetentionPolicyNotFound      = "AUDIT_001"
+	ErrCodeRetentionPolicyAlreadyExists = "AUDIT_002"
+	ErrCodeArchiveNotFound              = "AUDIT_003"
+	ErrCodeArchiveCorrupted             = "AUDIT_004"
+	ErrCodeLegalHoldNotFound            = "AUDIT_005"
+	ErrCodeLegalHoldReleased            = "AUDIT_006"
+	ErrCodeUnderLegalHold               = "AUDIT_007"
+
+	// セキュリティアラートエラー (SECURITY_xxx)
+	ErrCodeSecurityAlertNotFound     = "SECURITY_001"
+	ErrCodeSecurityAlertAcknowledged = "SECURITY_002"
 
 	// バリデーションエラー (VAL_xxx)
-	ErrCodeValidationError  = "VAL_001"
-	ErrCodeInvalidParameter = "VAL_002"
+	ErrCodeValidationError      = "VAL_001"
+	ErrCodeInvalidParameter     = "VAL_002"
+	ErrCodePreconditionRequired = "VAL_003"
+	ErrCodeInvalidPatch         = "VAL_004"
+	ErrCodePatchTestFailed      = "VAL_005"
 
 	// データベースエラー (DB_xxx)
-	ErrCodeDatabaseError  = "DB_001"
-	ErrCodeRecordNotFound = "DB_002"
+	ErrCodeDatabaseError   = "DB_001"
+	ErrCodeRecordNotFound  = "DB_002"
+	ErrCodeVersionConflict = "DB_003"
 
 	// システムエラー (SYS_xxx)
-	ErrCodeInternalError     = "SYS_001"
-	ErrCodePasswordHashError = "SYS_002"
+	ErrCodeInternalError      = "SYS_001"
+	ErrCodePasswordHashError  = "SYS_002"
+	ErrCodeMailDeliveryError  = "SYS_003"
+	ErrCodeServiceUnavailable = "SYS_004"
 )
 
 // AppError はアプリケーションエラーを表します
 type AppError struct {
 	Code       string `json:"code"`
 	Message    string `json:"message"`
-	StatusCode int    `json:"-"`
-	Err        error  `json:"-"`
+	StatusCode int         `json:"-"`
+	Details    interface{} `json:"-"`
+	Err        error       `json:"-"`
 }
 
 // Error はerrorインターフェースを実装します
@@ -57,6 +101,17 @@ func NewBadRequestError(code string, err error) *AppError {
 	}
 }
 
+// NewValidationError はフィールドごとの詳細を持つ400エラーを作成します
+func NewValidationError(details map[string]string, err error) *AppError {
+	return &AppError{
+		Code:       ErrCodeValidationError,
+		Message:    "Validation failed",
+		StatusCode: http.StatusBadRequest,
+		Details:    details,
+		Err:        err,
+	}
+}
+
 // NewUnauthorizedError は401エラーを作成します
 func NewUnauthorizedError(code string, err error) *AppError {
 	return &AppError{
@@ -97,6 +152,36 @@ func NewConflictError(code string, err error) *AppError {
 	}
 }
 
+// NewPreconditionFailedError は412エラーを作成します
+func NewPreconditionFailedError(code string, err error) *AppError {
+	return &AppError{
+		Code:       code,
+		Message:    "Precondition failed",
+		StatusCode: http.StatusPreconditionFailed,
+		Err:        err,
+	}
+}
+
+// NewPreconditionRequiredError は428エラーを作成します
+func NewPreconditionRequiredError(code string, err error) *AppError {
+	return &AppError{
+		Code:       code,
+		Message:    "Precondition required",
+		StatusCode: http.StatusPreconditionRequired,
+		Err:        err,
+	}
+}
+
+// NewServiceUnavailableError は503エラーを作成します
+func NewServiceUnavailableError(code string, err error) *AppError {
+	return &AppError{
+		Code:       code,
+		Message:    "Service unavailable",
+		StatusCode: http.StatusServiceUnavailable,
+		Err:        err,
+	}
+}
+
 // NewInternalError は500エラーを作成します
 func NewInternalError(code string, err error) *AppError {
 	return &AppError{
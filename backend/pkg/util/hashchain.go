@@ -0,0 +1,42 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashChain は改ざん検知用のハッシュチェーンを計算します
+// 鍵付きハッシュ（HMAC-SHA256）を使用するため、鍵を持たない者はデータベースを直接書き換えても
+// 整合するハッシュを再計算できません
+type HashChain struct {
+	key []byte
+}
+
+// NewHashChain は新しいHashChainを作成します
+func NewHashChain(key string) *HashChain {
+	return &HashChain{
+		key: []byte(key),
+	}
+}
+
+// Link は直前の要素のハッシュと要素の正規化した内容からハッシュを計算します
+func (c *HashChain) Link(prevHash string, payload []byte) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write([]byte(prevHash))
+	mac.Write([]byte{0}) // 区切り（前のハッシュと内容の境界を一意にする）
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Sign はチェックポイント等の内容に署名します
+func (c *HashChain) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature は署名が正しいかを定数時間で比較します
+func (c *HashChain) VerifySignature(payload []byte, signature string) bool {
+	return hmac.Equal([]byte(c.Sign(payload)), []byte(signature))
+}
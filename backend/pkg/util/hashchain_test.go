@@ -0,0 +1,31 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashChainLink(t *testing.T) {
+	chain := NewHashChain("test-key")
+
+	first := chain.Link("", []byte(`{"id":1}`))
+	second := chain.Link(first, []byte(`{"id":2}`))
+
+	assert.Len(t, first, 64)
+	assert.Equal(t, first, chain.Link("", []byte(`{"id":1}`)))
+	assert.NotEqual(t, second, chain.Link("", []byte(`{"id":2}`)), "previous hash must affect the link")
+	assert.NotEqual(t, first, chain.Link("", []byte(`{"id":3}`)), "payload must affect the link")
+	assert.NotEqual(t, first, NewHashChain("other-key").Link("", []byte(`{"id":1}`)), "key must affect the link")
+}
+
+func TestHashChainSignature(t *testing.T) {
+	chain := NewHashChain("test-key")
+	payload := []byte(`{"last_id":10}`)
+
+	signature := chain.Sign(payload)
+
+	assert.True(t, chain.VerifySignature(payload, signature))
+	assert.False(t, chain.VerifySignature([]byte(`{"last_id":11}`), signature))
+	assert.False(t, NewHashChain("other-key").VerifySignature(payload, signature))
+}
@@ -0,0 +1,229 @@
+package util
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 項目の変更の種類
+const (
+	FieldAdded    = "added"
+	FieldRemoved  = "removed"
+	FieldModified = "modified"
+)
+
+// RedactedValue は秘密情報の値の代わりに記録する文字列です
+const RedactedValue = "[REDACTED]"
+
+// secretFieldPatterns は秘密情報として扱うキーに含まれる文字列です（小文字、区切り文字を除いて比較）
+var secretFieldPatterns = []string{
+	"password",
+	"passwd",
+	"secret",
+	"token",
+	"apikey",
+	"privatekey",
+	"credential",
+	"authorization",
+	"cookie",
+}
+
+// FieldChange は2つのJSONドキュメントの間で変更された項目です
+// Path はキーをドットで、配列の要素を [インデックス] でつないだ位置です（例: attributes.tags[1]）
+type FieldChange struct {
+	Path   string      `json:"path"`
+	Type   string      `json:"type"` // added, removed, modified
+	Before interface{} `json:"before,omitempty"`
+	After  interface{} `json:"after,omitempty"`
+}
+
+// DiffJSON は before と after をJSONとして比較し、変更された項目をパスの順に返します
+// オブジェクトと配列は再帰的に比較し、それ以外の値は値が異なる場合に modified とします
+// 値はJSONに変換してから比較するため、Goの型が異なっても同じJSONになる値は変更とみなしません
+func DiffJSON(before, after interface{}) ([]FieldChange, error) {
+	normalizedBefore, err := NormalizeJSON(before)
+	if err != nil {
+		return nil, err
+	}
+	normalizedAfter, err := NormalizeJSON(after)
+	if err != nil {
+		return nil, err
+	}
+
+	// ドキュメント全体が空の場合は空のオブジェクトとして比較する
+	if normalizedBefore == nil {
+		normalizedBefore = map[string]interface{}{}
+	}
+	if normalizedAfter == nil {
+		normalizedAfter = map[string]interface{}{}
+	}
+
+	changes := []FieldChange{}
+	diffJSONValues("", normalizedBefore, normalizedAfter, &changes)
+	return changes, nil
+}
+
+// diffJSONValues は path の位置の値を比較し、変更された項目を changes に追加します
+func diffJSONValues(path string, before, after interface{}, changes *[]FieldChange) {
+	beforeObject, beforeIsObject := before.(map[string]interface{})
+	afterObject, afterIsObject := after.(map[string]interface{})
+	if beforeIsObject && afterIsObject {
+		keys := make([]string, 0, len(beforeObject)+len(afterObject))
+		for key := range beforeObject {
+			keys = append(keys, key)
+		}
+		for key := range afterObject {
+			if _, ok := beforeObject[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			child := joinFieldPath(path, key)
+			beforeValue, inBefore := beforeObject[key]
+			afterValue, inAfter := afterObject[key]
+			switch {
+			case !inAfter:
+				*changes = append(*changes, FieldChange{Path: child, Type: FieldRemoved, Before: beforeValue})
+			case !inBefore:
+				*changes = append(*changes, FieldChange{Path: child, Type: FieldAdded, After: afterValue})
+			default:
+				diffJSONValues(child, beforeValue, afterValue, changes)
+			}
+		}
+		return
+	}
+
+	beforeArray, beforeIsArray := before.([]interface{})
+	afterArray, afterIsArray := after.([]interface{})
+	if beforeIsArray && afterIsArray {
+		length := len(beforeArray)
+		if len(afterArray) > length {
+			length = len(afterArray)
+		}
+		for i := 0; i < length; i++ {
+			child := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= len(afterArray):
+				*changes = append(*changes, FieldChange{Path: child, Type: FieldRemoved, Before: beforeArray[i]})
+			case i >= len(beforeArray):
+				*changes = append(*changes, FieldChange{Path: child, Type: FieldAdded, After: afterArray[i]})
+			default:
+				diffJSONValues(child, beforeArray[i], afterArray[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		*changes = append(*changes, FieldChange{Path: path, Type: FieldModified, Before: before, After: after})
+	}
+}
+
+// joinFieldPath は親の位置にキーをつなげます
+func joinFieldPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// ChangedFieldPaths は変更された項目の位置の一覧を返します
+func ChangedFieldPaths(changes []FieldChange) []string {
+	paths := make([]string, len(changes))
+	for i, change := range changes {
+		paths[i] = change.Path
+	}
+	return paths
+}
+
+// NormalizeJSON は値をJSONに変換してデコードし直した値（map[string]interface{} 等）を返します
+func NormalizeJSON(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// IsSecretField はキーがパスワードやトークン等の秘密情報を表すかを返します
+func IsSecretField(key string) bool {
+	normalized := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	for _, pattern := range secretFieldPatterns {
+		if strings.Contains(normalized, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactSecrets はJSONとしてデコードされた値をコピーし、秘密情報のキーの値を RedactedValue に置き換えます
+// 値が空（null または空文字列）の場合は、設定されていないことがわかるようそのまま残します
+func RedactSecrets(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if IsSecretField(key) {
+				redacted[key] = redactSecretValue(child)
+				continue
+			}
+			redacted[key] = RedactSecrets(child)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, child := range v {
+			redacted[i] = RedactSecrets(child)
+		}
+		return redacted
+	default:
+		return v
+	}
+}
+
+// RedactFieldChanges は変更された項目のうち、位置に秘密情報のキーを含む項目の値を置き換えます
+// 変更されたこと自体は残すため、項目は削除しません
+func RedactFieldChanges(changes []FieldChange) []FieldChange {
+	redacted := make([]FieldChange, len(changes))
+	for i, change := range changes {
+		if isSecretFieldPath(change.Path) {
+			change.Before = redactSecretValue(change.Before)
+			change.After = redactSecretValue(change.After)
+		} else {
+			change.Before = RedactSecrets(change.Before)
+			change.After = RedactSecrets(change.After)
+		}
+		redacted[i] = change
+	}
+	return redacted
+}
+
+// isSecretFieldPath は位置のいずれかのキーが秘密情報を表すかを返します
+func isSecretFieldPath(path string) bool {
+	for _, segment := range strings.Split(path, ".") {
+		if i := strings.Index(segment, "["); i >= 0 {
+			segment = segment[:i]
+		}
+		if IsSecretField(segment) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactSecretValue は空でない値を RedactedValue に置き換えます
+func redactSecretValue(value interface{}) interface{} {
+	if value == nil || value == "" {
+		return value
+	}
+	return RedactedValue
+}
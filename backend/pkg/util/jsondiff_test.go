@@ -0,0 +1,181 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		before   interface{}
+		after    interface{}
+		expected []FieldChange
+	}{
+		{
+			name:     "No changes",
+			before:   map[string]interface{}{"role": "user", "tags": []string{"a"}},
+			after:    map[string]interface{}{"role": "user", "tags": []string{"a"}},
+			expected: []FieldChange{},
+		},
+		{
+			name:   "Modified value",
+			before: map[string]interface{}{"role": "user", "department": "Sales"},
+			after:  map[string]interface{}{"role": "admin", "department": "Sales"},
+			expected: []FieldChange{
+				{Path: "role", Type: FieldModified, Before: "user", After: "admin"},
+			},
+		},
+		{
+			name:   "Added and removed keys",
+			before: map[string]interface{}{"department": "Sales"},
+			after:  map[string]interface{}{"role": "admin"},
+			expected: []FieldChange{
+				{Path: "department", Type: FieldRemoved, Before: "Sales"},
+				{Path: "role", Type: FieldAdded, After: "admin"},
+			},
+		},
+		{
+			name: "Nested objects",
+			before: map[string]interface{}{
+				"attributes": map[string]interface{}{"location": "Tokyo", "level": 1},
+			},
+			after: map[string]interface{}{
+				"attributes": map[string]interface{}{"location": "Osaka", "level": 1, "team": "core"},
+			},
+			expected: []FieldChange{
+				{Path: "attributes.location", Type: FieldModified, Before: "Tokyo", After: "Osaka"},
+				{Path: "attributes.team", Type: FieldAdded, After: "core"},
+			},
+		},
+		{
+			name:   "Array elements",
+			before: map[string]interface{}{"tags": []interface{}{"a", "b", "c"}},
+			after:  map[string]interface{}{"tags": []interface{}{"a", "x"}},
+			expected: []FieldChange{
+				{Path: "tags[1]", Type: FieldModified, Before: "b", After: "x"},
+				{Path: "tags[2]", Type: FieldRemoved, Before: "c"},
+			},
+		},
+		{
+			name:   "Objects in arrays",
+			before: map[string]interface{}{"members": []interface{}{map[string]interface{}{"id": 1, "role": "member"}}},
+			after:  map[string]interface{}{"members": []interface{}{map[string]interface{}{"id": 1, "role": "owner"}}},
+			expected: []FieldChange{
+				{Path: "members[0].role", Type: FieldModified, Before: "member", After: "owner"},
+			},
+		},
+		{
+			name:   "Type change",
+			before: map[string]interface{}{"expires_at": nil},
+			after:  map[string]interface{}{"expires_at": "2026-12-31T00:00:00Z"},
+			expected: []FieldChange{
+				{Path: "expires_at", Type: FieldModified, Before: nil, After: "2026-12-31T00:00:00Z"},
+			},
+		},
+		{
+			name:     "Go types are compared as JSON",
+			before:   map[string]interface{}{"count": uint(3)},
+			after:    map[string]interface{}{"count": float64(3)},
+			expected: []FieldChange{},
+		},
+		{
+			name:   "Nil document",
+			before: nil,
+			after:  map[string]interface{}{"role": "user"},
+			expected: []FieldChange{
+				{Path: "role", Type: FieldAdded, After: "user"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes, err := DiffJSON(tt.before, tt.after)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, changes)
+		})
+	}
+}
+
+func TestIsSecretField(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected bool
+	}{
+		{"password", true},
+		{"password_hash", true},
+		{"newPassword", true},
+		{"mfa_secret", true},
+		{"refresh_token", true},
+		{"api-key", true},
+		{"API_KEY", true},
+		{"private_key", true},
+		{"Authorization", true},
+		{"email", false},
+		{"role", false},
+		{"key", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsSecretField(tt.key))
+		})
+	}
+}
+
+func TestRedactSecrets(t *testing.T) {
+	value := map[string]interface{}{
+		"email":    "user@example.com",
+		"password": "hunter2",
+		"api_key":  "",
+		"webhook": map[string]interface{}{
+			"url":    "https://example.com/hook",
+			"secret": "s3cr3t",
+		},
+		"headers": []interface{}{
+			map[string]interface{}{"authorization": "Bearer abc"},
+		},
+	}
+
+	redacted := RedactSecrets(value)
+
+	assert.Equal(t, map[string]interface{}{
+		"email":    "user@example.com",
+		"password": RedactedValue,
+		"api_key":  "",
+		"webhook": map[string]interface{}{
+			"url":    "https://example.com/hook",
+			"secret": RedactedValue,
+		},
+		"headers": []interface{}{
+			map[string]interface{}{"authorization": RedactedValue},
+		},
+	}, redacted)
+	// 元の値は変更しない
+	assert.Equal(t, "hunter2", value["password"])
+}
+
+func TestRedactFieldChanges(t *testing.T) {
+	changes := []FieldChange{
+		{Path: "role", Type: FieldModified, Before: "user", After: "admin"},
+		{Path: "password", Type: FieldModified, Before: "old", After: "new"},
+		{Path: "webhook.secret", Type: FieldAdded, After: "s3cr3t"},
+		{Path: "tokens[0]", Type: FieldRemoved, Before: "abc"},
+		{Path: "webhook", Type: FieldAdded, After: map[string]interface{}{"secret": "s3cr3t"}},
+	}
+
+	redacted := RedactFieldChanges(changes)
+
+	assert.Equal(t, []FieldChange{
+		{Path: "role", Type: FieldModified, Before: "user", After: "admin"},
+		{Path: "password", Type: FieldModified, Before: RedactedValue, After: RedactedValue},
+		{Path: "webhook.secret", Type: FieldAdded, After: RedactedValue},
+		{Path: "tokens[0]", Type: FieldRemoved, Before: RedactedValue},
+		{Path: "webhook", Type: FieldAdded, After: map[string]interface{}{"secret": RedactedValue}},
+	}, redacted)
+	assert.Equal(t, []string{"role", "password", "webhook.secret", "tokens[0]", "webhook"}, ChangedFieldPaths(redacted))
+}
@@ -0,0 +1,199 @@
+package util
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newPatchTestDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"email":      "user@example.com",
+		"department": "Engineering",
+		"role":       "user",
+		"tags":       []interface{}{"a", "b"},
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected map[string]interface{}
+	}{
+		{
+			name:  "Replace value",
+			patch: `{"role":"manager"}`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "Engineering",
+				"role":       "manager",
+				"tags":       []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "Null removes key",
+			patch: `{"department":null}`,
+			expected: map[string]interface{}{
+				"email": "user@example.com",
+				"role":  "user",
+				"tags":  []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "Empty string is kept as value",
+			patch: `{"department":""}`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "",
+				"role":       "user",
+				"tags":       []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "Arrays are replaced as a whole",
+			patch: `{"tags":["c"]}`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "Engineering",
+				"role":       "user",
+				"tags":       []interface{}{"c"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newPatchTestDoc()
+			result, err := ApplyMergePatch(doc, []byte(tt.patch))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			// 元のドキュメントは変更されない
+			assert.Equal(t, newPatchTestDoc(), doc)
+		})
+	}
+}
+
+func TestApplyMergePatch_Invalid(t *testing.T) {
+	for _, patch := range []string{`not json`, `["role"]`, `"text"`, `{"a":1} {"b":2}`} {
+		_, err := ApplyMergePatch(newPatchTestDoc(), []byte(patch))
+		assert.True(t, errors.Is(err, ErrInvalidPatch), patch)
+	}
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	tests := []struct {
+		name     string
+		patch    string
+		expected map[string]interface{}
+	}{
+		{
+			name:  "Test and replace",
+			patch: `[{"op":"test","path":"/role","value":"user"},{"op":"replace","path":"/role","value":"manager"}]`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "Engineering",
+				"role":       "manager",
+				"tags":       []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "Remove key",
+			patch: `[{"op":"remove","path":"/department"}]`,
+			expected: map[string]interface{}{
+				"email": "user@example.com",
+				"role":  "user",
+				"tags":  []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "Add to array",
+			patch: `[{"op":"add","path":"/tags/1","value":"x"},{"op":"add","path":"/tags/-","value":"z"}]`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "Engineering",
+				"role":       "user",
+				"tags":       []interface{}{"a", "x", "b", "z"},
+			},
+		},
+		{
+			name:  "Remove from array",
+			patch: `[{"op":"remove","path":"/tags/0"}]`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "Engineering",
+				"role":       "user",
+				"tags":       []interface{}{"b"},
+			},
+		},
+		{
+			name:  "Move and copy",
+			patch: `[{"op":"copy","from":"/email","path":"/backup"},{"op":"move","from":"/department","path":"/dept"}]`,
+			expected: map[string]interface{}{
+				"email":  "user@example.com",
+				"backup": "user@example.com",
+				"dept":   "Engineering",
+				"role":   "user",
+				"tags":   []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:  "Escaped pointer",
+			patch: `[{"op":"add","path":"/a~1b~0c","value":1}]`,
+			expected: map[string]interface{}{
+				"email":      "user@example.com",
+				"department": "Engineering",
+				"role":       "user",
+				"tags":       []interface{}{"a", "b"},
+				"a/b~c":      float64(1),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newPatchTestDoc()
+			result, err := ApplyJSONPatch(doc, []byte(tt.patch))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+			assert.Equal(t, newPatchTestDoc(), doc)
+		})
+	}
+}
+
+func TestApplyJSONPatch_TestFailed(t *testing.T) {
+	patch := `[{"op":"test","path":"/role","value":"admin"},{"op":"replace","path":"/role","value":"manager"}]`
+
+	result, err := ApplyJSONPatch(newPatchTestDoc(), []byte(patch))
+
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, ErrPatchTestFailed))
+}
+
+func TestApplyJSONPatch_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+	}{
+		{name: "Not an array", patch: `{"op":"remove","path":"/role"}`},
+		{name: "Unknown operation", patch: `[{"op":"merge","path":"/role"}]`},
+		{name: "Missing value", patch: `[{"op":"replace","path":"/role"}]`},
+		{name: "Replace missing path", patch: `[{"op":"replace","path":"/missing","value":1}]`},
+		{name: "Remove missing path", patch: `[{"op":"remove","path":"/missing"}]`},
+		{name: "Invalid pointer", patch: `[{"op":"remove","path":"role"}]`},
+		{name: "Array index out of range", patch: `[{"op":"add","path":"/tags/5","value":"x"}]`},
+		{name: "Leading zero index", patch: `[{"op":"remove","path":"/tags/01"}]`},
+		{name: "Remove whole document", patch: `[{"op":"remove","path":""}]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ApplyJSONPatch(newPatchTestDoc(), []byte(tt.patch))
+			assert.True(t, errors.Is(err, ErrInvalidPatch), err)
+		})
+	}
+}
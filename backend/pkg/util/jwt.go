@@ -16,6 +16,10 @@ type AccessTokenClaims struct {
 	jwt.RegisteredClaims
 }
 
+// accessTokenAudience はアクセストークンを他のトークンと区別するためのaudienceです
+// 同じ秘密鍵で署名した招待・メール確認トークンを認証に使えないよう、検証時に必須とします
+const accessTokenAudience = "effisio-access"
+
 // RefreshTokenClaims はリフレッシュトークンのクレームです
 type RefreshTokenClaims struct {
 	UserID  uint   `json:"user_id"`
@@ -23,6 +27,27 @@ type RefreshTokenClaims struct {
 	jwt.RegisteredClaims
 }
 
+// InvitationTokenClaims は招待トークンのクレームです
+type InvitationTokenClaims struct {
+	InvitationID uint   `json:"invitation_id"`
+	TokenID      string `json:"token_id"`
+	jwt.RegisteredClaims
+}
+
+// invitationAudience は招待トークンを他のトークンと区別するためのaudienceです
+const invitationAudience = "effisio-invitation"
+
+// EmailVerificationTokenClaims はメールアドレス確認トークンのクレームです
+type EmailVerificationTokenClaims struct {
+	UserID  uint   `json:"user_id"`
+	Email   string `json:"email"`
+	TokenID string `json:"token_id"`
+	jwt.RegisteredClaims
+}
+
+// emailVerificationAudience はメール確認トークンを他のトークンと区別するためのaudienceです
+const emailVerificationAudience = "effisio-email-verification"
+
 // JWTService はJWT関連の処理を提供します
 type JWTService struct {
 	secret                  []byte
@@ -53,6 +78,7 @@ func (s *JWTService) GenerateAccessToken(userID uint, username, role string, per
 			NotBefore: jwt.NewNumericDate(now),
 			Issuer:    "effisio",
 			Subject:   username,
+			Audience:  jwt.ClaimStrings{accessTokenAudience},
 		},
 	}
 
@@ -86,7 +112,7 @@ func (s *JWTService) ValidateAccessToken(tokenString string) (*AccessTokenClaims
 			return nil, errors.New("unexpected signing method")
 		}
 		return s.secret, nil
-	})
+	}, jwt.WithAudience(accessTokenAudience))
 
 	if err != nil {
 		return nil, err
@@ -120,6 +146,88 @@ func (s *JWTService) ValidateRefreshToken(tokenString string) (*RefreshTokenClai
 	return nil, errors.New("invalid token")
 }
 
+// GenerateInvitationToken は招待トークンを生成します
+// 有効期限は招待ごとに指定します
+func (s *JWTService) GenerateInvitationToken(invitationID uint, tokenID string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := &InvitationTokenClaims{
+		InvitationID: invitationID,
+		TokenID:      tokenID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "effisio",
+			Audience:  jwt.ClaimStrings{invitationAudience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.secret)
+}
+
+// ValidateInvitationToken は招待トークンを検証します
+func (s *JWTService) ValidateInvitationToken(tokenString string) (*InvitationTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &InvitationTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// 署名方式の確認
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.secret, nil
+	}, jwt.WithAudience(invitationAudience))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*InvitationTokenClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
+
+// GenerateEmailVerificationToken はメールアドレス確認トークンを生成します
+func (s *JWTService) GenerateEmailVerificationToken(userID uint, email, tokenID string, expiresAt time.Time) (string, error) {
+	now := time.Now()
+	claims := &EmailVerificationTokenClaims{
+		UserID:  userID,
+		Email:   email,
+		TokenID: tokenID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Issuer:    "effisio",
+			Audience:  jwt.ClaimStrings{emailVerificationAudience},
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.secret)
+}
+
+// ValidateEmailVerificationToken はメールアドレス確認トークンを検証します
+func (s *JWTService) ValidateEmailVerificationToken(tokenString string) (*EmailVerificationTokenClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &EmailVerificationTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
+		// 署名方式の確認
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("unexpected signing method")
+		}
+		return s.secret, nil
+	}, jwt.WithAudience(emailVerificationAudience))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*EmailVerificationTokenClaims); ok && token.Valid {
+		return claims, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
+
 // ExtractTokenFromAuthHeader はAuthorizationヘッダーからトークンを抽出します
 func ExtractTokenFromAuthHeader(authHeader string) (string, error) {
 	if authHeader == "" {
@@ -139,37 +247,61 @@ func ExtractTokenFromAuthHeader(authHeader string) (string, error) {
 	return authHeader[len(bearerPrefix):], nil
 }
 
+// rolePermissions はロールごとの権限リストです
+// ルートはロールではなく権限で保護するため、グループで付与した権限もロールの権限と同様に扱われます
+var rolePermissions = map[string][]string{
+	"admin": {
+		"users:read",
+		"users:write",
+		"users:create",
+		"users:delete",
+		"users:privacy",
+		"groups:write",
+		"tasks:read",
+		"tasks:write",
+		"tasks:delete",
+		"settings:read",
+		"settings:write",
+		"webhooks:manage",
+		"audit_logs:manage",
+		"legal_holds:manage",
+		"security_alerts:manage",
+	},
+	"manager": {
+		"users:read",
+		"users:write",
+		"tasks:read",
+		"tasks:write",
+		"tasks:delete",
+	},
+	"user": {
+		"tasks:read",
+		"tasks:write",
+	},
+	"viewer": {
+		"tasks:read",
+	},
+}
+
 // GetPermissionsForRole はロールに基づいて権限リストを返します
 func GetPermissionsForRole(role string) []string {
-	permissionMap := map[string][]string{
-		"admin": {
-			"users:read",
-			"users:write",
-			"users:delete",
-			"tasks:read",
-			"tasks:write",
-			"tasks:delete",
-			"settings:read",
-			"settings:write",
-		},
-		"manager": {
-			"users:read",
-			"tasks:read",
-			"tasks:write",
-			"tasks:delete",
-		},
-		"user": {
-			"tasks:read",
-			"tasks:write",
-		},
-		"viewer": {
-			"tasks:read",
-		},
-	}
-
-	if permissions, ok := permissionMap[role]; ok {
-		return permissions
+	if permissions, ok := rolePermissions[role]; ok {
+		// 呼び出し側で追加・変更されても定義に影響しないようコピーを返す
+		return append([]string{}, permissions...)
 	}
 
 	return []string{} // デフォルトは権限なし
 }
+
+// IsValidPermission は権限がいずれかのロールで定義されているかチェックします
+// グループに付与できる権限はロールで定義済みのものに限ります
+func IsValidPermission(permission string) bool {
+	for _, permissions := range rolePermissions {
+		for _, p := range permissions {
+			if p == permission {
+				return true
+			}
+		}
+	}
+	return false
+}
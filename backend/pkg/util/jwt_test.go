@@ -190,6 +190,35 @@ func TestValidateAccessToken(t *testing.T) {
 	}
 }
 
+func TestValidateAccessToken_RejectsOtherTokens(t *testing.T) {
+	svc := NewJWTService("test-secret-key", 15*time.Minute, 7*24*time.Hour)
+
+	// 同じ秘密鍵で署名された他の用途のトークンはアクセストークンとして扱わない
+	invitationToken, err := svc.GenerateInvitationToken(1, "invite-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	verificationToken, err := svc.GenerateEmailVerificationToken(1, "user@example.com", "verify-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	refreshToken, err := svc.GenerateRefreshToken(1, "token-123")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Invitation token", invitationToken},
+		{"Email verification token", verificationToken},
+		{"Refresh token", refreshToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := svc.ValidateAccessToken(tt.token)
+			assert.Error(t, err)
+			assert.Nil(t, claims)
+		})
+	}
+}
+
 func TestValidateRefreshToken(t *testing.T) {
 	secret := "test-secret-key"
 	svc := NewJWTService(secret, 15*time.Minute, 7*24*time.Hour)
@@ -249,6 +278,60 @@ func TestValidateRefreshToken(t *testing.T) {
 	}
 }
 
+func TestValidateInvitationToken(t *testing.T) {
+	svc := NewJWTService("test-secret-key", 15*time.Minute, 7*24*time.Hour)
+
+	validToken, err := svc.GenerateInvitationToken(10, "invite-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	expiredToken, err := svc.GenerateInvitationToken(10, "invite-123", time.Now().Add(-time.Minute))
+	require.NoError(t, err)
+
+	// 同じ秘密鍵で署名されたリフレッシュトークンは招待トークンとして扱わない
+	refreshToken, err := svc.GenerateRefreshToken(10, "invite-123")
+	require.NoError(t, err)
+
+	t.Run("Valid invitation token", func(t *testing.T) {
+		claims, err := svc.ValidateInvitationToken(validToken)
+		require.NoError(t, err)
+		assert.Equal(t, uint(10), claims.InvitationID)
+		assert.Equal(t, "invite-123", claims.TokenID)
+	})
+
+	t.Run("Expired invitation token", func(t *testing.T) {
+		claims, err := svc.ValidateInvitationToken(expiredToken)
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("Refresh token is rejected", func(t *testing.T) {
+		claims, err := svc.ValidateInvitationToken(refreshToken)
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+}
+
+func TestValidateEmailVerificationToken(t *testing.T) {
+	svc := NewJWTService("test-secret-key", 15*time.Minute, 7*24*time.Hour)
+
+	validToken, err := svc.GenerateEmailVerificationToken(5, "new@example.com", "verify-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	// 招待トークンはメール確認トークンとして扱わない
+	invitationToken, err := svc.GenerateInvitationToken(5, "verify-123", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	claims, err := svc.ValidateEmailVerificationToken(validToken)
+	require.NoError(t, err)
+	assert.Equal(t, uint(5), claims.UserID)
+	assert.Equal(t, "new@example.com", claims.Email)
+	assert.Equal(t, "verify-123", claims.TokenID)
+
+	claims, err = svc.ValidateEmailVerificationToken(invitationToken)
+	assert.Error(t, err)
+	assert.Nil(t, claims)
+}
+
 func TestTokenExpiration(t *testing.T) {
 	secret := "test-secret"
 	// Create service with very short expiration
@@ -355,14 +438,14 @@ func TestGetPermissionsForRole(t *testing.T) {
 		{
 			name:            "Admin role permissions",
 			role:            "admin",
-			expectedMinPerms: 8,
-			expectedPerms:   []string{"users:read", "users:write", "users:delete"},
+			expectedMinPerms: 15,
+			expectedPerms:   []string{"users:read", "users:write", "users:delete", "groups:write", "audit_logs:manage"},
 		},
 		{
 			name:            "Manager role permissions",
 			role:            "manager",
-			expectedMinPerms: 4,
-			expectedPerms:   []string{"users:read", "tasks:read", "tasks:write"},
+			expectedMinPerms: 5,
+			expectedPerms:   []string{"users:read", "users:write", "tasks:read", "tasks:write"},
 		},
 		{
 			name:            "User role permissions",
@@ -402,6 +485,20 @@ func TestGetPermissionsForRole(t *testing.T) {
 	}
 }
 
+func TestGetPermissionsForRole_ReturnsCopy(t *testing.T) {
+	perms := GetPermissionsForRole("viewer")
+	perms[0] = "settings:write"
+
+	assert.Equal(t, []string{"tasks:read"}, GetPermissionsForRole("viewer"))
+}
+
+func TestIsValidPermission(t *testing.T) {
+	assert.True(t, IsValidPermission("users:read"))
+	assert.True(t, IsValidPermission("settings:write"))
+	assert.False(t, IsValidPermission("users:admin"))
+	assert.False(t, IsValidPermission(""))
+}
+
 func TestTokenRoundTrip(t *testing.T) {
 	svc := NewJWTService("test-secret", 15*time.Minute, 7*24*time.Hour)
 
@@ -67,7 +67,7 @@ func ValidationError(c *gin.Context, errors map[string]string) {
 // HandleError はAppErrorからレスポンスを生成します
 func HandleError(c *gin.Context, err error) {
 	if appErr, ok := err.(*AppError); ok {
-		Error(c, appErr.StatusCode, appErr.Code, appErr.Message, nil)
+		Error(c, appErr.StatusCode, appErr.Code, appErr.Message, appErr.Details)
 	} else {
 		Error(c, http.StatusInternalServerError, ErrCodeInternalError, "Internal server error", nil)
 	}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// リクエストヘッダー
+const (
+	HeaderSignature = "X-Effisio-Signature" // t=<UNIX時刻>,v1=<署名>
+	HeaderEvent     = "X-Effisio-Event"     // イベント種別
+	HeaderDelivery  = "X-Effisio-Delivery"  // 配信ID（再試行・再送でも同じ値）
+)
+
+// maxResponseBody は配信ログに残すレスポンス本文の最大バイト数です
+const maxResponseBody = 1024
+
+var (
+	// ErrInvalidSignature は署名が一致しないことを表します
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrSignatureExpired は署名の時刻が許容範囲外であることを表します（リプレイ攻撃の防止）
+	ErrSignatureExpired = errors.New("webhook signature timestamp is outside the tolerance")
+)
+
+// GenerateSecret は署名用のランダムなシークレットを生成します
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Sign は署名ヘッダーの値を返します
+// 署名は「UNIX時刻.本文」のHMAC-SHA256で、時刻を含めることで古いリクエストの再送を受信側で拒否できます
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeSignature(secret, ts, body)
+}
+
+// Verify は署名ヘッダーを検証します（受信側の実装例・テスト用）
+// tolerance が0より大きい場合は、now との差が tolerance を超える署名を拒否します
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	if ts == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if tolerance > 0 {
+		diff := now.Sub(time.Unix(unix, 0))
+		if diff > tolerance || diff < -tolerance {
+			return ErrSignatureExpired
+		}
+	}
+
+	expected := computeSignature(secret, ts, body)
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// computeSignature は「UNIX時刻.本文」のHMAC-SHA256を16進数で返します
+func computeSignature(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Request は送信するWebhookです
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID string
+	Body       []byte
+}
+
+// Result は送信結果です
+type Result struct {
+	StatusCode   int           // レスポンスのステータスコード（接続できなかった場合は0）
+	ResponseBody string        // レスポンス本文の先頭（配信ログ用）
+	Duration     time.Duration // 送信にかかった時間
+	Err          error         // 送信できなかった場合、または2xx以外の場合のエラー
+}
+
+// Succeeded は受信側が2xxで応答したかを返します
+func (r *Result) Succeeded() bool {
+	return r.Err == nil
+}
+
+// Sender はWebhookをHTTP POSTで送信します
+type Sender struct {
+	client    *http.Client
+	userAgent string
+	now       func() time.Time
+}
+
+// NewSender は新しいSenderを作成します
+// リダイレクト先への署名付きリクエストの転送を防ぐため、リダイレクトには従いません
+func NewSender(timeout time.Duration) *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: timeout,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		userAgent: "Effisio-Webhook/1.0",
+		now:       time.Now,
+	}
+}
+
+// Send はWebhookを送信します
+// 送信のたびに現在時刻で署名し直すため、再試行しても署名が期限切れになりません
+func (s *Sender) Send(ctx context.Context, req *Request) *Result {
+	started := s.now()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return &Result{Err: fmt.Errorf("failed to build webhook request: %w", err)}
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", s.userAgent)
+	httpReq.Header.Set(HeaderEvent, req.EventType)
+	httpReq.Header.Set(HeaderDelivery, req.DeliveryID)
+	httpReq.Header.Set(HeaderSignature, Sign(req.Secret, started, req.Body))
+
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return &Result{Duration: time.Since(started), Err: fmt.Errorf("failed to send webhook: %w", err)}
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	// 接続を再利用できるよう残りを読み捨てる
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	result := &Result{
+		StatusCode:   resp.StatusCode,
+		ResponseBody: string(body),
+		Duration:     time.Since(started),
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		result.Err = fmt.Errorf("webhook endpoint responded with status %d", resp.StatusCode)
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2024, 4, 1, 9, 0, 0, 0, time.UTC)
+	body := []byte(`{"type":"user.created"}`)
+
+	header := Sign("secret", now, body)
+
+	assert.Regexp(t, `^t=1711962000,v1=[0-9a-f]{64}$`, header)
+	assert.NoError(t, Verify("secret", header, body, 5*time.Minute, now.Add(time.Minute)))
+	assert.ErrorIs(t, Verify("other", header, body, 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"type":"user.deleted"}`), 5*time.Minute, now), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify("secret", header, body, 5*time.Minute, now.Add(10*time.Minute)), ErrSignatureExpired)
+	assert.ErrorIs(t, Verify("secret", "v1=abc", body, 0, now), ErrInvalidSignature)
+	assert.NoError(t, Verify("secret", header, body, 0, now.Add(24*time.Hour)), "tolerance 0 disables the time check")
+}
+
+func TestGenerateSecret(t *testing.T) {
+	first, err := GenerateSecret()
+	require.NoError(t, err)
+	second, err := GenerateSecret()
+	require.NoError(t, err)
+
+	assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, first)
+	assert.NotEqual(t, first, second)
+}
+
+func TestSenderSend(t *testing.T) {
+	var received *http.Request
+	var receivedBody []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		receivedBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	body := []byte(`{"id":"evt_1"}`)
+	result := NewSender(time.Second).Send(context.Background(), &Request{
+		URL:        server.URL,
+		Secret:     "secret",
+		EventType:  "user.created",
+		DeliveryID: "12",
+		Body:       body,
+	})
+
+	require.True(t, result.Succeeded(), result.Err)
+	assert.Equal(t, http.StatusAccepted, result.StatusCode)
+	assert.Equal(t, "ok", result.ResponseBody)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "user.created", received.Header.Get(HeaderEvent))
+	assert.Equal(t, "12", received.Header.Get(HeaderDelivery))
+	assert.Equal(t, body, receivedBody)
+	assert.NoError(t, Verify("secret", received.Header.Get(HeaderSignature), receivedBody, time.Minute, time.Now()))
+}
+
+func TestSenderFailures(t *testing.T) {
+	t.Run("Error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		result := NewSender(time.Second).Send(context.Background(), &Request{URL: server.URL})
+
+		assert.False(t, result.Succeeded())
+		assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+		assert.Equal(t, "maintenance\n", result.ResponseBody)
+	})
+
+	t.Run("Redirect is not followed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Redirect(w, r, "https://example.com/", http.StatusFound)
+		}))
+		defer server.Close()
+
+		result := NewSender(time.Second).Send(context.Background(), &Request{URL: server.URL})
+
+		assert.False(t, result.Succeeded())
+		assert.Equal(t, http.StatusFound, result.StatusCode)
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		result := NewSender(20*time.Millisecond).Send(context.Background(), &Request{URL: server.URL})
+
+		assert.False(t, result.Succeeded())
+		assert.Equal(t, 0, result.StatusCode)
+	})
+
+	t.Run("Connection refused", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		result := NewSender(time.Second).Send(context.Background(), &Request{URL: url})
+
+		assert.False(t, result.Succeeded())
+		assert.Equal(t, 0, result.StatusCode)
+	})
+}
@@ -62,7 +62,7 @@ export default function EditUserPage({ params }: EditUserPageProps) {
 
   const onSubmit = async (data: FormData) => {
     try {
-      await updateUserMutation.mutateAsync({ id: userId, data });
+      await updateUserMutation.mutateAsync({ id: userId, data, etag: user?.etag });
       setSuccessMessage('ユーザーを更新しました。');
       setTimeout(() => {
         router.push(`/users/${userId}`);
@@ -68,6 +68,7 @@ export default function UserDetailPage({ params }: UserDetailPageProps) {
     active: 'アクティブ',
     inactive: '非アクティブ',
     suspended: '停止中',
+    pending: '招待中',
   };
 
   return (
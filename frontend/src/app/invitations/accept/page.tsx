@@ -0,0 +1,140 @@
+'use client';
+
+import Link from 'next/link';
+import { useRouter } from 'next/navigation';
+import { useState } from 'react';
+import { useForm } from 'react-hook-form';
+
+import { usersApi } from '@/lib/users';
+
+interface AcceptInvitationPageProps {
+  searchParams: {
+    token?: string;
+  };
+}
+
+interface FormData {
+  password: string;
+  passwordConfirm: string;
+}
+
+export default function AcceptInvitationPage({ searchParams }: AcceptInvitationPageProps) {
+  const router = useRouter();
+  const token = searchParams.token ?? '';
+  const [errorMessage, setErrorMessage] = useState<string>('');
+  const [accepted, setAccepted] = useState(false);
+  const {
+    register,
+    handleSubmit,
+    getValues,
+    formState: { errors, isSubmitting },
+  } = useForm<FormData>({
+    defaultValues: {
+      password: '',
+      passwordConfirm: '',
+    },
+  });
+
+  const onSubmit = async (data: FormData) => {
+    setErrorMessage('');
+    try {
+      await usersApi.acceptInvitation({ token, password: data.password });
+      setAccepted(true);
+      setTimeout(() => {
+        router.push('/auth/login');
+      }, 1500);
+    } catch {
+      setErrorMessage('招待リンクが無効か、有効期限が切れています。管理者に再送を依頼してください。');
+    }
+  };
+
+  return (
+    <main className="flex min-h-screen items-center justify-center bg-gray-50 px-4 py-12">
+      <div className="w-full max-w-md rounded-lg bg-white p-8 shadow-md">
+        <h1 className="mb-8 text-center text-3xl font-bold text-gray-900">Effisio</h1>
+        <p className="mb-6 text-center text-sm text-gray-600">
+          パスワードを設定してアカウントを有効化します
+        </p>
+
+        {!token && (
+          <div className="mb-4 rounded-lg bg-red-50 p-4 text-sm text-red-800">
+            招待リンクが正しくありません。メールに記載されたリンクからアクセスしてください。
+          </div>
+        )}
+
+        {errorMessage && (
+          <div className="mb-4 rounded-lg bg-red-50 p-4 text-sm text-red-800">
+            {errorMessage}
+          </div>
+        )}
+
+        {accepted ? (
+          <div className="rounded-lg bg-green-50 p-4 text-sm text-green-800">
+            アカウントを有効化しました。ログイン画面に移動します。
+          </div>
+        ) : (
+          <form onSubmit={handleSubmit(onSubmit)} className="space-y-4">
+            {/* パスワード入力 */}
+            <div>
+              <label htmlFor="password" className="block text-sm font-medium text-gray-700">
+                パスワード
+              </label>
+              <input
+                {...register('password', {
+                  required: 'パスワードは必須です',
+                  minLength: { value: 8, message: 'パスワードは8文字以上で入力してください' },
+                  maxLength: { value: 72, message: 'パスワードは72文字以内で入力してください' },
+                })}
+                id="password"
+                type="password"
+                className={`mt-1 w-full rounded-lg border px-3 py-2 text-gray-900 focus:border-blue-500 focus:outline-none ${
+                  errors.password ? 'border-red-500' : 'border-gray-300'
+                }`}
+                disabled={!token || isSubmitting}
+              />
+              {errors.password && (
+                <p className="mt-1 text-sm text-red-600">{errors.password.message}</p>
+              )}
+            </div>
+
+            {/* パスワード確認 */}
+            <div>
+              <label htmlFor="passwordConfirm" className="block text-sm font-medium text-gray-700">
+                パスワード（確認）
+              </label>
+              <input
+                {...register('passwordConfirm', {
+                  required: '確認用パスワードは必須です',
+                  validate: (value) => value === getValues('password') || 'パスワードが一致しません',
+                })}
+                id="passwordConfirm"
+                type="password"
+                className={`mt-1 w-full rounded-lg border px-3 py-2 text-gray-900 focus:border-blue-500 focus:outline-none ${
+                  errors.passwordConfirm ? 'border-red-500' : 'border-gray-300'
+                }`}
+                disabled={!token || isSubmitting}
+              />
+              {errors.passwordConfirm && (
+                <p className="mt-1 text-sm text-red-600">{errors.passwordConfirm.message}</p>
+              )}
+            </div>
+
+            <button
+              type="submit"
+              disabled={!token || isSubmitting}
+              className="mt-6 w-full rounded-lg bg-blue-600 px-4 py-2 font-semibold text-white hover:bg-blue-700 focus:outline-none disabled:bg-gray-400"
+            >
+              {isSubmitting ? '設定中...' : 'パスワードを設定'}
+            </button>
+          </form>
+        )}
+
+        <div className="mt-6 text-center text-sm">
+          <Link href="/auth/login" className="text-blue-600 hover:underline">
+            ログイン画面へ
+          </Link>
+        </div>
+      </div>
+    </main>
+  );
+}
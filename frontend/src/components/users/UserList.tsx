@@ -115,14 +115,18 @@ export function UserList({ users }: UserListProps) {
                       ? 'bg-green-100 text-green-800'
                       : user.status === 'inactive'
                         ? 'bg-gray-100 text-gray-800'
-                        : 'bg-red-100 text-red-800'
+                        : user.status === 'pending'
+                          ? 'bg-yellow-100 text-yellow-800'
+                          : 'bg-red-100 text-red-800'
                   }`}
                 >
                   {user.status === 'active'
                     ? 'アクティブ'
                     : user.status === 'inactive'
                       ? '非アクティブ'
-                      : '停止中'}
+                      : user.status === 'pending'
+                        ? '招待中'
+                        : '停止中'}
                 </span>
               </td>
               <td className="whitespace-nowrap px-6 py-4 text-sm text-gray-500">
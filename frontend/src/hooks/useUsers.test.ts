@@ -266,14 +266,14 @@ describe('useUsers hooks', () => {
         wrapper: TestWrapper,
       });
 
-      result.current.mutate({ id: 1, data: updateRequest });
+      result.current.mutate({ id: 1, data: updateRequest, etag: '"1"' });
 
       await waitFor(() => {
         expect(result.current.isSuccess).toBe(true);
       });
 
       expect(result.current.data).toEqual(updatedUser);
-      expect(usersApi.updateUser).toHaveBeenCalledWith(1, updateRequest);
+      expect(usersApi.updateUser).toHaveBeenCalledWith(1, updateRequest, '"1"');
     });
 
     it('should handle update error', async () => {
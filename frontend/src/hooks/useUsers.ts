@@ -39,8 +39,8 @@ export function useUpdateUser() {
   const queryClient = useQueryClient();
 
   return useMutation({
-    mutationFn: ({ id, data }: { id: number; data: UpdateUserRequest }) =>
-      usersApi.updateUser(id, data),
+    mutationFn: ({ id, data, etag }: { id: number; data: UpdateUserRequest; etag?: string }) =>
+      usersApi.updateUser(id, data, etag),
     onSuccess: (_, variables) => {
       queryClient.invalidateQueries({ queryKey: USERS_QUERY_KEY });
       queryClient.invalidateQueries({ queryKey: [...USERS_QUERY_KEY, variables.id] });
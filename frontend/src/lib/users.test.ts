@@ -270,10 +270,12 @@ describe('usersApi', () => {
         },
       });
 
-      const result = await usersApi.updateUser(1, updateRequest);
+      const result = await usersApi.updateUser(1, updateRequest, '"3"');
 
       expect(result).toEqual(updatedUser);
-      expect(api.put).toHaveBeenCalledWith('/users/1', updateRequest);
+      expect(api.put).toHaveBeenCalledWith('/users/1', updateRequest, {
+        headers: { 'If-Match': '"3"' },
+      });
     });
 
     it('should update partial user fields', async () => {
@@ -339,4 +341,31 @@ describe('usersApi', () => {
       await expect(usersApi.deleteUser(1)).rejects.toThrow('Server error');
     });
   });
+
+  describe('acceptInvitation', () => {
+    it('should accept invitation and return activated user', async () => {
+      vi.mocked(api.post).mockResolvedValueOnce({
+        data: { code: 200, message: 'success', data: { user: mockUser } },
+      });
+
+      const result = await usersApi.acceptInvitation({
+        token: 'invitation-token',
+        password: 'SecurePassword123!',
+      });
+
+      expect(result).toEqual(mockUser);
+      expect(api.post).toHaveBeenCalledWith('/invitations/accept', {
+        token: 'invitation-token',
+        password: 'SecurePassword123!',
+      });
+    });
+
+    it('should throw error when invitation is invalid', async () => {
+      vi.mocked(api.post).mockRejectedValueOnce(new Error('Invitation is invalid'));
+
+      await expect(
+        usersApi.acceptInvitation({ token: 'expired', password: 'Password123!' })
+      ).rejects.toThrow('Invitation is invalid');
+    });
+  });
 });
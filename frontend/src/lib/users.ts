@@ -6,6 +6,7 @@ import type {
   ApiResponse,
   CreateUserRequest,
   UpdateUserRequest,
+  AcceptInvitationRequest,
 } from '@/types/user';
 
 export const usersApi = {
@@ -29,9 +30,11 @@ export const usersApi = {
     return response.data.data.user;
   },
 
-  // ユーザーを更新
-  async updateUser(id: number, data: UpdateUserRequest): Promise<User> {
-    const response = await api.put<ApiResponse<{ user: User }>>(`/users/${id}`, data);
+  // ユーザーを更新（取得時のETagをIf-Matchに指定し、同時更新を検出する）
+  async updateUser(id: number, data: UpdateUserRequest, etag?: string): Promise<User> {
+    const response = await api.put<ApiResponse<{ user: User }>>(`/users/${id}`, data, {
+      headers: etag ? { 'If-Match': etag } : undefined,
+    });
     return response.data.data.user;
   },
 
@@ -39,4 +42,10 @@ export const usersApi = {
   async deleteUser(id: number): Promise<void> {
     await api.delete(`/users/${id}`);
   },
+
+  // 招待を受諾してパスワードを設定（認証不要）
+  async acceptInvitation(data: AcceptInvitationRequest): Promise<User> {
+    const response = await api.post<ApiResponse<{ user: User }>>('/invitations/accept', data);
+    return response.data.data.user;
+  },
 };
@@ -1,15 +1,21 @@
 export type UserRole = 'admin' | 'manager' | 'user' | 'viewer';
-export type UserStatus = 'active' | 'inactive' | 'suspended';
+export type UserStatus = 'active' | 'inactive' | 'suspended' | 'pending';
 
 export interface User {
   id: number;
   username: string;
   email: string;
+  email_verified?: boolean;
+  pending_email?: string;
   full_name: string;
   department: string;
   role: UserRole;
   status: UserStatus;
+  attributes?: Record<string, string | number | boolean>;
+  account_expires_at?: string | null;
   last_login: string | null;
+  version?: number;
+  etag?: string;
   created_at: string;
   updated_at: string;
 }
@@ -21,6 +27,8 @@ export interface CreateUserRequest {
   department?: string;
   password: string;
   role: UserRole;
+  attributes?: Record<string, string | number | boolean>;
+  account_expires_at?: string;
 }
 
 export interface UpdateUserRequest {
@@ -29,6 +37,13 @@ export interface UpdateUserRequest {
   department?: string;
   role?: UserRole;
   status?: UserStatus;
+  attributes?: Record<string, string | number | boolean | null>;
+  account_expires_at?: string;
+}
+
+export interface AcceptInvitationRequest {
+  token: string;
+  password: string;
 }
 
 export interface PaginationInfo {